@@ -0,0 +1,522 @@
+package main
+
+// Backup and restore of the on-disk database as a single archive.
+//
+// A backup is a gzipped tarball containing one .gom file per schema, a .kv
+// file of the raw pairs of each schema that doesn't hold messages (system
+// addresses and the provenance of fields), and the database's state files,
+// followed by a MANIFEST that lists the checksum and item count of each file.
+// The schemas are read through pogreb's iterators, so a backup can be taken
+// while the database is in use.
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kfsone/gomenacing/pkg/gomschema"
+	"google.golang.org/protobuf/proto"
+)
+
+// BackupManifest is the name of the manifest entry within a backup archive.
+const BackupManifest = "MANIFEST"
+
+// backupVersion is the first line of a manifest, identifying the archive layout.
+const backupVersion = "gomenacing-backup 1"
+
+type backupSchema struct {
+	name       string
+	headerType gomschema.Header_Type
+	optional   bool // Older backups may not include it.
+}
+
+// backupSchemas lists the schemas captured by a backup, in the order they are written.
+var backupSchemas = []backupSchema{
+	{"commodities", gomschema.Header_CCommodity, false},
+	{"systems", gomschema.Header_CSystem, false},
+	{"facilities", gomschema.Header_CFacility, false},
+	{"listings", gomschema.Header_CListing, false},
+	{"modules", gomschema.Header_CModule, true},
+	{"ships", gomschema.Header_CShip, true},
+	{"outfitting", gomschema.Header_COutfitting, true},
+	{"shipyards", gomschema.Header_CShipyard, true},
+}
+
+// backupStores lists the schemas captured as raw key/value pairs, in the order
+// they are written.
+func backupStores() []string {
+	stores := []string{"addresses"}
+	for _, info := range backupSchemas {
+		stores = append(stores, path.Join(provenanceDir, info.name))
+	}
+	return stores
+}
+
+// backupStateFiles lists the files of database state captured by a backup,
+// when they exist.
+var backupStateFiles = []string{generationFile, syncedFile, commanderFile}
+
+// BackupEntry describes one file within a backup archive.
+type BackupEntry struct {
+	Filename string
+	Count    int
+	Checksum string
+}
+
+func (e BackupEntry) String() string {
+	return fmt.Sprintf("%s %d %s", e.Checksum, e.Count, e.Filename)
+}
+
+func parseManifest(data []byte) (map[string]BackupEntry, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	if !scanner.Scan() || scanner.Text() != backupVersion {
+		return nil, errors.New("unrecognized backup manifest")
+	}
+	entries := make(map[string]BackupEntry, len(backupSchemas))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("malformed manifest line: %s", scanner.Text())
+		}
+		count, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("malformed manifest count: %w", err)
+		}
+		entries[fields[2]] = BackupEntry{Filename: fields[2], Count: count, Checksum: fields[0]}
+	}
+	return entries, scanner.Err()
+}
+
+func writeTarEntry(archive *tar.Writer, name string, data []byte, modTime time.Time) error {
+	header := &tar.Header{Name: name, Mode: 0640, Size: int64(len(data)), ModTime: modTime}
+	if err := archive.WriteHeader(header); err != nil {
+		return err
+	}
+	_, err := archive.Write(data)
+	return err
+}
+
+// addBackupEntry writes data to the archive and the manifest as filename.
+func addBackupEntry(archive *tar.Writer, manifest io.Writer, filename string, count int, data []byte, modTime time.Time) (BackupEntry, error) {
+	checksum := sha256.Sum256(data)
+	entry := BackupEntry{Filename: filename, Count: count, Checksum: hex.EncodeToString(checksum[:])}
+	if err := writeTarEntry(archive, entry.Filename, data, modTime); err != nil {
+		return entry, err
+	}
+	_, err := fmt.Fprintln(manifest, entry)
+	return entry, err
+}
+
+// encodeStore returns every pair of a schema, each as a uvarint-prefixed key
+// and value, and how many there were.
+func encodeStore(schema *Schema) ([]byte, int, error) {
+	var data bytes.Buffer
+	count := 0
+	lengthBuf := make([]byte, binary.MaxVarintLen64)
+	err := schema.Iterate(func(key, value []byte) error {
+		for _, field := range [][]byte{key, value} {
+			data.Write(lengthBuf[:binary.PutUvarint(lengthBuf, uint64(len(field)))])
+			data.Write(field)
+		}
+		count++
+		return nil
+	})
+	return data.Bytes(), count, err
+}
+
+// BackupDatabase writes every schema of db into a single archive at pathname.
+// The archive is written to a temporary file first and only renamed into
+// place once it is complete.
+func BackupDatabase(db *Database, pathname string) (entries []BackupEntry, err error) {
+	tmpPath := pathname + ".tmp"
+	file, err := os.Create(tmpPath)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			_ = file.Close()
+			_ = os.Remove(tmpPath)
+		}
+	}()
+
+	compressor := gzip.NewWriter(file)
+	archive := tar.NewWriter(compressor)
+	now := time.Now()
+
+	manifest := bytes.NewBufferString(backupVersion + "\n")
+	for _, info := range backupSchemas {
+		var schema *Schema
+		if schema, err = db.GetSchema(info.name); err != nil {
+			return nil, err
+		}
+		writer := gomschema.NewGOMWriter(info.headerType, "backup")
+		err = schema.Iterate(func(_, value []byte) error {
+			writer.AddData(value)
+			return nil
+		})
+		if closeErr := schema.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", info.name, err)
+		}
+
+		var data bytes.Buffer
+		if _, err = writer.WriteTo(&data); err != nil {
+			return nil, err
+		}
+		var entry BackupEntry
+		if entry, err = addBackupEntry(archive, manifest, info.name+".gom", writer.Count(), data.Bytes(), now); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	for _, name := range backupStores() {
+		var schema *Schema
+		if schema, err = db.GetSchema(name); err != nil {
+			return nil, err
+		}
+		data, count, iterErr := encodeStore(schema)
+		if err = schema.Close(); iterErr != nil {
+			err = iterErr
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		var entry BackupEntry
+		if entry, err = addBackupEntry(archive, manifest, name+".kv", count, data, now); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	for _, filename := range backupStateFiles {
+		var data []byte
+		if data, err = ioutil.ReadFile(filepath.Join(db.Path(), filename)); os.IsNotExist(err) {
+			err = nil
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		var entry BackupEntry
+		if entry, err = addBackupEntry(archive, manifest, filename, 1, data, now); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	if err = writeTarEntry(archive, BackupManifest, manifest.Bytes(), now); err != nil {
+		return nil, err
+	}
+	if err = archive.Close(); err != nil {
+		return nil, err
+	}
+	if err = compressor.Close(); err != nil {
+		return nil, err
+	}
+	if err = file.Close(); err != nil {
+		return nil, err
+	}
+	if err = os.Rename(tmpPath, pathname); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// restoreSchemaFile loads one .gom entry of an archive into a schema of the staging database.
+func restoreSchemaFile(staging *Database, info backupSchema, source io.Reader) (BackupEntry, error) {
+	entry := BackupEntry{Filename: info.name + ".gom"}
+	hash := sha256.New()
+	tee := io.TeeReader(source, hash)
+
+	gomFile, err := gomschema.OpenGOMFile(tee)
+	if err != nil {
+		return entry, fmt.Errorf("%s: %w", entry.Filename, err)
+	}
+	defer gomFile.Close()
+	if gomFile.Header().HeaderType != info.headerType {
+		return entry, fmt.Errorf("%s: contains %s messages", entry.Filename, gomFile.Header().HeaderType)
+	}
+
+	schema, err := staging.GetSchema(info.name)
+	if err != nil {
+		return entry, err
+	}
+	err = gomFile.Read(func(message proto.Message, _ uint) error {
+		entry.Count++
+		return writeMessageForId(message, schema)
+	})
+	if closeErr := schema.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return entry, fmt.Errorf("%s: %w", entry.Filename, err)
+	}
+
+	// Anything trailing the messages still counts towards the checksum.
+	if _, err = io.Copy(ioutil.Discard, tee); err != nil {
+		return entry, err
+	}
+	entry.Checksum = hex.EncodeToString(hash.Sum(nil))
+	return entry, nil
+}
+
+// restoreStoreFile loads one .kv entry of an archive into a schema of the staging database.
+func restoreStoreFile(staging *Database, name string, source io.Reader) (BackupEntry, error) {
+	entry := BackupEntry{Filename: name + ".kv"}
+	hash := sha256.New()
+	reader := bufio.NewReader(io.TeeReader(source, hash))
+
+	schema, err := staging.GetSchema(name)
+	if err != nil {
+		return entry, err
+	}
+	readField := func() ([]byte, error) {
+		length, err := binary.ReadUvarint(reader)
+		if err != nil {
+			return nil, err
+		}
+		field := make([]byte, length)
+		_, err = io.ReadFull(reader, field)
+		return field, err
+	}
+	for {
+		var key, value []byte
+		if key, err = readField(); err == io.EOF {
+			err = nil
+			break
+		}
+		if err == nil {
+			value, err = readField()
+		}
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		if err == nil {
+			err = schema.Put(key, value)
+		}
+		if err != nil {
+			break
+		}
+		entry.Count++
+	}
+	if closeErr := schema.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return entry, fmt.Errorf("%s: %w", entry.Filename, err)
+	}
+	entry.Checksum = hex.EncodeToString(hash.Sum(nil))
+	return entry, nil
+}
+
+// restoreStateFile copies one state file of an archive into the staging database.
+func restoreStateFile(staging *Database, filename string, source io.Reader) (BackupEntry, error) {
+	entry := BackupEntry{Filename: filename, Count: 1}
+	data, err := ioutil.ReadAll(source)
+	if err == nil {
+		err = ioutil.WriteFile(filepath.Join(staging.Path(), filename), data, 0640)
+	}
+	if err != nil {
+		return entry, fmt.Errorf("%s: %w", filename, err)
+	}
+	checksum := sha256.Sum256(data)
+	entry.Checksum = hex.EncodeToString(checksum[:])
+	return entry, nil
+}
+
+// validateRestore confirms that everything the manifest lists was restored
+// intact, and that it lists every schema a backup must have.
+func validateRestore(manifest map[string]BackupEntry, restored map[string]BackupEntry) error {
+	if manifest == nil {
+		return fmt.Errorf("archive has no %s", BackupManifest)
+	}
+	for _, info := range backupSchemas {
+		filename := info.name + ".gom"
+		if _, listed := manifest[filename]; !listed && !info.optional {
+			return fmt.Errorf("%s: missing from manifest", filename)
+		}
+	}
+	filenames := make([]string, 0, len(manifest)+len(restored))
+	for filename := range manifest {
+		filenames = append(filenames, filename)
+	}
+	for filename := range restored {
+		if _, listed := manifest[filename]; !listed {
+			filenames = append(filenames, filename)
+		}
+	}
+	sort.Strings(filenames)
+	for _, filename := range filenames {
+		expected, listed := manifest[filename]
+		actual, present := restored[filename]
+		switch {
+		case !listed:
+			return fmt.Errorf("%s: missing from manifest", filename)
+		case !present:
+			return fmt.Errorf("%s: missing from archive", filename)
+		case expected.Checksum != actual.Checksum:
+			return fmt.Errorf("%s: checksum mismatch", filename)
+		case expected.Count != actual.Count:
+			return fmt.Errorf("%s: expected %d items, found %d", filename, expected.Count, actual.Count)
+		}
+	}
+	return nil
+}
+
+// RestoreDatabase replaces the contents of db with the archive at pathname.
+// The archive is unpacked into a staging database alongside db and is only
+// swapped in once every file has been read and checked against the manifest.
+// The restored generation is moved past both the archived and current ones,
+// since whatever was derived from either is stale.
+func RestoreDatabase(db *Database, pathname string) (entries []BackupEntry, err error) {
+	file, err := os.Open(pathname)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = file.Close() }()
+
+	decompressor, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", pathname, err)
+	}
+	archive := tar.NewReader(decompressor)
+
+	staging := &Database{storePath: db.Path() + ".restore"}
+	if err = os.RemoveAll(staging.Path()); err != nil {
+		return nil, err
+	}
+	if _, err = ensureDirectory(staging.Path()); err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			_ = os.RemoveAll(staging.Path())
+		}
+	}()
+
+	var manifest map[string]BackupEntry
+	restored := make(map[string]BackupEntry, len(backupSchemas))
+	for {
+		var header *tar.Header
+		header, err = archive.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", pathname, err)
+		}
+		if header.Name == BackupManifest {
+			var data []byte
+			if data, err = ioutil.ReadAll(archive); err == nil {
+				manifest, err = parseManifest(data)
+			}
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		var entry BackupEntry
+		if entry, err = restoreArchiveEntry(staging, header.Name, archive); err != nil {
+			return nil, err
+		}
+		restored[entry.Filename] = entry
+	}
+
+	if err = validateRestore(manifest, restored); err != nil {
+		return nil, err
+	}
+	if err = restoreGeneration(db, staging); err != nil {
+		return nil, err
+	}
+
+	// Swap the staging database in, keeping the original until we know that worked.
+	previous := db.Path() + ".old"
+	if err = os.RemoveAll(previous); err != nil {
+		return nil, err
+	}
+	if err = os.Rename(db.Path(), previous); err != nil {
+		return nil, err
+	}
+	if err = os.Rename(staging.Path(), db.Path()); err != nil {
+		_ = os.Rename(previous, db.Path())
+		return nil, err
+	}
+	if err = os.RemoveAll(previous); err != nil {
+		return nil, err
+	}
+
+	var filenames []string
+	for _, info := range backupSchemas {
+		filenames = append(filenames, info.name+".gom")
+	}
+	for _, name := range backupStores() {
+		filenames = append(filenames, name+".kv")
+	}
+	filenames = append(filenames, backupStateFiles...)
+	for _, filename := range filenames {
+		if entry, present := restored[filename]; present {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+// restoreArchiveEntry unpacks the archive entry called name into the staging database.
+func restoreArchiveEntry(staging *Database, name string, source io.Reader) (BackupEntry, error) {
+	for _, info := range backupSchemas {
+		if info.name+".gom" == name {
+			return restoreSchemaFile(staging, info, source)
+		}
+	}
+	for _, store := range backupStores() {
+		if store+".kv" == name {
+			return restoreStoreFile(staging, store, source)
+		}
+	}
+	for _, filename := range backupStateFiles {
+		if filename == name {
+			return restoreStateFile(staging, filename, source)
+		}
+	}
+	return BackupEntry{}, fmt.Errorf("%w: archive entry: %s", ErrUnknownEntity, name)
+}
+
+// restoreGeneration sets the generation of staging past both its own, as
+// restored, and that of db.
+func restoreGeneration(db *Database, staging *Database) error {
+	current, err := db.Generation()
+	if err != nil {
+		return err
+	}
+	restored, err := staging.Generation()
+	if err != nil {
+		return err
+	}
+	if restored > current {
+		current = restored
+	}
+	data := []byte(strconv.FormatUint(current+1, 10) + "\n")
+	return ioutil.WriteFile(filepath.Join(staging.Path(), generationFile), data, 0640)
+}
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/kfsone/gomenacing/pkg/gomschema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func populateTestDatabase(t *testing.T, db *Database, messages ...proto.Message) {
+	for _, message := range messages {
+		schema, err := getSchemaForMessage(db, message)
+		require.Nil(t, err)
+		require.Nil(t, writeMessageForId(message, schema))
+		require.Nil(t, schema.Close())
+	}
+}
+
+func countSchema(t *testing.T, db *Database, name string) uint32 {
+	schema, err := db.GetSchema(name)
+	require.Nil(t, err)
+	defer func() { failOnError(schema.Close()) }()
+	return schema.Count()
+}
+
+func Test_parseManifest(t *testing.T) {
+	entries, err := parseManifest([]byte(backupVersion + "\nabcd 3 systems.gom\n\n0123 0 listings.gom\n"))
+	require.Nil(t, err)
+	assert.Equal(t, map[string]BackupEntry{
+		"systems.gom":  {Filename: "systems.gom", Count: 3, Checksum: "abcd"},
+		"listings.gom": {Filename: "listings.gom", Count: 0, Checksum: "0123"},
+	}, entries)
+
+	_, err = parseManifest([]byte("not a manifest\n"))
+	assert.Error(t, err)
+	_, err = parseManifest([]byte(backupVersion + "\nabcd systems.gom\n"))
+	assert.Error(t, err)
+	_, err = parseManifest([]byte(backupVersion + "\nabcd three systems.gom\n"))
+	assert.Error(t, err)
+}
+
+func TestBackupDatabase(t *testing.T) {
+	testDir := GetTestDir()
+	defer testDir.Close()
+
+	db, err := OpenDatabase(testDir.Path(), "source.db")
+	require.Nil(t, err)
+	populateTestDatabase(t, db,
+		&gomschema.Commodity{Id: 1, Name: "Gold", CategoryId: gomschema.Commodity_CatMetals},
+		&gomschema.System{Id: 10, Name: "Sol", Position: &gomschema.Coordinate{}},
+		&gomschema.System{Id: 11, Name: "Lave", Position: &gomschema.Coordinate{X: 1}},
+		&gomschema.Facility{Id: 100, SystemId: 10, Name: "Abraham Lincoln"},
+		&gomschema.FacilityListing{Id: 100, Listings: []*gomschema.CommodityListing{{CommodityId: 1, SupplyUnits: 5}}},
+		&gomschema.Module{Id: 1, Name: "5A Frame Shift Drive"},
+		&gomschema.FacilityOutfitting{Id: 100, ModuleIds: []uint32{1}},
+	)
+
+	archivePath := filepath.Join(testDir.Path(), "menace.bak")
+	entries, err := BackupDatabase(db, archivePath)
+	require.Nil(t, err)
+	assert.FileExists(t, archivePath)
+	assert.NoFileExists(t, archivePath+".tmp")
+	// The schemas, their stores, and the generation file; there's no synced or commander state.
+	if assert.Len(t, entries, len(backupSchemas)+len(backupStores())+1) {
+		assert.Equal(t, "commodities.gom", entries[0].Filename)
+		assert.Equal(t, 1, entries[0].Count)
+		assert.Equal(t, "systems.gom", entries[1].Filename)
+		assert.Equal(t, 2, entries[1].Count)
+		assert.Equal(t, 1, entries[2].Count)
+		assert.Equal(t, 1, entries[3].Count)
+		assert.Equal(t, "modules.gom", entries[4].Filename)
+		assert.Equal(t, 1, entries[4].Count)
+		assert.Equal(t, 0, entries[5].Count)
+		assert.Equal(t, 1, entries[6].Count)
+		assert.Equal(t, 0, entries[7].Count)
+		assert.Equal(t, "addresses.kv", entries[8].Filename)
+		assert.Equal(t, "provenance/commodities.kv", entries[9].Filename)
+		assert.Equal(t, generationFile, entries[len(entries)-1].Filename)
+	}
+
+	t.Run("Restore into a different database", func(t *testing.T) {
+		target, err := OpenDatabase(testDir.Path(), "target.db")
+		require.Nil(t, err)
+		populateTestDatabase(t, target, &gomschema.System{Id: 99, Name: "Leftover", Position: &gomschema.Coordinate{}})
+
+		restored, err := RestoreDatabase(target, archivePath)
+		require.Nil(t, err)
+		assert.Equal(t, entries, restored)
+		assert.NoDirExists(t, target.Path()+".restore")
+		assert.NoDirExists(t, target.Path()+".old")
+		assert.EqualValues(t, 1, countSchema(t, target, "commodities"))
+		assert.EqualValues(t, 2, countSchema(t, target, "systems"))
+		assert.EqualValues(t, 1, countSchema(t, target, "facilities"))
+		assert.EqualValues(t, 1, countSchema(t, target, "listings"))
+
+		sdb := NewSystemDatabase(target)
+		require.Nil(t, target.LoadDatabase(sdb))
+		assert.NotNil(t, sdb.GetSystem("Lave"))
+		assert.Nil(t, sdb.GetSystem("Leftover"))
+		assert.True(t, sdb.GetFacilityByID(100).Outfitting.Has(1))
+	})
+
+	t.Run("Corrupt archives are rejected", func(t *testing.T) {
+		target, err := OpenDatabase(testDir.Path(), "corrupt.db")
+		require.Nil(t, err)
+		populateTestDatabase(t, target, &gomschema.System{Id: 99, Name: "Keeper", Position: &gomschema.Coordinate{}})
+
+		data, err := ioutil.ReadFile(archivePath)
+		require.Nil(t, err)
+		corruptPath := filepath.Join(testDir.Path(), "corrupt.bak")
+		require.Nil(t, ioutil.WriteFile(corruptPath, data[:len(data)/2], 0640))
+
+		_, err = RestoreDatabase(target, corruptPath)
+		assert.Error(t, err)
+		assert.NoDirExists(t, target.Path()+".restore")
+		assert.EqualValues(t, 1, countSchema(t, target, "systems"))
+		assert.EqualValues(t, 0, countSchema(t, target, "commodities"))
+	})
+
+	t.Run("Missing archive", func(t *testing.T) {
+		_, err := RestoreDatabase(db, filepath.Join(testDir.Path(), "nonesuch.bak"))
+		assert.Error(t, err)
+	})
+}
+
+func TestRestoreDatabase_keepsState(t *testing.T) {
+	testDir := GetTestDir()
+	defer testDir.Close()
+
+	db, err := OpenDatabase(testDir.Path(), "state.db")
+	require.Nil(t, err)
+	sdb := NewSystemDatabase(db)
+	writer := newMessageWriter(sdb, db, "eddn")
+	require.Nil(t, writer.apply(&gomschema.System{Id: 1, Name: "Sol", TimestampUtc: 100, Position: &gomschema.Coordinate{}}))
+	writer.Close()
+	addresses, err := db.SystemAddresses()
+	require.Nil(t, err)
+	require.Nil(t, addresses.Put(addressKey(10477373803), addressValue(1)))
+	require.Nil(t, addresses.Close())
+	require.Nil(t, db.setSyncedTo(100))
+	commander := NewCommander(CommanderPath(db))
+	commander.SystemID, commander.CreditsCr = 1, 1000
+	require.Nil(t, commander.Save())
+
+	archivePath := filepath.Join(testDir.Path(), "state.bak")
+	entries, err := BackupDatabase(db, archivePath)
+	require.Nil(t, err)
+	filenames := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		filenames = append(filenames, entry.Filename)
+	}
+	assert.Contains(t, filenames, syncedFile)
+	assert.Contains(t, filenames, commanderFile)
+
+	// Move on from the backup, then go back to it.
+	require.Nil(t, db.setSyncedTo(200))
+	commander.CreditsCr = 5
+	require.Nil(t, commander.Save())
+	for i := 0; i < 3; i++ {
+		require.Nil(t, db.bumpGeneration())
+	}
+	before, err := db.Generation()
+	require.Nil(t, err)
+
+	_, err = RestoreDatabase(db, archivePath)
+	require.Nil(t, err)
+	synced, err := db.SyncedTo()
+	require.Nil(t, err)
+	assert.EqualValues(t, 100, synced)
+	restoredCommander, err := LoadCommander(CommanderPath(db))
+	require.Nil(t, err)
+	assert.EqualValues(t, 1000, restoredCommander.CreditsCr)
+	generation, err := db.Generation()
+	require.Nil(t, err)
+	assert.Greater(t, generation, before)
+
+	record, err := db.GetProvenance("systems", 1)
+	require.Nil(t, err)
+	assert.Equal(t, map[string]Provenance{"": {"eddn", 100}}, record)
+	addresses, err = db.SystemAddresses()
+	require.Nil(t, err)
+	value, err := addresses.Get(addressKey(10477373803))
+	require.Nil(t, addresses.Close())
+	require.Nil(t, err)
+	assert.Equal(t, addressValue(1), value)
+}
+
+func Test_validateRestore(t *testing.T) {
+	full := make(map[string]BackupEntry, len(backupSchemas))
+	for _, info := range backupSchemas {
+		filename := info.name + ".gom"
+		full[filename] = BackupEntry{Filename: filename, Count: 1, Checksum: "abcd"}
+	}
+	assert.Nil(t, validateRestore(full, full))
+	assert.Error(t, validateRestore(nil, full))
+
+	// Backups from before the optional schemas existed can still be restored.
+	older := make(map[string]BackupEntry, len(full))
+	for filename, entry := range full {
+		older[filename] = entry
+	}
+	delete(older, "shipyards.gom")
+	assert.Nil(t, validateRestore(older, older))
+
+	// But not if a required schema is missing.
+	delete(older, "listings.gom")
+	assert.Error(t, validateRestore(older, older))
+
+	// Or the archive and manifest disagree.
+	assert.Error(t, validateRestore(full, older))
+	extra := map[string]BackupEntry{syncedFile: {Filename: syncedFile, Count: 1, Checksum: "abcd"}}
+	for filename, entry := range full {
+		extra[filename] = entry
+	}
+	assert.Error(t, validateRestore(full, extra))
+	assert.Nil(t, validateRestore(extra, extra))
+}
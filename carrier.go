@@ -0,0 +1,240 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+
+	gom "github.com/kfsone/gomenacing/pkg/gomschema"
+)
+
+const (
+	// CarrierJumpRange is the furthest a fleet carrier can jump, in ly.
+	CarrierJumpRange = 500.0
+	// CarrierCapacity is the tonnage available for cargo on a fleet carrier.
+	CarrierCapacity = 25000
+	// carrierBaseMass is the mass-equivalent of the carrier itself when costing a jump.
+	carrierBaseMass = 25000
+)
+
+// ErrCarrierCapacity represents an order or load that doesn't fit on a carrier.
+var ErrCarrierCapacity = errors.New("insufficient carrier capacity")
+
+// TritiumForJump is the tritium, in tons, burned by a carrier jumping
+// distanceLy while carrying load tons (including the tritium itself).
+func TritiumForJump(distanceLy float64, load int) int {
+	return int(math.Ceil(5 + distanceLy*float64(carrierBaseMass+load)/200000))
+}
+
+// CarrierOrder is an order on a carrier's market.
+type CarrierOrder struct {
+	CommodityID EntityID
+	Units       int
+	PriceCr     uint32
+}
+
+// Carrier tracks a fleet carrier's hold and market orders. It's kept with
+// the commander so that it survives restarts.
+type Carrier struct {
+	Capacity   int // Tons of cargo space.
+	Tritium    int // Tons of tritium in the hold.
+	Cargo      map[EntityID]int
+	BuyOrders  map[EntityID]CarrierOrder
+	SellOrders map[EntityID]CarrierOrder
+}
+
+// NewCarrier constructs an empty carrier with full capacity.
+func NewCarrier() *Carrier {
+	return &Carrier{
+		Capacity:   CarrierCapacity,
+		Cargo:      make(map[EntityID]int),
+		BuyOrders:  make(map[EntityID]CarrierOrder),
+		SellOrders: make(map[EntityID]CarrierOrder),
+	}
+}
+
+// Load is the tons in the hold, including tritium.
+func (c *Carrier) Load() int {
+	load := c.Tritium
+	for _, units := range c.Cargo {
+		load += units
+	}
+	return load
+}
+
+// SetCargo replaces the hold, trimming sell orders to what's left in it.
+func (c *Carrier) SetCargo(cargo map[EntityID]int) {
+	c.Cargo = cargo
+	for commodityID, order := range c.SellOrders {
+		if held := cargo[commodityID]; held <= 0 {
+			delete(c.SellOrders, commodityID)
+		} else if order.Units > held {
+			order.Units = held
+			c.SellOrders[commodityID] = order
+		}
+	}
+}
+
+// FreeSpace is the tons left once the hold and outstanding buy orders are accounted for.
+func (c *Carrier) FreeSpace() int {
+	free := c.Capacity - c.Load()
+	for _, order := range c.BuyOrders {
+		free -= order.Units
+	}
+	return free
+}
+
+// SetBuyOrder places or replaces an order for the carrier to buy units of
+// commodity at priceCr each. Zero units cancels the order.
+func (c *Carrier) SetBuyOrder(commodity *Commodity, units int, priceCr uint32) error {
+	previous := c.BuyOrders[commodity.ID].Units
+	if units <= 0 {
+		delete(c.BuyOrders, commodity.ID)
+		return nil
+	}
+	if units-previous > c.FreeSpace() {
+		return fmt.Errorf("%w: buy %d %s: %d free", ErrCarrierCapacity, units, commodity.Name(), c.FreeSpace()+previous)
+	}
+	c.BuyOrders[commodity.ID] = CarrierOrder{CommodityID: commodity.ID, Units: units, PriceCr: priceCr}
+	return nil
+}
+
+// SetSellOrder places or replaces an order for the carrier to sell units of
+// commodity from its hold at priceCr each. Zero units cancels the order.
+func (c *Carrier) SetSellOrder(commodity *Commodity, units int, priceCr uint32) error {
+	if units <= 0 {
+		delete(c.SellOrders, commodity.ID)
+		return nil
+	}
+	if units > c.Cargo[commodity.ID] {
+		return fmt.Errorf("%w: sell %d %s: %d in hold", ErrCarrierCapacity, units, commodity.Name(), c.Cargo[commodity.ID])
+	}
+	c.SellOrders[commodity.ID] = CarrierOrder{CommodityID: commodity.ID, Units: units, PriceCr: priceCr}
+	return nil
+}
+
+// CarrierJump is one leg of a carrier route.
+type CarrierJump struct {
+	System     *System
+	DistanceLy float64
+	Tritium    int
+}
+
+// CarrierRoute is a sequence of carrier jumps.
+type CarrierRoute struct {
+	Jumps      []CarrierJump
+	DistanceLy float64
+	Tritium    int
+}
+
+// PlanCarrierRoute plots jumps from one system to another carrying load tons,
+// each to the known system within CarrierJumpRange that is closest to the
+// destination. Tritium burned along the way lightens the load.
+func (sdb *SystemDatabase) PlanCarrierRoute(from, to *System, load int) (*CarrierRoute, error) {
+	route := &CarrierRoute{}
+	current := from
+	for current != to {
+		next, remaining := current, Distance(current, to)
+		if remaining <= NewSquareFloat(CarrierJumpRange) {
+			next = to
+		} else {
+			_, err := sdb.getSystemsWithinRange(current, CarrierJumpRange, func(candidate *System, _ SquareFloat) bool {
+				if distSq := Distance(candidate, to); distSq < remaining {
+					next, remaining = candidate, distSq
+				}
+				return true
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+		if next == current {
+			return nil, fmt.Errorf("%w: %s to %s: stranded at %s", ErrNoRoute, from.Name(), to.Name(), current.Name())
+		}
+		distance := Distance(current, next).Root()
+		tritium := TritiumForJump(distance, load)
+		route.Jumps = append(route.Jumps, CarrierJump{System: next, DistanceLy: distance, Tritium: tritium})
+		route.DistanceLy += distance
+		route.Tritium += tritium
+		if load -= tritium; load < 0 {
+			load = 0
+		}
+		current = next
+	}
+	return route, nil
+}
+
+// CarrierSetup suggests parking a carrier beside a station with high demand
+// for a commodity, stocked from a nearby source.
+type CarrierSetup struct {
+	Source  *Facility
+	Station *Facility
+	Outcome *TradeOutcome
+}
+
+// SuggestCarrierSetups pairs each high-demand market within rangeLy of origin
+// with the cheapest source of that commodity in the same range, best credits
+// per hour first for a carrier load of capacity tons. Carriers are ignored on either side
+// since they don't stay put.
+func (sdb *SystemDatabase) SuggestCarrierSetups(origin *System, rangeLy float64, capacity int, now uint64) ([]*CarrierSetup, error) {
+	type market struct {
+		facility *Facility
+		listing  *Listing
+	}
+	sources := make(map[EntityID]market)
+	buyers := make(map[EntityID][]market)
+	_, err := sdb.getSystemsWithinRange(origin, rangeLy, func(system *System, _ SquareFloat) bool {
+		for _, facility := range system.facilities {
+			if facility.IsCarrier() {
+				continue
+			}
+			for commodityID, listing := range facility.listings {
+				if sdb.isStale(listing.TimestampUtc, now) {
+					continue
+				}
+				if listing.StationAsks > 0 && listing.SupplyBracket != gom.MarketBracket_BracketNone {
+					if best, exists := sources[commodityID]; !exists || listing.StationAsks < best.listing.StationAsks {
+						sources[commodityID] = market{facility, listing}
+					}
+				}
+				if listing.StationPays > 0 && listing.DemandBracket == gom.MarketBracket_BracketHigh {
+					buyers[commodityID] = append(buyers[commodityID], market{facility, listing})
+				}
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	setups := make([]*CarrierSetup, 0, len(buyers))
+	for commodityID, stations := range buyers {
+		source, exists := sources[commodityID]
+		commodity := sdb.GetCommodityByID(commodityID)
+		if !exists || commodity == nil {
+			continue
+		}
+		for _, station := range stations {
+			if station.facility == source.facility {
+				continue
+			}
+			if outcome := NewTradeOutcome(commodity, source.listing, station.listing, now); outcome != nil {
+				outcome.TravelTime = sdb.travel.Estimate(source.facility, station.facility).Total()
+				setups = append(setups, &CarrierSetup{Source: source.facility, Station: station.facility, Outcome: outcome})
+			}
+		}
+	}
+	sort.SliceStable(setups, func(i, j int) bool {
+		lhs, rhs := setups[i].Outcome.rate(capacity), setups[j].Outcome.rate(capacity)
+		if lhs != rhs {
+			return lhs > rhs
+		}
+		if setups[i].Station.ID != setups[j].Station.ID {
+			return setups[i].Station.ID < setups[j].Station.ID
+		}
+		return setups[i].Outcome.Commodity.ID < setups[j].Outcome.Commodity.ID
+	})
+	return setups, nil
+}
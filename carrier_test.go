@@ -0,0 +1,214 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	gom "github.com/kfsone/gomenacing/pkg/gomschema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTritiumForJump(t *testing.T) {
+	assert.Equal(t, 68, TritiumForJump(CarrierJumpRange, 0))
+	assert.Equal(t, 130, TritiumForJump(CarrierJumpRange, CarrierCapacity))
+	assert.Equal(t, 6, TritiumForJump(1, 0))
+}
+
+func TestCarrier_Orders(t *testing.T) {
+	gold := &Commodity{DbEntity: DbEntity{ID: 1, DbName: "Gold"}}
+	tritium := &Commodity{DbEntity: DbEntity{ID: 2, DbName: "Tritium"}}
+
+	carrier := NewCarrier()
+	carrier.Tritium = 1000
+	carrier.Cargo[gold.ID] = 4000
+	assert.Equal(t, 5000, carrier.Load())
+	assert.Equal(t, 20000, carrier.FreeSpace())
+
+	assert.Nil(t, carrier.SetBuyOrder(tritium, 15000, 50000))
+	assert.Equal(t, 5000, carrier.FreeSpace())
+	// Replacing an order only needs room for the difference.
+	assert.Nil(t, carrier.SetBuyOrder(tritium, 20000, 50000))
+	err := carrier.SetBuyOrder(tritium, 20001, 50000)
+	assert.True(t, errors.Is(err, ErrCarrierCapacity))
+	assert.Nil(t, carrier.SetBuyOrder(tritium, 0, 0))
+	assert.Empty(t, carrier.BuyOrders)
+
+	assert.Nil(t, carrier.SetSellOrder(gold, 4000, 60000))
+	err = carrier.SetSellOrder(gold, 4001, 60000)
+	assert.True(t, errors.Is(err, ErrCarrierCapacity))
+	assert.Len(t, carrier.SellOrders, 1)
+
+	carrier.SetCargo(map[EntityID]int{gold.ID: 1000})
+	assert.Equal(t, 1000, carrier.SellOrders[gold.ID].Units)
+	carrier.SetCargo(map[EntityID]int{})
+	assert.Empty(t, carrier.SellOrders)
+}
+
+func TestSystemDatabase_PlanCarrierRoute(t *testing.T) {
+	sdb := NewSystemDatabase(nil)
+	require.Nil(t, sdb.newSystem(&gom.System{Id: 1, Name: "Sol", Position: &gom.Coordinate{}}))
+	require.Nil(t, sdb.newSystem(&gom.System{Id: 2, Name: "Waypoint One", Position: &gom.Coordinate{X: 400}}))
+	require.Nil(t, sdb.newSystem(&gom.System{Id: 3, Name: "Waypoint Two", Position: &gom.Coordinate{X: 800}}))
+	require.Nil(t, sdb.newSystem(&gom.System{Id: 4, Name: "Colonia", Position: &gom.Coordinate{X: 1200}}))
+	require.Nil(t, sdb.newSystem(&gom.System{Id: 5, Name: "Beagle Point", Position: &gom.Coordinate{X: 9000}}))
+	sol, colonia := sdb.GetSystem("Sol"), sdb.GetSystem("Colonia")
+
+	route, err := sdb.PlanCarrierRoute(sol, colonia, 0)
+	require.Nil(t, err)
+	require.Len(t, route.Jumps, 3)
+	assert.Equal(t, "Waypoint One", route.Jumps[0].System.DbName)
+	assert.Equal(t, "Colonia", route.Jumps[2].System.DbName)
+	assert.InDelta(t, 1200, route.DistanceLy, 0.001)
+	assert.Equal(t, 3*TritiumForJump(400, 0), route.Tritium)
+
+	// Carrying cargo costs more tritium.
+	loaded, err := sdb.PlanCarrierRoute(sol, colonia, CarrierCapacity)
+	require.Nil(t, err)
+	assert.Greater(t, loaded.Tritium, route.Tritium)
+
+	_, err = sdb.PlanCarrierRoute(sol, sdb.GetSystem("Beagle Point"), 0)
+	assert.True(t, errors.Is(err, ErrNoRoute))
+
+	route, err = sdb.PlanCarrierRoute(sol, sol, 0)
+	require.Nil(t, err)
+	assert.Empty(t, route.Jumps)
+}
+
+func TestSystemDatabase_SuggestCarrierSetups(t *testing.T) {
+	sdb := NewSystemDatabase(nil)
+	require.Nil(t, sdb.newSystem(&gom.System{Id: 1, Name: "Sol", Position: &gom.Coordinate{}}))
+	require.Nil(t, sdb.newSystem(&gom.System{Id: 6, Name: "Alpha Centauri", Position: &gom.Coordinate{Y: 4.4}}))
+	require.Nil(t, sdb.newCommodity(&gom.Commodity{Id: 1, Name: "Gold"}))
+	require.Nil(t, sdb.newCommodity(&gom.Commodity{Id: 2, Name: "Tritium"}))
+	require.Nil(t, sdb.newFacility(&gom.Facility{Id: 1, SystemId: 1, Name: "Galileo"}))
+	require.Nil(t, sdb.newFacility(&gom.Facility{Id: 2, SystemId: 6, Name: "Hutton Orbital"}))
+	require.Nil(t, sdb.newFacility(&gom.Facility{Id: 3, SystemId: 6, Name: "K7Q-BQL", FacilityType: gom.FacilityType_FTFleetCarrier}))
+	require.Nil(t, sdb.newListings(&gom.FacilityListing{Id: 1, Listings: []*gom.CommodityListing{
+		{CommodityId: 1, SupplyUnits: 5000, SupplyCredits: 9000, SupplyBracket: gom.MarketBracket_BracketHigh},
+		{CommodityId: 2, DemandUnits: 5000, DemandCredits: 40000, DemandBracket: gom.MarketBracket_BracketHigh},
+	}}))
+	require.Nil(t, sdb.newListings(&gom.FacilityListing{Id: 2, Listings: []*gom.CommodityListing{
+		{CommodityId: 1, DemandUnits: 20000, DemandCredits: 11000, DemandBracket: gom.MarketBracket_BracketHigh},
+	}}))
+	// A carrier selling tritium cheaply shouldn't be suggested as a source.
+	require.Nil(t, sdb.newListings(&gom.FacilityListing{Id: 3, Listings: []*gom.CommodityListing{
+		{CommodityId: 2, SupplyUnits: 5000, SupplyCredits: 20000, SupplyBracket: gom.MarketBracket_BracketHigh},
+	}}))
+
+	setups, err := sdb.SuggestCarrierSetups(sdb.GetSystem("Sol"), 10, CarrierCapacity, 0)
+	require.Nil(t, err)
+	require.Len(t, setups, 1)
+	assert.Equal(t, "Hutton Orbital", setups[0].Station.DbName)
+	assert.Equal(t, "Galileo", setups[0].Source.DbName)
+	assert.Equal(t, "Gold", setups[0].Outcome.Commodity.DbName)
+	assert.EqualValues(t, 2000, setups[0].Outcome.GainCr)
+}
+
+func TestRepl_CarrierRoute(t *testing.T) {
+	sdb := NewSystemDatabase(nil)
+	require.Nil(t, sdb.newSystem(&gom.System{Id: 1, Name: "Sol", Position: &gom.Coordinate{}}))
+	require.Nil(t, sdb.newSystem(&gom.System{Id: 2, Name: "Waypoint One", Position: &gom.Coordinate{X: 400}}))
+	require.Nil(t, sdb.newSystem(&gom.System{Id: 3, Name: "Waypoint Two", Position: &gom.Coordinate{X: 800}}))
+	require.Nil(t, sdb.newSystem(&gom.System{Id: 4, Name: "Colonia", Position: &gom.Coordinate{X: 1200}}))
+	require.Nil(t, sdb.newSystem(&gom.System{Id: 5, Name: "Beagle Point", Position: &gom.Coordinate{X: 9000}}))
+	require.Nil(t, sdb.newSystem(&gom.System{Id: 6, Name: "Alpha Centauri", Position: &gom.Coordinate{Y: 4.4}}))
+	require.Nil(t, sdb.newCommodity(&gom.Commodity{Id: 1, Name: "Gold"}))
+	require.Nil(t, sdb.newCommodity(&gom.Commodity{Id: 2, Name: "Tritium"}))
+	require.Nil(t, sdb.newFacility(&gom.Facility{Id: 1, SystemId: 1, Name: "Galileo"}))
+	require.Nil(t, sdb.newFacility(&gom.Facility{Id: 2, SystemId: 6, Name: "Hutton Orbital"}))
+	require.Nil(t, sdb.newFacility(&gom.Facility{Id: 3, SystemId: 6, Name: "K7Q-BQL", FacilityType: gom.FacilityType_FTFleetCarrier}))
+	require.Nil(t, sdb.newListings(&gom.FacilityListing{Id: 1, Listings: []*gom.CommodityListing{
+		{CommodityId: 1, SupplyUnits: 5000, SupplyCredits: 9000, SupplyBracket: gom.MarketBracket_BracketHigh},
+		{CommodityId: 2, DemandUnits: 5000, DemandCredits: 40000, DemandBracket: gom.MarketBracket_BracketHigh},
+	}}))
+	require.Nil(t, sdb.newListings(&gom.FacilityListing{Id: 2, Listings: []*gom.CommodityListing{
+		{CommodityId: 1, DemandUnits: 20000, DemandCredits: 11000, DemandBracket: gom.MarketBracket_BracketHigh},
+	}}))
+	// A carrier selling tritium cheaply shouldn't be suggested as a source.
+	require.Nil(t, sdb.newListings(&gom.FacilityListing{Id: 3, Listings: []*gom.CommodityListing{
+		{CommodityId: 2, SupplyUnits: 5000, SupplyCredits: 20000, SupplyBracket: gom.MarketBracket_BracketHigh},
+	}}))
+
+	var output bytes.Buffer
+	repl := &Repl{sdb: sdb, out: &output}
+	cmdCarrierRoute(repl, strings.Fields("Sol to Colonia"), nil)
+	assert.Contains(t, output.String(), "3 jumps, 1200.00ly")
+
+	output.Reset()
+	cmdCarrierSetups(repl, strings.Fields("10 Sol"), nil)
+	assert.Contains(t, output.String(), "park at Alpha Centauri/Hutton Orbital: Gold from Sol/Galileo")
+}
+
+func TestRepl_CarrierOrders(t *testing.T) {
+	testDir := GetTestDir()
+	defer testDir.Close()
+	path := filepath.Join(testDir.Path(), commanderFile)
+
+	sdb := NewSystemDatabase(nil)
+	require.Nil(t, sdb.newSystem(&gom.System{Id: 1, Name: "Sol", Position: &gom.Coordinate{}}))
+	require.Nil(t, sdb.newSystem(&gom.System{Id: 2, Name: "Waypoint One", Position: &gom.Coordinate{X: 400}}))
+	require.Nil(t, sdb.newSystem(&gom.System{Id: 3, Name: "Waypoint Two", Position: &gom.Coordinate{X: 800}}))
+	require.Nil(t, sdb.newSystem(&gom.System{Id: 4, Name: "Colonia", Position: &gom.Coordinate{X: 1200}}))
+	require.Nil(t, sdb.newSystem(&gom.System{Id: 5, Name: "Beagle Point", Position: &gom.Coordinate{X: 9000}}))
+	require.Nil(t, sdb.newSystem(&gom.System{Id: 6, Name: "Alpha Centauri", Position: &gom.Coordinate{Y: 4.4}}))
+	require.Nil(t, sdb.newCommodity(&gom.Commodity{Id: 1, Name: "Gold"}))
+	require.Nil(t, sdb.newCommodity(&gom.Commodity{Id: 2, Name: "Tritium"}))
+	require.Nil(t, sdb.newFacility(&gom.Facility{Id: 1, SystemId: 1, Name: "Galileo"}))
+	require.Nil(t, sdb.newFacility(&gom.Facility{Id: 2, SystemId: 6, Name: "Hutton Orbital"}))
+	require.Nil(t, sdb.newFacility(&gom.Facility{Id: 3, SystemId: 6, Name: "K7Q-BQL", FacilityType: gom.FacilityType_FTFleetCarrier}))
+	require.Nil(t, sdb.newListings(&gom.FacilityListing{Id: 1, Listings: []*gom.CommodityListing{
+		{CommodityId: 1, SupplyUnits: 5000, SupplyCredits: 9000, SupplyBracket: gom.MarketBracket_BracketHigh},
+		{CommodityId: 2, DemandUnits: 5000, DemandCredits: 40000, DemandBracket: gom.MarketBracket_BracketHigh},
+	}}))
+	require.Nil(t, sdb.newListings(&gom.FacilityListing{Id: 2, Listings: []*gom.CommodityListing{
+		{CommodityId: 1, DemandUnits: 20000, DemandCredits: 11000, DemandBracket: gom.MarketBracket_BracketHigh},
+	}}))
+	// A carrier selling tritium cheaply shouldn't be suggested as a source.
+	require.Nil(t, sdb.newListings(&gom.FacilityListing{Id: 3, Listings: []*gom.CommodityListing{
+		{CommodityId: 2, SupplyUnits: 5000, SupplyCredits: 20000, SupplyBracket: gom.MarketBracket_BracketHigh},
+	}}))
+
+	var output bytes.Buffer
+	repl := &Repl{sdb: sdb, out: &output, commander: NewCommander(path)}
+	cmdCarrierRoute(repl, strings.Fields("Sol to Colonia"), nil)
+	empty := output.String()
+
+	cmdCarrierTritium(repl, []string{"100"}, nil)
+	cmdCarrierCargo(repl, strings.Fields("4000 gold"), nil)
+	cmdCarrierBuy(repl, strings.Fields("15000 gold at 9000"), nil)
+	cmdCarrierSell(repl, strings.Fields("4000 gold at 12000cr"), nil)
+	output.Reset()
+	cmdCarrierBuy(repl, strings.Fields("20901 gold at 9000"), nil)
+	assert.Contains(t, output.String(), ErrCarrierCapacity.Error())
+
+	output.Reset()
+	cmdCarrierShow(repl, nil, nil)
+	assert.Equal(t, "Carrier: hold 4100/25000t, 100t tritium, 5900t free\n"+
+		"- 4000t Gold\n- buying 15000 Gold at 9000cr\n- selling 4000 Gold at 12000cr\n", output.String())
+
+	// The hold weighs the carrier down, and there isn't enough tritium for the trip.
+	output.Reset()
+	cmdCarrierRoute(repl, strings.Fields("Sol to Colonia"), nil)
+	assert.NotEqual(t, empty, output.String())
+	assert.Contains(t, output.String(), "Warning: the carrier only has 100t tritium.")
+
+	// Setups are sized to the space left once the buy orders are filled.
+	output.Reset()
+	cmdCarrierSetups(repl, strings.Fields("10 Sol"), nil)
+	setups, err := repl.sdb.SuggestCarrierSetups(repl.sdb.GetSystem("Sol"), 10, 5900, 0)
+	require.Nil(t, err)
+	require.Len(t, setups, 1)
+	assert.Contains(t, output.String(), fmt.Sprintf("%.0fcr/h", setups[0].Outcome.CreditsPerHour(5900)))
+
+	loaded, err := LoadCommander(path)
+	require.Nil(t, err)
+	assert.Equal(t, repl.commander.Carrier, loaded.Carrier)
+
+	cmdCarrierSell(repl, strings.Fields("0 gold"), nil)
+	assert.Empty(t, repl.commander.Carrier.SellOrders)
+}
@@ -0,0 +1,244 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/tidwall/gjson"
+)
+
+// commanderFile is the name of the commander state within the database directory.
+const commanderFile = "commander.json"
+
+// hereName is the name commands accept for the commander's current system or station.
+const hereName = "here"
+
+// Commander is the player's situation: where they are, what they're flying
+// and carrying, and what they can spend. It's followed from the journal or
+// set by hand, and saved so that it survives restarts.
+type Commander struct {
+	SystemID   EntityID
+	FacilityID EntityID
+	// The names are kept too, as systems and stations first seen in the
+	// journal are given new ids when an EDDB import catches up with them.
+	SystemName    string `json:",omitempty"`
+	FacilityName  string `json:",omitempty"`
+	ShipID        EntityID
+	ShipName      string
+	CargoCapacity int
+	CreditsCr     int64
+	Cargo         map[EntityID]int
+	Carrier       *Carrier `json:",omitempty"`
+
+	path string
+}
+
+// CommanderPath returns the location of the commander state for a database.
+func CommanderPath(db *Database) string {
+	return filepath.Join(db.Path(), commanderFile)
+}
+
+// NewCommander creates an empty commander which will be saved to path, if given.
+func NewCommander(path string) *Commander {
+	return &Commander{Cargo: make(map[EntityID]int), path: path}
+}
+
+// LoadCommander reads the commander saved at path, or returns a new one if
+// nothing has been saved yet.
+func LoadCommander(path string) (*Commander, error) {
+	commander := NewCommander(path)
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return commander, nil
+		}
+		return nil, err
+	}
+	if err = json.Unmarshal(data, commander); err != nil {
+		return nil, err
+	}
+	if commander.Cargo == nil {
+		commander.Cargo = make(map[EntityID]int)
+	}
+	if carrier := commander.Carrier; carrier != nil {
+		if carrier.Cargo == nil {
+			carrier.Cargo = make(map[EntityID]int)
+		}
+		if carrier.BuyOrders == nil {
+			carrier.BuyOrders = make(map[EntityID]CarrierOrder)
+		}
+		if carrier.SellOrders == nil {
+			carrier.SellOrders = make(map[EntityID]CarrierOrder)
+		}
+	}
+	return commander, nil
+}
+
+// Save writes the commander to its file; commanders without one aren't saved.
+func (c *Commander) Save() error {
+	if c.path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(c.path, data, 0644)
+}
+
+// System returns the commander's current system, if known.
+func (c *Commander) System(sdb *SystemDatabase) *System {
+	system := sdb.GetSystemByID(c.SystemID)
+	if c.SystemName != "" && (system == nil || !strings.EqualFold(system.DbName, c.SystemName)) {
+		// It has been given a new id since the commander was there.
+		if system = sdb.GetSystem(c.SystemName); system != nil {
+			c.SystemID = system.ID
+		}
+	}
+	return system
+}
+
+// Facility returns the station the commander is docked at, if any.
+func (c *Commander) Facility(sdb *SystemDatabase) *Facility {
+	facility := sdb.GetFacilityByID(c.FacilityID)
+	if c.FacilityName != "" && (facility == nil || !strings.EqualFold(facility.DbName, c.FacilityName)) {
+		facility = nil
+		if system := c.System(sdb); system != nil {
+			if facility = system.GetFacility(c.FacilityName); facility != nil {
+				c.FacilityID = facility.ID
+			}
+		}
+	}
+	return facility
+}
+
+// Ship returns the commander's active ship, if known.
+func (c *Commander) Ship(sdb *SystemDatabase) *Ship {
+	return sdb.GetShipByID(c.ShipID)
+}
+
+// SetLocation moves the commander to system, docked at facility if it isn't nil.
+func (c *Commander) SetLocation(system *System, facility *Facility) {
+	c.SystemID, c.FacilityID, c.SystemName, c.FacilityName = 0, 0, "", ""
+	if facility != nil {
+		c.FacilityID, c.FacilityName = facility.ID, facility.DbName
+		system = facility.System
+	}
+	if system != nil {
+		c.SystemID, c.SystemName = system.ID, system.DbName
+	}
+}
+
+// AddCargo adjusts the units of a commodity in the hold, which can't go below empty.
+func (c *Commander) AddCargo(commodityID EntityID, units int) {
+	units += c.Cargo[commodityID]
+	if units > 0 {
+		c.Cargo[commodityID] = units
+	} else {
+		delete(c.Cargo, commodityID)
+	}
+}
+
+// CargoUnits is the total number of units in the hold.
+func (c *Commander) CargoUnits() int {
+	total := 0
+	for _, units := range c.Cargo {
+		total += units
+	}
+	return total
+}
+
+// lookupShipSymbol finds a ship by the game's name for it, such as "krait_mkii".
+func lookupShipSymbol(sdb *SystemDatabase, symbol string) *Ship {
+	squash := func(name string) string {
+		return strings.NewReplacer(" ", "", "_", "", "-", "", ".", "").Replace(strings.ToLower(name))
+	}
+	symbol = squash(symbol)
+	for _, ship := range sdb.shipsByID {
+		if squash(ship.DbName) == symbol {
+			return ship
+		}
+	}
+	return nil
+}
+
+// journalFacility finds the station described by an event.
+func journalFacility(sdb *SystemDatabase, event gjson.Result) *Facility {
+	if facility := sdb.GetFacilityByMarketID(event.Get("MarketID").Uint()); facility != nil {
+		return facility
+	}
+	if system := sdb.GetSystem(event.Get("StarSystem").String()); system != nil {
+		return system.GetFacility(event.Get("StationName").String())
+	}
+	return nil
+}
+
+// applyEvent follows the commander through a journal event, returning true
+// if it changed anything.
+func (c *Commander) applyEvent(j *JournalImporter, event gjson.Result) bool {
+	switch event.Get("event").String() {
+	case "FSDJump", "CarrierJump", "Location":
+		var facility *Facility
+		if event.Get("Docked").Bool() {
+			facility = journalFacility(j.sdb, event)
+		}
+		c.SetLocation(j.sdb.GetSystem(event.Get("StarSystem").String()), facility)
+
+	case "Docked":
+		c.SetLocation(j.sdb.GetSystem(event.Get("StarSystem").String()), journalFacility(j.sdb, event))
+
+	case "Undocked":
+		c.FacilityID, c.FacilityName = 0, ""
+
+	case "LoadGame":
+		c.CreditsCr = event.Get("Credits").Int()
+		c.setShip(j.sdb, event.Get("Ship").String(), event.Get("ShipName").String())
+
+	case "Loadout":
+		c.setShip(j.sdb, event.Get("Ship").String(), event.Get("ShipName").String())
+		c.CargoCapacity = int(event.Get("CargoCapacity").Int())
+
+	case "ShipyardSwap":
+		c.setShip(j.sdb, event.Get("ShipType").String(), "")
+
+	case "Cargo":
+		// Cargo events for the SRV, or without an inventory, don't describe the ship's hold.
+		inventory := event.Get("Inventory")
+		if event.Get("Vessel").String() != "Ship" || !inventory.Exists() {
+			return false
+		}
+		c.Cargo = make(map[EntityID]int)
+		for _, item := range inventory.Array() {
+			if commodityID, exists := j.lookupCommodity(item.Get("Name_Localised").String(), item.Get("Name").String()); exists {
+				c.AddCargo(commodityID, int(item.Get("Count").Int()))
+			}
+		}
+
+	case "MarketBuy":
+		if commodityID, exists := j.lookupCommodity(event.Get("Type_Localised").String(), event.Get("Type").String()); exists {
+			c.AddCargo(commodityID, int(event.Get("Count").Int()))
+		}
+		c.CreditsCr -= event.Get("TotalCost").Int()
+
+	case "MarketSell":
+		if commodityID, exists := j.lookupCommodity(event.Get("Type_Localised").String(), event.Get("Type").String()); exists {
+			c.AddCargo(commodityID, -int(event.Get("Count").Int()))
+		}
+		c.CreditsCr += event.Get("TotalSale").Int()
+
+	default:
+		return false
+	}
+	return true
+}
+
+func (c *Commander) setShip(sdb *SystemDatabase, symbol, name string) {
+	c.ShipID = 0
+	if ship := lookupShipSymbol(sdb, symbol); ship != nil {
+		c.ShipID = ship.ID
+	}
+	c.ShipName = name
+}
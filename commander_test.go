@@ -0,0 +1,185 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	gom "github.com/kfsone/gomenacing/pkg/gomschema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func TestCommander_SaveLoad(t *testing.T) {
+	testDir := GetTestDir()
+	defer testDir.Close()
+	path := filepath.Join(testDir.Path(), commanderFile)
+
+	commander, err := LoadCommander(path)
+	require.Nil(t, err)
+	assert.Equal(t, NewCommander(path), commander)
+
+	commander.SystemID, commander.FacilityID, commander.CreditsCr = 1, 1, 12345
+	commander.AddCargo(1, 10)
+	commander.AddCargo(2, 5)
+	commander.AddCargo(2, -10)
+	assert.Equal(t, map[EntityID]int{1: 10}, commander.Cargo)
+	assert.Equal(t, 10, commander.CargoUnits())
+	require.Nil(t, commander.Save())
+
+	loaded, err := LoadCommander(path)
+	require.Nil(t, err)
+	assert.Equal(t, commander, loaded)
+
+	// Without a path there's nowhere to save to.
+	assert.Nil(t, NewCommander("").Save())
+}
+
+func TestCommander_applyEvent(t *testing.T) {
+	sdb := NewSystemDatabase(nil)
+	require.Nil(t, sdb.newCommodity(&gom.Commodity{Id: 1, Name: "Gold"}))
+	require.Nil(t, sdb.newCommodity(&gom.Commodity{Id: 2, Name: "Mineral Oil"}))
+	require.Nil(t, sdb.newSystem(&gom.System{Id: 1, Name: "Sol", Position: &gom.Coordinate{}}))
+	require.Nil(t, sdb.newSystem(&gom.System{Id: 2, Name: "Alpha Centauri", Position: &gom.Coordinate{X: 4.4}}))
+	require.Nil(t, sdb.newFacility(&gom.Facility{Id: 1, SystemId: 1, Name: "Galileo", MarketId: 128016640}))
+	require.Nil(t, sdb.newFacility(&gom.Facility{Id: 2, SystemId: 2, Name: "Hutton Orbital"}))
+	require.Nil(t, sdb.newShip(&gom.Ship{Id: 1, Name: "Krait Mk II"}))
+	require.Nil(t, sdb.newShip(&gom.Ship{Id: 2, Name: "Python"}))
+	importer := NewJournalImporter(sdb, nil)
+	commander := NewCommander("")
+	apply := func(event string) bool {
+		return commander.applyEvent(importer, gjson.Parse(event))
+	}
+
+	assert.True(t, apply(`{"event":"LoadGame","Ship":"krait_mkii","ShipName":"Gold Digger","Credits":1000000}`))
+	assert.EqualValues(t, 1, commander.ShipID)
+	assert.Equal(t, "Gold Digger", commander.ShipName)
+	assert.EqualValues(t, 1000000, commander.CreditsCr)
+
+	assert.True(t, apply(`{"event":"Loadout","Ship":"python","ShipName":"","CargoCapacity":256}`))
+	assert.Equal(t, sdb.GetShipByID(2), commander.Ship(sdb))
+	assert.Equal(t, 256, commander.CargoCapacity)
+
+	assert.True(t, apply(`{"event":"Location","StarSystem":"Sol","Docked":true,"StationName":"Galileo","MarketID":128016640}`))
+	assert.Equal(t, sdb.GetFacilityByID(1), commander.Facility(sdb))
+	assert.Equal(t, sdb.GetSystem("Sol"), commander.System(sdb))
+
+	assert.True(t, apply(`{"event":"MarketBuy","MarketID":128016640,"Type":"gold","Count":100,"BuyPrice":9000,"TotalCost":900000}`))
+	assert.True(t, apply(`{"event":"MarketBuy","MarketID":128016640,"Type":"mineraloil","Type_Localised":"Mineral Oil","Count":10,"TotalCost":1000}`))
+	assert.Equal(t, map[EntityID]int{1: 100, 2: 10}, commander.Cargo)
+	assert.EqualValues(t, 99000, commander.CreditsCr)
+
+	assert.True(t, apply(`{"event":"Undocked","StationName":"Galileo","MarketID":128016640}`))
+	assert.Nil(t, commander.Facility(sdb))
+	assert.True(t, apply(`{"event":"FSDJump","StarSystem":"Alpha Centauri","StarPos":[4.4,0,0]}`))
+	assert.Equal(t, sdb.GetSystem("Alpha Centauri"), commander.System(sdb))
+	assert.True(t, apply(`{"event":"Docked","StarSystem":"Alpha Centauri","StationName":"Hutton Orbital","MarketID":1}`))
+	assert.Equal(t, sdb.GetFacilityByID(2), commander.Facility(sdb))
+
+	assert.True(t, apply(`{"event":"MarketSell","MarketID":1,"Type":"gold","Count":40,"TotalSale":400000}`))
+	assert.Equal(t, map[EntityID]int{1: 60, 2: 10}, commander.Cargo)
+	assert.EqualValues(t, 499000, commander.CreditsCr)
+
+	// The SRV's cargo isn't the ship's.
+	assert.False(t, apply(`{"event":"Cargo","Vessel":"SRV","Count":0,"Inventory":[]}`))
+	assert.True(t, apply(`{"event":"Cargo","Vessel":"Ship","Count":8,"Inventory":[{"Name":"gold","Count":8}]}`))
+	assert.Equal(t, map[EntityID]int{1: 8}, commander.Cargo)
+
+	assert.False(t, apply(`{"event":"Music","MusicTrack":"Exploration"}`))
+}
+
+func TestRepl_HereAfterRekey(t *testing.T) {
+	testDir := GetTestDir()
+	defer testDir.Close()
+	db, err := OpenDatabase(testDir.Path(), "commander.db")
+	require.Nil(t, err)
+	defer db.Close()
+
+	// The commander docks somewhere EDDB hasn't told us about yet.
+	repl, err := NewRepl(db, NewSystemDatabase(db), nil, &bytes.Buffer{})
+	require.Nil(t, err)
+	importer := NewJournalImporter(repl.sdb, db)
+	importer.commander = repl.getCommander()
+	importer.ImportJournal(strings.NewReader(testJournal))
+	importer.Close()
+	require.Nil(t, repl.commander.Save())
+	require.NotNil(t, repl.lookupFacility("here"))
+	assert.Equal(t, syntheticIDBase, repl.lookupSystem("here").ID)
+
+	// Once it has, here is still where the commander is.
+	systems, err := db.Systems()
+	require.Nil(t, err)
+	require.Nil(t, repl.sdb.registerFromMessage(&gom.System{Id: 8, Name: "Shinrarta Dezhra", Position: &gom.Coordinate{X: 55.71875}}, systems, "eddb"))
+	require.Nil(t, systems.Close())
+	facilities, err := db.Facilities()
+	require.Nil(t, err)
+	require.Nil(t, repl.sdb.registerFromMessage(&gom.Facility{Id: 3, SystemId: 8, Name: "Jameson Memorial", MarketId: 128666762}, facilities, "eddb"))
+	require.Nil(t, facilities.Close())
+	assert.Equal(t, repl.sdb.GetSystemByID(8), repl.lookupSystem("here"))
+	assert.Equal(t, repl.sdb.GetFacilityByID(3), repl.lookupFacility("here"))
+
+	// Even for a commander saved before the import.
+	reloaded := NewSystemDatabase(db)
+	require.Nil(t, db.LoadDatabase(reloaded))
+	restarted, err := NewRepl(db, reloaded, nil, &bytes.Buffer{})
+	require.Nil(t, err)
+	assert.Equal(t, reloaded.GetSystemByID(8), restarted.lookupSystem("here"))
+	assert.Equal(t, reloaded.GetFacilityByID(3), restarted.lookupFacility("here"))
+}
+
+func TestRepl_Here(t *testing.T) {
+	sdb := NewSystemDatabase(nil)
+	require.Nil(t, sdb.newCommodity(&gom.Commodity{Id: 1, Name: "Gold"}))
+	require.Nil(t, sdb.newCommodity(&gom.Commodity{Id: 2, Name: "Mineral Oil"}))
+	require.Nil(t, sdb.newSystem(&gom.System{Id: 1, Name: "Sol", Position: &gom.Coordinate{}}))
+	require.Nil(t, sdb.newSystem(&gom.System{Id: 2, Name: "Alpha Centauri", Position: &gom.Coordinate{X: 4.4}}))
+	require.Nil(t, sdb.newFacility(&gom.Facility{Id: 1, SystemId: 1, Name: "Galileo", MarketId: 128016640}))
+	require.Nil(t, sdb.newFacility(&gom.Facility{Id: 2, SystemId: 2, Name: "Hutton Orbital"}))
+	require.Nil(t, sdb.newShip(&gom.Ship{Id: 1, Name: "Krait Mk II"}))
+	require.Nil(t, sdb.newShip(&gom.Ship{Id: 2, Name: "Python"}))
+
+	var output bytes.Buffer
+	repl := &Repl{sdb: sdb, out: &output}
+
+	// Nowhere is here until we know where we are.
+	assert.Nil(t, repl.lookupSystem("here"))
+	assert.Nil(t, repl.lookupFacility("here"))
+
+	cmdCommanderAt(repl, strings.Fields("Alpha Centauri"), nil)
+	assert.Contains(t, output.String(), "Location: in Alpha Centauri")
+	assert.Equal(t, repl.sdb.GetSystem("Alpha Centauri"), repl.lookupSystem("HERE"))
+	assert.Nil(t, repl.lookupFacility("here"))
+
+	output.Reset()
+	cmdCommanderAt(repl, strings.Fields("sol/galileo"), nil)
+	assert.Contains(t, output.String(), "Location: docked at Sol/Galileo")
+	assert.Equal(t, repl.sdb.GetFacilityByID(1), repl.lookupFacility("here"))
+	assert.Equal(t, repl.sdb.GetSystem("Sol"), repl.lookupSystem("here"))
+
+	output.Reset()
+	cmdSystemFind(repl, []string{"here"}, nil)
+	assert.Contains(t, output.String(), "Sol")
+
+	output.Reset()
+	cmdCommanderAt(repl, strings.Fields("Nowhere"), nil)
+	assert.Contains(t, output.String(), "Unrecognized system or station: Nowhere")
+
+	output.Reset()
+	cmdCommanderShip(repl, strings.Fields("256 python"), nil)
+	assert.Equal(t, 256, repl.cargoUnits())
+	assert.Contains(t, output.String(), "Ship: Python, hold 0/256t")
+
+	output.Reset()
+	cmdCommanderCargo(repl, strings.Fields("64 gold, 32 mineral oil"), nil)
+	assert.Contains(t, output.String(), "hold 96/256t")
+	cmdCommanderCargo(repl, []string{"empty"}, nil)
+	assert.Empty(t, repl.commander.Cargo)
+
+	output.Reset()
+	cmdCommanderCredits(repl, []string{"1500000cr"}, nil)
+	assert.EqualValues(t, 1500000, repl.commander.CreditsCr)
+	cmdCommanderCredits(repl, []string{"lots"}, nil)
+	assert.Contains(t, output.String(), "Invalid credits: lots")
+}
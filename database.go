@@ -7,10 +7,21 @@ import (
 	"github.com/kfsone/gomenacing/pkg/gomschema"
 	"golang.org/x/sync/errgroup"
 	"google.golang.org/protobuf/proto"
+	"io/ioutil"
 	"log"
+	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
 )
 
+// generationFile is where the database records how many times it has been modified.
+const generationFile = "generation"
+
+// syncedFile is where the database records the newest delta it has applied.
+const syncedFile = "synced"
+
 type Database struct {
 	storePath string
 }
@@ -37,10 +48,57 @@ func (db *Database) GetSchema(name string) (schema *Schema, err error) {
 	if err != nil {
 		return nil, err
 	}
-	schema = &Schema{db, name, store}
+	schema = &Schema{db: db, name: name, store: store}
 	return schema, nil
 }
 
+// Generation returns a counter that increases every time a schema of the
+// database is modified, so that derived data (such as snapshots) can tell
+// whether it is stale.
+func (db *Database) Generation() (uint64, error) {
+	data, err := ioutil.ReadFile(filepath.Join(db.Path(), generationFile))
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+}
+
+// generationLock serializes bumps of the generation, since the schemas of a
+// database may be written to concurrently, e.g. while loading.
+var generationLock sync.Mutex
+
+func (db *Database) bumpGeneration() error {
+	generationLock.Lock()
+	defer generationLock.Unlock()
+	generation, err := db.Generation()
+	if err != nil {
+		return err
+	}
+	data := []byte(strconv.FormatUint(generation+1, 10) + "\n")
+	return ioutil.WriteFile(filepath.Join(db.Path(), generationFile), data, 0640)
+}
+
+// SyncedTo returns the target timestamp of the newest dump or delta applied to
+// the database, or 0 if none has been.
+func (db *Database) SyncedTo() (uint64, error) {
+	data, err := ioutil.ReadFile(filepath.Join(db.Path(), syncedFile))
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+}
+
+func (db *Database) setSyncedTo(timestamp uint64) error {
+	data := []byte(strconv.FormatUint(timestamp, 10) + "\n")
+	return ioutil.WriteFile(filepath.Join(db.Path(), syncedFile), data, 0640)
+}
+
 // Returns an open handle to the commodity schema
 func (db *Database) Commodities() (*Schema, error) {
 	return db.GetSchema("commodities")
@@ -60,6 +118,31 @@ func (db *Database) Systems() (*Schema, error) {
 	return db.GetSchema("systems")
 }
 
+// Returns an open handle to the ship module schema
+func (db *Database) Modules() (*Schema, error) {
+	return db.GetSchema("modules")
+}
+
+// Returns an open handle to the ship schema
+func (db *Database) Ships() (*Schema, error) {
+	return db.GetSchema("ships")
+}
+
+// Returns an open handle to the facility outfitting schema
+func (db *Database) Outfitting() (*Schema, error) {
+	return db.GetSchema("outfitting")
+}
+
+// Returns an open handle to the facility shipyard schema
+func (db *Database) Shipyards() (*Schema, error) {
+	return db.GetSchema("shipyards")
+}
+
+// Returns an open handle to the schema mapping id64 system addresses to system ids
+func (db *Database) SystemAddresses() (*Schema, error) {
+	return db.GetSchema("addresses")
+}
+
 func getSchemaForMessage(db *Database, message proto.Message) (*Schema, error) {
 	switch v := message.(type) {
 	case *gomschema.Commodity:
@@ -74,11 +157,64 @@ func getSchemaForMessage(db *Database, message proto.Message) (*Schema, error) {
 	case *gomschema.FacilityListing:
 		return db.Listings()
 
+	case *gomschema.Module:
+		return db.Modules()
+
+	case *gomschema.Ship:
+		return db.Ships()
+
+	case *gomschema.FacilityOutfitting:
+		return db.Outfitting()
+
+	case *gomschema.FacilityShipyard:
+		return db.Shipyards()
+
 	default:
 		return nil, fmt.Errorf("%w: message type: %t", ErrUnknownEntity, v)
 	}
 }
 
+// messageWriter registers messages with a SystemDatabase and writes them
+// through to the database, opening each schema once.
+type messageWriter struct {
+	sdb     *SystemDatabase
+	db      *Database
+	schemas map[string]*Schema
+	source  string
+	Updates int
+}
+
+// newMessageWriter returns a writer of updates from the named source.
+func newMessageWriter(sdb *SystemDatabase, db *Database, source string) messageWriter {
+	return messageWriter{sdb: sdb, db: db, schemas: make(map[string]*Schema), source: source}
+}
+
+// apply registers an update and writes it to the database.
+func (w *messageWriter) apply(message proto.Message) error {
+	kind := fmt.Sprintf("%T", message)
+	schema, exists := w.schemas[kind]
+	if !exists {
+		var err error
+		if schema, err = getSchemaForMessage(w.db, message); err != nil {
+			return err
+		}
+		w.schemas[kind] = schema
+	}
+	if err := w.sdb.registerFromMessage(message, schema, w.source); err != nil {
+		return err
+	}
+	w.Updates++
+	return nil
+}
+
+// Close releases the schemas opened by the writer.
+func (w *messageWriter) Close() {
+	for _, schema := range w.schemas {
+		Must(schema.Close())
+	}
+	w.schemas = make(map[string]*Schema)
+}
+
 func (db *Database) loadSystems(sdb *SystemDatabase) error {
 	schema, err := db.Systems()
 	var loaded int
@@ -151,6 +287,74 @@ func (db *Database) loadListings(sdb *SystemDatabase) error {
 	return err
 }
 
+func (db *Database) loadModules(sdb *SystemDatabase) error {
+	schema, err := db.Modules()
+	var loaded int
+	if err == nil {
+		sdb.moduleIDs = make(map[string]EntityID, schema.Count())
+		sdb.modulesByID = make(map[EntityID]*Module, schema.Count())
+		temporary := &gomschema.Module{}
+		loader, err := NewTypedDataLoader("gom", temporary, func() error { return sdb.newModule(temporary) })
+		if err == nil {
+			loaded, err = schema.LoadData(loader)
+			if err == nil {
+				log.Printf("Loaded %d Modules.", loaded)
+			}
+		}
+	}
+	return err
+}
+
+func (db *Database) loadShips(sdb *SystemDatabase) error {
+	schema, err := db.Ships()
+	var loaded int
+	if err == nil {
+		sdb.shipIDs = make(map[string]EntityID, schema.Count())
+		sdb.shipsByID = make(map[EntityID]*Ship, schema.Count())
+		temporary := &gomschema.Ship{}
+		loader, err := NewTypedDataLoader("gom", temporary, func() error { return sdb.newShip(temporary) })
+		if err == nil {
+			loaded, err = schema.LoadData(loader)
+			if err == nil {
+				log.Printf("Loaded %d Ships.", loaded)
+			}
+		}
+	}
+	return err
+}
+
+func (db *Database) loadOutfitting(sdb *SystemDatabase) error {
+	schema, err := db.Outfitting()
+	var loaded int
+	if err == nil {
+		temporary := &gomschema.FacilityOutfitting{}
+		loader, err := NewTypedDataLoader("gom", temporary, func() error { return sdb.newOutfitting(temporary) })
+		if err == nil {
+			loaded, err = schema.LoadData(loader)
+			if err == nil {
+				log.Printf("Loaded Outfitting at %d Facilities.", loaded)
+			}
+		}
+	}
+	return err
+}
+
+func (db *Database) loadShipyards(sdb *SystemDatabase) error {
+	schema, err := db.Shipyards()
+	var loaded int
+	if err == nil {
+		temporary := &gomschema.FacilityShipyard{}
+		loader, err := NewTypedDataLoader("gom", temporary, func() error { return sdb.newShipyard(temporary) })
+		if err == nil {
+			loaded, err = schema.LoadData(loader)
+			if err == nil {
+				log.Printf("Loaded Shipyards at %d Facilities.", loaded)
+			}
+		}
+	}
+	return err
+}
+
 func (db *Database) LoadDatabase(sdb *SystemDatabase) (err error) {
 	///TODO: Speed up import-load by making import a part of load.
 	/// Thus we can load commodities as soon as we've imported them.
@@ -169,14 +373,26 @@ func (db *Database) LoadDatabase(sdb *SystemDatabase) (err error) {
 		}
 		return err
 	})
-	// We can load the commodity list in parallel
+	// We can load the commodity, module and ship lists in parallel
 	eg.Go(func() error {
 		return db.loadCommodities(sdb)
 	})
+	eg.Go(func() error {
+		if err := db.loadModules(sdb); err != nil {
+			return err
+		}
+		return db.loadShips(sdb)
+	})
 	if err := eg.Wait(); err != nil {
 		return err
 	}
 
-	// Now import any prices.
-	return db.loadListings(sdb)
+	// Now import any prices, and what's for sale.
+	if err = db.loadListings(sdb); err != nil {
+		return err
+	}
+	if err = db.loadOutfitting(sdb); err != nil {
+		return err
+	}
+	return db.loadShipyards(sdb)
 }
@@ -4,6 +4,7 @@ import (
 	"github.com/kfsone/gomenacing/pkg/gomschema"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/sync/errgroup"
 	"google.golang.org/protobuf/proto"
 	"os"
 	"path/filepath"
@@ -157,3 +158,82 @@ func Test_getSchemaForMessage(t *testing.T) {
 		})
 	}
 }
+
+func TestDatabase_Generation(t *testing.T) {
+	testDir := GetTestDir()
+	defer testDir.Close()
+
+	db, err := OpenDatabase(testDir.Path(), "generation.db")
+	require.Nil(t, err)
+	defer db.Close()
+
+	generation, err := db.Generation()
+	require.Nil(t, err)
+	assert.Zero(t, generation)
+
+	// Opening and closing a schema without writing shouldn't change anything.
+	schema, err := db.Systems()
+	require.Nil(t, err)
+	failOnError(schema.Close())
+	generation, err = db.Generation()
+	require.Nil(t, err)
+	assert.Zero(t, generation)
+
+	// Writing should bump the generation once, as soon as the schema is written to.
+	schema, err = db.Systems()
+	require.Nil(t, err)
+	require.Nil(t, schema.Put([]byte("key1"), []byte("value")))
+	generation, err = db.Generation()
+	require.Nil(t, err)
+	assert.EqualValues(t, 1, generation)
+	require.Nil(t, schema.Put([]byte("key2"), []byte("value")))
+	failOnError(schema.Close())
+	generation, err = db.Generation()
+	require.Nil(t, err)
+	assert.EqualValues(t, 1, generation)
+
+	// Schemas written to at the same time each count.
+	var group errgroup.Group
+	names := []string{"commodities", "facilities", "listings", "modules", "ships", "outfitting", "shipyards", "systems"}
+	for _, name := range names {
+		name := name
+		group.Go(func() error {
+			schema, err := db.GetSchema(name)
+			if err != nil {
+				return err
+			}
+			if err = schema.Put([]byte("key"), []byte(name)); err != nil {
+				return err
+			}
+			return schema.Close()
+		})
+	}
+	require.Nil(t, group.Wait())
+	generation, err = db.Generation()
+	require.Nil(t, err)
+	assert.EqualValues(t, 1+len(names), generation)
+}
+
+func TestDatabase_LoadListings(t *testing.T) {
+	testDir := GetTestDir()
+	defer testDir.Close()
+
+	db, err := OpenDatabase(testDir.Path(), "listings.db")
+	require.Nil(t, err)
+	defer db.Close()
+	populateTestDatabase(t, db,
+		&gomschema.Commodity{Id: 1, Name: "Gold"},
+		&gomschema.System{Id: 1, Name: "Sol", Position: &gomschema.Coordinate{}},
+		&gomschema.Facility{Id: 1, SystemId: 1, Name: "Galileo"},
+		&gomschema.FacilityListing{Id: 1, Listings: []*gomschema.CommodityListing{
+			{CommodityId: 1, SupplyUnits: 10, SupplyCredits: 9500, DemandUnits: 20, DemandCredits: 9400, TimestampUtc: 100},
+		}},
+	)
+
+	// Listings are loaded the same way updates apply them: supply is what the
+	// station sells, and so asks for, demand what it buys and pays for.
+	sdb := NewSystemDatabase(db)
+	require.Nil(t, db.LoadDatabase(sdb))
+	assert.Equal(t, Listing{CommodityID: 1, Supply: 10, StationAsks: 9500, Demand: 20, StationPays: 9400, TimestampUtc: 100},
+		*sdb.GetFacilityByID(1).listings[1])
+}
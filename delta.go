@@ -0,0 +1,315 @@
+package main
+
+// A delta is a directory of GOM files holding only what changed in a database
+// after a base timestamp, named like a full import so "import <dir>" applies
+// it. Each header records the base and the target - the newest timestamp in
+// the database it came from - and a delta is only applied on top of a
+// database that has caught up to its base. Applying a delta again is
+// harmless: updates that aren't newer than what's there are skipped, and the
+// database remembers the newest target it has applied.
+//
+// A full dump made by "export dump" is read the same way, and records the
+// newest timestamp it holds as its dump time, so that importing it syncs the
+// database to that point and the deltas that follow can be applied on top.
+//
+// Commodities, modules and ships are small reference tables without reliable
+// timestamps, so every delta includes all of them. The database never deletes
+// entities, so there are no tombstones to carry.
+//
+// A delta is from the source its exporter names, but each entity also
+// carries the provenance its fields had in the exporting database, so that
+// what came from a feed is still ranked as that feed's by the receiver. The
+// carried provenance is only believed if the delta's source is trusted.
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	gom "github.com/kfsone/gomenacing/pkg/gomschema"
+	"google.golang.org/protobuf/proto"
+)
+
+// Header userdata keys recording the timestamps a delta runs between.
+const (
+	DeltaBaseKey   = "delta-base"
+	DeltaTargetKey = "delta-target"
+	// The provenance of the file's entities: for each, a uvarint-prefixed key
+	// and stored provenance record.
+	DeltaProvenanceKey = "delta-provenance"
+	// The newest timestamp in the database a full dump was taken from.
+	DumpTimeKey = "dump-time"
+)
+
+// DeltaSource is the source of a delta whose exporter doesn't name one.
+const DeltaSource = "delta"
+
+// ErrDeltaBase indicates a delta that doesn't follow on from the database.
+var ErrDeltaBase = errors.New("delta does not follow on from the database")
+
+type deltaSchema struct {
+	schema     string
+	filename   string
+	headerType gom.Header_Type
+	newMessage func() proto.Message // nil for reference data, which is always included.
+}
+
+// deltaSchemas are the files of a delta, in the order they're applied.
+var deltaSchemas = []deltaSchema{
+	{"commodities", "commodities.gom.zst", gom.Header_CCommodity, nil},
+	{"systems", "systems.gom.zst", gom.Header_CSystem, func() proto.Message { return &gom.System{} }},
+	{"facilities", "stations.gom.zst", gom.Header_CFacility, func() proto.Message { return &gom.Facility{} }},
+	{"listings", "listings.gom.zst", gom.Header_CListing, func() proto.Message { return &gom.FacilityListing{} }},
+	{"modules", "modules.gom.zst", gom.Header_CModule, nil},
+	{"ships", "ships.gom.zst", gom.Header_CShip, nil},
+	{"outfitting", "outfitting.gom.zst", gom.Header_COutfitting, func() proto.Message { return &gom.FacilityOutfitting{} }},
+	{"shipyards", "shipyards.gom.zst", gom.Header_CShipyard, func() proto.Message { return &gom.FacilityShipyard{} }},
+}
+
+// DeltaFile describes one file of a delta.
+type DeltaFile struct {
+	Filename string
+	Count    int
+}
+
+// messageTimestamp is when a stored message was last updated; for listings
+// that's the newest of its items.
+func messageTimestamp(message proto.Message) uint64 {
+	if listing, isListing := message.(*gom.FacilityListing); isListing {
+		var newest uint64
+		for _, item := range listing.Listings {
+			if item.TimestampUtc > newest {
+				newest = item.TimestampUtc
+			}
+		}
+		return newest
+	}
+	return message.(Timestamped).GetTimestampUtc()
+}
+
+// formatDeltaTime formats a delta timestamp for people.
+func formatDeltaTime(timestamp uint64) string {
+	if timestamp == 0 {
+		return "the beginning"
+	}
+	return time.Unix(int64(timestamp), 0).UTC().Format(pricesTimeFormat)
+}
+
+// parseDeltaTime parses when a delta should start from: a unix time, a UTC
+// date and optional time, or a duration before now such as "24h".
+func parseDeltaTime(text string, now time.Time) (uint64, error) {
+	if timestamp, err := strconv.ParseUint(text, 10, 64); err == nil {
+		return timestamp, nil
+	}
+	if age, err := time.ParseDuration(text); err == nil && age > 0 {
+		return uint64(now.Add(-age).Unix()), nil
+	}
+	for _, layout := range []string{pricesTimeFormat, "2006-01-02"} {
+		if when, err := time.Parse(layout, text); err == nil {
+			return uint64(when.Unix()), nil
+		}
+	}
+	return 0, fmt.Errorf("invalid time: %s", text)
+}
+
+// ExportDelta writes what changed in db after base into dir as from source,
+// returning the delta's target timestamp and what was written.
+func ExportDelta(db *Database, dir string, base uint64, source string) (target uint64, files []DeltaFile, err error) {
+	return exportChanges(db, dir, base, source, false)
+}
+
+// ExportDump writes everything in db into dir as from source, returning the
+// dump time and what was written.
+func ExportDump(db *Database, dir string, source string) (dumped uint64, files []DeltaFile, err error) {
+	return exportChanges(db, dir, 0, source, true)
+}
+
+// exportChanges writes what changed in db after base into dir, as a full
+// dump or as a delta.
+func exportChanges(db *Database, dir string, base uint64, source string, full bool) (target uint64, files []DeltaFile, err error) {
+	if source == "" {
+		source = DeltaSource
+	}
+	target = base
+	writers := make([]*gom.GOMWriter, 0, len(deltaSchemas))
+	carried := make([][]byte, 0, len(deltaSchemas))
+	for _, info := range deltaSchemas {
+		writer := gom.NewGOMWriter(info.headerType, source)
+		writer.SetChecksum(gom.ChecksumSHA256)
+		schema, err := db.GetSchema(info.schema)
+		if err != nil {
+			return 0, nil, err
+		}
+		var provenance bytes.Buffer
+		err = schema.Iterate(func(key, value []byte) error {
+			if info.newMessage != nil {
+				message := info.newMessage()
+				if err := proto.Unmarshal(value, message); err != nil {
+					return err
+				}
+				timestamp := messageTimestamp(message)
+				if timestamp > target {
+					target = timestamp
+				}
+				if timestamp <= base {
+					return nil
+				}
+			}
+			writer.AddData(value)
+			return appendCarriedProvenance(&provenance, schema, key)
+		})
+		if closeErr := schema.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return 0, nil, fmt.Errorf("%s: %w", info.schema, err)
+		}
+		writers = append(writers, writer)
+		carried = append(carried, provenance.Bytes())
+	}
+
+	if _, err = ensureDirectory(dir); err != nil {
+		return 0, nil, err
+	}
+	for idx, writer := range writers {
+		if full {
+			writer.Header().Userdata = map[string][]byte{DumpTimeKey: []byte(strconv.FormatUint(target, 10))}
+		} else {
+			writer.Header().Userdata = map[string][]byte{
+				DeltaBaseKey:   []byte(strconv.FormatUint(base, 10)),
+				DeltaTargetKey: []byte(strconv.FormatUint(target, 10)),
+			}
+		}
+		if len(carried[idx]) > 0 {
+			writer.Header().Userdata[DeltaProvenanceKey] = carried[idx]
+		}
+		filename := deltaSchemas[idx].filename
+		if err = writer.WriteFile(filepath.Join(dir, filename)); err != nil {
+			return 0, files, fmt.Errorf("%s: %w", filename, err)
+		}
+		files = append(files, DeltaFile{filename, writer.Count()})
+	}
+	return target, files, nil
+}
+
+// appendCarriedProvenance adds the provenance stored for key in schema, if
+// any, to the provenance a delta carries.
+func appendCarriedProvenance(carried *bytes.Buffer, schema *Schema, key []byte) error {
+	provenance, err := schema.provenanceSchema()
+	if err != nil {
+		return err
+	}
+	record, err := provenance.Get(key)
+	if err != nil || record == nil {
+		return err
+	}
+	varint := make([]byte, binary.MaxVarintLen64)
+	for _, field := range [][]byte{key, record} {
+		carried.Write(varint[:binary.PutUvarint(varint, uint64(len(field)))])
+		carried.Write(field)
+	}
+	return nil
+}
+
+// carriedProvenance returns the provenance carried by a delta's header, by
+// entity key, if its source is trusted with it.
+func carriedProvenance(sources DataSources, header *gom.Header) (map[string]provenanceRecord, error) {
+	data, present := header.Userdata[DeltaProvenanceKey]
+	if !present || sources.Get(header.Source).Trust != TrustTrusted {
+		return nil, nil
+	}
+	errMalformed := errors.New("malformed delta provenance")
+	next := func() ([]byte, bool) {
+		length, size := binary.Uvarint(data)
+		if size <= 0 || length > uint64(len(data)-size) {
+			return nil, false
+		}
+		field := data[size : size+int(length)]
+		data = data[size+int(length):]
+		return field, true
+	}
+	carried := make(map[string]provenanceRecord)
+	for len(data) > 0 {
+		key, okKey := next()
+		encoded, okRecord := next()
+		if !okKey || !okRecord {
+			return nil, errMalformed
+		}
+		record, err := unmarshalProvenance(encoded)
+		if err != nil {
+			return nil, err
+		}
+		carried[string(key)] = record
+	}
+	return carried, nil
+}
+
+// deltaRange returns the base and target of a delta's header; isDelta is
+// false for full dumps.
+func deltaRange(header *gom.Header) (base, target uint64, isDelta bool, err error) {
+	baseText, hasBase := header.Userdata[DeltaBaseKey]
+	targetText, hasTarget := header.Userdata[DeltaTargetKey]
+	if !hasBase && !hasTarget {
+		return 0, 0, false, nil
+	}
+	if base, err = strconv.ParseUint(string(baseText), 10, 64); err == nil {
+		target, err = strconv.ParseUint(string(targetText), 10, 64)
+	}
+	if err != nil {
+		return 0, 0, true, fmt.Errorf("malformed delta range: %s-%s", baseText, targetText)
+	}
+	return base, target, true, nil
+}
+
+// checkDeltaBase returns an error if header is of a delta that db hasn't
+// caught up with the base of.
+func checkDeltaBase(db *Database, header *gom.Header) error {
+	base, _, isDelta, err := deltaRange(header)
+	if err != nil || !isDelta {
+		return err
+	}
+	synced, err := db.SyncedTo()
+	if err != nil {
+		return err
+	}
+	if base > synced {
+		return fmt.Errorf("%w: it starts from %s but the database is synced to %s", ErrDeltaBase,
+			formatDeltaTime(base), formatDeltaTime(synced))
+	}
+	return nil
+}
+
+// syncPoint returns what importing all of header's dump or delta syncs a
+// database to: a delta's target or a dump's time. known is false for files
+// that don't say.
+func syncPoint(header *gom.Header) (point uint64, known bool, err error) {
+	_, target, isDelta, err := deltaRange(header)
+	if err != nil || isDelta {
+		return target, isDelta, err
+	}
+	dumped, present := header.Userdata[DumpTimeKey]
+	if !present {
+		return 0, false, nil
+	}
+	if point, err = strconv.ParseUint(string(dumped), 10, 64); err != nil {
+		return 0, false, fmt.Errorf("malformed dump time: %s", dumped)
+	}
+	return point, true, nil
+}
+
+// recordSync notes that the dump or delta with header has been applied to db.
+func recordSync(db *Database, header *gom.Header) error {
+	target, known, err := syncPoint(header)
+	if err != nil || !known {
+		return err
+	}
+	synced, err := db.SyncedTo()
+	if err != nil || synced >= target {
+		return err
+	}
+	return db.setSyncedTo(target)
+}
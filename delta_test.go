@@ -0,0 +1,298 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	gom "github.com/kfsone/gomenacing/pkg/gomschema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func applyDeltaTestMessages(t *testing.T, sdb *SystemDatabase, db *Database, messages ...proto.Message) {
+	writer := newMessageWriter(sdb, db, "test")
+	defer writer.Close()
+	for _, message := range messages {
+		require.Nil(t, writer.apply(message))
+	}
+}
+
+func Test_parseDeltaTime(t *testing.T) {
+	now := time.Unix(1596283200, 0)
+	for text, expected := range map[string]uint64{
+		"1596283200":          1596283200,
+		"2020-08-01 12:00:00": 1596283200,
+		"2020-08-01":          1596240000,
+		"24h":                 1596196800,
+	} {
+		timestamp, err := parseDeltaTime(text, now)
+		assert.Nil(t, err, text)
+		assert.Equal(t, expected, timestamp, text)
+	}
+	_, err := parseDeltaTime("yesterday", now)
+	assert.Error(t, err)
+	_, err = parseDeltaTime("-24h", now)
+	assert.Error(t, err)
+}
+
+func TestDelta(t *testing.T) {
+	testDir := GetTestDir()
+	defer testDir.Close()
+	publisherDb, err := OpenDatabase(testDir.Path(), "publisher.db")
+	require.Nil(t, err)
+	publisher := NewSystemDatabase(publisherDb)
+	applyDeltaTestMessages(t, publisher, publisherDb,
+		&gom.Commodity{Id: 1, Name: "Gold"},
+		&gom.Commodity{Id: 2, Name: "Silver"},
+		&gom.System{Id: 1, Name: "Sol", TimestampUtc: 100, Position: &gom.Coordinate{}},
+		&gom.Facility{Id: 1, SystemId: 1, Name: "Galileo", TimestampUtc: 100},
+		&gom.FacilityListing{Id: 1, Listings: []*gom.CommodityListing{
+			{CommodityId: 1, SupplyCredits: 9000, TimestampUtc: 100},
+			{CommodityId: 2, SupplyCredits: 4000, TimestampUtc: 100},
+		}},
+	)
+
+	fullDir := filepath.Join(testDir.Path(), "full")
+	target, files, err := ExportDelta(publisherDb, fullDir, 0, "")
+	require.Nil(t, err)
+	assert.EqualValues(t, 100, target)
+	assert.Contains(t, files, DeltaFile{"systems.gom.zst", 1})
+
+	// Later, Alpha Centauri is added, Galileo is surveyed and its gold price changes.
+	applyDeltaTestMessages(t, publisher, publisherDb,
+		&gom.Facility{Id: 1, SystemId: 1, Name: "Galileo", TimestampUtc: 200, LsFromStar: 505},
+		&gom.System{Id: 2, Name: "Alpha Centauri", TimestampUtc: 200, Position: &gom.Coordinate{X: 4.4}},
+		&gom.Facility{Id: 2, SystemId: 2, Name: "Hutton Orbital", TimestampUtc: 200},
+		&gom.FacilityListing{Id: 1, Listings: []*gom.CommodityListing{
+			{CommodityId: 1, SupplyCredits: 9500, TimestampUtc: 250},
+			{CommodityId: 2, SupplyCredits: 4000, TimestampUtc: 100},
+		}},
+	)
+	var output bytes.Buffer
+	publisherRepl := &Repl{db: publisherDb, sdb: publisher, out: &output}
+	deltaDir := filepath.Join(testDir.Path(), "delta")
+	cmdExportDelta(publisherRepl, strings.Fields(deltaDir+" since 100"), nil)
+	assert.Contains(t, output.String(), "- commodities.gom.zst: 2 items\n- systems.gom.zst: 1 items\n- stations.gom.zst: 2 items\n- listings.gom.zst: 1 items\n")
+	assert.Contains(t, output.String(), "Wrote changes from 1970-01-01 00:01:40 to 1970-01-01 00:04:10 into "+deltaDir)
+
+	receiverDb, err := OpenDatabase(testDir.Path(), "receiver.db")
+	require.Nil(t, err)
+	output.Reset()
+	receiver := &Repl{db: receiverDb, sdb: NewSystemDatabase(receiverDb), out: &output}
+
+	// The delta needs the data it was based on.
+	cmdImport(receiver, []string{deltaDir}, nil)
+	assert.Contains(t, output.String(), "delta does not follow on from the database: it starts from 1970-01-01 00:01:40 but the database is synced to the beginning")
+	assert.Nil(t, receiver.sdb.GetCommodity("Gold"))
+
+	cmdImport(receiver, []string{fullDir}, nil)
+	synced, err := receiverDb.SyncedTo()
+	require.Nil(t, err)
+	assert.EqualValues(t, 100, synced)
+	assert.EqualValues(t, 9000, receiver.sdb.GetFacilityByID(1).listings[1].StationAsks)
+
+	// A delta is only recorded once all of it has been applied, not file by file.
+	brokenDir := filepath.Join(testDir.Path(), "broken")
+	_, err = ensureDirectory(brokenDir)
+	require.Nil(t, err)
+	for _, info := range deltaSchemas {
+		data, err := ioutil.ReadFile(filepath.Join(deltaDir, info.filename))
+		require.Nil(t, err)
+		if info.schema == "facilities" {
+			data = data[:len(data)/2]
+		}
+		require.Nil(t, ioutil.WriteFile(filepath.Join(brokenDir, info.filename), data, 0640))
+	}
+	cmdImport(receiver, []string{filepath.Join(deltaDir, "systems.gom.zst")}, nil)
+	cmdImport(receiver, []string{brokenDir}, nil)
+	synced, err = receiverDb.SyncedTo()
+	require.Nil(t, err)
+	assert.EqualValues(t, 100, synced)
+	assert.NotNil(t, receiver.sdb.GetSystem("Alpha Centauri"))
+
+	// Applying the delta, even more than once, brings the receiver up to date.
+	for pass := 0; pass < 2; pass++ {
+		output.Reset()
+		cmdImport(receiver, []string{deltaDir}, nil)
+		assert.NotContains(t, output.String(), "delta does not follow on")
+		synced, err = receiverDb.SyncedTo()
+		require.Nil(t, err)
+		assert.EqualValues(t, 250, synced)
+		assert.Len(t, receiver.sdb.systemsByID, 2)
+		assert.NotNil(t, receiver.sdb.GetSystem("Alpha Centauri").GetFacility("Hutton Orbital"))
+		assert.Equal(t, publisher.GetFacilityByID(1).listings, receiver.sdb.GetFacilityByID(1).listings)
+	}
+
+	// An older delta is harmless too.
+	cmdImport(receiver, []string{fullDir}, nil)
+	synced, err = receiverDb.SyncedTo()
+	require.Nil(t, err)
+	assert.EqualValues(t, 250, synced)
+	assert.EqualValues(t, 9500, receiver.sdb.GetFacilityByID(1).listings[1].StationAsks)
+	assert.EqualValues(t, 505, receiver.sdb.GetFacilityByID(1).LsFromStar)
+
+	// What was stored survives a reload.
+	reloaded := NewSystemDatabase(receiverDb)
+	require.Nil(t, receiverDb.LoadDatabase(reloaded))
+	assert.Equal(t, publisher.GetFacilityByID(1).listings, reloaded.GetFacilityByID(1).listings)
+	assert.EqualValues(t, 505, reloaded.GetFacilityByID(1).LsFromStar)
+}
+
+func TestDelta_followsFullDump(t *testing.T) {
+	testDir := GetTestDir()
+	defer testDir.Close()
+	publisherDb, err := OpenDatabase(testDir.Path(), "publisher.db")
+	require.Nil(t, err)
+	publisher := NewSystemDatabase(publisherDb)
+	applyDeltaTestMessages(t, publisher, publisherDb,
+		&gom.Commodity{Id: 1, Name: "Gold"},
+		&gom.System{Id: 1, Name: "Sol", TimestampUtc: 100, Position: &gom.Coordinate{}},
+		&gom.Facility{Id: 1, SystemId: 1, Name: "Galileo", TimestampUtc: 120},
+		&gom.FacilityListing{Id: 1, Listings: []*gom.CommodityListing{
+			{CommodityId: 1, SupplyCredits: 9000, TimestampUtc: 150},
+		}},
+	)
+	var output bytes.Buffer
+	publisherRepl := &Repl{db: publisherDb, sdb: publisher, out: &output}
+	dumpDir := filepath.Join(testDir.Path(), "dump")
+	cmdExportDump(publisherRepl, []string{dumpDir}, nil)
+	assert.Contains(t, output.String(), "Wrote everything up to 1970-01-01 00:02:30 into "+dumpDir)
+
+	applyDeltaTestMessages(t, publisher, publisherDb,
+		&gom.FacilityListing{Id: 1, Listings: []*gom.CommodityListing{
+			{CommodityId: 1, SupplyCredits: 9500, TimestampUtc: 300},
+		}},
+	)
+	deltaDir := filepath.Join(testDir.Path(), "delta")
+	cmdExportDelta(publisherRepl, strings.Fields(deltaDir+" since 150"), nil)
+
+	// Importing the dump syncs the receiver to it, so the delta follows on.
+	receiverDb, err := OpenDatabase(testDir.Path(), "receiver.db")
+	require.Nil(t, err)
+	output.Reset()
+	receiver := &Repl{db: receiverDb, sdb: NewSystemDatabase(receiverDb), out: &output}
+	cmdImport(receiver, []string{dumpDir}, nil)
+	synced, err := receiverDb.SyncedTo()
+	require.Nil(t, err)
+	assert.EqualValues(t, 150, synced)
+
+	cmdImport(receiver, []string{deltaDir}, nil)
+	assert.NotContains(t, output.String(), "delta does not follow on")
+	synced, err = receiverDb.SyncedTo()
+	require.Nil(t, err)
+	assert.EqualValues(t, 300, synced)
+	assert.EqualValues(t, 9500, receiver.sdb.GetFacilityByID(1).listings[1].StationAsks)
+
+	// A database built some other way can say where it is synced to.
+	otherDb, err := OpenDatabase(testDir.Path(), "other.db")
+	require.Nil(t, err)
+	output.Reset()
+	other := &Repl{db: otherDb, sdb: NewSystemDatabase(otherDb), out: &output}
+	applyDeltaTestMessages(t, other.sdb, otherDb,
+		&gom.Commodity{Id: 1, Name: "Gold"},
+		&gom.System{Id: 1, Name: "Sol", TimestampUtc: 100, Position: &gom.Coordinate{}},
+		&gom.Facility{Id: 1, SystemId: 1, Name: "Galileo", TimestampUtc: 120},
+	)
+	cmdDbSynced(other, []string{"150"}, nil)
+	assert.Contains(t, output.String(), "Synced to 1970-01-01 00:02:30.")
+	cmdImport(other, []string{deltaDir}, nil)
+	assert.NotContains(t, output.String(), "delta does not follow on")
+	assert.EqualValues(t, 9500, other.sdb.GetFacilityByID(1).listings[1].StationAsks)
+}
+
+func TestDelta_carriesProvenance(t *testing.T) {
+	testDir := GetTestDir()
+	defer testDir.Close()
+	sourcesPath := filepath.Join(testDir.Path(), "sources")
+	require.Nil(t, ioutil.WriteFile(sourcesPath, []byte(testSourcesFile), 0644))
+	defer func(saved string) { *SourcesFile = saved }(*SourcesFile)
+	*SourcesFile = sourcesPath
+
+	feed := func(timestamp uint64, credits uint32) []proto.Message {
+		return []proto.Message{
+			&gom.Commodity{Id: 1, Name: "Gold"},
+			&gom.System{Id: 1, Name: "Sol", TimestampUtc: 100, Position: &gom.Coordinate{}},
+			&gom.Facility{Id: 1, SystemId: 1, Name: "Galileo", TimestampUtc: timestamp, LsFromStar: 500},
+			&gom.FacilityListing{Id: 1, Listings: []*gom.CommodityListing{
+				{CommodityId: 1, SupplyCredits: credits, TimestampUtc: timestamp},
+			}},
+		}
+	}
+	openRepl := func(name string) *Repl {
+		db, err := OpenDatabase(testDir.Path(), name)
+		require.Nil(t, err)
+		return &Repl{db: db, sdb: NewSystemDatabase(db), out: &bytes.Buffer{}}
+	}
+	apply := func(r *Repl, source string, messages ...proto.Message) {
+		writer := newMessageWriter(r.sdb, r.db, source)
+		defer writer.Close()
+		for _, message := range messages {
+			require.Nil(t, writer.apply(message))
+		}
+	}
+
+	// A curator follows the feed and corrects it by hand.
+	curator := openRepl("curator.db")
+	apply(curator, "eddn", feed(200, 9500)...)
+	apply(curator, "manual", &gom.Facility{Id: 1, SystemId: 1, Name: "Galileo", TimestampUtc: 250, LsFromStar: 505})
+	deltaDir := filepath.Join(testDir.Path(), "curated")
+	cmdExportDelta(curator, strings.Fields(deltaDir+" as curator"), nil)
+	file, err := os.Open(filepath.Join(deltaDir, "stations.gom.zst"))
+	require.Nil(t, err)
+	defer file.Close()
+	gomFile, err := gom.OpenGOMFile(file)
+	require.Nil(t, err)
+	assert.Equal(t, "curator", gomFile.Header().Source)
+	assert.Contains(t, gomFile.Header().Userdata, DeltaProvenanceKey)
+	gomFile.Close()
+
+	// The curator isn't registered, so ranks below the feed, but what it
+	// passes on from the feed and manual corrections are ranked as such.
+	receiver := openRepl("receiver.db")
+	apply(receiver, "eddn", feed(100, 9000)...)
+	cmdImport(receiver, []string{deltaDir}, nil)
+	facility := receiver.sdb.GetFacilityByID(1)
+	assert.EqualValues(t, 9500, facility.listings[1].StationAsks)
+	assert.EqualValues(t, 505, facility.LsFromStar)
+	record, err := receiver.db.GetProvenance("facilities", 1)
+	require.Nil(t, err)
+	assert.Equal(t, Provenance{"manual", 250}, provenanceRecord(record).get("ls_from_star", Provenance{}))
+	record, err = receiver.db.GetProvenance("listings", 1)
+	require.Nil(t, err)
+	assert.Equal(t, Provenance{"eddn", 200}, provenanceRecord(record).get(listingField(1), Provenance{}))
+
+	// An untrusted exporter can't vouch for anyone else's data.
+	cmdExportDelta(curator, strings.Fields(deltaDir+" as community"), nil)
+	doubter := openRepl("doubter.db")
+	apply(doubter, "eddn", feed(100, 9000)...)
+	cmdImport(doubter, []string{deltaDir}, nil)
+	assert.EqualValues(t, 9000, doubter.sdb.GetFacilityByID(1).listings[1].StationAsks)
+	assert.EqualValues(t, 500, doubter.sdb.GetFacilityByID(1).LsFromStar)
+}
+
+func Test_carriedProvenance(t *testing.T) {
+	record := provenanceRecord{"": {"eddn", 100}}
+	var data bytes.Buffer
+	for _, field := range [][]byte{messageKey(7), record.marshal()} {
+		data.WriteByte(byte(len(field)))
+		data.Write(field)
+	}
+	header := &gom.Header{Source: "curator", Userdata: map[string][]byte{DeltaProvenanceKey: data.Bytes()}}
+	carried, err := carriedProvenance(DataSources{}, header)
+	require.Nil(t, err)
+	assert.Equal(t, map[string]provenanceRecord{string(messageKey(7)): record}, carried)
+
+	carried, err = carriedProvenance(DataSources{"curator": {Name: "curator", Trust: TrustUntrusted}}, header)
+	assert.Nil(t, err)
+	assert.Nil(t, carried)
+
+	header.Userdata[DeltaProvenanceKey] = data.Bytes()[:data.Len()-1]
+	_, err = carriedProvenance(DataSources{}, header)
+	assert.Error(t, err)
+}
@@ -0,0 +1,499 @@
+package main
+
+// "db diff" compares two copies of the data entity by entity, matching them
+// by id. Either side may be a database directory, a directory of GOM files
+// such as a dump or delta, a single GOM file, or a "db backup" archive.
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	gom "github.com/kfsone/gomenacing/pkg/gomschema"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Kinds of entity a diff reports on, in the order they're reported.
+const (
+	DiffCommodity = "commodity"
+	DiffSystem    = "system"
+	DiffFacility  = "facility"
+	DiffListing   = "listing"
+)
+
+var diffKinds = []string{DiffCommodity, DiffSystem, DiffFacility, DiffListing}
+
+// What happened to an entity between the two sides of a diff.
+const (
+	DiffAdded   = "added"
+	DiffRemoved = "removed"
+	DiffChanged = "changed"
+)
+
+// DiffSet is everything a diff compares from one side.
+type DiffSet struct {
+	commodities map[uint32]*gom.Commodity
+	systems     map[uint32]*gom.System
+	facilities  map[uint32]*gom.Facility
+	listings    map[uint32]*gom.FacilityListing
+}
+
+func newDiffSet() *DiffSet {
+	return &DiffSet{
+		commodities: make(map[uint32]*gom.Commodity),
+		systems:     make(map[uint32]*gom.System),
+		facilities:  make(map[uint32]*gom.Facility),
+		listings:    make(map[uint32]*gom.FacilityListing),
+	}
+}
+
+// add records message if it's of a kind that's compared.
+func (s *DiffSet) add(message proto.Message) {
+	switch m := message.(type) {
+	case *gom.Commodity:
+		s.commodities[m.Id] = m
+	case *gom.System:
+		s.systems[m.Id] = m
+	case *gom.Facility:
+		s.facilities[m.Id] = m
+	case *gom.FacilityListing:
+		s.listings[m.Id] = m
+	}
+}
+
+func (s *DiffSet) empty() bool {
+	return len(s.commodities) == 0 && len(s.systems) == 0 && len(s.facilities) == 0 && len(s.listings) == 0
+}
+
+func (s *DiffSet) commodityName(id uint32) string {
+	if commodity := s.commodities[id]; commodity != nil {
+		return commodity.Name
+	}
+	return fmt.Sprintf("#%d", id)
+}
+
+func (s *DiffSet) systemName(id uint32) string {
+	if system := s.systems[id]; system != nil {
+		return system.Name
+	}
+	return fmt.Sprintf("#%d", id)
+}
+
+func (s *DiffSet) facilityName(id uint32) string {
+	if facility := s.facilities[id]; facility != nil {
+		return s.systemName(facility.SystemId) + "/" + facility.Name
+	}
+	return fmt.Sprintf("#%d", id)
+}
+
+// readGOM adds the messages of a GOM stream to the set.
+func (s *DiffSet) readGOM(source io.Reader) error {
+	gomFile, err := gom.OpenGOMFile(source)
+	if err != nil {
+		return err
+	}
+	defer gomFile.Close()
+	// The reader reuses its message, so keep copies.
+	return gomFile.Read(func(message proto.Message, _ uint) error {
+		s.add(proto.Clone(message))
+		return nil
+	})
+}
+
+func (s *DiffSet) readGOMFile(pathname string) error {
+	file, err := os.Open(pathname)
+	if err != nil {
+		return err
+	}
+	defer func() { Must(file.Close()) }()
+	if err = s.readGOM(file); err != nil {
+		return fmt.Errorf("%s: %w", pathname, err)
+	}
+	return nil
+}
+
+// readDatabase adds the stored entities of the database at path.
+func (s *DiffSet) readDatabase(path string) error {
+	db := &Database{storePath: path}
+	for _, info := range []struct {
+		schema     string
+		newMessage func() proto.Message
+	}{
+		{"commodities", func() proto.Message { return &gom.Commodity{} }},
+		{"systems", func() proto.Message { return &gom.System{} }},
+		{"facilities", func() proto.Message { return &gom.Facility{} }},
+		{"listings", func() proto.Message { return &gom.FacilityListing{} }},
+	} {
+		schema, err := db.GetSchema(info.schema)
+		if err != nil {
+			return err
+		}
+		err = schema.Iterate(func(_, value []byte) error {
+			message := info.newMessage()
+			if err := proto.Unmarshal(value, message); err != nil {
+				return err
+			}
+			s.add(message)
+			return nil
+		})
+		if closeErr := schema.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", info.schema, err)
+		}
+	}
+	return nil
+}
+
+// readBackup adds the contents of a "db backup" archive.
+func (s *DiffSet) readBackup(pathname string) error {
+	file, err := os.Open(pathname)
+	if err != nil {
+		return err
+	}
+	defer func() { Must(file.Close()) }()
+	decompressor, err := gzip.NewReader(file)
+	if err != nil {
+		return fmt.Errorf("%s: not a GOM file or backup: %w", pathname, err)
+	}
+	archive := tar.NewReader(decompressor)
+	for {
+		header, err := archive.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", pathname, err)
+		}
+		if !isGOMFilename(header.Name) {
+			continue
+		}
+		if err = s.readGOM(archive); err != nil {
+			return fmt.Errorf("%s: %s: %w", pathname, header.Name, err)
+		}
+	}
+}
+
+// LoadDiffSet reads what's to be compared from pathname.
+func LoadDiffSet(pathname string) (*DiffSet, error) {
+	info, err := os.Stat(pathname)
+	if err != nil {
+		return nil, err
+	}
+	set := newDiffSet()
+	switch {
+	case !info.IsDir() && isGOMFilename(pathname):
+		err = set.readGOMFile(pathname)
+	case !info.IsDir():
+		err = set.readBackup(pathname)
+	case isDirectory(filepath.Join(pathname, "systems")):
+		err = set.readDatabase(pathname)
+	default:
+		for _, filename := range GetImportFilenames() {
+			if found := findImportFile(pathname, filename); found != "" {
+				if err = set.readGOMFile(found); err != nil {
+					break
+				}
+			}
+		}
+	}
+	if err == nil && set.empty() {
+		err = fmt.Errorf("%s: no systems, stations, commodities or listings found", pathname)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return set, nil
+}
+
+func isDirectory(pathname string) bool {
+	info, err := os.Stat(pathname)
+	return err == nil && info.IsDir()
+}
+
+// FieldChange is the before and after of one field of a changed entity.
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// DiffChange describes an entity that differs between the two sides.
+type DiffChange struct {
+	Kind   string        `json:"kind"`
+	Change string        `json:"change"`
+	ID     string        `json:"id"`
+	Name   string        `json:"name"`
+	Fields []FieldChange `json:"fields,omitempty"`
+}
+
+func (c DiffChange) String() string {
+	marker := map[string]string{DiffAdded: "+", DiffRemoved: "-", DiffChanged: "~"}[c.Change]
+	text := fmt.Sprintf("%s %s %s", marker, c.Kind, c.Name)
+	if len(c.Fields) > 0 {
+		fields := make([]string, 0, len(c.Fields))
+		for _, field := range c.Fields {
+			fields = append(fields, fmt.Sprintf("%s %s -> %s", field.Field, field.Old, field.New))
+		}
+		text += ": " + strings.Join(fields, ", ")
+	}
+	return text
+}
+
+// DiffSummary counts the changes to one kind of entity.
+type DiffSummary struct {
+	Added   int `json:"added"`
+	Removed int `json:"removed"`
+	Changed int `json:"changed"`
+}
+
+// DatabaseDiff is the result of comparing two DiffSets.
+type DatabaseDiff struct {
+	From    string                 `json:"from"`
+	To      string                 `json:"to"`
+	Changes []DiffChange           `json:"changes"`
+	Summary map[string]DiffSummary `json:"summary"`
+}
+
+// fieldValue is one flattened field of a message.
+type fieldValue struct {
+	name, value string
+}
+
+// formatField formats a scalar field value for people.
+func formatField(fd protoreflect.FieldDescriptor, value protoreflect.Value) string {
+	switch fd.Kind() {
+	case protoreflect.EnumKind:
+		if enum := fd.Enum().Values().ByNumber(value.Enum()); enum != nil {
+			return string(enum.Name())
+		}
+		return fmt.Sprint(value.Enum())
+	case protoreflect.BytesKind:
+		return hex.EncodeToString(value.Bytes())
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		parts := make([]string, 0)
+		for _, field := range flattenMessage("", value.Message(), nil) {
+			parts = append(parts, field.name+"="+field.value)
+		}
+		return "{" + strings.Join(parts, " ") + "}"
+	}
+	return fmt.Sprint(value.Interface())
+}
+
+// flattenMessage lists the fields of message, bar those in skip, in
+// declaration order; nested messages are flattened into dotted names.
+func flattenMessage(prefix string, message protoreflect.Message, skip map[string]bool) []fieldValue {
+	var values []fieldValue
+	fields := message.Descriptor().Fields()
+	for idx := 0; idx < fields.Len(); idx++ {
+		fd := fields.Get(idx)
+		name := prefix + string(fd.Name())
+		if skip[name] {
+			continue
+		}
+		value := message.Get(fd)
+		switch {
+		case fd.IsList():
+			list := value.List()
+			items := make([]string, list.Len())
+			for item := range items {
+				items[item] = formatField(fd, list.Get(item))
+			}
+			values = append(values, fieldValue{name, "[" + strings.Join(items, " ") + "]"})
+		case fd.IsMap():
+			var items []string
+			value.Map().Range(func(key protoreflect.MapKey, value protoreflect.Value) bool {
+				items = append(items, key.String()+":"+formatField(fd.MapValue(), value))
+				return true
+			})
+			sort.Strings(items)
+			values = append(values, fieldValue{name, "[" + strings.Join(items, " ") + "]"})
+		case fd.Kind() == protoreflect.MessageKind:
+			values = append(values, flattenMessage(name+".", value.Message(), skip)...)
+		default:
+			values = append(values, fieldValue{name, formatField(fd, value)})
+		}
+	}
+	return values
+}
+
+// diffFields returns the fields that differ between two messages of the same type.
+func diffFields(from, to proto.Message, skip ...string) []FieldChange {
+	skipped := make(map[string]bool, len(skip))
+	for _, name := range skip {
+		skipped[name] = true
+	}
+	before := flattenMessage("", from.ProtoReflect(), skipped)
+	after := flattenMessage("", to.ProtoReflect(), skipped)
+	var changes []FieldChange
+	for idx := range before {
+		if before[idx].value != after[idx].value {
+			changes = append(changes, FieldChange{before[idx].name, before[idx].value, after[idx].value})
+		}
+	}
+	return changes
+}
+
+// diffEntities compares the messages of one kind, keyed by id.
+func diffEntities(kind string, ids []uint32, from, to func(uint32) proto.Message, name func(uint32, bool) string, skip ...string) []DiffChange {
+	var changes []DiffChange
+	for _, id := range ids {
+		before, after := from(id), to(id)
+		change := DiffChange{Kind: kind, ID: fmt.Sprint(id)}
+		switch {
+		case before == nil:
+			change.Change, change.Name = DiffAdded, name(id, true)
+		case after == nil:
+			change.Change, change.Name = DiffRemoved, name(id, false)
+		default:
+			if change.Fields = diffFields(before, after, skip...); change.Fields == nil {
+				continue
+			}
+			change.Change, change.Name = DiffChanged, name(id, true)
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+// unionIDs returns the ids of either map, sorted.
+func unionIDs(from, to interface{}) []uint32 {
+	seen := make(map[uint32]bool)
+	for _, ids := range []interface{}{from, to} {
+		for _, key := range mapKeys(ids) {
+			seen[key] = true
+		}
+	}
+	ids := make([]uint32, 0, len(seen))
+	for id := range seen {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+func mapKeys(entities interface{}) []uint32 {
+	var keys []uint32
+	switch m := entities.(type) {
+	case map[uint32]*gom.Commodity:
+		for key := range m {
+			keys = append(keys, key)
+		}
+	case map[uint32]*gom.System:
+		for key := range m {
+			keys = append(keys, key)
+		}
+	case map[uint32]*gom.Facility:
+		for key := range m {
+			keys = append(keys, key)
+		}
+	case map[uint32]*gom.FacilityListing:
+		for key := range m {
+			keys = append(keys, key)
+		}
+	case map[uint32]*gom.CommodityListing:
+		for key := range m {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// listingItems indexes the items of a facility's listing by commodity.
+func listingItems(listing *gom.FacilityListing) map[uint32]*gom.CommodityListing {
+	items := make(map[uint32]*gom.CommodityListing)
+	if listing != nil {
+		for _, item := range listing.Listings {
+			items[item.CommodityId] = item
+		}
+	}
+	return items
+}
+
+// DiffDatabases compares from with to, reporting what to added, removed or changed.
+func DiffDatabases(from, to *DiffSet) []DiffChange {
+	// Names come from the side the entity is on, preferring the newer one.
+	side := func(present bool) *DiffSet {
+		if present {
+			return to
+		}
+		return from
+	}
+	var changes []DiffChange
+
+	changes = append(changes, diffEntities(DiffCommodity, unionIDs(from.commodities, to.commodities),
+		func(id uint32) proto.Message { return nilMessage(from.commodities[id]) },
+		func(id uint32) proto.Message { return nilMessage(to.commodities[id]) },
+		func(id uint32, present bool) string { return side(present).commodityName(id) }, "id")...)
+	changes = append(changes, diffEntities(DiffSystem, unionIDs(from.systems, to.systems),
+		func(id uint32) proto.Message { return nilMessage(from.systems[id]) },
+		func(id uint32) proto.Message { return nilMessage(to.systems[id]) },
+		func(id uint32, present bool) string { return side(present).systemName(id) }, "id")...)
+	changes = append(changes, diffEntities(DiffFacility, unionIDs(from.facilities, to.facilities),
+		func(id uint32) proto.Message { return nilMessage(from.facilities[id]) },
+		func(id uint32) proto.Message { return nilMessage(to.facilities[id]) },
+		func(id uint32, present bool) string { return side(present).facilityName(id) }, "id")...)
+
+	// Listings are compared item by item.
+	for _, facilityID := range unionIDs(from.listings, to.listings) {
+		before, after := listingItems(from.listings[facilityID]), listingItems(to.listings[facilityID])
+		for _, change := range diffEntities(DiffListing, unionIDs(before, after),
+			func(id uint32) proto.Message { return nilMessage(before[id]) },
+			func(id uint32) proto.Message { return nilMessage(after[id]) },
+			func(id uint32, present bool) string {
+				return side(present).facilityName(facilityID) + "/" + side(present).commodityName(id)
+			}, "commodity_id") {
+			change.ID = fmt.Sprintf("%d/%s", facilityID, change.ID)
+			changes = append(changes, change)
+		}
+	}
+	return changes
+}
+
+// nilMessage turns a nil pointer of a message type into a nil interface.
+func nilMessage(message proto.Message) proto.Message {
+	if message == nil || !message.ProtoReflect().IsValid() {
+		return nil
+	}
+	return message
+}
+
+// NewDatabaseDiff loads and compares the data at fromPath and toPath.
+func NewDatabaseDiff(fromPath, toPath string) (*DatabaseDiff, error) {
+	from, err := LoadDiffSet(fromPath)
+	if err != nil {
+		return nil, err
+	}
+	to, err := LoadDiffSet(toPath)
+	if err != nil {
+		return nil, err
+	}
+	diff := &DatabaseDiff{From: fromPath, To: toPath, Changes: DiffDatabases(from, to), Summary: make(map[string]DiffSummary)}
+	for _, kind := range diffKinds {
+		diff.Summary[kind] = DiffSummary{}
+	}
+	for _, change := range diff.Changes {
+		summary := diff.Summary[change.Kind]
+		switch change.Change {
+		case DiffAdded:
+			summary.Added++
+		case DiffRemoved:
+			summary.Removed++
+		case DiffChanged:
+			summary.Changed++
+		}
+		diff.Summary[change.Kind] = summary
+	}
+	if diff.Changes == nil {
+		diff.Changes = []DiffChange{}
+	}
+	return diff, nil
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	gom "github.com/kfsone/gomenacing/pkg/gomschema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func Test_diffFields(t *testing.T) {
+	from := &gom.System{Id: 1, Name: "Sol", Position: &gom.Coordinate{X: 1}, SecurityLevel: gom.SecurityLevel_SecurityLow}
+	to := &gom.System{Id: 2, Name: "Sol", Position: &gom.Coordinate{X: 1, Z: 2.5}, SecurityLevel: gom.SecurityLevel_SecurityHigh}
+	assert.Equal(t, []FieldChange{
+		{"position.z", "0", "2.5"},
+		{"security_level", "SecurityLow", "SecurityHigh"},
+	}, diffFields(from, to, "id"))
+	assert.Nil(t, diffFields(from, proto.Clone(from)))
+
+	// An unset position is the same as the origin.
+	assert.Nil(t, diffFields(&gom.System{}, &gom.System{Position: &gom.Coordinate{}}))
+}
+
+func TestDiffDatabases(t *testing.T) {
+	testDir := GetTestDir()
+	defer testDir.Close()
+	db, err := OpenDatabase(testDir.Path(), "diff.db")
+	require.Nil(t, err)
+	sdb := NewSystemDatabase(db)
+	applyDeltaTestMessages(t, sdb, db,
+		&gom.Commodity{Id: 1, Name: "Gold"},
+		&gom.Commodity{Id: 2, Name: "Silver"},
+		&gom.System{Id: 1, Name: "Sol", TimestampUtc: 100, Position: &gom.Coordinate{}},
+		&gom.Facility{Id: 1, SystemId: 1, Name: "Galileo", TimestampUtc: 100, LsFromStar: 500},
+		&gom.Facility{Id: 2, SystemId: 1, Name: "Abraham Lincoln", TimestampUtc: 100},
+		&gom.FacilityListing{Id: 1, Listings: []*gom.CommodityListing{
+			{CommodityId: 1, SupplyCredits: 9000, TimestampUtc: 100},
+			{CommodityId: 2, SupplyCredits: 4000, TimestampUtc: 100},
+		}},
+	)
+	backupPath := filepath.Join(testDir.Path(), "yesterday.bak")
+	_, err = BackupDatabase(db, backupPath)
+	require.Nil(t, err)
+
+	applyDeltaTestMessages(t, sdb, db,
+		&gom.Facility{Id: 1, SystemId: 1, Name: "Galileo", TimestampUtc: 200, LsFromStar: 505},
+		&gom.System{Id: 2, Name: "Alpha Centauri", TimestampUtc: 200, Position: &gom.Coordinate{X: 4.4}},
+		&gom.FacilityListing{Id: 1, Listings: []*gom.CommodityListing{
+			{CommodityId: 1, SupplyCredits: 9500, TimestampUtc: 250},
+		}},
+		&gom.FacilityListing{Id: 2, Listings: []*gom.CommodityListing{
+			{CommodityId: 2, DemandCredits: 3900, TimestampUtc: 250},
+		}},
+	)
+
+	// Compare the backup with the database, and with a GOM dump of it.
+	dumpDir := filepath.Join(testDir.Path(), "dump")
+	_, _, err = ExportDelta(db, dumpDir, 0, "")
+	require.Nil(t, err)
+	for _, current := range []string{db.Path(), dumpDir} {
+		diff, err := NewDatabaseDiff(backupPath, current)
+		require.Nil(t, err, current)
+		assert.Equal(t, []DiffChange{
+			{Kind: DiffSystem, Change: DiffAdded, ID: "2", Name: "Alpha Centauri"},
+			{Kind: DiffFacility, Change: DiffChanged, ID: "1", Name: "Sol/Galileo", Fields: []FieldChange{
+				{"timestamp_utc", "100", "200"},
+				{"ls_from_star", "500", "505"},
+			}},
+			{Kind: DiffListing, Change: DiffChanged, ID: "1/1", Name: "Sol/Galileo/Gold", Fields: []FieldChange{
+				{"supply_credits", "9000", "9500"},
+				{"timestamp_utc", "100", "250"},
+			}},
+			{Kind: DiffListing, Change: DiffAdded, ID: "2/2", Name: "Sol/Abraham Lincoln/Silver"},
+		}, diff.Changes, current)
+		assert.Equal(t, DiffSummary{Added: 1, Changed: 1}, diff.Summary[DiffListing])
+		assert.Equal(t, DiffSummary{}, diff.Summary[DiffCommodity])
+	}
+
+	// Going the other way, the additions are removals.
+	diff, err := NewDatabaseDiff(dumpDir, backupPath)
+	require.Nil(t, err)
+	assert.Equal(t, DiffChange{Kind: DiffSystem, Change: DiffRemoved, ID: "2", Name: "Alpha Centauri"}, diff.Changes[0])
+
+	_, err = NewDatabaseDiff(backupPath, testDir.Path())
+	assert.Error(t, err)
+}
+
+func TestRepl_DbDiff(t *testing.T) {
+	testDir := GetTestDir()
+	defer testDir.Close()
+	db, err := OpenDatabase(testDir.Path(), "diff.db")
+	require.Nil(t, err)
+	sdb := NewSystemDatabase(db)
+	applyDeltaTestMessages(t, sdb, db, &gom.Commodity{Id: 1, Name: "Gold"}, &gom.Commodity{Id: 2, Name: "Silver"})
+	backupPath := filepath.Join(testDir.Path(), "yesterday.bak")
+	_, err = BackupDatabase(db, backupPath)
+	require.Nil(t, err)
+	applyDeltaTestMessages(t, sdb, db, &gom.Commodity{Id: 2, Name: "Silver", AverageCr: 4500})
+
+	var output bytes.Buffer
+	repl := &Repl{db: db, sdb: sdb, out: &output}
+	cmdDbDiff(repl, []string{backupPath, db.Path()}, nil)
+	assert.Equal(t, "~ commodity Silver: average_cr 0 -> 4500\n"+
+		"commodity: 0 added, 0 removed, 1 changed; system: 0 added, 0 removed, 0 changed; "+
+		"facility: 0 added, 0 removed, 0 changed; listing: 0 added, 0 removed, 0 changed\n", output.String())
+
+	output.Reset()
+	cmdDbDiff(repl, []string{backupPath, db.Path(), "json"}, nil)
+	var diff DatabaseDiff
+	require.Nil(t, json.Unmarshal(output.Bytes(), &diff))
+	assert.Equal(t, []DiffChange{{Kind: DiffCommodity, Change: DiffChanged, ID: "2", Name: "Silver",
+		Fields: []FieldChange{{"average_cr", "0", "4500"}}}}, diff.Changes)
+	assert.Equal(t, db.Path(), diff.To)
+
+	output.Reset()
+	cmdDbDiff(repl, strings.Fields(backupPath), nil)
+	assert.Contains(t, output.String(), "Please specify")
+}
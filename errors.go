@@ -9,3 +9,6 @@ var ErrDuplicateEntity = errors.New("duplicate")
 
 // ErrUnknownEntity represents detection that an ID references an unknown entity.
 var ErrUnknownEntity = errors.New("unknown")
+
+// ErrNoRoute represents failure to find a way between two points.
+var ErrNoRoute = errors.New("no route")
@@ -60,6 +60,42 @@ func DeserializeCommodity(into *Commodity, from *gom.Commodity, _ *SystemDatabas
 //////////////////////////////////////////////////////////////////////////////////////////
 // System
 
+func serializeFactions(from []FactionPresence) []*gom.FactionPresence {
+	if len(from) == 0 {
+		return nil
+	}
+	factions := make([]*gom.FactionPresence, len(from))
+	for idx, faction := range from {
+		factions[idx] = &gom.FactionPresence{FactionId: faction.FactionID, Influence: faction.Influence, States: copyStates(faction.States)}
+	}
+	return factions
+}
+
+func deserializeFactions(from []*gom.FactionPresence) []FactionPresence {
+	if len(from) == 0 {
+		return nil
+	}
+	factions := make([]FactionPresence, len(from))
+	for idx, faction := range from {
+		factions[idx] = FactionPresence{FactionID: faction.GetFactionId(), Influence: faction.GetInfluence(), States: copyStates(faction.GetStates())}
+	}
+	return factions
+}
+
+func copyEconomies(from []gom.EconomyType) []gom.EconomyType {
+	if len(from) == 0 {
+		return nil
+	}
+	return append([]gom.EconomyType(nil), from...)
+}
+
+func copyStates(from []gom.StateType) []gom.StateType {
+	if len(from) == 0 {
+		return nil
+	}
+	return append([]gom.StateType(nil), from...)
+}
+
 // SerializeSystem converts from a local System into a schema System
 func SerializeSystem(into *gom.System, from *System) {
 	into.Id = uint32(from.DbEntity.ID)
@@ -77,6 +113,12 @@ func SerializeSystem(into *gom.System, from *System) {
 	into.SecurityLevel = from.SecurityLevel
 	into.Government = from.Government
 	into.Allegiance = from.Allegiance
+	into.Population = from.Population
+	into.PrimaryEconomy = from.PrimaryEconomy
+	into.States = copyStates(from.States)
+	into.ControllingFactionId = from.ControllingFactionID
+	into.ControllingFaction = from.ControllingFaction
+	into.Factions = serializeFactions(from.Factions)
 }
 
 // DeserializeSystem converts from a schema System into a local System
@@ -91,6 +133,12 @@ func DeserializeSystem(into *System, from *gom.System, _ *SystemDatabase) error
 		into.SecurityLevel = from.SecurityLevel
 		into.Government = from.Government
 		into.Allegiance = from.Allegiance
+		into.Population = from.GetPopulation()
+		into.PrimaryEconomy = from.GetPrimaryEconomy()
+		into.States = copyStates(from.GetStates())
+		into.ControllingFactionID = from.GetControllingFactionId()
+		into.ControllingFaction = from.GetControllingFaction()
+		into.Factions = deserializeFactions(from.GetFactions())
 		return nil
 	} else {
 		return err
@@ -103,12 +151,20 @@ func DeserializeSystem(into *System, from *gom.System, _ *SystemDatabase) error
 // SerializeFacility converts from a local Facility into a schema Facility
 func SerializeFacility(into *gom.Facility, from *Facility) error {
 	into.Id = uint32(from.DbEntity.ID)
+	if from.System != nil {
+		into.SystemId = uint32(from.System.ID)
+	}
 	into.Name = from.DbEntity.DbName
 	into.TimestampUtc = from.TimestampUtc
 	into.FacilityType = from.FacilityType
 	into.Features = uint32(from.Features)
+	into.LsFromStar = from.LsFromStar
 	into.Government = from.Government
 	into.Allegiance = from.Allegiance
+	into.MarketId = from.MarketID
+	into.Economies = copyEconomies(from.Economies)
+	into.Body = from.Body
+	into.PlanetaryLanding = from.Landing
 	return nil
 }
 
@@ -136,6 +192,10 @@ func DeserializeFacility(into *Facility, from *gom.Facility, db *SystemDatabase)
 	into.LsFromStar = from.LsFromStar
 	into.Government = from.Government
 	into.Allegiance = from.Allegiance
+	into.MarketID = from.GetMarketId()
+	into.Economies = copyEconomies(from.GetEconomies())
+	into.Body = from.GetBody()
+	into.Landing = from.GetPlanetaryLanding()
 
 	return nil
 }
@@ -1,6 +1,12 @@
 package main
 
-import "testing"
+import (
+	"testing"
+
+	gom "github.com/kfsone/gomenacing/pkg/gomschema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
 
 func Test_validateEntityForSerialization(t *testing.T) {
 	type args struct {
@@ -32,3 +38,54 @@ func Test_validateEntityForSerialization(t *testing.T) {
 		})
 	}
 }
+
+func TestSerializeSystem(t *testing.T) {
+	system := NewSystem(DbEntity{ID: 42, DbName: "Lave"}, Coordinate{X: 75.75, Y: 48.75, Z: 70.75})
+	system.TimestampUtc = 1596909218
+	system.Populated = true
+	system.Government = gom.GovernmentType_GovDictatorship
+	system.Allegiance = gom.AllegianceType_AllegIndependent
+	system.Population = 1500000
+	system.PrimaryEconomy = gom.EconomyType_EcoAgriculture
+	system.States = []gom.StateType{gom.StateType_StateBoom, gom.StateType_StateElection}
+	system.ControllingFactionID = 7
+	system.ControllingFaction = "Lave Radio"
+	system.Factions = []FactionPresence{
+		{FactionID: 7, Influence: 61.5, States: []gom.StateType{gom.StateType_StateBoom}},
+		{FactionID: 8, Influence: 38.5},
+	}
+
+	message := &gom.System{Position: &gom.Coordinate{}}
+	SerializeSystem(message, system)
+	assert.EqualValues(t, 1500000, message.Population)
+	assert.Equal(t, gom.EconomyType_EcoAgriculture, message.PrimaryEconomy)
+	assert.Len(t, message.Factions, 2)
+
+	var copied System
+	require.Nil(t, DeserializeSystem(&copied, message, nil))
+	assert.Equal(t, *system, copied)
+	assert.True(t, copied.HasState(gom.StateType_StateElection))
+	assert.False(t, copied.HasState(gom.StateType_StateOutbreak))
+}
+
+func TestSerializeFacility(t *testing.T) {
+	sdb := NewSystemDatabase(nil)
+	system := NewSystem(DbEntity{ID: 7, DbName: "Shinrarta Dezhra"}, Coordinate{X: 55.71875, Y: 17.59375, Z: 27.15625})
+	require.Nil(t, sdb.registerSystem(system))
+	facility, err := NewFacility(DbEntity{ID: 9, DbName: "Jameson Memorial"}, system, gom.FacilityType_FTOrbisStarport, FeatLargePad|FeatCommodities)
+	require.Nil(t, err)
+	facility.LsFromStar = 347
+	facility.MarketID = 128666762
+	facility.Economies = []gom.EconomyType{gom.EconomyType_EcoHighTech, gom.EconomyType_EcoIndustrial}
+	facility.Body = "Shinrarta Dezhra A 1"
+
+	message := &gom.Facility{}
+	require.Nil(t, SerializeFacility(message, facility))
+	assert.EqualValues(t, 7, message.SystemId)
+	assert.EqualValues(t, 128666762, message.MarketId)
+	assert.False(t, message.PlanetaryLanding)
+
+	var copied Facility
+	require.Nil(t, DeserializeFacility(&copied, message, sdb))
+	assert.Equal(t, *facility, copied)
+}
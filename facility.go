@@ -16,7 +16,7 @@ const (
 	FeatDocking     = FacilityFeatureMask(1 << gom.FeatureBit_Docking)
 	FeatFleet       = FacilityFeatureMask(1 << gom.FeatureBit_Fleet)
 	FeatLargePad    = FacilityFeatureMask(1 << gom.FeatureBit_LargePad)
-	FeatMediumPad   = FacilityFeatureMask(1 << gom.FeatureBit_Market)
+	FeatMediumPad   = FacilityFeatureMask(1 << gom.FeatureBit_MediumPad)
 	FeatOutfitting  = FacilityFeatureMask(1 << gom.FeatureBit_Outfitting)
 	FeatPlanetary   = FacilityFeatureMask(1 << gom.FeatureBit_Planetary)
 	FeatRearm       = FacilityFeatureMask(1 << gom.FeatureBit_Rearm)
@@ -36,6 +36,12 @@ type Facility struct {
 	LsFromStar   uint32              // Distance from star.
 	Government   gom.GovernmentType  // Government operating the facility.
 	Allegiance   gom.AllegianceType  // Group to which the facility is allied.
+	MarketID     uint64              // Frontier's market id, if known.
+	Economies    []gom.EconomyType   // Economies of the facility, primary first.
+	Body         string              // Body the facility is on/orbiting, if known.
+	Landing      bool                // Whether reaching the facility requires a planetary landing.
+	Outfitting   *Inventory          // Modules for sale, if known.
+	Shipyard     *Inventory          // Ships for sale, if known.
 
 	listings map[EntityID]*Listing // Table of sales/purchases
 }
@@ -67,6 +73,34 @@ func (f *Facility) HasFeatures(featureMask FacilityFeatureMask) bool {
 	return f.Features&featureMask == featureMask
 }
 
+// IsCarrier returns true if the facility is a fleet carrier, which can move.
+func (f *Facility) IsCarrier() bool {
+	return f.FacilityType == gom.FacilityType_FTFleetCarrier
+}
+
+// IsPlanetary returns true if the facility is on a planet's surface.
+func (f *Facility) IsPlanetary() bool {
+	return f.Landing || f.HasFeatures(FeatPlanetary)
+}
+
+// PrimaryEconomy returns the facility's main economy, if known.
+func (f *Facility) PrimaryEconomy() gom.EconomyType {
+	if len(f.Economies) > 0 {
+		return f.Economies[0]
+	}
+	return gom.EconomyType_EcoNone
+}
+
+// HasEconomy returns true if economy is any of the facility's economies.
+func (f *Facility) HasEconomy(economy gom.EconomyType) bool {
+	for _, candidate := range f.Economies {
+		if candidate == economy {
+			return true
+		}
+	}
+	return false
+}
+
 func (f *Facility) IsTrading() bool {
 	return f.HasFeatures(FeatCommodities) || len(f.listings) > 0
 }
@@ -97,7 +97,7 @@ func TestFacility_HasFeatures(t *testing.T) {
 
 func TestFacility_IsTrading(t *testing.T) {
 	var facility Facility
-	listing := Listing{EntityID(1), 0, 0, 0, 0, 0}
+	listing := Listing{CommodityID: EntityID(1)}
 	listings := map[EntityID]*Listing{listing.CommodityID: &listing}
 	facility = Facility{}
 	assert.False(t, facility.IsTrading())
@@ -151,3 +151,31 @@ func TestFacility_SupportsPadSize(t *testing.T) {
 	assert.False(t, facility.SupportsPadSize(FacilityFeatureMask(0)))
 	assert.False(t, facility.SupportsPadSize(FeatRefuel))
 }
+
+func TestFacility_IsPlanetary(t *testing.T) {
+	facility := Facility{}
+	assert.False(t, facility.IsPlanetary())
+	facility.Features = FeatPlanetary
+	assert.True(t, facility.IsPlanetary())
+	facility.Features = 0
+	facility.Landing = true
+	assert.True(t, facility.IsPlanetary())
+}
+
+func TestFacility_Economies(t *testing.T) {
+	facility := Facility{}
+	assert.Equal(t, gom.EconomyType_EcoNone, facility.PrimaryEconomy())
+	assert.False(t, facility.HasEconomy(gom.EconomyType_EcoRefinery))
+
+	facility.Economies = []gom.EconomyType{gom.EconomyType_EcoRefinery, gom.EconomyType_EcoIndustrial}
+	assert.Equal(t, gom.EconomyType_EcoRefinery, facility.PrimaryEconomy())
+	assert.True(t, facility.HasEconomy(gom.EconomyType_EcoIndustrial))
+	assert.False(t, facility.HasEconomy(gom.EconomyType_EcoTourism))
+}
+
+func TestFacility_IsCarrier(t *testing.T) {
+	facility := Facility{FacilityType: gom.FacilityType_FTOrbisStarport}
+	assert.False(t, facility.IsCarrier())
+	facility.FacilityType = gom.FacilityType_FTFleetCarrier
+	assert.True(t, facility.IsCarrier())
+}
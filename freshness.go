@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	flag "github.com/spf13/pflag"
+)
+
+// MaxAge is the default limit on the age of market data used by queries.
+var MaxAge = flag.Duration("maxage", 0, "Ignore market data older than this, e.g. 72h; 0 for no limit.")
+
+// confidenceHalfLife is how long it takes for market data to become a coin-toss.
+const confidenceHalfLife = 72 * time.Hour
+
+// Confidence is how far market data of a given age can be trusted, from 1
+// for brand new data, halving every confidenceHalfLife.
+func Confidence(age time.Duration) float64 {
+	if age <= 0 {
+		return 1
+	}
+	return math.Pow(0.5, float64(age)/float64(confidenceHalfLife))
+}
+
+// ParseAge parses a duration, additionally accepting days ("3d") and weeks
+// ("2w"). "off", "none" and "0" mean no limit.
+func ParseAge(text string) (time.Duration, error) {
+	text = strings.ToLower(strings.TrimSpace(text))
+	switch text {
+	case "":
+		return 0, fmt.Errorf("invalid age: empty")
+	case "off", "none", "0":
+		return 0, nil
+	}
+	units := map[byte]time.Duration{'d': 24 * time.Hour, 'w': 7 * 24 * time.Hour}
+	if unit, exists := units[text[len(text)-1]]; exists && len(text) > 1 {
+		count, err := strconv.ParseFloat(text[:len(text)-1], 64)
+		if err != nil || count < 0 {
+			return 0, fmt.Errorf("invalid age: %s", text)
+		}
+		return time.Duration(count * float64(unit)), nil
+	}
+	age, err := time.ParseDuration(text)
+	if err != nil || age < 0 {
+		return 0, fmt.Errorf("invalid age: %s", text)
+	}
+	return age, nil
+}
+
+// isStale returns true if data with the given timestamp is older, as of now,
+// than the database's max age allows.
+func (sdb *SystemDatabase) isStale(timestamp, now uint64) bool {
+	return sdb.maxAge > 0 && time.Duration(ageAt(timestamp, now))*time.Second > sdb.maxAge
+}
+
+// Confidence is how far the outcome can be trusted given the age of the
+// seller's and buyer's data.
+func (t *TradeOutcome) Confidence() float64 {
+	return Confidence(time.Duration(t.SrcAge)*time.Second) * Confidence(time.Duration(t.DstAge)*time.Second)
+}
+
+// freshnessBuckets are the upper bounds of the freshness histogram.
+var freshnessBuckets = []struct {
+	label string
+	limit time.Duration
+}{
+	{"<1h", time.Hour},
+	{"<1d", 24 * time.Hour},
+	{"<1w", 7 * 24 * time.Hour},
+	{"<30d", 30 * 24 * time.Hour},
+	{"older", time.Duration(math.MaxInt64)},
+}
+
+// freshnessHistogram counts listings by age as of now; the final count is of
+// listings with no timestamp.
+func freshnessHistogram(sdb *SystemDatabase, now uint64) []int {
+	counts := make([]int, len(freshnessBuckets)+1)
+	for _, facility := range sdb.facilitiesByID {
+		for _, listing := range facility.listings {
+			if listing.TimestampUtc == 0 {
+				counts[len(freshnessBuckets)]++
+				continue
+			}
+			age := time.Duration(ageAt(listing.TimestampUtc, now)) * time.Second
+			for idx, bucket := range freshnessBuckets {
+				if age < bucket.limit {
+					counts[idx]++
+					break
+				}
+			}
+		}
+	}
+	return counts
+}
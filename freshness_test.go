@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	gom "github.com/kfsone/gomenacing/pkg/gomschema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfidence(t *testing.T) {
+	assert.Equal(t, 1.0, Confidence(0))
+	assert.Equal(t, 1.0, Confidence(-time.Hour))
+	assert.InDelta(t, 0.5, Confidence(confidenceHalfLife), 0.0001)
+	assert.InDelta(t, 0.25, Confidence(2*confidenceHalfLife), 0.0001)
+
+	outcome := &TradeOutcome{SrcAge: int(confidenceHalfLife.Seconds()), DstAge: int(confidenceHalfLife.Seconds())}
+	assert.InDelta(t, 0.25, outcome.Confidence(), 0.0001)
+}
+
+func TestParseAge(t *testing.T) {
+	tests := map[string]time.Duration{
+		"36h":  36 * time.Hour,
+		"90m":  90 * time.Minute,
+		"3d":   72 * time.Hour,
+		"1.5d": 36 * time.Hour,
+		"2w":   14 * 24 * time.Hour,
+		"off":  0,
+		"0":    0,
+	}
+	for text, expected := range tests {
+		age, err := ParseAge(text)
+		assert.Nil(t, err, text)
+		assert.Equal(t, expected, age, text)
+	}
+	for _, invalid := range []string{"", "d", "soon", "-3d", "-1h"} {
+		_, err := ParseAge(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestRankTradeOutcomes_Confidence(t *testing.T) {
+	// A slightly better trade on week-old data loses to fresh data.
+	stale := &TradeOutcome{GainCr: 1100, DstAge: int((7 * 24 * time.Hour).Seconds())}
+	fresh := &TradeOutcome{GainCr: 1000}
+	outcomes := []*TradeOutcome{stale, fresh}
+	RankTradeOutcomes(outcomes, 10)
+	assert.Equal(t, fresh, outcomes[0])
+}
+
+func TestSystemDatabase_MaxAge(t *testing.T) {
+	const now = 1000000
+	sdb := NewSystemDatabase(nil)
+	require.Nil(t, sdb.newCommodity(&gom.Commodity{Id: 1, Name: "Gold"}))
+	require.Nil(t, sdb.newCommodity(&gom.Commodity{Id: 2, Name: "Silver"}))
+	require.Nil(t, sdb.newSystem(&gom.System{Id: 1, Name: "Sol", Position: &gom.Coordinate{}}))
+	require.Nil(t, sdb.newFacility(&gom.Facility{Id: 1, SystemId: 1, Name: "Src"}))
+	require.Nil(t, sdb.newFacility(&gom.Facility{Id: 2, SystemId: 1, Name: "Dst"}))
+	require.Nil(t, sdb.newListings(&gom.FacilityListing{Id: 1, Listings: []*gom.CommodityListing{
+		{CommodityId: 1, SupplyUnits: 100, SupplyCredits: 9000, TimestampUtc: now - 3600},
+		{CommodityId: 2, SupplyUnits: 100, SupplyCredits: 4000, TimestampUtc: now - 5*86400},
+	}}))
+	require.Nil(t, sdb.newListings(&gom.FacilityListing{Id: 2, Listings: []*gom.CommodityListing{
+		{CommodityId: 1, DemandUnits: 100, DemandCredits: 10000, TimestampUtc: now - 60},
+		{CommodityId: 2, DemandUnits: 100, DemandCredits: 4900, TimestampUtc: now - 60},
+	}}))
+	src, dst := sdb.GetFacilityByID(1), sdb.GetFacilityByID(2)
+
+	assert.Len(t, sdb.FindTradeOutcomes(src, dst, 10, now), 2)
+
+	sdb.maxAge = 2 * 24 * time.Hour
+	assert.True(t, sdb.isStale(now-3*86400, now))
+	assert.False(t, sdb.isStale(now-86400, now))
+	outcomes := sdb.FindTradeOutcomes(src, dst, 10, now)
+	require.Len(t, outcomes, 1)
+	assert.Equal(t, "Gold", outcomes[0].Commodity.DbName)
+
+	counts := freshnessHistogram(sdb, now)
+	assert.Equal(t, []int{2, 1, 1, 0, 0, 0}, counts)
+}
+
+func TestRepl_MaxAge(t *testing.T) {
+	var output bytes.Buffer
+	repl := &Repl{sdb: NewSystemDatabase(nil), out: &output}
+	defer func() { *MaxAge = 0 }()
+
+	cmdSetMaxAge(repl, []string{"3d"}, nil)
+	assert.Equal(t, 72*time.Hour, repl.sdb.maxAge)
+	assert.Contains(t, output.String(), "MaxAge is now: 72h0m0s")
+
+	// Query overrides only last until they're restored.
+	args, restore, err := repl.overrideMaxAge(strings.Fields("10 sol maxage=1h"))
+	require.Nil(t, err)
+	assert.Equal(t, []string{"10", "sol"}, args)
+	assert.Equal(t, time.Hour, repl.sdb.maxAge)
+	restore()
+	assert.Equal(t, 72*time.Hour, repl.sdb.maxAge)
+
+	_, _, err = repl.overrideMaxAge([]string{"maxage=never"})
+	assert.Error(t, err)
+	assert.Equal(t, 72*time.Hour, repl.sdb.maxAge)
+
+	output.Reset()
+	cmdSetMaxAge(repl, []string{"off"}, nil)
+	assert.Contains(t, output.String(), "MaxAge is now: off")
+}
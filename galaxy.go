@@ -0,0 +1,236 @@
+package main
+
+// Galaxy dumps, such as Spansh's galaxy.json.gz or EDSM's
+// systemsWithCoordinates.json.gz, list every known system - most of them
+// unpopulated - as one enormous JSON array:
+//
+//	[
+//	{"id64":10477373803,"name":"Sol","coords":{"x":0,"y":0,"z":0},"updateTime":"2020-08-01 12:00:00+00"},
+//	...
+//	]
+//
+// Systems are identified by their 64-bit "system address", which won't fit
+// in an EntityID, so the addresses schema remembers which id each address
+// was given and re-importing a dump reuses them. Backups include it, though
+// systems are matched by name first, so a lost mapping is rebuilt by the
+// next import anyway.
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"time"
+	"unicode"
+
+	gom "github.com/kfsone/gomenacing/pkg/gomschema"
+)
+
+// ErrInvalidGalaxy indicates a malformed galaxy dump.
+var ErrInvalidGalaxy = errors.New("invalid galaxy dump")
+
+// galaxyProgressInterval is how many systems are read between progress reports.
+const galaxyProgressInterval = 1000000
+
+// galaxyTimeFormats are the layouts of the dumps' timestamps, which are UTC.
+var galaxyTimeFormats = []string{"2006-01-02 15:04:05-07", pricesTimeFormat, time.RFC3339}
+
+// galaxySystem is the part of a dump entry we're interested in; Spansh
+// dates entries with updateTime and EDSM with date.
+type galaxySystem struct {
+	ID64   uint64 `json:"id64"`
+	Name   string `json:"name"`
+	Coords *struct {
+		X, Y, Z float64
+	} `json:"coords"`
+	Population uint64 `json:"population"`
+	UpdateTime string `json:"updateTime"`
+	Date       string `json:"date"`
+}
+
+// parseGalaxyTime returns the unix time of a dump timestamp, or 0.
+func parseGalaxyTime(text string) uint64 {
+	for _, layout := range galaxyTimeFormats {
+		if when, err := time.Parse(layout, text); err == nil {
+			return uint64(when.Unix())
+		}
+	}
+	return 0
+}
+
+// addressKey is the addresses schema key for a system address.
+func addressKey(id64 uint64) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, id64)
+	return key
+}
+
+// GalaxyImporter adds the systems from galaxy dumps that aren't already known.
+type GalaxyImporter struct {
+	messageWriter
+	addresses *Schema
+	nextID    EntityID
+	// Added counts new systems, Known those we already had and Skipped those
+	// without a usable name or coordinates.
+	Added, Known, Skipped int
+}
+
+// NewGalaxyImporter returns a GalaxyImporter that writes to sdb and db.
+func NewGalaxyImporter(sdb *SystemDatabase, db *Database) (*GalaxyImporter, error) {
+	addresses, err := db.SystemAddresses()
+	if err != nil {
+		return nil, err
+	}
+	return &GalaxyImporter{messageWriter: newMessageWriter(sdb, db, SourceGalaxy), addresses: addresses, nextID: sdb.nextSystemID()}, nil
+}
+
+// Close releases the importer's schemas.
+func (g *GalaxyImporter) Close() {
+	g.messageWriter.Close()
+	Must(g.addresses.Close())
+}
+
+// systemID returns the id for a dump entry: that of the system with the same
+// name, else the one given to its address before, else a new one.
+func (g *GalaxyImporter) systemID(entry *galaxySystem) (EntityID, error) {
+	key := addressKey(entry.ID64)
+	if system := g.sdb.GetSystem(strings.TrimSpace(entry.Name)); system != nil {
+		if entry.ID64 == 0 {
+			return system.ID, nil
+		}
+		return system.ID, g.addresses.Put(key, addressValue(system.ID))
+	}
+	if entry.ID64 != 0 {
+		value, err := g.addresses.Get(key)
+		if err != nil {
+			return 0, err
+		}
+		if len(value) == 4 {
+			return EntityID(binary.BigEndian.Uint32(value)), nil
+		}
+	}
+	id := g.nextID
+	g.nextID++
+	if entry.ID64 == 0 {
+		return id, nil
+	}
+	return id, g.addresses.Put(key, addressValue(id))
+}
+
+// addressValue is the addresses schema value for an id.
+func addressValue(id EntityID) []byte {
+	value := make([]byte, 4)
+	binary.BigEndian.PutUint32(value, uint32(id))
+	return value
+}
+
+// apply adds a dump entry unless we already know the system; systems we
+// know about came from richer sources than a dump.
+func (g *GalaxyImporter) apply(entry *galaxySystem) error {
+	if entry.Coords == nil || len(strings.TrimSpace(entry.Name)) <= 1 {
+		g.Skipped++
+		return nil
+	}
+	id, err := g.systemID(entry)
+	if err != nil {
+		return err
+	}
+	if g.sdb.GetSystemByID(id) != nil {
+		g.Known++
+		return nil
+	}
+	timestamp := parseGalaxyTime(entry.UpdateTime)
+	if timestamp == 0 {
+		timestamp = parseGalaxyTime(entry.Date)
+	}
+	system := &gom.System{
+		Id:           uint32(id),
+		Name:         strings.TrimSpace(entry.Name),
+		TimestampUtc: timestamp,
+		Position:     &gom.Coordinate{X: entry.Coords.X, Y: entry.Coords.Y, Z: entry.Coords.Z},
+		Populated:    entry.Population > 0,
+		Population:   entry.Population,
+	}
+	if err := g.messageWriter.apply(system); err != nil {
+		return err
+	}
+	g.Added++
+	return nil
+}
+
+// Import streams the systems from a galaxy dump, which may be gzipped and
+// may be either a JSON array or one object after another.
+func (g *GalaxyImporter) Import(source io.Reader) (err error) {
+	reader := bufio.NewReader(source)
+	if magic, peekErr := reader.Peek(2); peekErr == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		unzipper, zipErr := gzip.NewReader(reader)
+		if zipErr != nil {
+			return zipErr
+		}
+		defer func() {
+			// After a failed read, Close reports the same error again.
+			if closeErr := unzipper.Close(); err == nil {
+				err = closeErr
+			}
+		}()
+		reader = bufio.NewReader(unzipper)
+	}
+	return g.importSystems(reader)
+}
+
+// importSystems decodes the systems of an uncompressed dump.
+func (g *GalaxyImporter) importSystems(reader *bufio.Reader) error {
+	// Look at the first token to see whether it opens an array.
+	for {
+		next, err := reader.Peek(1)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if !unicode.IsSpace(rune(next[0])) {
+			break
+		}
+		if _, err := reader.Discard(1); err != nil {
+			return err
+		}
+	}
+	opening, _ := reader.Peek(1)
+
+	decoder := json.NewDecoder(reader)
+	if opening[0] == '[' {
+		if _, err := decoder.Token(); err != nil {
+			return fmt.Errorf("%w: %s", ErrInvalidGalaxy, err)
+		}
+	}
+	for read := 1; decoder.More(); read++ {
+		var entry galaxySystem
+		if err := decoder.Decode(&entry); err != nil {
+			return fmt.Errorf("%w: system %d: %s", ErrInvalidGalaxy, read, err)
+		}
+		if err := g.apply(&entry); err != nil {
+			return fmt.Errorf("system %d: %s: %w", read, entry.Name, err)
+		}
+		if read%galaxyProgressInterval == 0 {
+			log.Printf("Read %d systems.", read)
+		}
+	}
+	return nil
+}
+
+// ImportFile imports a galaxy dump file.
+func (g *GalaxyImporter) ImportFile(pathname string) error {
+	file, err := os.Open(pathname)
+	if err != nil {
+		return err
+	}
+	defer func() { Must(file.Close()) }()
+	return g.Import(file)
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	gom "github.com/kfsone/gomenacing/pkg/gomschema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testGalaxy = `[
+{"id64":10477373803,"name":"Sol","coords":{"x":0,"y":0,"z":0},"population":22780919531,"updateTime":"2020-08-01 12:00:00+00"},
+{"id64":1178708478315,"name":"Oochorrs UF-J c11-0","coords":{"x":-1435.5,"y":-22.5,"z":19623.75},"updateTime":"2020-08-01 12:00:00+00","bodies":[{"name":"A 1"}]},
+{"id64":5031654888146,"name":"Sagittarius A*","coords":{"x":25.21875,"y":-20.90625,"z":25899.96875},"date":"2015-05-12 15:29:33"},
+{"id64":42,"name":"Nowhere"},
+{"id64":43,"name":"X","coords":{"x":1,"y":2,"z":3}}
+]
+`
+
+func gzipped(t *testing.T, text string) []byte {
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	_, err := writer.Write([]byte(text))
+	require.Nil(t, err)
+	require.Nil(t, writer.Close())
+	return compressed.Bytes()
+}
+
+func Test_parseGalaxyTime(t *testing.T) {
+	assert.EqualValues(t, 1596283200, parseGalaxyTime("2020-08-01 12:00:00+00"))
+	assert.EqualValues(t, 1596283200, parseGalaxyTime("2020-08-01 12:00:00"))
+	assert.EqualValues(t, 1596283200, parseGalaxyTime("2020-08-01T12:00:00Z"))
+	assert.EqualValues(t, 0, parseGalaxyTime(""))
+}
+
+func TestGalaxyImporter_Import(t *testing.T) {
+	testDir := GetTestDir()
+	defer testDir.Close()
+	db, err := OpenDatabase(testDir.Path(), "galaxy.db")
+	require.Nil(t, err)
+	defer db.Close()
+
+	sdb := NewSystemDatabase(db)
+	require.Nil(t, sdb.newSystem(&gom.System{Id: 7, Name: "Sol", Position: &gom.Coordinate{}, Populated: true}))
+
+	importer, err := NewGalaxyImporter(sdb, db)
+	require.Nil(t, err)
+	require.Nil(t, importer.Import(bytes.NewReader(gzipped(t, testGalaxy))))
+	importer.Close()
+	assert.Equal(t, 2, importer.Added)
+	assert.Equal(t, 1, importer.Known)
+	assert.Equal(t, 2, importer.Skipped)
+
+	// Systems we knew about are left alone.
+	assert.EqualValues(t, 7, sdb.GetSystem("Sol").ID)
+	assert.True(t, sdb.GetSystem("Sol").Populated)
+	sagA := sdb.GetSystem("sagittarius a*")
+	require.NotNil(t, sagA)
+	assert.Equal(t, Coordinate{25.21875, -20.90625, 25899.96875}, *sagA.Position())
+	assert.EqualValues(t, 1431444573, sagA.TimestampUtc)
+	assert.False(t, sagA.Populated)
+	oochorrs := sdb.GetSystem("Oochorrs UF-J c11-0")
+	require.NotNil(t, oochorrs)
+	assert.Equal(t, syntheticIDBase, oochorrs.ID)
+
+	// Importing again changes nothing.
+	importer, err = NewGalaxyImporter(sdb, db)
+	require.Nil(t, err)
+	require.Nil(t, importer.Import(strings.NewReader(testGalaxy)))
+	importer.Close()
+	assert.Equal(t, 0, importer.Added)
+	assert.Equal(t, 3, importer.Known)
+	assert.Len(t, sdb.systemsByID, 3)
+
+	// Addresses keep their ids even when the names are new to us, and dumps
+	// may list one object after another instead of an array.
+	fresh := NewSystemDatabase(db)
+	importer, err = NewGalaxyImporter(fresh, db)
+	require.Nil(t, err)
+	lines := strings.Split(testGalaxy, "\n")
+	require.Nil(t, importer.Import(strings.NewReader(strings.TrimSuffix(lines[2], ",")+"\n"+strings.TrimSuffix(lines[3], ","))))
+	importer.Close()
+	assert.Equal(t, 2, importer.Added)
+	assert.Equal(t, oochorrs.ID, fresh.GetSystem("Oochorrs UF-J c11-0").ID)
+	assert.Equal(t, sagA.ID, fresh.GetSystem("Sagittarius A*").ID)
+
+	importer, err = NewGalaxyImporter(sdb, db)
+	require.Nil(t, err)
+	err = importer.Import(strings.NewReader(`[{"id64":1,"name":"Broken",`))
+	importer.Close()
+	assert.True(t, errors.Is(err, ErrInvalidGalaxy))
+
+	// A truncated download is reported rather than crashing.
+	compressed := gzipped(t, testGalaxy)
+	importer, err = NewGalaxyImporter(sdb, db)
+	require.Nil(t, err)
+	err = importer.Import(bytes.NewReader(compressed[:len(compressed)/2]))
+	importer.Close()
+	assert.Error(t, err)
+}
+
+func TestGalaxyImporter_thenEDDB(t *testing.T) {
+	testDir := GetTestDir()
+	defer testDir.Close()
+	db, err := OpenDatabase(testDir.Path(), "galaxy.db")
+	require.Nil(t, err)
+	defer db.Close()
+
+	sdb := NewSystemDatabase(db)
+	importer, err := NewGalaxyImporter(sdb, db)
+	require.Nil(t, err)
+	require.Nil(t, importer.Import(strings.NewReader(testGalaxy)))
+	importer.Close()
+	require.Equal(t, 3, importer.Added)
+
+	// EDDB's ids are its own, even where they're the next ones up from ours,
+	// and a system it has that we made up an id for takes EDDB's.
+	systems, err := db.Systems()
+	require.Nil(t, err)
+	require.Nil(t, sdb.registerFromMessage(&gom.System{Id: 1, Name: "Alpha Centauri", Position: &gom.Coordinate{X: 3}}, systems, "eddb"))
+	require.Nil(t, sdb.registerFromMessage(&gom.System{Id: 7, Name: "Sol", Position: &gom.Coordinate{}, Populated: true, TimestampUtc: 1600000000}, systems, "eddb"))
+	require.Nil(t, systems.Close())
+	assert.Len(t, sdb.systemsByID, 4)
+	sol := sdb.GetSystem("Sol")
+	assert.EqualValues(t, 7, sol.ID)
+	assert.True(t, sol.Populated)
+	assert.Nil(t, sdb.GetSystemByID(syntheticIDBase))
+	var nearby []string
+	_, err = sdb.getSystemsWithinRange(sdb.GetSystem("Alpha Centauri"), 5, func(system *System, _ SquareFloat) bool {
+		nearby = append(nearby, system.DbName)
+		return true
+	})
+	require.Nil(t, err)
+	assert.ElementsMatch(t, []string{"Alpha Centauri", "Sol"}, nearby)
+
+	// Stored under EDDB's id, and dumps carry on matching it by name.
+	reloaded := NewSystemDatabase(db)
+	require.Nil(t, db.LoadDatabase(reloaded))
+	assert.Len(t, reloaded.systemsByID, 4)
+	assert.EqualValues(t, 7, reloaded.GetSystem("Sol").ID)
+	importer, err = NewGalaxyImporter(reloaded, db)
+	require.Nil(t, err)
+	require.Nil(t, importer.Import(strings.NewReader(testGalaxy)))
+	importer.Close()
+	assert.Equal(t, 0, importer.Added)
+	assert.Equal(t, 3, importer.Known)
+}
+
+func TestRepl_ImportGalaxy(t *testing.T) {
+	testDir := GetTestDir()
+	defer testDir.Close()
+	db, err := OpenDatabase(testDir.Path(), "repl.db")
+	require.Nil(t, err)
+	defer db.Close()
+
+	var output bytes.Buffer
+	repl := &Repl{db: db, sdb: NewSystemDatabase(db), out: &output}
+	pathname := filepath.Join(testDir.Path(), "galaxy.json.gz")
+	require.Nil(t, ioutil.WriteFile(pathname, gzipped(t, testGalaxy), 0644))
+
+	cmdImport(repl, []string{"galaxy", pathname}, nil)
+	assert.Contains(t, output.String(), ": 3 new systems, 0 already known, 2 skipped.")
+	assert.NotNil(t, repl.sdb.GetSystem("Sagittarius A*"))
+
+	output.Reset()
+	cmdImport(repl, []string{"galaxy"}, nil)
+	assert.Contains(t, output.String(), "Please specify the dump to import")
+}
@@ -5,6 +5,7 @@ go 1.14
 require (
 	github.com/akrylysov/pogreb v0.9.1
 	github.com/golang/protobuf v1.4.2
+	github.com/klauspost/compress v1.11.3
 	github.com/mattn/go-shellwords v1.0.10
 	github.com/spf13/pflag v1.0.5
 	github.com/stretchr/testify v1.6.1
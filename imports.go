@@ -5,10 +5,38 @@ import (
 	"github.com/kfsone/gomenacing/pkg/gomschema"
 	"google.golang.org/protobuf/proto"
 	"os"
+	"path/filepath"
+	"strings"
 )
 
 func GetImportFilenames() []string {
-	return []string{"commodities.gom", "systems.gom", "stations.gom", "listings.gom"}
+	return []string{"commodities.gom", "systems.gom", "stations.gom", "listings.gom",
+		"modules.gom", "ships.gom", "outfitting.gom", "shipyards.gom"}
+}
+
+// gomExtensions are the extensions of GOM files, which may be compressed.
+var gomExtensions = []string{".gom", ".gom.gz", ".gom.zst"}
+
+// isGOMFilename returns true if the filename has one of the gomExtensions.
+func isGOMFilename(filename string) bool {
+	for _, extension := range gomExtensions {
+		if strings.HasSuffix(filename, extension) {
+			return true
+		}
+	}
+	return false
+}
+
+// findImportFile returns the path of filename within dir, preferring an
+// uncompressed copy over a .gz or .zst one, or "" if there is none.
+func findImportFile(dir, filename string) string {
+	for _, suffix := range []string{"", ".gz", ".zst"} {
+		pathname := filepath.Join(dir, filename+suffix)
+		if _, err := os.Stat(pathname); err == nil {
+			return pathname
+		}
+	}
+	return ""
 }
 
 /*
@@ -19,40 +47,132 @@ func GetImportFilenames() []string {
  * For now it's going to do both.
  */
 
-func fnImportFile(r *Repl, pathname string, required bool) bool {
+// fnImportFile imports a GOM file, returning its header if it was read, and
+// an error if it couldn't be or not all of it could be applied.
+func fnImportFile(r *Repl, pathname string, required bool) (*gomschema.Header, error) {
 	file, err := os.Open(pathname)
 	if err != nil {
 		if required {
 			fmt.Fprintln(r, file, ": error opening file: ", err)
 		}
-		return false
+		return nil, err
 	}
 	defer func() { Must(file.Close()) }()
 
 	gomFile, err := gomschema.OpenGOMFile(file)
 	if err != nil {
 		fmt.Fprintln(r, file, ": ", err)
-		return false
+		return nil, err
 	}
 	defer gomFile.Close()
+	if err = checkSigner(gomFile); err == nil {
+		err = checkDeltaBase(r.db, gomFile.Header())
+	}
+	if err != nil {
+		fmt.Fprintf(r, "%s: %s\n", pathname, err)
+		return nil, err
+	}
 
 	schema, err := getSchemaForMessage(r.db, *gomFile.Item())
 	if err != nil {
 		fmt.Println(r, "Error:", err)
-		return false
+		return nil, err
 	}
 	defer schema.Close()
 
+	carried, err := carriedProvenance(r.sdb.dataSources(), gomFile.Header())
+	if err != nil {
+		fmt.Fprintf(r, "%s: %s\n", pathname, err)
+		return nil, err
+	}
+
 	count := 0
+	from := attribution{source: gomFile.Header().Source}
 	err = gomFile.Read(func(message proto.Message, index uint) (err error) {
-		err = r.sdb.registerFromMessage(message, schema)
+		from.carried = carried[string(messageKey(message.(Identifiable).GetId()))]
+		err = r.sdb.registerAttributed(message, schema, from)
 		if err == nil {
 			count++
 		}
 		return FilterError(err)
 	})
+	if err != nil {
+		fmt.Fprintf(r, "%s: %s\n", pathname, err)
+	}
 
 	fmt.Fprintf(r, "%s: read %d items.\n", pathname, count)
 
-	return true
+	return gomFile.Header(), err
+}
+
+// fnImportDir imports the GOM files of a dump or delta in dir, returning how
+// many were read. The database is only recorded as synced to the dump or
+// delta once all of its files have been applied.
+func fnImportDir(r *Repl, dir string) int {
+	imports, failed := 0, false
+	var synced *gomschema.Header
+	var syncedTo uint64
+	for _, filename := range GetImportFilenames() {
+		found := findImportFile(dir, filename)
+		if found == "" {
+			continue
+		}
+		header, err := fnImportFile(r, found, false)
+		if header != nil {
+			imports++
+		}
+		if err != nil {
+			failed = true
+			continue
+		}
+		// Should the files disagree, only the earliest point is certain.
+		if point, known, _ := syncPoint(header); known && (synced == nil || point < syncedTo) {
+			synced, syncedTo = header, point
+		}
+	}
+	if synced != nil && !failed {
+		if err := recordSync(r.db, synced); err != nil {
+			fmt.Fprintf(r, "%s: %s\n", dir, err)
+		}
+	}
+	return imports
+}
+
+// fnImportPrices imports a TradeDangerous .prices file; items without a
+// timestamp are dated by the file's modification time.
+func fnImportPrices(r *Repl, pathname string) bool {
+	file, err := os.Open(pathname)
+	if err != nil {
+		fmt.Fprintf(r, "%s: error opening file: %s\n", pathname, err)
+		return false
+	}
+	defer func() { Must(file.Close()) }()
+	stat, err := file.Stat()
+	if err != nil {
+		fmt.Fprintf(r, "%s: %s\n", pathname, err)
+		return false
+	}
+
+	schema, err := r.db.Listings()
+	if err != nil {
+		fmt.Fprintln(r, "Error:", err)
+		return false
+	}
+	defer schema.Close()
+
+	count := 0
+	err = r.sdb.ReadPrices(file, uint64(stat.ModTime().Unix()), func(listing *gomschema.FacilityListing) error {
+		err := r.sdb.registerFromMessage(listing, schema, SourceTradeDangerous)
+		if err == nil {
+			count++
+		}
+		return FilterError(err)
+	})
+	if err != nil {
+		fmt.Fprintf(r, "%s: %s\n", pathname, err)
+	}
+
+	fmt.Fprintf(r, "%s: read %d stations.\n", pathname, count)
+
+	return err == nil
 }
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	gom "github.com/kfsone/gomenacing/pkg/gomschema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func buildCompressionTestWriter(t *testing.T) *gom.GOMWriter {
+	writer := gom.NewGOMWriter(gom.Header_CSystem, "test")
+	for id := uint32(1); id <= 100; id++ {
+		require.Nil(t, writer.AddMessage(&gom.System{Id: id, Name: fmt.Sprintf("Test System %d", id), Position: &gom.Coordinate{X: float64(id)}}))
+	}
+	return writer
+}
+
+func Test_isGOMFilename(t *testing.T) {
+	assert.True(t, isGOMFilename("systems.gom"))
+	assert.True(t, isGOMFilename("/tmp/listings.gom.gz"))
+	assert.True(t, isGOMFilename("listings.gom.zst"))
+	assert.False(t, isGOMFilename("listings.gz"))
+	assert.False(t, isGOMFilename("Sol.prices"))
+
+	assert.Equal(t, gom.CompressionGzip, gom.CompressionForFilename("listings.gom.gz"))
+	assert.Equal(t, gom.CompressionZstd, gom.CompressionForFilename("listings.gom.zst"))
+	assert.Equal(t, gom.CompressionNone, gom.CompressionForFilename("listings.gom"))
+	compression, err := gom.ParseCompression("ZSTD")
+	assert.Nil(t, err)
+	assert.Equal(t, gom.CompressionZstd, compression)
+	_, err = gom.ParseCompression("lzma")
+	assert.Error(t, err)
+}
+
+func TestGOMCompression(t *testing.T) {
+	magic := map[gom.Compression][]byte{
+		gom.CompressionNone: []byte(gom.MAGIC),
+		gom.CompressionGzip: {0x1f, 0x8b},
+		gom.CompressionZstd: {0x28, 0xb5, 0x2f, 0xfd},
+	}
+	uncompressed := 0
+	for _, stream := range []gom.Compression{gom.CompressionNone, gom.CompressionGzip, gom.CompressionZstd} {
+		for _, payload := range []gom.Compression{gom.CompressionNone, gom.CompressionGzip, gom.CompressionZstd} {
+			name := fmt.Sprintf("%s/%s", stream, payload)
+			writer := buildCompressionTestWriter(t)
+			writer.SetPayloadCompression(payload)
+			var data bytes.Buffer
+			require.Nil(t, writer.WriteCompressed(&data, stream), name)
+			assert.Equal(t, magic[stream], data.Bytes()[:len(magic[stream])], name)
+			if stream == gom.CompressionNone && payload == gom.CompressionNone {
+				uncompressed = data.Len()
+			} else {
+				assert.Less(t, data.Len(), uncompressed, name)
+			}
+
+			file, err := gom.OpenGOMFile(&data)
+			require.Nil(t, err, name)
+			if payload == gom.CompressionNone {
+				assert.NotContains(t, file.Header().Userdata, gom.PayloadCompressionKey, name)
+			} else {
+				assert.Equal(t, []byte(payload.String()), file.Header().Userdata[gom.PayloadCompressionKey], name)
+			}
+			read := 0
+			require.Nil(t, file.Read(func(message proto.Message, idx uint) error {
+				read++
+				assert.Equal(t, fmt.Sprintf("Test System %d", idx+1), message.(*gom.System).Name, name)
+				return nil
+			}), name)
+			assert.Equal(t, 100, read, name)
+			file.Close()
+		}
+	}
+
+	// A payload compression the reader doesn't know is an error.
+	header, err := proto.Marshal(&gom.Header{HeaderType: gom.Header_CSystem, Userdata: map[string][]byte{gom.PayloadCompressionKey: []byte("lzma")}})
+	require.Nil(t, err)
+	_, err = gom.OpenGOMFile(bytes.NewBufferString(fmt.Sprintf("%s%08x%s", gom.MAGIC, len(header), header)))
+	assert.Error(t, err)
+}
+
+func TestRepl_ImportCompressedGOM(t *testing.T) {
+	testDir := GetTestDir()
+	defer testDir.Close()
+	db, err := OpenDatabase(testDir.Path(), "gom.db")
+	require.Nil(t, err)
+	defer db.Close()
+
+	dir := filepath.Join(testDir.Path(), "data")
+	_, err = ensureDirectory(dir)
+	require.Nil(t, err)
+	require.Nil(t, buildCompressionTestWriter(t).WriteFile(filepath.Join(dir, "systems.gom.zst")))
+	commodities := gom.NewGOMWriter(gom.Header_CCommodity, "test")
+	require.Nil(t, commodities.AddMessage(&gom.Commodity{Id: 1, Name: "Gold"}))
+	commodities.SetPayloadCompression(gom.CompressionGzip)
+	require.Nil(t, commodities.WriteFile(filepath.Join(dir, "commodities.gom.gz")))
+
+	var output bytes.Buffer
+	repl := &Repl{db: db, sdb: NewSystemDatabase(db), out: &output}
+	cmdImport(repl, []string{dir}, nil)
+	assert.Contains(t, output.String(), "systems.gom.zst: read 100 items.")
+	assert.Contains(t, output.String(), "commodities.gom.gz: read 1 items.")
+	assert.NotNil(t, repl.sdb.GetSystem("Test System 42"))
+	assert.NotNil(t, repl.sdb.GetCommodity("Gold"))
+
+	output.Reset()
+	cmdImport(repl, []string{filepath.Join(dir, "commodities.gom.gz")}, nil)
+	assert.Contains(t, output.String(), "commodities.gom.gz: read 1 items.")
+}
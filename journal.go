@@ -0,0 +1,508 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	gom "github.com/kfsone/gomenacing/pkg/gomschema"
+	"github.com/kfsone/gomenacing/pkg/parsing"
+	flag "github.com/spf13/pflag"
+	"github.com/tidwall/gjson"
+)
+
+// JournalDir is the game's journal directory, followed for new events while the REPL runs.
+var JournalDir = flag.String("journaldir", "", "Directory of game Journal.*.log files to follow for new events.")
+
+// journalPollInterval is how often the journal directory is checked for changes.
+const journalPollInterval = 2 * time.Second
+
+// marketFilename is the file the game rewrites with the market of the station you are docked at.
+const marketFilename = "Market.json"
+
+// isJournalFile returns true for the game's journal logs.
+func isJournalFile(filename string) bool {
+	return strings.HasPrefix(filename, "Journal.") && strings.HasSuffix(filename, ".log")
+}
+
+// journalTimestamp is the unix time of an event, or 0 if it isn't dated.
+func journalTimestamp(event gjson.Result) uint64 {
+	when, err := time.Parse(time.RFC3339, event.Get("timestamp").String())
+	if err != nil || when.Unix() < 0 {
+		return 0
+	}
+	return uint64(when.Unix())
+}
+
+// journalSymbol strips the decoration from a game symbol such as
+// "$government_Democracy;", returning "Democracy" for the prefix "government_".
+func journalSymbol(text, prefix string) string {
+	text = strings.TrimSuffix(strings.TrimPrefix(text, "$"), ";")
+	if len(text) >= len(prefix) && strings.EqualFold(text[:len(prefix)], prefix) {
+		text = text[len(prefix):]
+	}
+	return text
+}
+
+// journalEnum looks up the enum value for a journal symbol, returning 0 for
+// anything unrecognized since the game adds new values from time to time.
+func journalEnum(names map[int32]string, prefix, symbol string) int32 {
+	if symbol == "" {
+		return 0
+	}
+	value, err := parseEnumName(names, prefix, symbol)
+	if err != nil {
+		return 0
+	}
+	return value
+}
+
+// journalEconomyAliases are the economy symbols which don't match their names.
+var journalEconomyAliases = map[string]gom.EconomyType{
+	"agri":     gom.EconomyType_EcoAgriculture,
+	"engineer": gom.EconomyType_EcoEngineering,
+}
+
+func journalEconomy(text string) gom.EconomyType {
+	symbol := journalSymbol(text, "economy_")
+	if economy, exists := journalEconomyAliases[strings.ToLower(symbol)]; exists {
+		return economy
+	}
+	return gom.EconomyType(journalEnum(gom.EconomyType_name, "Eco", symbol))
+}
+
+func journalGovernment(text string) gom.GovernmentType {
+	return gom.GovernmentType(journalEnum(gom.GovernmentType_name, "Gov", journalSymbol(text, "government_")))
+}
+
+func journalAllegiance(text string) gom.AllegianceType {
+	return gom.AllegianceType(journalEnum(gom.AllegianceType_name, "Alleg", text))
+}
+
+// journalSecurity translates "$SYSTEM_SECURITY_medium;"; anarchies use a
+// different symbol, "$GAlAXY_MAP_INFO_state_anarchy;".
+func journalSecurity(text string) gom.SecurityLevel {
+	if strings.Contains(strings.ToLower(text), "anarchy") {
+		return gom.SecurityLevel_SecurityAnarchy
+	}
+	return gom.SecurityLevel(journalEnum(gom.SecurityLevel_name, "Security", journalSymbol(text, "system_security_")))
+}
+
+// journalStationTypes maps the game's StationType values. Outposts don't say
+// what kind of outpost they are.
+var journalStationTypes = map[string]gom.FacilityType{
+	"asteroidbase":     gom.FacilityType_FTAsteroidBase,
+	"bernal":           gom.FacilityType_FTOcellusStarport,
+	"coriolis":         gom.FacilityType_FTCoriolisStarport,
+	"crateroutpost":    gom.FacilityType_FTPlanetaryOutpost,
+	"craterport":       gom.FacilityType_FTPlanetaryPort,
+	"fleetcarrier":     gom.FacilityType_FTFleetCarrier,
+	"megaship":         gom.FacilityType_FTMegaship,
+	"ocellus":          gom.FacilityType_FTOcellusStarport,
+	"onfootsettlement": gom.FacilityType_FTPlanetarySettlement,
+	"orbis":            gom.FacilityType_FTOrbisStarport,
+	"outpost":          gom.FacilityType_FTCivilianOutpost,
+}
+
+// journalServices maps StationServices entries to facility features.
+var journalServices = map[string]FacilityFeatureMask{
+	"blackmarket": FeatBlackMarket,
+	"commodities": FeatMarket | FeatCommodities,
+	"dock":        FeatDocking,
+	"outfitting":  FeatOutfitting,
+	"rearm":       FeatRearm,
+	"refuel":      FeatRefuel,
+	"repair":      FeatRepair,
+	"shipyard":    FeatShipyard,
+}
+
+// journalFeatures derives a facility's features from a docking event.
+func journalFeatures(event gjson.Result, facilityType gom.FacilityType) FacilityFeatureMask {
+	var features FacilityFeatureMask
+	for _, service := range event.Get("StationServices").Array() {
+		features |= journalServices[strings.ToLower(service.String())]
+	}
+	pads := event.Get("LandingPads")
+	if pads.Get("Small").Int() > 0 {
+		features |= FeatSmallPad
+	}
+	if pads.Get("Medium").Int() > 0 {
+		features |= FeatMediumPad
+	}
+	if pads.Get("Large").Int() > 0 {
+		features |= FeatLargePad
+	}
+	switch facilityType {
+	case gom.FacilityType_FTPlanetaryOutpost, gom.FacilityType_FTPlanetaryPort, gom.FacilityType_FTPlanetarySettlement:
+		features |= FeatPlanetary
+	case gom.FacilityType_FTFleetCarrier:
+		features |= FeatFleet
+	}
+	return features
+}
+
+// journalBracket translates a Market.json bracket, 0-3 or "" where it doesn't apply.
+func journalBracket(bracket gjson.Result) gom.MarketBracket {
+	if bracket.Type != gjson.Number {
+		return gom.MarketBracket_BracketUnknown
+	}
+	return gom.MarketBracket(bracket.Int() + 1)
+}
+
+// JournalImporter translates the game's journal events into updates of the
+// database, writing them through to the store.
+type JournalImporter struct {
+	messageWriter
+	// commodities maps commodity names, lower-case and without spaces, to ids.
+	commodities map[string]EntityID
+	// commander, if set, follows the player's location, ship, cargo and credits.
+	commander *Commander
+	// CommanderUpdates counts the events that changed the commander.
+	CommanderUpdates int
+}
+
+// NewJournalImporter creates an importer that applies events to sdb and db.
+func NewJournalImporter(sdb *SystemDatabase, db *Database) *JournalImporter {
+	return &JournalImporter{messageWriter: newMessageWriter(sdb, db, SourceJournal)}
+}
+
+// ApplyEvent applies a single journal event to the database and commander;
+// events that don't concern either are ignored.
+func (j *JournalImporter) ApplyEvent(event gjson.Result) error {
+	err := j.applyToDatabase(event)
+	if j.commander != nil && j.commander.applyEvent(j, event) {
+		j.CommanderUpdates++
+	}
+	return err
+}
+
+func (j *JournalImporter) applyToDatabase(event gjson.Result) error {
+	switch event.Get("event").String() {
+	case "FSDJump", "CarrierJump", "Location":
+		if err := j.applySystem(event); err != nil {
+			return err
+		}
+		if event.Get("Docked").Bool() {
+			return j.applyFacility(event)
+		}
+		return nil
+
+	case "Docked":
+		return j.applyFacility(event)
+
+	case "Market":
+		// The journal's Market event only signals that Market.json has been
+		// rewritten; it's the file that has the items.
+		if event.Get("Items").Exists() {
+			return j.applyMarket(event)
+		}
+		return nil
+
+	default:
+		return nil
+	}
+}
+
+func (j *JournalImporter) applySystem(event gjson.Result) error {
+	name := event.Get("StarSystem").String()
+	position := event.Get("StarPos").Array()
+	if name == "" || len(position) != 3 {
+		return fmt.Errorf("%s event without a system name and position", event.Get("event").String())
+	}
+	timestamp := journalTimestamp(event)
+
+	item := &gom.System{Position: &gom.Coordinate{}}
+	if system := j.sdb.GetSystem(name); system != nil {
+		if timestamp < system.TimestampUtc {
+			return nil
+		}
+		SerializeSystem(item, system)
+	} else {
+		item.Id = uint32(j.sdb.nextSystemID())
+	}
+	item.Name = name
+	item.TimestampUtc = timestamp
+	item.Position.X, item.Position.Y, item.Position.Z = position[0].Float(), position[1].Float(), position[2].Float()
+	item.Population = event.Get("Population").Uint()
+	item.Populated = item.Population > 0
+	item.SecurityLevel = journalSecurity(event.Get("SystemSecurity").String())
+	item.Government = journalGovernment(event.Get("SystemGovernment").String())
+	item.Allegiance = journalAllegiance(event.Get("SystemAllegiance").String())
+	item.PrimaryEconomy = journalEconomy(event.Get("SystemEconomy").String())
+	if faction := event.Get("SystemFaction.Name"); faction.Exists() {
+		item.ControllingFaction = faction.String()
+	}
+
+	return j.apply(item)
+}
+
+func (j *JournalImporter) applyFacility(event gjson.Result) error {
+	system := j.sdb.GetSystem(event.Get("StarSystem").String())
+	if system == nil {
+		return fmt.Errorf("%w: system for station %s: %s", ErrUnknownEntity, event.Get("StationName").String(), event.Get("StarSystem").String())
+	}
+	name := event.Get("StationName").String()
+	marketID := event.Get("MarketID").Uint()
+	timestamp := journalTimestamp(event)
+
+	facility := j.sdb.GetFacilityByMarketID(marketID)
+	if facility == nil || marketID == 0 {
+		facility = system.GetFacility(name)
+	}
+	item := &gom.Facility{}
+	if facility != nil {
+		if timestamp < facility.TimestampUtc {
+			return nil
+		}
+		Must(SerializeFacility(item, facility))
+	} else {
+		item.Id = uint32(j.sdb.nextFacilityID())
+	}
+	item.SystemId = uint32(system.ID)
+	item.Name = name
+	item.TimestampUtc = timestamp
+	item.MarketId = marketID
+	item.FacilityType = journalStationTypes[strings.ToLower(event.Get("StationType").String())]
+	item.Features = uint32(journalFeatures(event, item.FacilityType))
+	item.PlanetaryLanding = item.Features&uint32(FeatPlanetary) != 0
+	if ls := event.Get("DistFromStarLS"); ls.Exists() {
+		item.LsFromStar = uint32(ls.Float() + 0.5)
+	}
+	item.Government = journalGovernment(event.Get("StationGovernment").String())
+	item.Allegiance = journalAllegiance(event.Get("StationAllegiance").String())
+	if economies := event.Get("StationEconomies").Array(); len(economies) > 0 {
+		item.Economies = make([]gom.EconomyType, 0, len(economies))
+		for _, economy := range economies {
+			item.Economies = append(item.Economies, journalEconomy(economy.Get("Name").String()))
+		}
+	}
+
+	return j.apply(item)
+}
+
+// lookupCommodity finds a commodity by its English name, or failing that by
+// the name in its symbol, "$mineraloil_name;" or "mineraloil".
+func (j *JournalImporter) lookupCommodity(localised, symbol string) (EntityID, bool) {
+	if j.commodities == nil {
+		j.commodities = make(map[string]EntityID, len(j.sdb.commodityIDs))
+		for name, id := range j.sdb.commodityIDs {
+			j.commodities[strings.ReplaceAll(name, " ", "")] = id
+		}
+	}
+	for _, name := range []string{localised, journalSymbol(strings.TrimSuffix(symbol, "_name;"), "")} {
+		if id, exists := j.commodities[strings.ToLower(strings.ReplaceAll(name, " ", ""))]; exists {
+			return id, true
+		}
+	}
+	return 0, false
+}
+
+func (j *JournalImporter) applyMarket(event gjson.Result) error {
+	marketID := event.Get("MarketID").Uint()
+	facility := j.sdb.GetFacilityByMarketID(marketID)
+	if facility == nil {
+		return fmt.Errorf("%w: facility for market %d (%s)", ErrUnknownEntity, marketID, event.Get("StationName").String())
+	}
+	timestamp := journalTimestamp(event)
+
+	items := event.Get("Items").Array()
+	listing := &gom.FacilityListing{Id: uint32(facility.ID), Listings: make([]*gom.CommodityListing, 0, len(items))}
+	for _, item := range items {
+		commodityID, exists := j.lookupCommodity(item.Get("Name_Localised").String(), item.Get("Name").String())
+		if !exists {
+			FilterError(fmt.Errorf("%w: commodity in market %d: %s", ErrUnknownEntity, marketID, item.Get("Name").String()))
+			continue
+		}
+		listing.Listings = append(listing.Listings, &gom.CommodityListing{
+			CommodityId:   uint32(commodityID),
+			SupplyUnits:   uint32(item.Get("Stock").Uint()),
+			SupplyCredits: uint32(item.Get("BuyPrice").Uint()),
+			DemandUnits:   uint32(item.Get("Demand").Uint()),
+			DemandCredits: uint32(item.Get("SellPrice").Uint()),
+			TimestampUtc:  timestamp,
+			SupplyBracket: journalBracket(item.Get("StockBracket")),
+			DemandBracket: journalBracket(item.Get("DemandBracket")),
+		})
+	}
+
+	return j.apply(listing)
+}
+
+// applyAll applies each event in turn, logging those that fail.
+func (j *JournalImporter) applyAll(events <-chan *gjson.Result) {
+	for event := range events {
+		if err := FilterError(j.ApplyEvent(*event)); err != nil {
+			log.Printf("journal: %s: %s", event.Get("event").String(), err)
+		}
+	}
+}
+
+// ImportJournal applies the events in a Journal.*.log source.
+func (j *JournalImporter) ImportJournal(source io.Reader) {
+	j.applyAll(parsing.ParseJSONObjects(source))
+}
+
+// ImportMarket applies the contents of a Market.json file.
+func (j *JournalImporter) ImportMarket(data []byte) error {
+	event := gjson.ParseBytes(data)
+	if !event.IsObject() {
+		return fmt.Errorf("%s: not a json object", marketFilename)
+	}
+	return j.ApplyEvent(event)
+}
+
+// ImportFile applies a journal log or Market.json file.
+func (j *JournalImporter) ImportFile(pathname string) error {
+	data, err := ioutil.ReadFile(pathname)
+	if err != nil {
+		return err
+	}
+	if filepath.Base(pathname) == marketFilename {
+		return j.ImportMarket(data)
+	}
+	j.ImportJournal(bytes.NewReader(data))
+	return nil
+}
+
+// JournalBatch is data that has appeared in one of the journal directory's files.
+type JournalBatch struct {
+	Filename string
+	Data     []byte
+}
+
+// JournalTailer follows a journal directory, collecting the complete lines
+// appended to the journal logs and each rewrite of Market.json.
+type JournalTailer struct {
+	dir        string
+	offsets    map[string]int64
+	marketTime time.Time
+}
+
+// NewJournalTailer starts following dir from the current end of its files,
+// so that only new events are seen.
+func NewJournalTailer(dir string) (*JournalTailer, error) {
+	stat, err := os.Stat(dir)
+	if err != nil {
+		return nil, err
+	}
+	if !stat.IsDir() {
+		return nil, fmt.Errorf("%s: not a directory", dir)
+	}
+	tailer := &JournalTailer{dir: dir, offsets: make(map[string]int64)}
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		if isJournalFile(file.Name()) {
+			tailer.offsets[file.Name()] = file.Size()
+		} else if file.Name() == marketFilename {
+			tailer.marketTime = file.ModTime()
+		}
+	}
+	return tailer, nil
+}
+
+// readFrom returns the complete lines in a file after offset.
+func readFrom(pathname string, offset int64) ([]byte, error) {
+	file, err := os.Open(pathname)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { Must(file.Close()) }()
+	if _, err = file.Seek(offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+	data, err := ioutil.ReadAll(file)
+	if err != nil {
+		return nil, err
+	}
+	return data[:bytes.LastIndexByte(data, '\n')+1], nil
+}
+
+// Poll returns what has been added to the directory since the last poll,
+// journals in name order - which is chronological - and then the market.
+func (t *JournalTailer) Poll() ([]JournalBatch, error) {
+	files, err := ioutil.ReadDir(t.dir)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Name() < files[j].Name() })
+
+	batches := make([]JournalBatch, 0, 2)
+	var market os.FileInfo
+	for _, file := range files {
+		if file.Name() == marketFilename {
+			market = file
+			continue
+		}
+		if !isJournalFile(file.Name()) || file.Size() <= t.offsets[file.Name()] {
+			continue
+		}
+		data, err := readFrom(filepath.Join(t.dir, file.Name()), t.offsets[file.Name()])
+		if err != nil {
+			return batches, err
+		}
+		if len(data) > 0 {
+			t.offsets[file.Name()] += int64(len(data))
+			batches = append(batches, JournalBatch{file.Name(), data})
+		}
+	}
+
+	if market != nil && market.ModTime().After(t.marketTime) {
+		data, err := ioutil.ReadFile(filepath.Join(t.dir, marketFilename))
+		if err != nil {
+			return batches, err
+		}
+		t.marketTime = market.ModTime()
+		batches = append(batches, JournalBatch{marketFilename, data})
+	}
+
+	return batches, nil
+}
+
+// Follow polls the directory every interval until stop is closed, sending
+// what it finds to the returned channel.
+func (t *JournalTailer) Follow(interval time.Duration, stop <-chan struct{}) <-chan JournalBatch {
+	channel := make(chan JournalBatch, 16)
+	go func() {
+		defer close(channel)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+			}
+			batches, err := t.Poll()
+			if err != nil {
+				log.Printf("journal: %s", err)
+			}
+			for _, batch := range batches {
+				select {
+				case channel <- batch:
+				case <-stop:
+					return
+				}
+			}
+		}
+	}()
+	return channel
+}
+
+// Import applies the batch to the database.
+func (b JournalBatch) Import(importer *JournalImporter) error {
+	if b.Filename == marketFilename {
+		return importer.ImportMarket(b.Data)
+	}
+	importer.ImportJournal(bytes.NewReader(b.Data))
+	return nil
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	gom "github.com/kfsone/gomenacing/pkg/gomschema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+const testJournal = `{ "timestamp":"2020-08-01T12:00:00Z", "event":"Fileheader", "part":1, "gameversion":"3.7" }
+{ "timestamp":"2020-08-01T12:05:00Z", "event":"FSDJump", "StarSystem":"Shinrarta Dezhra", "SystemAddress":3932277478106, "StarPos":[55.71875,17.59375,27.15625], "SystemAllegiance":"PilotsFederation", "SystemEconomy":"$economy_HighTech;", "SystemGovernment":"$government_Democracy;", "SystemSecurity":"$SYSTEM_SECURITY_high;", "Population":85206935, "SystemFaction":{ "Name":"Pilots' Federation Local Branch" } }
+{ "timestamp":"2020-08-01T12:10:00Z", "event":"Docked", "StationName":"Jameson Memorial", "StationType":"Orbis", "StarSystem":"Shinrarta Dezhra", "MarketID":128666762, "StationGovernment":"$government_Democracy;", "StationAllegiance":"PilotsFederation", "StationServices":[ "dock", "commodities", "blackmarket", "shipyard", "refuel" ], "StationEconomies":[ { "Name":"$economy_HighTech;", "Proportion":0.8 }, { "Name":"$economy_Industrial;", "Proportion":0.2 } ], "LandingPads":{ "Small":17, "Medium":18, "Large":9 }, "DistFromStarLS":346.4 }
+{ "timestamp":"2020-08-01T12:10:05Z", "event":"Market", "MarketID":128666762, "StationName":"Jameson Memorial", "StarSystem":"Shinrarta Dezhra" }
+`
+
+const testMarket = `{ "timestamp":"2020-08-01T12:10:05Z", "event":"Market", "MarketID":128666762, "StationName":"Jameson Memorial", "StarSystem":"Shinrarta Dezhra", "Items":[
+{ "id":128049152, "Name":"$platinum_name;", "Name_Localised":"Platinum", "BuyPrice":0, "SellPrice":45000, "StockBracket":0, "DemandBracket":3, "Stock":0, "Demand":1200 },
+{ "id":128049153, "Name":"$mineraloil_name;", "BuyPrice":150, "SellPrice":140, "StockBracket":2, "DemandBracket":"", "Stock":5000, "Demand":0 },
+{ "id":128049154, "Name":"$unobtainium_name;", "Name_Localised":"Unobtainium", "BuyPrice":1, "SellPrice":1, "Stock":1, "Demand":1 } ] }`
+
+func Test_journalSymbols(t *testing.T) {
+	assert.Equal(t, gom.GovernmentType_GovPrisonColony, journalGovernment("$government_PrisonColony;"))
+	assert.Equal(t, gom.GovernmentType_GovNone, journalGovernment("$government_Engineer;"))
+	assert.Equal(t, gom.EconomyType_EcoAgriculture, journalEconomy("$economy_Agri;"))
+	assert.Equal(t, gom.EconomyType_EcoHighTech, journalEconomy("$economy_HighTech;"))
+	assert.Equal(t, gom.SecurityLevel_SecurityMedium, journalSecurity("$SYSTEM_SECURITY_medium;"))
+	assert.Equal(t, gom.SecurityLevel_SecurityAnarchy, journalSecurity("$GAlAXY_MAP_INFO_state_anarchy;"))
+	assert.Equal(t, gom.AllegianceType_AllegEmpire, journalAllegiance("Empire"))
+	assert.Equal(t, gom.MarketBracket_BracketHigh, journalBracket(gjson.Parse("3")))
+	assert.Equal(t, gom.MarketBracket_BracketNone, journalBracket(gjson.Parse("0")))
+	assert.Equal(t, gom.MarketBracket_BracketUnknown, journalBracket(gjson.Parse(`""`)))
+}
+
+func TestJournalImporter(t *testing.T) {
+	testDir := GetTestDir()
+	defer testDir.Close()
+	db, err := OpenDatabase(testDir.Path(), "journal.db")
+	require.Nil(t, err)
+	defer db.Close()
+
+	sdb := NewSystemDatabase(db)
+	importer := NewJournalImporter(sdb, db)
+	require.Nil(t, importer.apply(&gom.Commodity{Id: 1, Name: "Platinum"}))
+	require.Nil(t, importer.apply(&gom.Commodity{Id: 2, Name: "Mineral Oil"}))
+	require.Nil(t, importer.apply(&gom.System{Id: 7, Name: "Sol", Position: &gom.Coordinate{}}))
+
+	importer.ImportJournal(strings.NewReader(testJournal))
+	require.Nil(t, importer.ImportMarket([]byte(testMarket)))
+	importer.Close()
+	assert.Equal(t, 3+3, importer.Updates)
+
+	system := sdb.GetSystem("Shinrarta Dezhra")
+	require.NotNil(t, system)
+	assert.Equal(t, syntheticIDBase, system.ID)
+	assert.Equal(t, Coordinate{55.71875, 17.59375, 27.15625}, *system.Position())
+	assert.Equal(t, gom.SecurityLevel_SecurityHigh, system.SecurityLevel)
+	assert.Equal(t, gom.GovernmentType_GovDemocracy, system.Government)
+	assert.Equal(t, gom.AllegianceType_AllegPilotsFederation, system.Allegiance)
+	assert.Equal(t, gom.EconomyType_EcoHighTech, system.PrimaryEconomy)
+	assert.True(t, system.Populated)
+	assert.Equal(t, "Pilots' Federation Local Branch", system.ControllingFaction)
+
+	facility := sdb.GetFacilityByMarketID(128666762)
+	require.NotNil(t, facility)
+	assert.Equal(t, "Jameson Memorial", facility.DbName)
+	assert.Equal(t, system, facility.System)
+	assert.Equal(t, gom.FacilityType_FTOrbisStarport, facility.FacilityType)
+	assert.EqualValues(t, 346, facility.LsFromStar)
+	assert.True(t, facility.HasFeatures(FeatLargePad|FeatBlackMarket|FeatCommodities|FeatShipyard))
+	assert.False(t, facility.HasFeatures(FeatOutfitting))
+	assert.Equal(t, []gom.EconomyType{gom.EconomyType_EcoHighTech, gom.EconomyType_EcoIndustrial}, facility.Economies)
+
+	require.Len(t, facility.listings, 2)
+	assert.EqualValues(t, 45000, facility.listings[1].StationPays)
+	assert.Equal(t, gom.MarketBracket_BracketHigh, facility.listings[1].DemandBracket)
+	assert.EqualValues(t, 5000, facility.listings[2].Supply)
+	assert.EqualValues(t, 150, facility.listings[2].StationAsks)
+
+	// Older news is ignored.
+	older := strings.Replace(strings.Split(testJournal, "\n")[2], "12:10:00", "11:00:00", 1)
+	older = strings.Replace(older, `"Orbis"`, `"Coriolis"`, 1)
+	require.Nil(t, importer.ApplyEvent(gjson.Parse(older)))
+	assert.Equal(t, gom.FacilityType_FTOrbisStarport, facility.FacilityType)
+
+	// Markets for unknown stations are reported.
+	err = importer.ImportMarket([]byte(strings.Replace(testMarket, "128666762", "1", 1)))
+	assert.True(t, errors.Is(err, ErrUnknownEntity))
+	importer.Close()
+
+	// The updates were written through to the database.
+	reloaded := NewSystemDatabase(db)
+	require.Nil(t, db.LoadDatabase(reloaded))
+	facility = reloaded.GetFacilityByMarketID(128666762)
+	require.NotNil(t, facility)
+	assert.Len(t, facility.listings, 2)
+
+	// A later EDDB import moves them to EDDB's ids, taking their records along.
+	systems, err := db.Systems()
+	require.Nil(t, err)
+	require.Nil(t, reloaded.registerFromMessage(&gom.System{Id: 8, Name: "Shinrarta Dezhra", Position: &gom.Coordinate{X: 55.71875}}, systems, "eddb"))
+	require.Nil(t, systems.Close())
+	facilities, err := db.Facilities()
+	require.Nil(t, err)
+	require.Nil(t, reloaded.registerFromMessage(&gom.Facility{Id: 3, SystemId: 8, Name: "Jameson Memorial", MarketId: 128666762}, facilities, "eddb"))
+	require.Nil(t, facilities.Close())
+	assert.EqualValues(t, 8, reloaded.GetSystem("Shinrarta Dezhra").ID)
+	assert.EqualValues(t, 3, reloaded.GetFacilityByMarketID(128666762).ID)
+
+	rekeyed := NewSystemDatabase(db)
+	require.Nil(t, db.LoadDatabase(rekeyed))
+	assert.Len(t, rekeyed.systemsByID, 2)
+	facility = rekeyed.GetFacilityByID(3)
+	require.NotNil(t, facility)
+	assert.Equal(t, rekeyed.GetSystemByID(8), facility.System)
+	assert.Len(t, facility.listings, 2)
+	assert.Nil(t, rekeyed.GetFacilityByID(syntheticIDBase))
+}
+
+func TestJournalTailer(t *testing.T) {
+	dir, err := ioutil.TempDir("", "journal")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+	write := func(filename, text string, flags int) {
+		file, err := os.OpenFile(filepath.Join(dir, filename), flags|os.O_CREATE|os.O_WRONLY, 0644)
+		require.Nil(t, err)
+		_, err = file.WriteString(text)
+		require.Nil(t, err)
+		require.Nil(t, file.Close())
+	}
+
+	// What was there before we started is history.
+	write("Journal.200801120000.01.log", "{\"event\":\"Old\"}\n", os.O_TRUNC)
+	write(marketFilename, "{}", os.O_TRUNC)
+	tailer, err := NewJournalTailer(dir)
+	require.Nil(t, err)
+	batches, err := tailer.Poll()
+	require.Nil(t, err)
+	assert.Empty(t, batches)
+
+	// Only complete lines are collected.
+	write("Journal.200801120000.01.log", "{\"event\":\"New\"}\n{\"event\":", os.O_APPEND)
+	write("Journal.200801130000.01.log", "{\"event\":\"Next\"}\n", os.O_TRUNC)
+	batches, err = tailer.Poll()
+	require.Nil(t, err)
+	require.Len(t, batches, 2)
+	assert.Equal(t, JournalBatch{"Journal.200801120000.01.log", []byte("{\"event\":\"New\"}\n")}, batches[0])
+	assert.Equal(t, "Journal.200801130000.01.log", batches[1].Filename)
+
+	write("Journal.200801120000.01.log", "\"Rest\"}\n", os.O_APPEND)
+	later := time.Now().Add(time.Minute)
+	write(marketFilename, testMarket, os.O_TRUNC)
+	require.Nil(t, os.Chtimes(filepath.Join(dir, marketFilename), later, later))
+	batches, err = tailer.Poll()
+	require.Nil(t, err)
+	require.Len(t, batches, 2)
+	assert.Equal(t, []byte("{\"event\":\"Rest\"}\n"), batches[0].Data)
+	assert.Equal(t, marketFilename, batches[1].Filename)
+	assert.True(t, bytes.Contains(batches[1].Data, []byte("Jameson Memorial")))
+
+	batches, err = tailer.Poll()
+	require.Nil(t, err)
+	assert.Empty(t, batches)
+
+	_, err = NewJournalTailer(filepath.Join(dir, marketFilename))
+	assert.Error(t, err)
+}
@@ -2,15 +2,44 @@ package main
 
 import (
 	"fmt"
+
+	gom "github.com/kfsone/gomenacing/pkg/gomschema"
 )
 
 type Listing struct {
-	CommodityID  EntityID
-	Supply       uint32
-	StationPays  uint32
-	Demand       uint32
-	StationAsks  uint32
-	TimestampUtc uint64
+	CommodityID   EntityID
+	Supply        uint32
+	StationPays   uint32
+	Demand        uint32
+	StationAsks   uint32
+	TimestampUtc  uint64
+	SupplyBracket gom.MarketBracket
+	DemandBracket gom.MarketBracket
+}
+
+// applyCommodityListing copies the market data from a schema listing.
+func (l *Listing) applyCommodityListing(from *gom.CommodityListing) {
+	l.Supply = from.GetSupplyUnits()
+	l.StationAsks = from.GetSupplyCredits()
+	l.Demand = from.GetDemandUnits()
+	l.StationPays = from.GetDemandCredits()
+	l.TimestampUtc = from.GetTimestampUtc()
+	l.SupplyBracket = from.GetSupplyBracket()
+	l.DemandBracket = from.GetDemandBracket()
+}
+
+// commodityListing is the inverse of applyCommodityListing.
+func (l *Listing) commodityListing() *gom.CommodityListing {
+	return &gom.CommodityListing{
+		CommodityId:   uint32(l.CommodityID),
+		SupplyUnits:   l.Supply,
+		SupplyCredits: l.StationAsks,
+		DemandUnits:   l.Demand,
+		DemandCredits: l.StationPays,
+		TimestampUtc:  l.TimestampUtc,
+		SupplyBracket: l.SupplyBracket,
+		DemandBracket: l.DemandBracket,
+	}
 }
 
 func (l *Listing) GetId() uint32 {
@@ -1,6 +1,7 @@
 package main
 
 import (
+	gom "github.com/kfsone/gomenacing/pkg/gomschema"
 	"github.com/stretchr/testify/assert"
 	"testing"
 )
@@ -21,3 +22,27 @@ func TestListing_GetTimestampUtc(t *testing.T) {
 	listing := Listing{TimestampUtc: 4770}
 	assert.Equal(t, uint64(4770), listing.GetTimestampUtc())
 }
+
+func TestListing_applyCommodityListing(t *testing.T) {
+	listing := Listing{CommodityID: 5}
+	listing.applyCommodityListing(&gom.CommodityListing{
+		CommodityId:   5,
+		SupplyUnits:   1200,
+		SupplyCredits: 310,
+		DemandUnits:   0,
+		DemandCredits: 280,
+		TimestampUtc:  1596909218,
+		SupplyBracket: gom.MarketBracket_BracketHigh,
+		DemandBracket: gom.MarketBracket_BracketNone,
+	})
+	assert.Equal(t, Listing{
+		CommodityID:   5,
+		Supply:        1200,
+		StationAsks:   310,
+		Demand:        0,
+		StationPays:   280,
+		TimestampUtc:  1596909218,
+		SupplyBracket: gom.MarketBracket_BracketHigh,
+		DemandBracket: gom.MarketBracket_BracketNone,
+	}, listing)
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"time"
 
 	flag "github.com/spf13/pflag"
 )
@@ -35,15 +36,45 @@ func main() {
 		fmt.Printf("import not implemented")
 		//importEddbData(db)
 	}
-	failOnError(db.LoadDatabase(sdb))
+	if !loadFromSnapshot(db, sdb) {
+		failOnError(db.LoadDatabase(sdb))
+	}
 
 	reader := bufio.NewReader(os.Stdin)
 
 	repl, err := NewRepl(db, sdb, bufio.NewScanner(reader), os.Stdout)
 	failOnError(err)
 
+	if *JournalDir != "" {
+		stop, err := repl.FollowJournal(*JournalDir)
+		failOnError(err)
+		defer stop()
+	}
+
 	if db != nil {
 		err = repl.Run("GoM> ")
 		failOnError(err)
+		if *UseSnapshot {
+			failOnError(UpdateSnapshot(db, repl.sdb))
+		}
+	}
+}
+
+// loadFromSnapshot populates sdb from the database snapshot, if there is a
+// current one, and returns false if the database needs loading normally.
+func loadFromSnapshot(db *Database, sdb *SystemDatabase) bool {
+	if !*UseSnapshot {
+		return false
+	}
+	start := time.Now()
+	if err := LoadSnapshot(db, sdb); err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("Not using snapshot: %s", err)
+		}
+		*sdb = *NewSystemDatabase(db)
+		return false
 	}
+	log.Printf("Loaded %d Systems, %d Facilities, %d Commodities from snapshot in %s.",
+		len(sdb.systemsByID), len(sdb.facilitiesByID), len(sdb.commoditiesByID), time.Since(start))
+	return true
 }
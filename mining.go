@@ -0,0 +1,209 @@
+package main
+
+import (
+	"sort"
+
+	gom "github.com/kfsone/gomenacing/pkg/gomschema"
+	flag "github.com/spf13/pflag"
+)
+
+// MiningRate is what an hour of travel is assumed to cost a miner in time
+// that could otherwise be spent mining.
+var MiningRate = flag.Int64("miningrate", 50000000, "Credits an hour of mining earns, charged against travel when selling a haul.")
+
+// MinedCargo is a quantity of a mined commodity to be sold.
+type MinedCargo struct {
+	Commodity *Commodity
+	Units     int
+}
+
+// mergeMinedCargo combines entries for the same commodity, keeping the order
+// in which each was first listed.
+func mergeMinedCargo(cargo []MinedCargo) []MinedCargo {
+	merged := make([]MinedCargo, 0, len(cargo))
+	index := make(map[*Commodity]int, len(cargo))
+	for _, haul := range cargo {
+		if idx, exists := index[haul.Commodity]; exists {
+			merged[idx].Units += haul.Units
+		} else {
+			index[haul.Commodity] = len(merged)
+			merged = append(merged, haul)
+		}
+	}
+	return merged
+}
+
+// MiningSale is part of a haul sold at a facility.
+type MiningSale struct {
+	Commodity *Commodity
+	Units     int
+	PriceCr   uint32
+}
+
+// MiningStop is a facility at which some of a haul is sold.
+type MiningStop struct {
+	Facility *Facility
+	Sales    []MiningSale
+	// GrossCr is what the sales will earn.
+	GrossCr int64
+	// TravelCostCr is the cost of getting to the facility from the previous stop.
+	TravelCostCr int64
+}
+
+// NetCr is the stop's earnings after travel.
+func (s *MiningStop) NetCr() int64 {
+	return s.GrossCr - s.TravelCostCr
+}
+
+// Units is how many units are sold at the stop.
+func (s *MiningStop) Units() int {
+	units := 0
+	for _, sale := range s.Sales {
+		units += sale.Units
+	}
+	return units
+}
+
+// NetPerUnit is the stop's earnings after travel per unit sold.
+func (s *MiningStop) NetPerUnit() float64 {
+	if units := s.Units(); units > 0 {
+		return float64(s.NetCr()) / float64(units)
+	}
+	return 0
+}
+
+// MiningPlan is a sequence of stops selling a haul.
+type MiningPlan struct {
+	Stops        []*MiningStop
+	GrossCr      int64
+	TravelCostCr int64
+	// Unsold is what is left over when nowhere else wants it.
+	Unsold []MinedCargo
+}
+
+// NetCr is the plan's earnings after travel.
+func (p *MiningPlan) NetCr() int64 {
+	return p.GrossCr - p.TravelCostCr
+}
+
+// miningTravelCostCr estimates the cost of travelling from a system to a facility.
+func (sdb *SystemDatabase) miningTravelCostCr(from *System, to *Facility) int64 {
+	return int64(sdb.travel.Arrive(from, to).Total().Hours() * float64(sdb.miningRate))
+}
+
+// sellToFacility works out how much of the cargo facility will buy, as of now.
+func (sdb *SystemDatabase) sellToFacility(from *System, facility *Facility, cargo []MinedCargo, now uint64) *MiningStop {
+	stop := &MiningStop{Facility: facility, TravelCostCr: sdb.miningTravelCostCr(from, facility)}
+	for _, haul := range cargo {
+		listing, exists := facility.listings[haul.Commodity.ID]
+		if !exists || haul.Units <= 0 || listing.StationPays == 0 || listing.DemandBracket == gom.MarketBracket_BracketNone {
+			continue
+		}
+		if sdb.isStale(listing.TimestampUtc, now) {
+			continue
+		}
+		units := haul.Units
+		if listing.Demand > 0 && int(listing.Demand) < units {
+			units = int(listing.Demand)
+		}
+		stop.Sales = append(stop.Sales, MiningSale{Commodity: haul.Commodity, Units: units, PriceCr: listing.StationPays})
+		stop.GrossCr += int64(units) * int64(listing.StationPays)
+	}
+	return stop
+}
+
+// rankMiningStops sorts stops by score, best first.
+func rankMiningStops(stops []*MiningStop, score func(*MiningStop) float64) {
+	sort.SliceStable(stops, func(i, j int) bool {
+		if lhs, rhs := score(stops[i]), score(stops[j]); lhs != rhs {
+			return lhs > rhs
+		}
+		return stops[i].Facility.ID < stops[j].Facility.ID
+	})
+}
+
+// miningMarkets lists the facilities within rangeLy of origin that buy any of the cargo.
+func (sdb *SystemDatabase) miningMarkets(origin *System, rangeLy float64, cargo []MinedCargo, now uint64) ([]*Facility, error) {
+	markets := make([]*Facility, 0, 32)
+	_, err := sdb.getSystemsWithinRange(origin, rangeLy, func(system *System, _ SquareFloat) bool {
+		for _, facility := range system.facilities {
+			if len(sdb.sellToFacility(origin, facility, cargo, now).Sales) > 0 {
+				markets = append(markets, facility)
+			}
+		}
+		return true
+	})
+	return markets, err
+}
+
+// RankMiningMarkets returns, for each facility within rangeLy of origin that
+// will buy any of the cargo, what selling there alone would earn, ranked by
+// credits after travel.
+func (sdb *SystemDatabase) RankMiningMarkets(origin *System, rangeLy float64, cargo []MinedCargo, now uint64) ([]*MiningStop, error) {
+	cargo = mergeMinedCargo(cargo)
+	markets, err := sdb.miningMarkets(origin, rangeLy, cargo, now)
+	if err != nil {
+		return nil, err
+	}
+	stops := make([]*MiningStop, len(markets))
+	for idx, facility := range markets {
+		stops[idx] = sdb.sellToFacility(origin, facility, cargo, now)
+	}
+	rankMiningStops(stops, func(stop *MiningStop) float64 { return float64(stop.NetCr()) })
+	return stops, nil
+}
+
+// PlanMiningSales finds where to sell cargo within rangeLy of origin. Each
+// market only takes as many units as it has demand for, so a large haul is
+// split: the next stop is always the one that earns the most per unit after
+// travel from the last, so the best prices are used up first, until
+// everything is sold or nowhere else is worth the trip. The first stop is
+// taken even if travel costs more than it earns, so a small haul still gets
+// the best place to sell it.
+func (sdb *SystemDatabase) PlanMiningSales(origin *System, rangeLy float64, cargo []MinedCargo, now uint64) (*MiningPlan, error) {
+	remaining := mergeMinedCargo(cargo)
+	markets, err := sdb.miningMarkets(origin, rangeLy, remaining, now)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &MiningPlan{}
+	visited := make(map[*Facility]bool, len(markets))
+	position := origin
+	for {
+		candidates := make([]*MiningStop, 0, len(markets))
+		for _, facility := range markets {
+			if !visited[facility] {
+				if stop := sdb.sellToFacility(position, facility, remaining, now); len(stop.Sales) > 0 {
+					candidates = append(candidates, stop)
+				}
+			}
+		}
+		rankMiningStops(candidates, (*MiningStop).NetPerUnit)
+		if len(candidates) == 0 || (candidates[0].NetCr() <= 0 && len(plan.Stops) > 0) {
+			break
+		}
+
+		stop := candidates[0]
+		visited[stop.Facility] = true
+		plan.Stops = append(plan.Stops, stop)
+		plan.GrossCr += stop.GrossCr
+		plan.TravelCostCr += stop.TravelCostCr
+		for _, sale := range stop.Sales {
+			for idx := range remaining {
+				if remaining[idx].Commodity == sale.Commodity {
+					remaining[idx].Units -= sale.Units
+					break
+				}
+			}
+		}
+		position = stop.Facility.System
+	}
+
+	for _, haul := range remaining {
+		if haul.Units > 0 {
+			plan.Unsold = append(plan.Unsold, haul)
+		}
+	}
+	return plan, nil
+}
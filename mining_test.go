@@ -0,0 +1,162 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	gom "github.com/kfsone/gomenacing/pkg/gomschema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSystemDatabase_RankMiningMarkets(t *testing.T) {
+	sdb := NewSystemDatabase(nil)
+	require.Nil(t, sdb.newCommodity(&gom.Commodity{Id: 1, Name: "Painite"}))
+	require.Nil(t, sdb.newCommodity(&gom.Commodity{Id: 2, Name: "Platinum"}))
+	require.Nil(t, sdb.newSystem(&gom.System{Id: 1, Name: "Sol", Position: &gom.Coordinate{}}))
+	require.Nil(t, sdb.newSystem(&gom.System{Id: 2, Name: "Alpha Centauri", Position: &gom.Coordinate{X: 4.4}}))
+	require.Nil(t, sdb.newSystem(&gom.System{Id: 3, Name: "Barnard's Star", Position: &gom.Coordinate{X: 6}}))
+	require.Nil(t, sdb.newFacility(&gom.Facility{Id: 1, SystemId: 1, Name: "Galileo", LsFromStar: 100}))
+	require.Nil(t, sdb.newFacility(&gom.Facility{Id: 2, SystemId: 2, Name: "Hutton Orbital", LsFromStar: 500}))
+	// Pays the best, but is so far out it isn't worth the trip.
+	require.Nil(t, sdb.newFacility(&gom.Facility{Id: 3, SystemId: 3, Name: "Miller Depot", LsFromStar: 500000}))
+	require.Nil(t, sdb.newListings(&gom.FacilityListing{Id: 1, Listings: []*gom.CommodityListing{
+		{CommodityId: 1, DemandUnits: 100, DemandCredits: 500000, DemandBracket: gom.MarketBracket_BracketHigh},
+	}}))
+	require.Nil(t, sdb.newListings(&gom.FacilityListing{Id: 2, Listings: []*gom.CommodityListing{
+		{CommodityId: 1, DemandUnits: 1000, DemandCredits: 450000, DemandBracket: gom.MarketBracket_BracketHigh},
+		{CommodityId: 2, DemandUnits: 1000, DemandCredits: 50000, DemandBracket: gom.MarketBracket_BracketHigh},
+	}}))
+	require.Nil(t, sdb.newListings(&gom.FacilityListing{Id: 3, Listings: []*gom.CommodityListing{
+		{CommodityId: 1, DemandUnits: 5, DemandCredits: 600000, DemandBracket: gom.MarketBracket_BracketHigh},
+	}}))
+	painite := sdb.GetCommodityByID(1)
+
+	stops, err := sdb.RankMiningMarkets(sdb.GetSystem("Sol"), 10, []MinedCargo{{painite, 300}}, 0)
+	require.Nil(t, err)
+	require.Len(t, stops, 3)
+	// Hutton has the demand for the whole haul.
+	assert.Equal(t, "Hutton Orbital", stops[0].Facility.DbName)
+	assert.EqualValues(t, 300*450000, stops[0].GrossCr)
+	assert.Equal(t, sdb.miningTravelCostCr(sdb.GetSystem("Sol"), stops[0].Facility), stops[0].TravelCostCr)
+	assert.Equal(t, "Galileo", stops[1].Facility.DbName)
+	assert.Equal(t, 100, stops[1].Units())
+	assert.Equal(t, "Miller Depot", stops[2].Facility.DbName)
+	assert.True(t, stops[2].NetCr() < 0)
+}
+
+func TestSystemDatabase_PlanMiningSales(t *testing.T) {
+	sdb := NewSystemDatabase(nil)
+	require.Nil(t, sdb.newCommodity(&gom.Commodity{Id: 1, Name: "Painite"}))
+	require.Nil(t, sdb.newCommodity(&gom.Commodity{Id: 2, Name: "Platinum"}))
+	require.Nil(t, sdb.newSystem(&gom.System{Id: 1, Name: "Sol", Position: &gom.Coordinate{}}))
+	require.Nil(t, sdb.newSystem(&gom.System{Id: 2, Name: "Alpha Centauri", Position: &gom.Coordinate{X: 4.4}}))
+	require.Nil(t, sdb.newSystem(&gom.System{Id: 3, Name: "Barnard's Star", Position: &gom.Coordinate{X: 6}}))
+	require.Nil(t, sdb.newFacility(&gom.Facility{Id: 1, SystemId: 1, Name: "Galileo", LsFromStar: 100}))
+	require.Nil(t, sdb.newFacility(&gom.Facility{Id: 2, SystemId: 2, Name: "Hutton Orbital", LsFromStar: 500}))
+	// Pays the best, but is so far out it isn't worth the trip.
+	require.Nil(t, sdb.newFacility(&gom.Facility{Id: 3, SystemId: 3, Name: "Miller Depot", LsFromStar: 500000}))
+	require.Nil(t, sdb.newListings(&gom.FacilityListing{Id: 1, Listings: []*gom.CommodityListing{
+		{CommodityId: 1, DemandUnits: 100, DemandCredits: 500000, DemandBracket: gom.MarketBracket_BracketHigh},
+	}}))
+	require.Nil(t, sdb.newListings(&gom.FacilityListing{Id: 2, Listings: []*gom.CommodityListing{
+		{CommodityId: 1, DemandUnits: 1000, DemandCredits: 450000, DemandBracket: gom.MarketBracket_BracketHigh},
+		{CommodityId: 2, DemandUnits: 1000, DemandCredits: 50000, DemandBracket: gom.MarketBracket_BracketHigh},
+	}}))
+	require.Nil(t, sdb.newListings(&gom.FacilityListing{Id: 3, Listings: []*gom.CommodityListing{
+		{CommodityId: 1, DemandUnits: 5, DemandCredits: 600000, DemandBracket: gom.MarketBracket_BracketHigh},
+	}}))
+	painite, platinum := sdb.GetCommodityByID(1), sdb.GetCommodityByID(2)
+
+	// The haul is split, using up Galileo's better price first.
+	plan, err := sdb.PlanMiningSales(sdb.GetSystem("Sol"), 10, []MinedCargo{{painite, 300}, {platinum, 20}}, 0)
+	require.Nil(t, err)
+	require.Len(t, plan.Stops, 2)
+	assert.Equal(t, "Galileo", plan.Stops[0].Facility.DbName)
+	assert.Equal(t, "Hutton Orbital", plan.Stops[1].Facility.DbName)
+	assert.Equal(t, []MiningSale{{painite, 200, 450000}, {platinum, 20, 50000}}, plan.Stops[1].Sales)
+	assert.EqualValues(t, 100*500000+200*450000+20*50000, plan.GrossCr)
+	assert.Equal(t, plan.GrossCr-plan.TravelCostCr, plan.NetCr())
+	assert.Empty(t, plan.Unsold)
+
+	// With only Galileo in range, what it doesn't want is left over.
+	plan, err = sdb.PlanMiningSales(sdb.GetSystem("Sol"), 1, []MinedCargo{{painite, 300}, {platinum, 20}}, 0)
+	require.Nil(t, err)
+	require.Len(t, plan.Stops, 1)
+	assert.Equal(t, []MinedCargo{{painite, 200}, {platinum, 20}}, plan.Unsold)
+
+	// Listing a commodity twice doesn't sell it twice.
+	plan, err = sdb.PlanMiningSales(sdb.GetSystem("Sol"), 1, []MinedCargo{{painite, 60}, {platinum, 20}, {painite, 60}}, 0)
+	require.Nil(t, err)
+	require.Len(t, plan.Stops, 1)
+	assert.Equal(t, []MiningSale{{painite, 100, 500000}}, plan.Stops[0].Sales)
+	assert.Equal(t, []MinedCargo{{painite, 20}, {platinum, 20}}, plan.Unsold)
+
+	// A haul too small to pay for the trip still gets the best place to sell it.
+	plan, err = sdb.PlanMiningSales(sdb.GetSystem("Sol"), 10, []MinedCargo{{painite, 1}}, 0)
+	require.Nil(t, err)
+	require.Len(t, plan.Stops, 1)
+	assert.Equal(t, "Galileo", plan.Stops[0].Facility.DbName)
+	assert.True(t, plan.NetCr() < 0)
+
+	// It's worth the trip to a miner who earns less in the meantime.
+	sdb.miningRate = 1000000
+	plan, err = sdb.PlanMiningSales(sdb.GetSystem("Sol"), 10, []MinedCargo{{painite, 1}}, 0)
+	require.Nil(t, err)
+	require.Len(t, plan.Stops, 1)
+	assert.True(t, plan.NetCr() > 0)
+}
+
+func TestRepl_MiningSell(t *testing.T) {
+	sdb := NewSystemDatabase(nil)
+	require.Nil(t, sdb.newCommodity(&gom.Commodity{Id: 1, Name: "Painite"}))
+	require.Nil(t, sdb.newCommodity(&gom.Commodity{Id: 2, Name: "Platinum"}))
+	require.Nil(t, sdb.newSystem(&gom.System{Id: 1, Name: "Sol", Position: &gom.Coordinate{}}))
+	require.Nil(t, sdb.newSystem(&gom.System{Id: 2, Name: "Alpha Centauri", Position: &gom.Coordinate{X: 4.4}}))
+	require.Nil(t, sdb.newSystem(&gom.System{Id: 3, Name: "Barnard's Star", Position: &gom.Coordinate{X: 6}}))
+	require.Nil(t, sdb.newFacility(&gom.Facility{Id: 1, SystemId: 1, Name: "Galileo", LsFromStar: 100}))
+	require.Nil(t, sdb.newFacility(&gom.Facility{Id: 2, SystemId: 2, Name: "Hutton Orbital", LsFromStar: 500}))
+	// Pays the best, but is so far out it isn't worth the trip.
+	require.Nil(t, sdb.newFacility(&gom.Facility{Id: 3, SystemId: 3, Name: "Miller Depot", LsFromStar: 500000}))
+	require.Nil(t, sdb.newListings(&gom.FacilityListing{Id: 1, Listings: []*gom.CommodityListing{
+		{CommodityId: 1, DemandUnits: 100, DemandCredits: 500000, DemandBracket: gom.MarketBracket_BracketHigh},
+	}}))
+	require.Nil(t, sdb.newListings(&gom.FacilityListing{Id: 2, Listings: []*gom.CommodityListing{
+		{CommodityId: 1, DemandUnits: 1000, DemandCredits: 450000, DemandBracket: gom.MarketBracket_BracketHigh},
+		{CommodityId: 2, DemandUnits: 1000, DemandCredits: 50000, DemandBracket: gom.MarketBracket_BracketHigh},
+	}}))
+	require.Nil(t, sdb.newListings(&gom.FacilityListing{Id: 3, Listings: []*gom.CommodityListing{
+		{CommodityId: 1, DemandUnits: 5, DemandCredits: 600000, DemandBracket: gom.MarketBracket_BracketHigh},
+	}}))
+
+	var output bytes.Buffer
+	repl := &Repl{sdb: sdb, out: &output}
+	cmdMiningSell(repl, strings.Fields("10 Sol: 300 painite, 20 platinum"), nil)
+	assert.Contains(t, output.String(), "1. Sol/Galileo")
+	assert.Contains(t, output.String(), "2. Alpha Centauri/Hutton Orbital")
+
+	cargo, err := repl.parseMinedCargo("10 painite, 5 platinum, 20 painite")
+	require.Nil(t, err)
+	assert.Equal(t, []MinedCargo{{repl.sdb.GetCommodityByID(1), 30}, {repl.sdb.GetCommodityByID(2), 5}}, cargo)
+
+	output.Reset()
+	cmdMiningSell(repl, strings.Fields("10 Sol: lots painite"), nil)
+	assert.Contains(t, output.String(), "invalid tonnage")
+
+	output.Reset()
+	cmdMiningSell(repl, strings.Fields("10 Sol"), nil)
+	assert.Contains(t, output.String(), "Please specify")
+
+	output.Reset()
+	cmdMiningSell(repl, strings.Fields("10 Sol: 1 painite"), nil)
+	assert.Contains(t, output.String(), "1. Sol/Galileo")
+
+	defer func(rate int64) { *MiningRate = rate }(*MiningRate)
+	output.Reset()
+	cmdSetMiningRate(repl, []string{"1000000"}, nil)
+	assert.Contains(t, output.String(), "MiningRate is now: 1000000cr/h")
+	assert.EqualValues(t, 1000000, repl.sdb.miningRate)
+	cmdSetMiningRate(repl, []string{"lots"}, nil)
+	assert.Contains(t, output.String(), "Invalid mining rate: lots")
+}
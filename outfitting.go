@@ -0,0 +1,324 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	gom "github.com/kfsone/gomenacing/pkg/gomschema"
+	"google.golang.org/protobuf/proto"
+)
+
+// Module is a ship module that can be bought through outfitting.
+type Module struct {
+	DbEntity
+	Category  string // Kind of slot, e.g. "hardpoint".
+	Group     string // Family of module, e.g. "Frame Shift Drive".
+	SizeClass uint32 // Size class.
+	Rating    string // Rating letter.
+	PriceCr   uint32 // Base price.
+}
+
+func (m *Module) Name() string {
+	return m.DbName
+}
+
+// Ship is a type of ship that can be bought at a shipyard.
+type Ship struct {
+	DbEntity
+	PriceCr uint32 // Base price.
+}
+
+func (s *Ship) Name() string {
+	return s.DbName
+}
+
+// Inventory is the set of modules or ships a facility has for sale.
+type Inventory struct {
+	TimestampUtc uint64
+	Items        map[EntityID]bool
+}
+
+func newInventory(timestamp uint64, ids []uint32) *Inventory {
+	inventory := &Inventory{TimestampUtc: timestamp, Items: make(map[EntityID]bool, len(ids))}
+	for _, id := range ids {
+		inventory.Items[EntityID(id)] = true
+	}
+	return inventory
+}
+
+func (i *Inventory) GetTimestampUtc() uint64 {
+	return i.TimestampUtc
+}
+
+// Has returns true if the item is for sale. Safe to call on a nil inventory.
+func (i *Inventory) Has(id EntityID) bool {
+	return i != nil && i.Items[id]
+}
+
+// HasAny returns true if any of the items are for sale.
+func (i *Inventory) HasAny(ids []EntityID) bool {
+	for _, id := range ids {
+		if i.Has(id) {
+			return true
+		}
+	}
+	return false
+}
+
+// IDs returns the ids of the items for sale, in ascending order.
+func (i *Inventory) IDs() []uint32 {
+	if i == nil {
+		return nil
+	}
+	ids := make([]uint32, 0, len(i.Items))
+	for id := range i.Items {
+		ids = append(ids, uint32(id))
+	}
+	sort.Slice(ids, func(a, b int) bool { return ids[a] < ids[b] })
+	return ids
+}
+
+func (sdb *SystemDatabase) registerModule(module *Module) error {
+	if _, present := sdb.modulesByID[module.ID]; present {
+		return fmt.Errorf("%s (#%d): %w: module id", module.DbName, module.ID, ErrDuplicateEntity)
+	}
+	if !registerIDLookup(&module.DbEntity, sdb.moduleIDs) {
+		return fmt.Errorf("%s (#%d): %w: module name", module.DbName, module.ID, ErrDuplicateEntity)
+	}
+	sdb.modulesByID[module.ID] = module
+	return nil
+}
+
+func (sdb *SystemDatabase) registerShip(ship *Ship) error {
+	if _, present := sdb.shipsByID[ship.ID]; present {
+		return fmt.Errorf("%s (#%d): %w: ship id", ship.DbName, ship.ID, ErrDuplicateEntity)
+	}
+	if !registerIDLookup(&ship.DbEntity, sdb.shipIDs) {
+		return fmt.Errorf("%s (#%d): %w: ship name", ship.DbName, ship.ID, ErrDuplicateEntity)
+	}
+	sdb.shipsByID[ship.ID] = ship
+	return nil
+}
+
+func (sdb *SystemDatabase) GetModuleByID(id EntityID) *Module {
+	if module, exists := sdb.modulesByID[id]; exists {
+		return module
+	}
+	return nil
+}
+
+func (sdb *SystemDatabase) GetShipByID(id EntityID) *Ship {
+	if ship, exists := sdb.shipsByID[id]; exists {
+		return ship
+	}
+	return nil
+}
+
+// matchNames returns the ids of entries named name, or failing that, every
+// entry whose name contains it (ignoring case).
+func matchNames(name string, ids map[string]EntityID) []EntityID {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if id, exists := ids[name]; exists {
+		return []EntityID{id}
+	}
+	matches := make([]EntityID, 0, 8)
+	if name == "" {
+		return matches
+	}
+	for candidate, id := range ids {
+		if strings.Contains(candidate, name) {
+			matches = append(matches, id)
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool { return matches[i] < matches[j] })
+	return matches
+}
+
+// FindModules returns the modules matching name exactly, or partially.
+func (sdb *SystemDatabase) FindModules(name string) []EntityID {
+	return matchNames(name, sdb.moduleIDs)
+}
+
+// FindShips returns the ships matching name exactly, or partially.
+func (sdb *SystemDatabase) FindShips(name string) []EntityID {
+	return matchNames(name, sdb.shipIDs)
+}
+
+func (sdb *SystemDatabase) newModule(gomItem *gom.Module) error {
+	entity, err := NewDbEntity(int64(gomItem.Id), gomItem.Name)
+	if err != nil {
+		return err
+	}
+	return sdb.registerModule(&Module{
+		DbEntity:  entity,
+		Category:  gomItem.GetCategory(),
+		Group:     gomItem.GetGroup(),
+		SizeClass: gomItem.GetSizeClass(),
+		Rating:    gomItem.GetRating(),
+		PriceCr:   gomItem.GetPriceCr(),
+	})
+}
+
+func (sdb *SystemDatabase) newShip(gomItem *gom.Ship) error {
+	entity, err := NewDbEntity(int64(gomItem.Id), gomItem.Name)
+	if err != nil {
+		return err
+	}
+	return sdb.registerShip(&Ship{DbEntity: entity, PriceCr: gomItem.GetPriceCr()})
+}
+
+func (sdb *SystemDatabase) newOutfitting(gomItem *gom.FacilityOutfitting) error {
+	facility := sdb.GetFacilityByID(EntityID(gomItem.GetId()))
+	if facility == nil {
+		return fmt.Errorf("%w: facility for outfitting: %d", ErrUnknownEntity, gomItem.GetId())
+	}
+	facility.Outfitting = newInventory(gomItem.GetTimestampUtc(), gomItem.GetModuleIds())
+	return nil
+}
+
+func (sdb *SystemDatabase) newShipyard(gomItem *gom.FacilityShipyard) error {
+	facility := sdb.GetFacilityByID(EntityID(gomItem.GetId()))
+	if facility == nil {
+		return fmt.Errorf("%w: facility for shipyard: %d", ErrUnknownEntity, gomItem.GetId())
+	}
+	facility.Shipyard = newInventory(gomItem.GetTimestampUtc(), gomItem.GetShipIds())
+	return nil
+}
+
+// updateModule applies the fields of item that the source may change.
+func (sdb *SystemDatabase) updateModule(item *gom.Module, schema *Schema, from attribution) error {
+	provenance := from.record(item.TimestampUtc)
+	if module, exists := sdb.modulesByID[EntityID(item.Id)]; exists {
+		merged, record, err := sdb.resolveUpdate(item, Provenance{}, schema, from)
+		if err != nil {
+			return err
+		}
+		if merged == nil {
+			log.Printf("module %s (%d): stale or overridden update from %s", item.Name, item.Id, from.source)
+			return nil
+		}
+		item, provenance = merged.(*gom.Module), record
+		if !renameIDLookup(&module.DbEntity, item.Name, sdb.moduleIDs) {
+			return fmt.Errorf("module %s: %d: name collides with #%d", item.Name, item.Id, sdb.moduleIDs[strings.ToLower(item.Name)])
+		}
+		module.Category = item.GetCategory()
+		module.Group = item.GetGroup()
+		module.SizeClass = item.GetSizeClass()
+		module.Rating = item.GetRating()
+		module.PriceCr = item.GetPriceCr()
+	} else if err := sdb.newModule(item); err != nil {
+		return err
+	}
+	return writeMessageWithProvenance(item, provenance, schema)
+}
+
+// updateShip applies the fields of item that the source may change.
+func (sdb *SystemDatabase) updateShip(item *gom.Ship, schema *Schema, from attribution) error {
+	provenance := from.record(item.TimestampUtc)
+	if ship, exists := sdb.shipsByID[EntityID(item.Id)]; exists {
+		merged, record, err := sdb.resolveUpdate(item, Provenance{}, schema, from)
+		if err != nil {
+			return err
+		}
+		if merged == nil {
+			log.Printf("ship %s (%d): stale or overridden update from %s", item.Name, item.Id, from.source)
+			return nil
+		}
+		item, provenance = merged.(*gom.Ship), record
+		if !renameIDLookup(&ship.DbEntity, item.Name, sdb.shipIDs) {
+			return fmt.Errorf("ship %s: %d: name collides with #%d", item.Name, item.Id, sdb.shipIDs[strings.ToLower(item.Name)])
+		}
+		ship.PriceCr = item.GetPriceCr()
+	} else if err := sdb.newShip(item); err != nil {
+		return err
+	}
+	return writeMessageWithProvenance(item, provenance, schema)
+}
+
+// checkInventoryItems reports any ids in an inventory that aren't in the catalog.
+func checkInventoryItems(facility *Facility, kind string, ids []uint32, known func(EntityID) bool) {
+	for _, id := range ids {
+		if !known(EntityID(id)) {
+			FilterError(fmt.Errorf("%w: facility %s (%d): %s: %d", ErrUnknownEntity, facility.Name(), facility.GetId(), kind, id))
+		}
+	}
+}
+
+// resolveInventory decides whether the source may replace a facility's
+// inventory, current, with update; merged is nil if it may not.
+func (sdb *SystemDatabase) resolveInventory(update proto.Message, current *Inventory, schema *Schema, from attribution) (merged proto.Message, record provenanceRecord, err error) {
+	if current == nil {
+		return sdb.resolveUpdate(update, Provenance{}, schema, from)
+	}
+	return sdb.resolveUpdate(update, current, schema, from)
+}
+
+// updateFacilityOutfitting replaces a facility's outfitting, if the source may.
+func (sdb *SystemDatabase) updateFacilityOutfitting(item *gom.FacilityOutfitting, schema *Schema, from attribution) error {
+	facility := sdb.GetFacilityByID(EntityID(item.Id))
+	if facility == nil {
+		return fmt.Errorf("%w: facility for outfitting: %d", ErrUnknownEntity, item.Id)
+	}
+	merged, provenance, err := sdb.resolveInventory(item, facility.Outfitting, schema, from)
+	if err != nil {
+		return err
+	}
+	if merged == nil {
+		log.Printf("%s (%d): outfitting: stale or overridden update from %s", facility.Name(), item.Id, from.source)
+		return nil
+	}
+	item = merged.(*gom.FacilityOutfitting)
+	checkInventoryItems(facility, "module", item.ModuleIds, func(id EntityID) bool { return sdb.GetModuleByID(id) != nil })
+	facility.Outfitting = newInventory(item.TimestampUtc, item.ModuleIds)
+	return writeMessageWithProvenance(item, provenance, schema)
+}
+
+// updateFacilityShipyard replaces a facility's shipyard, if the source may.
+func (sdb *SystemDatabase) updateFacilityShipyard(item *gom.FacilityShipyard, schema *Schema, from attribution) error {
+	facility := sdb.GetFacilityByID(EntityID(item.Id))
+	if facility == nil {
+		return fmt.Errorf("%w: facility for shipyard: %d", ErrUnknownEntity, item.Id)
+	}
+	merged, provenance, err := sdb.resolveInventory(item, facility.Shipyard, schema, from)
+	if err != nil {
+		return err
+	}
+	if merged == nil {
+		log.Printf("%s (%d): shipyard: stale or overridden update from %s", facility.Name(), item.Id, from.source)
+		return nil
+	}
+	item = merged.(*gom.FacilityShipyard)
+	checkInventoryItems(facility, "ship", item.ShipIds, func(id EntityID) bool { return sdb.GetShipByID(id) != nil })
+	facility.Shipyard = newInventory(item.TimestampUtc, item.ShipIds)
+	return writeMessageWithProvenance(item, provenance, schema)
+}
+
+// FacilityNeighbor is a facility found by a proximity search and the distance^2 to its system.
+type FacilityNeighbor struct {
+	Facility *Facility
+	DistSq   SquareFloat
+}
+
+// NearestFacilities returns the facilities matching predicate in the count
+// systems closest to origin that have any, nearest first.
+func (sdb *SystemDatabase) NearestFacilities(origin *System, count int, predicate FacilityPredicate) []FacilityNeighbor {
+	systems := sdb.NearestSystems(origin, count, func(system *System) bool {
+		for _, facility := range system.facilities {
+			if predicate(facility) {
+				return true
+			}
+		}
+		return false
+	})
+	results := make([]FacilityNeighbor, 0, len(systems))
+	for _, neighbor := range systems {
+		for _, facility := range neighbor.System.facilities {
+			if predicate(facility) {
+				results = append(results, FacilityNeighbor{Facility: facility, DistSq: neighbor.DistSq})
+			}
+		}
+	}
+	return results
+}
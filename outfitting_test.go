@@ -0,0 +1,151 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	gom "github.com/kfsone/gomenacing/pkg/gomschema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInventory(t *testing.T) {
+	var missing *Inventory
+	assert.False(t, missing.Has(1))
+	assert.False(t, missing.HasAny([]EntityID{1, 2}))
+	assert.Nil(t, missing.IDs())
+
+	inventory := newInventory(100, []uint32{9, 3, 5})
+	assert.EqualValues(t, 100, inventory.GetTimestampUtc())
+	assert.True(t, inventory.Has(3))
+	assert.False(t, inventory.Has(4))
+	assert.True(t, inventory.HasAny([]EntityID{4, 5}))
+	assert.False(t, inventory.HasAny([]EntityID{4, 6}))
+	assert.Equal(t, []uint32{3, 5, 9}, inventory.IDs())
+}
+
+func Test_matchNames(t *testing.T) {
+	ids := map[string]EntityID{
+		"5a frame shift drive": 1,
+		"5b frame shift drive": 2,
+		"5a power plant":       3,
+	}
+	assert.Equal(t, []EntityID{1}, matchNames("5A Frame Shift Drive", ids))
+	assert.Equal(t, []EntityID{1, 2}, matchNames("frame shift", ids))
+	assert.Equal(t, []EntityID{1, 3}, matchNames(" 5a ", ids))
+	assert.Empty(t, matchNames("shield", ids))
+	assert.Empty(t, matchNames("", ids))
+}
+
+func TestSystemDatabase_Outfitting(t *testing.T) {
+	testDir := GetTestDir()
+	defer testDir.Close()
+	db, err := OpenDatabase(testDir.Path(), "outfitting.db")
+	require.Nil(t, err)
+	defer db.Close()
+	populateTestDatabase(t, db,
+		&gom.System{Id: 1, Name: "Sol", Position: &gom.Coordinate{}},
+		&gom.System{Id: 2, Name: "Alpha Centauri", Position: &gom.Coordinate{X: 4.4}},
+		&gom.System{Id: 3, Name: "Lave", Position: &gom.Coordinate{X: 100}},
+		&gom.Facility{Id: 1, SystemId: 1, Name: "Abraham Lincoln"},
+		&gom.Facility{Id: 2, SystemId: 2, Name: "Hutton Orbital"},
+		&gom.Facility{Id: 3, SystemId: 3, Name: "Lave Station"},
+		&gom.Module{Id: 1, Name: "5A Frame Shift Drive", Group: "Frame Shift Drive", SizeClass: 5, Rating: "A"},
+		&gom.Module{Id: 2, Name: "5D Frame Shift Drive", Group: "Frame Shift Drive", SizeClass: 5, Rating: "D"},
+		&gom.Ship{Id: 1, Name: "Python", PriceCr: 56978179},
+		&gom.Ship{Id: 2, Name: "Anaconda", PriceCr: 146969451},
+		&gom.FacilityOutfitting{Id: 2, TimestampUtc: 10, ModuleIds: []uint32{2}},
+		&gom.FacilityOutfitting{Id: 3, TimestampUtc: 10, ModuleIds: []uint32{1, 2}},
+		&gom.FacilityShipyard{Id: 3, TimestampUtc: 10, ShipIds: []uint32{1}},
+	)
+	sdb := NewSystemDatabase(db)
+	require.Nil(t, db.LoadDatabase(sdb))
+
+	assert.Equal(t, []EntityID{1}, sdb.FindModules("5a frame shift drive"))
+	assert.Equal(t, []EntityID{1, 2}, sdb.FindModules("frame shift"))
+	assert.Equal(t, []EntityID{2}, sdb.FindShips("anaconda"))
+	assert.Equal(t, "Python", sdb.GetShipByID(1).Name())
+	assert.Equal(t, "5D Frame Shift Drive", sdb.GetModuleByID(2).Name())
+
+	sol := sdb.GetSystem("Sol")
+	fsd := func(f *Facility) bool { return f.Outfitting.HasAny(sdb.FindModules("frame shift")) }
+	results := sdb.NearestFacilities(sol, 5, fsd)
+	require.Len(t, results, 2)
+	assert.Equal(t, "Hutton Orbital", results[0].Facility.DbName)
+	assert.Equal(t, "Lave Station", results[1].Facility.DbName)
+
+	results = sdb.NearestFacilities(sol, 1, fsd)
+	require.Len(t, results, 1)
+
+	// Stale updates are ignored, newer ones replace the inventory.
+	schema, err := db.Outfitting()
+	require.Nil(t, err)
+	require.Nil(t, sdb.updateFacilityOutfitting(&gom.FacilityOutfitting{Id: 2, TimestampUtc: 5, ModuleIds: []uint32{1}}, schema, attribution{source: "test"}))
+	assert.Equal(t, []uint32{2}, sdb.GetFacilityByID(2).Outfitting.IDs())
+	require.Nil(t, sdb.updateFacilityOutfitting(&gom.FacilityOutfitting{Id: 2, TimestampUtc: 20, ModuleIds: []uint32{1}}, schema, attribution{source: "test"}))
+	assert.Equal(t, []uint32{1}, sdb.GetFacilityByID(2).Outfitting.IDs())
+
+	err = sdb.updateFacilityOutfitting(&gom.FacilityOutfitting{Id: 99}, schema, attribution{source: "test"})
+	assert.Error(t, err)
+	require.Nil(t, schema.Close())
+
+	// Renames are matched by id; names still can't be shared.
+	schema, err = db.Modules()
+	require.Nil(t, err)
+	require.Nil(t, sdb.updateModule(&gom.Module{Id: 2, Name: "5D Frame Shift Drive (SCO)", Group: "Frame Shift Drive", SizeClass: 5, Rating: "D"}, schema, attribution{source: "test"}))
+	assert.Equal(t, []EntityID{2}, sdb.FindModules("(sco)"))
+	assert.NotContains(t, sdb.moduleIDs, "5d frame shift drive")
+	assert.Error(t, sdb.updateModule(&gom.Module{Id: 2, Name: "5A Frame Shift Drive"}, schema, attribution{source: "test"}))
+	assert.Equal(t, "5D Frame Shift Drive (SCO)", sdb.GetModuleByID(2).Name())
+	require.Nil(t, schema.Close())
+	schema, err = db.Ships()
+	require.Nil(t, err)
+	require.Nil(t, sdb.updateShip(&gom.Ship{Id: 1, Name: "Python Mk I", PriceCr: 56978179}, schema, attribution{source: "test"}))
+	assert.Equal(t, []EntityID{1}, sdb.FindShips("python mk i"))
+	assert.Error(t, sdb.updateShip(&gom.Ship{Id: 1, Name: "Anaconda"}, schema, attribution{source: "test"}))
+	require.Nil(t, schema.Close())
+
+	// Everything should survive being reloaded from the database.
+	reloaded := NewSystemDatabase(db)
+	require.Nil(t, db.LoadDatabase(reloaded))
+	assert.Len(t, reloaded.modulesByID, 2)
+	assert.Len(t, reloaded.shipsByID, 2)
+	assert.Equal(t, "5D Frame Shift Drive (SCO)", reloaded.GetModuleByID(2).Name())
+	assert.Equal(t, "Python Mk I", reloaded.GetShipByID(1).Name())
+	assert.Equal(t, []uint32{1, 2}, reloaded.GetFacilityByID(3).Outfitting.IDs())
+	assert.Equal(t, []uint32{1}, reloaded.GetFacilityByID(3).Shipyard.IDs())
+	assert.Nil(t, reloaded.GetFacilityByID(1).Shipyard)
+}
+
+func Test_parseFindNear(t *testing.T) {
+	item, system, filters, ok := parseFindNear(strings.Fields("5A Frame Shift Drive near Alpha Centauri pad=large"))
+	assert.True(t, ok)
+	assert.Equal(t, "5A Frame Shift Drive", item)
+	assert.Equal(t, "Alpha Centauri", system)
+	assert.Equal(t, []string{"pad=large"}, filters)
+
+	for _, invalid := range []string{"python", "python near", "near sol", ""} {
+		_, _, _, ok = parseFindNear(strings.Fields(invalid))
+		assert.False(t, ok, invalid)
+	}
+}
+
+func TestRepl_ShipFind(t *testing.T) {
+	sdb := NewSystemDatabase(nil)
+	require.Nil(t, sdb.newSystem(&gom.System{Id: 1, Name: "Sol", Position: &gom.Coordinate{}}))
+	require.Nil(t, sdb.newSystem(&gom.System{Id: 3, Name: "Lave", Position: &gom.Coordinate{X: 100}}))
+	require.Nil(t, sdb.newFacility(&gom.Facility{Id: 3, SystemId: 3, Name: "Lave Station"}))
+	require.Nil(t, sdb.newShip(&gom.Ship{Id: 1, Name: "Python", PriceCr: 56978179}))
+	require.Nil(t, sdb.newShip(&gom.Ship{Id: 2, Name: "Anaconda", PriceCr: 146969451}))
+	require.Nil(t, sdb.newShipyard(&gom.FacilityShipyard{Id: 3, TimestampUtc: 10, ShipIds: []uint32{1}}))
+
+	var output bytes.Buffer
+	repl := &Repl{sdb: sdb, out: &output}
+	cmdShipFind(repl, strings.Fields("python near sol"), nil)
+	assert.Contains(t, output.String(), "Lave/Lave Station")
+
+	output.Reset()
+	cmdShipFind(repl, strings.Fields("anaconda near sol"), nil)
+	assert.Contains(t, output.String(), "Nowhere is known to sell anaconda")
+}
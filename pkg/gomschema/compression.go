@@ -0,0 +1,123 @@
+package gomschema
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Compression identifies how a GOM stream, or the payload of messages within
+// it, is compressed.
+type Compression int
+
+const (
+	CompressionNone Compression = iota
+	CompressionGzip
+	CompressionZstd
+)
+
+// PayloadCompressionKey is the header userdata key naming the compression of
+// the message payload, when the writer compressed it.
+const PayloadCompressionKey = "payload-compression"
+
+var compressionNames = map[Compression]string{
+	CompressionNone: "none",
+	CompressionGzip: "gzip",
+	CompressionZstd: "zstd",
+}
+
+// compressionMagic are the bytes that begin a stream in each compression.
+var compressionMagic = map[Compression][]byte{
+	CompressionGzip: {0x1f, 0x8b},
+	CompressionZstd: {0x28, 0xb5, 0x2f, 0xfd},
+}
+
+func (c Compression) String() string {
+	if name, exists := compressionNames[c]; exists {
+		return name
+	}
+	return fmt.Sprintf("compression(%d)", int(c))
+}
+
+// ParseCompression returns the compression with the given name.
+func ParseCompression(name string) (Compression, error) {
+	for compression, candidate := range compressionNames {
+		if strings.EqualFold(name, candidate) {
+			return compression, nil
+		}
+	}
+	return CompressionNone, fmt.Errorf("unsupported compression: %s", name)
+}
+
+// CompressionForFilename returns the compression suggested by a filename's
+// extension, e.g. CompressionGzip for "listings.gom.gz".
+func CompressionForFilename(filename string) Compression {
+	switch {
+	case strings.HasSuffix(filename, ".gz"):
+		return CompressionGzip
+	case strings.HasSuffix(filename, ".zst"):
+		return CompressionZstd
+	default:
+		return CompressionNone
+	}
+}
+
+// detectCompression identifies the compression of source by its magic
+// bytes, without consuming them.
+func detectCompression(source *bufio.Reader) Compression {
+	for compression, magic := range compressionMagic {
+		if peeked, err := source.Peek(len(magic)); err == nil && bytes.Equal(peeked, magic) {
+			return compression
+		}
+	}
+	return CompressionNone
+}
+
+// NewDecompressor returns a reader of the uncompressed data from source.
+// Closing it releases the decompressor but not source.
+func NewDecompressor(source io.Reader, compression Compression) (io.ReadCloser, error) {
+	switch compression {
+	case CompressionNone:
+		return ioutil.NopCloser(source), nil
+	case CompressionGzip:
+		return gzip.NewReader(source)
+	case CompressionZstd:
+		decoder, err := zstd.NewReader(source)
+		if err != nil {
+			return nil, err
+		}
+		return decoder.IOReadCloser(), nil
+	default:
+		return nil, fmt.Errorf("unsupported compression: %s", compression)
+	}
+}
+
+// nopWriteCloser lets an io.Writer stand in for an io.WriteCloser.
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
+
+// NewCompressor returns a writer that compresses what is written to it into
+// dest. Closing it flushes the compressed stream but does not close dest.
+func NewCompressor(dest io.Writer, compression Compression) (io.WriteCloser, error) {
+	switch compression {
+	case CompressionNone:
+		return nopWriteCloser{dest}, nil
+	case CompressionGzip:
+		return gzip.NewWriter(dest), nil
+	case CompressionZstd:
+		return zstd.NewWriter(dest)
+	default:
+		return nil, fmt.Errorf("unsupported compression: %s", compression)
+	}
+}
@@ -220,6 +220,220 @@ func (SecurityLevel) EnumDescriptor() ([]byte, []int) {
 	return file_gomschema_proto_rawDescGZIP(), []int{2}
 }
 
+/// EconomyType enumerates the economies of systems and facilities.
+type EconomyType int32
+
+const (
+	EconomyType_EcoNone         EconomyType = 0
+	EconomyType_EcoAgriculture  EconomyType = 1
+	EconomyType_EcoColony       EconomyType = 2
+	EconomyType_EcoExtraction   EconomyType = 3
+	EconomyType_EcoHighTech     EconomyType = 4
+	EconomyType_EcoIndustrial   EconomyType = 5
+	EconomyType_EcoMilitary     EconomyType = 6
+	EconomyType_EcoRefinery     EconomyType = 7
+	EconomyType_EcoService      EconomyType = 8
+	EconomyType_EcoTerraforming EconomyType = 9
+	EconomyType_EcoTourism      EconomyType = 10
+	EconomyType_EcoPrison       EconomyType = 11
+	EconomyType_EcoDamaged      EconomyType = 12
+	EconomyType_EcoRescue       EconomyType = 13
+	EconomyType_EcoRepair       EconomyType = 14
+	EconomyType_EcoCarrier      EconomyType = 15
+	EconomyType_EcoEngineering  EconomyType = 16
+)
+
+// Enum value maps for EconomyType.
+var (
+	EconomyType_name = map[int32]string{
+		0:  "EcoNone",
+		1:  "EcoAgriculture",
+		2:  "EcoColony",
+		3:  "EcoExtraction",
+		4:  "EcoHighTech",
+		5:  "EcoIndustrial",
+		6:  "EcoMilitary",
+		7:  "EcoRefinery",
+		8:  "EcoService",
+		9:  "EcoTerraforming",
+		10: "EcoTourism",
+		11: "EcoPrison",
+		12: "EcoDamaged",
+		13: "EcoRescue",
+		14: "EcoRepair",
+		15: "EcoCarrier",
+		16: "EcoEngineering",
+	}
+	EconomyType_value = map[string]int32{
+		"EcoNone":         0,
+		"EcoAgriculture":  1,
+		"EcoColony":       2,
+		"EcoExtraction":   3,
+		"EcoHighTech":     4,
+		"EcoIndustrial":   5,
+		"EcoMilitary":     6,
+		"EcoRefinery":     7,
+		"EcoService":      8,
+		"EcoTerraforming": 9,
+		"EcoTourism":      10,
+		"EcoPrison":       11,
+		"EcoDamaged":      12,
+		"EcoRescue":       13,
+		"EcoRepair":       14,
+		"EcoCarrier":      15,
+		"EcoEngineering":  16,
+	}
+)
+
+func (x EconomyType) Enum() *EconomyType {
+	p := new(EconomyType)
+	*p = x
+	return p
+}
+
+func (x EconomyType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (EconomyType) Descriptor() protoreflect.EnumDescriptor {
+	return file_gomschema_proto_enumTypes[3].Descriptor()
+}
+
+func (EconomyType) Type() protoreflect.EnumType {
+	return &file_gomschema_proto_enumTypes[3]
+}
+
+func (x EconomyType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use EconomyType.Descriptor instead.
+func (EconomyType) EnumDescriptor() ([]byte, []int) {
+	return file_gomschema_proto_rawDescGZIP(), []int{3}
+}
+
+/// StateType enumerates the states a system or faction can be in, which influence prices.
+type StateType int32
+
+const (
+	StateType_StateNone                  StateType = 0
+	StateType_StateBoom                  StateType = 1
+	StateType_StateBust                  StateType = 2
+	StateType_StateCivilUnrest           StateType = 3
+	StateType_StateCivilWar              StateType = 4
+	StateType_StateElection              StateType = 5
+	StateType_StateExpansion             StateType = 6
+	StateType_StateFamine                StateType = 7
+	StateType_StateInvestment            StateType = 8
+	StateType_StateLockdown              StateType = 9
+	StateType_StateOutbreak              StateType = 10
+	StateType_StateRetreat               StateType = 11
+	StateType_StateWar                   StateType = 12
+	StateType_StateCivilLiberty          StateType = 13
+	StateType_StatePirateAttack          StateType = 14
+	StateType_StateBlight                StateType = 15
+	StateType_StateDrought               StateType = 16
+	StateType_StateInfrastructureFailure StateType = 17
+	StateType_StateNaturalDisaster       StateType = 18
+	StateType_StatePublicHoliday         StateType = 19
+	StateType_StateTerrorism             StateType = 20
+	StateType_StateColdWar               StateType = 21
+	StateType_StateColonisation          StateType = 22
+	StateType_StateHistoricEvent         StateType = 23
+	StateType_StateRevolution            StateType = 24
+	StateType_StateTechnologicalLeap     StateType = 25
+	StateType_StateTradeWar              StateType = 26
+)
+
+// Enum value maps for StateType.
+var (
+	StateType_name = map[int32]string{
+		0:  "StateNone",
+		1:  "StateBoom",
+		2:  "StateBust",
+		3:  "StateCivilUnrest",
+		4:  "StateCivilWar",
+		5:  "StateElection",
+		6:  "StateExpansion",
+		7:  "StateFamine",
+		8:  "StateInvestment",
+		9:  "StateLockdown",
+		10: "StateOutbreak",
+		11: "StateRetreat",
+		12: "StateWar",
+		13: "StateCivilLiberty",
+		14: "StatePirateAttack",
+		15: "StateBlight",
+		16: "StateDrought",
+		17: "StateInfrastructureFailure",
+		18: "StateNaturalDisaster",
+		19: "StatePublicHoliday",
+		20: "StateTerrorism",
+		21: "StateColdWar",
+		22: "StateColonisation",
+		23: "StateHistoricEvent",
+		24: "StateRevolution",
+		25: "StateTechnologicalLeap",
+		26: "StateTradeWar",
+	}
+	StateType_value = map[string]int32{
+		"StateNone":                  0,
+		"StateBoom":                  1,
+		"StateBust":                  2,
+		"StateCivilUnrest":           3,
+		"StateCivilWar":              4,
+		"StateElection":              5,
+		"StateExpansion":             6,
+		"StateFamine":                7,
+		"StateInvestment":            8,
+		"StateLockdown":              9,
+		"StateOutbreak":              10,
+		"StateRetreat":               11,
+		"StateWar":                   12,
+		"StateCivilLiberty":          13,
+		"StatePirateAttack":          14,
+		"StateBlight":                15,
+		"StateDrought":               16,
+		"StateInfrastructureFailure": 17,
+		"StateNaturalDisaster":       18,
+		"StatePublicHoliday":         19,
+		"StateTerrorism":             20,
+		"StateColdWar":               21,
+		"StateColonisation":          22,
+		"StateHistoricEvent":         23,
+		"StateRevolution":            24,
+		"StateTechnologicalLeap":     25,
+		"StateTradeWar":              26,
+	}
+)
+
+func (x StateType) Enum() *StateType {
+	p := new(StateType)
+	*p = x
+	return p
+}
+
+func (x StateType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (StateType) Descriptor() protoreflect.EnumDescriptor {
+	return file_gomschema_proto_enumTypes[4].Descriptor()
+}
+
+func (StateType) Type() protoreflect.EnumType {
+	return &file_gomschema_proto_enumTypes[4]
+}
+
+func (x StateType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use StateType.Descriptor instead.
+func (StateType) EnumDescriptor() ([]byte, []int) {
+	return file_gomschema_proto_rawDescGZIP(), []int{4}
+}
+
 /// Enumeration of facility kinds.
 type FacilityType int32
 
@@ -293,11 +507,11 @@ func (x FacilityType) String() string {
 }
 
 func (FacilityType) Descriptor() protoreflect.EnumDescriptor {
-	return file_gomschema_proto_enumTypes[3].Descriptor()
+	return file_gomschema_proto_enumTypes[5].Descriptor()
 }
 
 func (FacilityType) Type() protoreflect.EnumType {
-	return &file_gomschema_proto_enumTypes[3]
+	return &file_gomschema_proto_enumTypes[5]
 }
 
 func (x FacilityType) Number() protoreflect.EnumNumber {
@@ -306,7 +520,7 @@ func (x FacilityType) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use FacilityType.Descriptor instead.
 func (FacilityType) EnumDescriptor() ([]byte, []int) {
-	return file_gomschema_proto_rawDescGZIP(), []int{3}
+	return file_gomschema_proto_rawDescGZIP(), []int{5}
 }
 
 /// FeatureBit denotes which bits of the Features mask represent which capacity.
@@ -376,11 +590,11 @@ func (x FeatureBit) String() string {
 }
 
 func (FeatureBit) Descriptor() protoreflect.EnumDescriptor {
-	return file_gomschema_proto_enumTypes[4].Descriptor()
+	return file_gomschema_proto_enumTypes[6].Descriptor()
 }
 
 func (FeatureBit) Type() protoreflect.EnumType {
-	return &file_gomschema_proto_enumTypes[4]
+	return &file_gomschema_proto_enumTypes[6]
 }
 
 func (x FeatureBit) Number() protoreflect.EnumNumber {
@@ -389,18 +603,79 @@ func (x FeatureBit) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use FeatureBit.Descriptor instead.
 func (FeatureBit) EnumDescriptor() ([]byte, []int) {
-	return file_gomschema_proto_rawDescGZIP(), []int{4}
+	return file_gomschema_proto_rawDescGZIP(), []int{6}
+}
+
+/// MarketBracket is the game's indication of how strongly a facility is supplying
+/// or demanding a commodity, which determines how quickly the price will move.
+type MarketBracket int32
+
+const (
+	MarketBracket_BracketUnknown MarketBracket = 0
+	MarketBracket_BracketNone    MarketBracket = 1
+	MarketBracket_BracketLow     MarketBracket = 2
+	MarketBracket_BracketMedium  MarketBracket = 3
+	MarketBracket_BracketHigh    MarketBracket = 4
+)
+
+// Enum value maps for MarketBracket.
+var (
+	MarketBracket_name = map[int32]string{
+		0: "BracketUnknown",
+		1: "BracketNone",
+		2: "BracketLow",
+		3: "BracketMedium",
+		4: "BracketHigh",
+	}
+	MarketBracket_value = map[string]int32{
+		"BracketUnknown": 0,
+		"BracketNone":    1,
+		"BracketLow":     2,
+		"BracketMedium":  3,
+		"BracketHigh":    4,
+	}
+)
+
+func (x MarketBracket) Enum() *MarketBracket {
+	p := new(MarketBracket)
+	*p = x
+	return p
+}
+
+func (x MarketBracket) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (MarketBracket) Descriptor() protoreflect.EnumDescriptor {
+	return file_gomschema_proto_enumTypes[7].Descriptor()
+}
+
+func (MarketBracket) Type() protoreflect.EnumType {
+	return &file_gomschema_proto_enumTypes[7]
+}
+
+func (x MarketBracket) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use MarketBracket.Descriptor instead.
+func (MarketBracket) EnumDescriptor() ([]byte, []int) {
+	return file_gomschema_proto_rawDescGZIP(), []int{7}
 }
 
 type Header_Type int32
 
 const (
-	Header_CInvalid   Header_Type = 0
-	Header_CHeader    Header_Type = 1
-	Header_CCommodity Header_Type = 2
-	Header_CSystem    Header_Type = 3
-	Header_CFacility  Header_Type = 4
-	Header_CListing   Header_Type = 5
+	Header_CInvalid    Header_Type = 0
+	Header_CHeader     Header_Type = 1
+	Header_CCommodity  Header_Type = 2
+	Header_CSystem     Header_Type = 3
+	Header_CFacility   Header_Type = 4
+	Header_CListing    Header_Type = 5
+	Header_CModule     Header_Type = 6
+	Header_CShip       Header_Type = 7
+	Header_COutfitting Header_Type = 8
+	Header_CShipyard   Header_Type = 9
 )
 
 // Enum value maps for Header_Type.
@@ -412,14 +687,22 @@ var (
 		3: "CSystem",
 		4: "CFacility",
 		5: "CListing",
+		6: "CModule",
+		7: "CShip",
+		8: "COutfitting",
+		9: "CShipyard",
 	}
 	Header_Type_value = map[string]int32{
-		"CInvalid":   0,
-		"CHeader":    1,
-		"CCommodity": 2,
-		"CSystem":    3,
-		"CFacility":  4,
-		"CListing":   5,
+		"CInvalid":    0,
+		"CHeader":     1,
+		"CCommodity":  2,
+		"CSystem":     3,
+		"CFacility":   4,
+		"CListing":    5,
+		"CModule":     6,
+		"CShip":       7,
+		"COutfitting": 8,
+		"CShipyard":   9,
 	}
 )
 
@@ -434,11 +717,11 @@ func (x Header_Type) String() string {
 }
 
 func (Header_Type) Descriptor() protoreflect.EnumDescriptor {
-	return file_gomschema_proto_enumTypes[5].Descriptor()
+	return file_gomschema_proto_enumTypes[8].Descriptor()
 }
 
 func (Header_Type) Type() protoreflect.EnumType {
-	return &file_gomschema_proto_enumTypes[5]
+	return &file_gomschema_proto_enumTypes[8]
 }
 
 func (x Header_Type) Number() protoreflect.EnumNumber {
@@ -525,11 +808,11 @@ func (x Commodity_Category) String() string {
 }
 
 func (Commodity_Category) Descriptor() protoreflect.EnumDescriptor {
-	return file_gomschema_proto_enumTypes[6].Descriptor()
+	return file_gomschema_proto_enumTypes[9].Descriptor()
 }
 
 func (Commodity_Category) Type() protoreflect.EnumType {
-	return &file_gomschema_proto_enumTypes[6]
+	return &file_gomschema_proto_enumTypes[9]
 }
 
 func (x Commodity_Category) Number() protoreflect.EnumNumber {
@@ -788,6 +1071,73 @@ func (x *Coordinate) GetZ() float64 {
 	return 0
 }
 
+/// FactionPresence describes a minor faction's standing within a system.
+type FactionPresence struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	/// Source identifier of the minor faction.
+	FactionId uint32 `protobuf:"varint,1,opt,name=faction_id,json=factionId,proto3" json:"faction_id,omitempty"`
+	/// Percentage of influence the faction holds in the system.
+	Influence float32 `protobuf:"fixed32,2,opt,name=influence,proto3" json:"influence,omitempty"`
+	/// States the faction is currently in, within this system.
+	States []StateType `protobuf:"varint,3,rep,packed,name=states,proto3,enum=gomschema.StateType" json:"states,omitempty"`
+}
+
+func (x *FactionPresence) Reset() {
+	*x = FactionPresence{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_gomschema_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *FactionPresence) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FactionPresence) ProtoMessage() {}
+
+func (x *FactionPresence) ProtoReflect() protoreflect.Message {
+	mi := &file_gomschema_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FactionPresence.ProtoReflect.Descriptor instead.
+func (*FactionPresence) Descriptor() ([]byte, []int) {
+	return file_gomschema_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *FactionPresence) GetFactionId() uint32 {
+	if x != nil {
+		return x.FactionId
+	}
+	return 0
+}
+
+func (x *FactionPresence) GetInfluence() float32 {
+	if x != nil {
+		return x.Influence
+	}
+	return 0
+}
+
+func (x *FactionPresence) GetStates() []StateType {
+	if x != nil {
+		return x.States
+	}
+	return nil
+}
+
 /// System corresponds to an individual Elite-Dangerous star system, akin to a map.
 type System struct {
 	state         protoimpl.MessageState
@@ -812,12 +1162,24 @@ type System struct {
 	Government GovernmentType `protobuf:"varint,8,opt,name=government,proto3,enum=gomschema.GovernmentType" json:"government,omitempty"`
 	/// Which faction is the system allied to.
 	Allegiance AllegianceType `protobuf:"varint,9,opt,name=allegiance,proto3,enum=gomschema.AllegianceType" json:"allegiance,omitempty"`
+	/// Number of inhabitants.
+	Population uint64 `protobuf:"varint,10,opt,name=population,proto3" json:"population,omitempty"`
+	/// The dominant economy of the system.
+	PrimaryEconomy EconomyType `protobuf:"varint,11,opt,name=primary_economy,json=primaryEconomy,proto3,enum=gomschema.EconomyType" json:"primary_economy,omitempty"`
+	/// States the system is currently in (Boom, Outbreak, Famine, etc).
+	States []StateType `protobuf:"varint,12,rep,packed,name=states,proto3,enum=gomschema.StateType" json:"states,omitempty"`
+	/// Source identifier of the minor faction in control of the system.
+	ControllingFactionId uint32 `protobuf:"varint,13,opt,name=controlling_faction_id,json=controllingFactionId,proto3" json:"controlling_faction_id,omitempty"`
+	/// Name of the minor faction in control of the system.
+	ControllingFaction string `protobuf:"bytes,14,opt,name=controlling_faction,json=controllingFaction,proto3" json:"controlling_faction,omitempty"`
+	/// Minor factions present in the system.
+	Factions []*FactionPresence `protobuf:"bytes,15,rep,name=factions,proto3" json:"factions,omitempty"`
 }
 
 func (x *System) Reset() {
 	*x = System{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_gomschema_proto_msgTypes[3]
+		mi := &file_gomschema_proto_msgTypes[4]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -830,7 +1192,7 @@ func (x *System) String() string {
 func (*System) ProtoMessage() {}
 
 func (x *System) ProtoReflect() protoreflect.Message {
-	mi := &file_gomschema_proto_msgTypes[3]
+	mi := &file_gomschema_proto_msgTypes[4]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -843,7 +1205,7 @@ func (x *System) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use System.ProtoReflect.Descriptor instead.
 func (*System) Descriptor() ([]byte, []int) {
-	return file_gomschema_proto_rawDescGZIP(), []int{3}
+	return file_gomschema_proto_rawDescGZIP(), []int{4}
 }
 
 func (x *System) GetId() uint32 {
@@ -909,6 +1271,48 @@ func (x *System) GetAllegiance() AllegianceType {
 	return AllegianceType_AllegNone
 }
 
+func (x *System) GetPopulation() uint64 {
+	if x != nil {
+		return x.Population
+	}
+	return 0
+}
+
+func (x *System) GetPrimaryEconomy() EconomyType {
+	if x != nil {
+		return x.PrimaryEconomy
+	}
+	return EconomyType_EcoNone
+}
+
+func (x *System) GetStates() []StateType {
+	if x != nil {
+		return x.States
+	}
+	return nil
+}
+
+func (x *System) GetControllingFactionId() uint32 {
+	if x != nil {
+		return x.ControllingFactionId
+	}
+	return 0
+}
+
+func (x *System) GetControllingFaction() string {
+	if x != nil {
+		return x.ControllingFaction
+	}
+	return ""
+}
+
+func (x *System) GetFactions() []*FactionPresence {
+	if x != nil {
+		return x.Factions
+	}
+	return nil
+}
+
 /// Facility describes a station/planetary base, anything you can dock/trade with in-game.
 type Facility struct {
 	state         protoimpl.MessageState
@@ -933,12 +1337,20 @@ type Facility struct {
 	Government GovernmentType `protobuf:"varint,8,opt,name=government,proto3,enum=gomschema.GovernmentType" json:"government,omitempty"`
 	/// Type of allegiance this station holds.
 	Allegiance AllegianceType `protobuf:"varint,9,opt,name=allegiance,proto3,enum=gomschema.AllegianceType" json:"allegiance,omitempty"`
+	/// Frontier's market id, as used by journal and EDDN data to identify the facility.
+	MarketId uint64 `protobuf:"varint,10,opt,name=market_id,json=marketId,proto3" json:"market_id,omitempty"`
+	/// Economies of the facility, primary economy first.
+	Economies []EconomyType `protobuf:"varint,11,rep,packed,name=economies,proto3,enum=gomschema.EconomyType" json:"economies,omitempty"`
+	/// Name of the body the facility is on or orbiting, if known.
+	Body string `protobuf:"bytes,12,opt,name=body,proto3" json:"body,omitempty"`
+	/// If reaching the facility requires a planetary landing.
+	PlanetaryLanding bool `protobuf:"varint,13,opt,name=planetary_landing,json=planetaryLanding,proto3" json:"planetary_landing,omitempty"`
 }
 
 func (x *Facility) Reset() {
 	*x = Facility{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_gomschema_proto_msgTypes[4]
+		mi := &file_gomschema_proto_msgTypes[5]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -951,7 +1363,7 @@ func (x *Facility) String() string {
 func (*Facility) ProtoMessage() {}
 
 func (x *Facility) ProtoReflect() protoreflect.Message {
-	mi := &file_gomschema_proto_msgTypes[4]
+	mi := &file_gomschema_proto_msgTypes[5]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -964,7 +1376,7 @@ func (x *Facility) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Facility.ProtoReflect.Descriptor instead.
 func (*Facility) Descriptor() ([]byte, []int) {
-	return file_gomschema_proto_rawDescGZIP(), []int{4}
+	return file_gomschema_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *Facility) GetId() uint32 {
@@ -1030,6 +1442,34 @@ func (x *Facility) GetAllegiance() AllegianceType {
 	return AllegianceType_AllegNone
 }
 
+func (x *Facility) GetMarketId() uint64 {
+	if x != nil {
+		return x.MarketId
+	}
+	return 0
+}
+
+func (x *Facility) GetEconomies() []EconomyType {
+	if x != nil {
+		return x.Economies
+	}
+	return nil
+}
+
+func (x *Facility) GetBody() string {
+	if x != nil {
+		return x.Body
+	}
+	return ""
+}
+
+func (x *Facility) GetPlanetaryLanding() bool {
+	if x != nil {
+		return x.PlanetaryLanding
+	}
+	return false
+}
+
 /// The supply/demand levels and cost for an individual commodity at a facility.
 type CommodityListing struct {
 	state         protoimpl.MessageState
@@ -1048,12 +1488,16 @@ type CommodityListing struct {
 	DemandCredits uint32 `protobuf:"varint,5,opt,name=demand_credits,json=demandCredits,proto3" json:"demand_credits,omitempty"`
 	/// Unix timestamp of when this was collected.
 	TimestampUtc uint64 `protobuf:"varint,6,opt,name=timestamp_utc,json=timestampUtc,proto3" json:"timestamp_utc,omitempty"`
+	/// How strongly the facility is supplying the commodity.
+	SupplyBracket MarketBracket `protobuf:"varint,7,opt,name=supply_bracket,json=supplyBracket,proto3,enum=gomschema.MarketBracket" json:"supply_bracket,omitempty"`
+	/// How strongly the facility is seeking the commodity.
+	DemandBracket MarketBracket `protobuf:"varint,8,opt,name=demand_bracket,json=demandBracket,proto3,enum=gomschema.MarketBracket" json:"demand_bracket,omitempty"`
 }
 
 func (x *CommodityListing) Reset() {
 	*x = CommodityListing{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_gomschema_proto_msgTypes[5]
+		mi := &file_gomschema_proto_msgTypes[6]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1066,7 +1510,7 @@ func (x *CommodityListing) String() string {
 func (*CommodityListing) ProtoMessage() {}
 
 func (x *CommodityListing) ProtoReflect() protoreflect.Message {
-	mi := &file_gomschema_proto_msgTypes[5]
+	mi := &file_gomschema_proto_msgTypes[6]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1079,7 +1523,7 @@ func (x *CommodityListing) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CommodityListing.ProtoReflect.Descriptor instead.
 func (*CommodityListing) Descriptor() ([]byte, []int) {
-	return file_gomschema_proto_rawDescGZIP(), []int{5}
+	return file_gomschema_proto_rawDescGZIP(), []int{6}
 }
 
 func (x *CommodityListing) GetCommodityId() uint32 {
@@ -1124,6 +1568,20 @@ func (x *CommodityListing) GetTimestampUtc() uint64 {
 	return 0
 }
 
+func (x *CommodityListing) GetSupplyBracket() MarketBracket {
+	if x != nil {
+		return x.SupplyBracket
+	}
+	return MarketBracket_BracketUnknown
+}
+
+func (x *CommodityListing) GetDemandBracket() MarketBracket {
+	if x != nil {
+		return x.DemandBracket
+	}
+	return MarketBracket_BracketUnknown
+}
+
 /// All of the available supply and demand for a designated facility.
 type FacilityListing struct {
 	state         protoimpl.MessageState
@@ -1139,7 +1597,7 @@ type FacilityListing struct {
 func (x *FacilityListing) Reset() {
 	*x = FacilityListing{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_gomschema_proto_msgTypes[6]
+		mi := &file_gomschema_proto_msgTypes[7]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1152,7 +1610,7 @@ func (x *FacilityListing) String() string {
 func (*FacilityListing) ProtoMessage() {}
 
 func (x *FacilityListing) ProtoReflect() protoreflect.Message {
-	mi := &file_gomschema_proto_msgTypes[6]
+	mi := &file_gomschema_proto_msgTypes[7]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1165,7 +1623,7 @@ func (x *FacilityListing) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use FacilityListing.ProtoReflect.Descriptor instead.
 func (*FacilityListing) Descriptor() ([]byte, []int) {
-	return file_gomschema_proto_rawDescGZIP(), []int{6}
+	return file_gomschema_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *FacilityListing) GetId() uint32 {
@@ -1182,11 +1640,333 @@ func (x *FacilityListing) GetListings() []*CommodityListing {
 	return nil
 }
 
+/// Module is a type of ship module that can be bought through outfitting.
+type Module struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	/// Locally sourced identifier across this import.
+	Id uint32 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	/// Unique name of the module, e.g. "5A Frame Shift Drive".
+	Name string `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	/// Timestamp of when this was captured.
+	TimestampUtc uint64 `protobuf:"varint,3,opt,name=timestamp_utc,json=timestampUtc,proto3" json:"timestamp_utc,omitempty"`
+	/// Kind of slot the module fits, e.g. "hardpoint", "internal".
+	Category string `protobuf:"bytes,4,opt,name=category,proto3" json:"category,omitempty"`
+	/// Family of module, e.g. "Frame Shift Drive".
+	Group string `protobuf:"bytes,5,opt,name=group,proto3" json:"group,omitempty"`
+	/// Size class of the module.
+	SizeClass uint32 `protobuf:"varint,6,opt,name=size_class,json=sizeClass,proto3" json:"size_class,omitempty"`
+	/// Rating letter of the module (A-I).
+	Rating string `protobuf:"bytes,7,opt,name=rating,proto3" json:"rating,omitempty"`
+	/// Base price in credits.
+	PriceCr uint32 `protobuf:"varint,8,opt,name=price_cr,json=priceCr,proto3" json:"price_cr,omitempty"`
+}
+
+func (x *Module) Reset() {
+	*x = Module{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_gomschema_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Module) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Module) ProtoMessage() {}
+
+func (x *Module) ProtoReflect() protoreflect.Message {
+	mi := &file_gomschema_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Module.ProtoReflect.Descriptor instead.
+func (*Module) Descriptor() ([]byte, []int) {
+	return file_gomschema_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *Module) GetId() uint32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Module) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Module) GetTimestampUtc() uint64 {
+	if x != nil {
+		return x.TimestampUtc
+	}
+	return 0
+}
+
+func (x *Module) GetCategory() string {
+	if x != nil {
+		return x.Category
+	}
+	return ""
+}
+
+func (x *Module) GetGroup() string {
+	if x != nil {
+		return x.Group
+	}
+	return ""
+}
+
+func (x *Module) GetSizeClass() uint32 {
+	if x != nil {
+		return x.SizeClass
+	}
+	return 0
+}
+
+func (x *Module) GetRating() string {
+	if x != nil {
+		return x.Rating
+	}
+	return ""
+}
+
+func (x *Module) GetPriceCr() uint32 {
+	if x != nil {
+		return x.PriceCr
+	}
+	return 0
+}
+
+/// Ship is a type of ship that can be bought at a shipyard.
+type Ship struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	/// Locally sourced identifier across this import.
+	Id uint32 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	/// Unique name of the ship.
+	Name string `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	/// Timestamp of when this was captured.
+	TimestampUtc uint64 `protobuf:"varint,3,opt,name=timestamp_utc,json=timestampUtc,proto3" json:"timestamp_utc,omitempty"`
+	/// Base price in credits.
+	PriceCr uint32 `protobuf:"varint,4,opt,name=price_cr,json=priceCr,proto3" json:"price_cr,omitempty"`
+}
+
+func (x *Ship) Reset() {
+	*x = Ship{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_gomschema_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Ship) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Ship) ProtoMessage() {}
+
+func (x *Ship) ProtoReflect() protoreflect.Message {
+	mi := &file_gomschema_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Ship.ProtoReflect.Descriptor instead.
+func (*Ship) Descriptor() ([]byte, []int) {
+	return file_gomschema_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *Ship) GetId() uint32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Ship) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Ship) GetTimestampUtc() uint64 {
+	if x != nil {
+		return x.TimestampUtc
+	}
+	return 0
+}
+
+func (x *Ship) GetPriceCr() uint32 {
+	if x != nil {
+		return x.PriceCr
+	}
+	return 0
+}
+
+/// The modules available through a facility's outfitting service.
+type FacilityOutfitting struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	/// Locally sourced id for the facility this is for.
+	Id uint32 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	/// Unix timestamp of when this was collected.
+	TimestampUtc uint64 `protobuf:"varint,2,opt,name=timestamp_utc,json=timestampUtc,proto3" json:"timestamp_utc,omitempty"`
+	/// Ids of the modules for sale.
+	ModuleIds []uint32 `protobuf:"varint,3,rep,packed,name=module_ids,json=moduleIds,proto3" json:"module_ids,omitempty"`
+}
+
+func (x *FacilityOutfitting) Reset() {
+	*x = FacilityOutfitting{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_gomschema_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *FacilityOutfitting) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FacilityOutfitting) ProtoMessage() {}
+
+func (x *FacilityOutfitting) ProtoReflect() protoreflect.Message {
+	mi := &file_gomschema_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FacilityOutfitting.ProtoReflect.Descriptor instead.
+func (*FacilityOutfitting) Descriptor() ([]byte, []int) {
+	return file_gomschema_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *FacilityOutfitting) GetId() uint32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *FacilityOutfitting) GetTimestampUtc() uint64 {
+	if x != nil {
+		return x.TimestampUtc
+	}
+	return 0
+}
+
+func (x *FacilityOutfitting) GetModuleIds() []uint32 {
+	if x != nil {
+		return x.ModuleIds
+	}
+	return nil
+}
+
+/// The ships available at a facility's shipyard.
+type FacilityShipyard struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	/// Locally sourced id for the facility this is for.
+	Id uint32 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	/// Unix timestamp of when this was collected.
+	TimestampUtc uint64 `protobuf:"varint,2,opt,name=timestamp_utc,json=timestampUtc,proto3" json:"timestamp_utc,omitempty"`
+	/// Ids of the ships for sale.
+	ShipIds []uint32 `protobuf:"varint,3,rep,packed,name=ship_ids,json=shipIds,proto3" json:"ship_ids,omitempty"`
+}
+
+func (x *FacilityShipyard) Reset() {
+	*x = FacilityShipyard{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_gomschema_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *FacilityShipyard) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FacilityShipyard) ProtoMessage() {}
+
+func (x *FacilityShipyard) ProtoReflect() protoreflect.Message {
+	mi := &file_gomschema_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FacilityShipyard.ProtoReflect.Descriptor instead.
+func (*FacilityShipyard) Descriptor() ([]byte, []int) {
+	return file_gomschema_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *FacilityShipyard) GetId() uint32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *FacilityShipyard) GetTimestampUtc() uint64 {
+	if x != nil {
+		return x.TimestampUtc
+	}
+	return 0
+}
+
+func (x *FacilityShipyard) GetShipIds() []uint32 {
+	if x != nil {
+		return x.ShipIds
+	}
+	return nil
+}
+
 var File_gomschema_proto protoreflect.FileDescriptor
 
 var file_gomschema_proto_rawDesc = []byte{
 	0x0a, 0x0f, 0x67, 0x6f, 0x6d, 0x73, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x2e, 0x70, 0x72, 0x6f, 0x74,
-	0x6f, 0x12, 0x09, 0x67, 0x6f, 0x6d, 0x73, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x22, 0xd0, 0x02, 0x0a,
+	0x6f, 0x12, 0x09, 0x67, 0x6f, 0x6d, 0x73, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x22, 0x89, 0x03, 0x0a,
 	0x06, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x12, 0x37, 0x0a, 0x0b, 0x68, 0x65, 0x61, 0x64, 0x65,
 	0x72, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x16, 0x2e, 0x67,
 	0x6f, 0x6d, 0x73, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x2e, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x2e,
@@ -1201,188 +1981,331 @@ var file_gomschema_proto_rawDesc = []byte{
 	0x3b, 0x0a, 0x0d, 0x55, 0x73, 0x65, 0x72, 0x64, 0x61, 0x74, 0x61, 0x45, 0x6e, 0x74, 0x72, 0x79,
 	0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b,
 	0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28,
-	0x0c, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0x5b, 0x0a, 0x04,
-	0x54, 0x79, 0x70, 0x65, 0x12, 0x0c, 0x0a, 0x08, 0x43, 0x49, 0x6e, 0x76, 0x61, 0x6c, 0x69, 0x64,
-	0x10, 0x00, 0x12, 0x0b, 0x0a, 0x07, 0x43, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x10, 0x01, 0x12,
-	0x0e, 0x0a, 0x0a, 0x43, 0x43, 0x6f, 0x6d, 0x6d, 0x6f, 0x64, 0x69, 0x74, 0x79, 0x10, 0x02, 0x12,
-	0x0b, 0x0a, 0x07, 0x43, 0x53, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x10, 0x03, 0x12, 0x0d, 0x0a, 0x09,
-	0x43, 0x46, 0x61, 0x63, 0x69, 0x6c, 0x69, 0x74, 0x79, 0x10, 0x04, 0x12, 0x0c, 0x0a, 0x08, 0x43,
-	0x4c, 0x69, 0x73, 0x74, 0x69, 0x6e, 0x67, 0x10, 0x05, 0x4a, 0x04, 0x08, 0x03, 0x10, 0x04, 0x22,
-	0xad, 0x04, 0x0a, 0x09, 0x43, 0x6f, 0x6d, 0x6d, 0x6f, 0x64, 0x69, 0x74, 0x79, 0x12, 0x0e, 0x0a,
-	0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x02, 0x69, 0x64, 0x12, 0x12, 0x0a,
-	0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d,
-	0x65, 0x12, 0x23, 0x0a, 0x0d, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x5f, 0x75,
-	0x74, 0x63, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0c, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74,
-	0x61, 0x6d, 0x70, 0x55, 0x74, 0x63, 0x12, 0x3e, 0x0a, 0x0b, 0x63, 0x61, 0x74, 0x65, 0x67, 0x6f,
-	0x72, 0x79, 0x5f, 0x69, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x1d, 0x2e, 0x67, 0x6f,
-	0x6d, 0x73, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x2e, 0x43, 0x6f, 0x6d, 0x6d, 0x6f, 0x64, 0x69, 0x74,
-	0x79, 0x2e, 0x43, 0x61, 0x74, 0x65, 0x67, 0x6f, 0x72, 0x79, 0x52, 0x0a, 0x63, 0x61, 0x74, 0x65,
-	0x67, 0x6f, 0x72, 0x79, 0x49, 0x64, 0x12, 0x17, 0x0a, 0x07, 0x69, 0x73, 0x5f, 0x72, 0x61, 0x72,
-	0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x69, 0x73, 0x52, 0x61, 0x72, 0x65, 0x12,
-	0x2a, 0x0a, 0x11, 0x69, 0x73, 0x5f, 0x6e, 0x6f, 0x6e, 0x5f, 0x6d, 0x61, 0x72, 0x6b, 0x65, 0x74,
-	0x61, 0x62, 0x6c, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0f, 0x69, 0x73, 0x4e, 0x6f,
-	0x6e, 0x4d, 0x61, 0x72, 0x6b, 0x65, 0x74, 0x61, 0x62, 0x6c, 0x65, 0x12, 0x1d, 0x0a, 0x0a, 0x61,
-	0x76, 0x65, 0x72, 0x61, 0x67, 0x65, 0x5f, 0x63, 0x72, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0d, 0x52,
-	0x09, 0x61, 0x76, 0x65, 0x72, 0x61, 0x67, 0x65, 0x43, 0x72, 0x22, 0xb2, 0x02, 0x0a, 0x08, 0x43,
-	0x61, 0x74, 0x65, 0x67, 0x6f, 0x72, 0x79, 0x12, 0x0b, 0x0a, 0x07, 0x43, 0x61, 0x74, 0x4e, 0x6f,
-	0x6e, 0x65, 0x10, 0x00, 0x12, 0x10, 0x0a, 0x0c, 0x43, 0x61, 0x74, 0x43, 0x68, 0x65, 0x6d, 0x69,
-	0x63, 0x61, 0x6c, 0x73, 0x10, 0x01, 0x12, 0x14, 0x0a, 0x10, 0x43, 0x61, 0x74, 0x43, 0x6f, 0x6e,
-	0x73, 0x75, 0x6d, 0x65, 0x72, 0x49, 0x74, 0x65, 0x6d, 0x73, 0x10, 0x02, 0x12, 0x11, 0x0a, 0x0d,
-	0x43, 0x61, 0x74, 0x4c, 0x65, 0x67, 0x61, 0x6c, 0x44, 0x72, 0x75, 0x67, 0x73, 0x10, 0x03, 0x12,
-	0x0c, 0x0a, 0x08, 0x43, 0x61, 0x74, 0x46, 0x6f, 0x6f, 0x64, 0x73, 0x10, 0x04, 0x12, 0x1a, 0x0a,
-	0x16, 0x43, 0x61, 0x74, 0x49, 0x6e, 0x64, 0x75, 0x73, 0x74, 0x72, 0x69, 0x61, 0x6c, 0x4d, 0x61,
-	0x74, 0x65, 0x72, 0x69, 0x61, 0x6c, 0x73, 0x10, 0x05, 0x12, 0x10, 0x0a, 0x0c, 0x43, 0x61, 0x74,
-	0x4d, 0x61, 0x63, 0x68, 0x69, 0x6e, 0x65, 0x72, 0x79, 0x10, 0x06, 0x12, 0x10, 0x0a, 0x0c, 0x43,
-	0x61, 0x74, 0x4d, 0x65, 0x64, 0x69, 0x63, 0x69, 0x6e, 0x65, 0x73, 0x10, 0x07, 0x12, 0x0d, 0x0a,
-	0x09, 0x43, 0x61, 0x74, 0x4d, 0x65, 0x74, 0x61, 0x6c, 0x73, 0x10, 0x08, 0x12, 0x0f, 0x0a, 0x0b,
-	0x43, 0x61, 0x74, 0x4d, 0x69, 0x6e, 0x65, 0x72, 0x61, 0x6c, 0x73, 0x10, 0x09, 0x12, 0x0e, 0x0a,
-	0x0a, 0x43, 0x61, 0x74, 0x53, 0x6c, 0x61, 0x76, 0x65, 0x72, 0x79, 0x10, 0x0a, 0x12, 0x11, 0x0a,
-	0x0d, 0x43, 0x61, 0x74, 0x54, 0x65, 0x63, 0x68, 0x6e, 0x6f, 0x6c, 0x6f, 0x67, 0x79, 0x10, 0x0b,
-	0x12, 0x0f, 0x0a, 0x0b, 0x43, 0x61, 0x74, 0x54, 0x65, 0x78, 0x74, 0x69, 0x6c, 0x65, 0x73, 0x10,
-	0x0c, 0x12, 0x0c, 0x0a, 0x08, 0x43, 0x61, 0x74, 0x57, 0x61, 0x73, 0x74, 0x65, 0x10, 0x0d, 0x12,
-	0x0e, 0x0a, 0x0a, 0x43, 0x61, 0x74, 0x57, 0x65, 0x61, 0x70, 0x6f, 0x6e, 0x73, 0x10, 0x0e, 0x12,
-	0x0e, 0x0a, 0x0a, 0x43, 0x61, 0x74, 0x55, 0x6e, 0x6b, 0x6e, 0x6f, 0x77, 0x6e, 0x10, 0x0f, 0x12,
-	0x0e, 0x0a, 0x0a, 0x43, 0x61, 0x74, 0x53, 0x61, 0x6c, 0x76, 0x61, 0x67, 0x65, 0x10, 0x10, 0x22,
-	0x36, 0x0a, 0x0a, 0x43, 0x6f, 0x6f, 0x72, 0x64, 0x69, 0x6e, 0x61, 0x74, 0x65, 0x12, 0x0c, 0x0a,
-	0x01, 0x78, 0x18, 0x01, 0x20, 0x01, 0x28, 0x01, 0x52, 0x01, 0x78, 0x12, 0x0c, 0x0a, 0x01, 0x79,
-	0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x01, 0x79, 0x12, 0x0c, 0x0a, 0x01, 0x7a, 0x18, 0x03,
-	0x20, 0x01, 0x28, 0x01, 0x52, 0x01, 0x7a, 0x22, 0xfc, 0x02, 0x0a, 0x06, 0x53, 0x79, 0x73, 0x74,
-	0x65, 0x6d, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x02,
-	0x69, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
-	0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x23, 0x0a, 0x0d, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74,
-	0x61, 0x6d, 0x70, 0x5f, 0x75, 0x74, 0x63, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0c, 0x74,
-	0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x55, 0x74, 0x63, 0x12, 0x31, 0x0a, 0x08, 0x70,
-	0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x15, 0x2e,
-	0x67, 0x6f, 0x6d, 0x73, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x2e, 0x43, 0x6f, 0x6f, 0x72, 0x64, 0x69,
-	0x6e, 0x61, 0x74, 0x65, 0x52, 0x08, 0x70, 0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x1c,
-	0x0a, 0x09, 0x70, 0x6f, 0x70, 0x75, 0x6c, 0x61, 0x74, 0x65, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28,
-	0x08, 0x52, 0x09, 0x70, 0x6f, 0x70, 0x75, 0x6c, 0x61, 0x74, 0x65, 0x64, 0x12, 0x21, 0x0a, 0x0c,
-	0x6e, 0x65, 0x65, 0x64, 0x73, 0x5f, 0x70, 0x65, 0x72, 0x6d, 0x69, 0x74, 0x18, 0x06, 0x20, 0x01,
-	0x28, 0x08, 0x52, 0x0b, 0x6e, 0x65, 0x65, 0x64, 0x73, 0x50, 0x65, 0x72, 0x6d, 0x69, 0x74, 0x12,
-	0x3f, 0x0a, 0x0e, 0x73, 0x65, 0x63, 0x75, 0x72, 0x69, 0x74, 0x79, 0x5f, 0x6c, 0x65, 0x76, 0x65,
-	0x6c, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x18, 0x2e, 0x67, 0x6f, 0x6d, 0x73, 0x63, 0x68,
-	0x65, 0x6d, 0x61, 0x2e, 0x53, 0x65, 0x63, 0x75, 0x72, 0x69, 0x74, 0x79, 0x4c, 0x65, 0x76, 0x65,
-	0x6c, 0x52, 0x0d, 0x73, 0x65, 0x63, 0x75, 0x72, 0x69, 0x74, 0x79, 0x4c, 0x65, 0x76, 0x65, 0x6c,
-	0x12, 0x39, 0x0a, 0x0a, 0x67, 0x6f, 0x76, 0x65, 0x72, 0x6e, 0x6d, 0x65, 0x6e, 0x74, 0x18, 0x08,
-	0x20, 0x01, 0x28, 0x0e, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6d, 0x73, 0x63, 0x68, 0x65, 0x6d, 0x61,
-	0x2e, 0x47, 0x6f, 0x76, 0x65, 0x72, 0x6e, 0x6d, 0x65, 0x6e, 0x74, 0x54, 0x79, 0x70, 0x65, 0x52,
-	0x0a, 0x67, 0x6f, 0x76, 0x65, 0x72, 0x6e, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x39, 0x0a, 0x0a, 0x61,
-	0x6c, 0x6c, 0x65, 0x67, 0x69, 0x61, 0x6e, 0x63, 0x65, 0x18, 0x09, 0x20, 0x01, 0x28, 0x0e, 0x32,
-	0x19, 0x2e, 0x67, 0x6f, 0x6d, 0x73, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x2e, 0x41, 0x6c, 0x6c, 0x65,
-	0x67, 0x69, 0x61, 0x6e, 0x63, 0x65, 0x54, 0x79, 0x70, 0x65, 0x52, 0x0a, 0x61, 0x6c, 0x6c, 0x65,
-	0x67, 0x69, 0x61, 0x6e, 0x63, 0x65, 0x22, 0xe2, 0x02, 0x0a, 0x08, 0x46, 0x61, 0x63, 0x69, 0x6c,
-	0x69, 0x74, 0x79, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52,
-	0x02, 0x69, 0x64, 0x12, 0x1b, 0x0a, 0x09, 0x73, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x5f, 0x69, 0x64,
-	0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x08, 0x73, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x49, 0x64,
-	0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04,
+	0x0c, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0x93, 0x01, 0x0a,
+	0x04, 0x54, 0x79, 0x70, 0x65, 0x12, 0x0c, 0x0a, 0x08, 0x43, 0x49, 0x6e, 0x76, 0x61, 0x6c, 0x69,
+	0x64, 0x10, 0x00, 0x12, 0x0b, 0x0a, 0x07, 0x43, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x10, 0x01,
+	0x12, 0x0e, 0x0a, 0x0a, 0x43, 0x43, 0x6f, 0x6d, 0x6d, 0x6f, 0x64, 0x69, 0x74, 0x79, 0x10, 0x02,
+	0x12, 0x0b, 0x0a, 0x07, 0x43, 0x53, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x10, 0x03, 0x12, 0x0d, 0x0a,
+	0x09, 0x43, 0x46, 0x61, 0x63, 0x69, 0x6c, 0x69, 0x74, 0x79, 0x10, 0x04, 0x12, 0x0c, 0x0a, 0x08,
+	0x43, 0x4c, 0x69, 0x73, 0x74, 0x69, 0x6e, 0x67, 0x10, 0x05, 0x12, 0x0b, 0x0a, 0x07, 0x43, 0x4d,
+	0x6f, 0x64, 0x75, 0x6c, 0x65, 0x10, 0x06, 0x12, 0x09, 0x0a, 0x05, 0x43, 0x53, 0x68, 0x69, 0x70,
+	0x10, 0x07, 0x12, 0x0f, 0x0a, 0x0b, 0x43, 0x4f, 0x75, 0x74, 0x66, 0x69, 0x74, 0x74, 0x69, 0x6e,
+	0x67, 0x10, 0x08, 0x12, 0x0d, 0x0a, 0x09, 0x43, 0x53, 0x68, 0x69, 0x70, 0x79, 0x61, 0x72, 0x64,
+	0x10, 0x09, 0x4a, 0x04, 0x08, 0x03, 0x10, 0x04, 0x22, 0xad, 0x04, 0x0a, 0x09, 0x43, 0x6f, 0x6d,
+	0x6d, 0x6f, 0x64, 0x69, 0x74, 0x79, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x0d, 0x52, 0x02, 0x69, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x23, 0x0a, 0x0d, 0x74, 0x69,
+	0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x5f, 0x75, 0x74, 0x63, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x04, 0x52, 0x0c, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x55, 0x74, 0x63, 0x12,
+	0x3e, 0x0a, 0x0b, 0x63, 0x61, 0x74, 0x65, 0x67, 0x6f, 0x72, 0x79, 0x5f, 0x69, 0x64, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x0e, 0x32, 0x1d, 0x2e, 0x67, 0x6f, 0x6d, 0x73, 0x63, 0x68, 0x65, 0x6d, 0x61,
+	0x2e, 0x43, 0x6f, 0x6d, 0x6d, 0x6f, 0x64, 0x69, 0x74, 0x79, 0x2e, 0x43, 0x61, 0x74, 0x65, 0x67,
+	0x6f, 0x72, 0x79, 0x52, 0x0a, 0x63, 0x61, 0x74, 0x65, 0x67, 0x6f, 0x72, 0x79, 0x49, 0x64, 0x12,
+	0x17, 0x0a, 0x07, 0x69, 0x73, 0x5f, 0x72, 0x61, 0x72, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x08,
+	0x52, 0x06, 0x69, 0x73, 0x52, 0x61, 0x72, 0x65, 0x12, 0x2a, 0x0a, 0x11, 0x69, 0x73, 0x5f, 0x6e,
+	0x6f, 0x6e, 0x5f, 0x6d, 0x61, 0x72, 0x6b, 0x65, 0x74, 0x61, 0x62, 0x6c, 0x65, 0x18, 0x06, 0x20,
+	0x01, 0x28, 0x08, 0x52, 0x0f, 0x69, 0x73, 0x4e, 0x6f, 0x6e, 0x4d, 0x61, 0x72, 0x6b, 0x65, 0x74,
+	0x61, 0x62, 0x6c, 0x65, 0x12, 0x1d, 0x0a, 0x0a, 0x61, 0x76, 0x65, 0x72, 0x61, 0x67, 0x65, 0x5f,
+	0x63, 0x72, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x09, 0x61, 0x76, 0x65, 0x72, 0x61, 0x67,
+	0x65, 0x43, 0x72, 0x22, 0xb2, 0x02, 0x0a, 0x08, 0x43, 0x61, 0x74, 0x65, 0x67, 0x6f, 0x72, 0x79,
+	0x12, 0x0b, 0x0a, 0x07, 0x43, 0x61, 0x74, 0x4e, 0x6f, 0x6e, 0x65, 0x10, 0x00, 0x12, 0x10, 0x0a,
+	0x0c, 0x43, 0x61, 0x74, 0x43, 0x68, 0x65, 0x6d, 0x69, 0x63, 0x61, 0x6c, 0x73, 0x10, 0x01, 0x12,
+	0x14, 0x0a, 0x10, 0x43, 0x61, 0x74, 0x43, 0x6f, 0x6e, 0x73, 0x75, 0x6d, 0x65, 0x72, 0x49, 0x74,
+	0x65, 0x6d, 0x73, 0x10, 0x02, 0x12, 0x11, 0x0a, 0x0d, 0x43, 0x61, 0x74, 0x4c, 0x65, 0x67, 0x61,
+	0x6c, 0x44, 0x72, 0x75, 0x67, 0x73, 0x10, 0x03, 0x12, 0x0c, 0x0a, 0x08, 0x43, 0x61, 0x74, 0x46,
+	0x6f, 0x6f, 0x64, 0x73, 0x10, 0x04, 0x12, 0x1a, 0x0a, 0x16, 0x43, 0x61, 0x74, 0x49, 0x6e, 0x64,
+	0x75, 0x73, 0x74, 0x72, 0x69, 0x61, 0x6c, 0x4d, 0x61, 0x74, 0x65, 0x72, 0x69, 0x61, 0x6c, 0x73,
+	0x10, 0x05, 0x12, 0x10, 0x0a, 0x0c, 0x43, 0x61, 0x74, 0x4d, 0x61, 0x63, 0x68, 0x69, 0x6e, 0x65,
+	0x72, 0x79, 0x10, 0x06, 0x12, 0x10, 0x0a, 0x0c, 0x43, 0x61, 0x74, 0x4d, 0x65, 0x64, 0x69, 0x63,
+	0x69, 0x6e, 0x65, 0x73, 0x10, 0x07, 0x12, 0x0d, 0x0a, 0x09, 0x43, 0x61, 0x74, 0x4d, 0x65, 0x74,
+	0x61, 0x6c, 0x73, 0x10, 0x08, 0x12, 0x0f, 0x0a, 0x0b, 0x43, 0x61, 0x74, 0x4d, 0x69, 0x6e, 0x65,
+	0x72, 0x61, 0x6c, 0x73, 0x10, 0x09, 0x12, 0x0e, 0x0a, 0x0a, 0x43, 0x61, 0x74, 0x53, 0x6c, 0x61,
+	0x76, 0x65, 0x72, 0x79, 0x10, 0x0a, 0x12, 0x11, 0x0a, 0x0d, 0x43, 0x61, 0x74, 0x54, 0x65, 0x63,
+	0x68, 0x6e, 0x6f, 0x6c, 0x6f, 0x67, 0x79, 0x10, 0x0b, 0x12, 0x0f, 0x0a, 0x0b, 0x43, 0x61, 0x74,
+	0x54, 0x65, 0x78, 0x74, 0x69, 0x6c, 0x65, 0x73, 0x10, 0x0c, 0x12, 0x0c, 0x0a, 0x08, 0x43, 0x61,
+	0x74, 0x57, 0x61, 0x73, 0x74, 0x65, 0x10, 0x0d, 0x12, 0x0e, 0x0a, 0x0a, 0x43, 0x61, 0x74, 0x57,
+	0x65, 0x61, 0x70, 0x6f, 0x6e, 0x73, 0x10, 0x0e, 0x12, 0x0e, 0x0a, 0x0a, 0x43, 0x61, 0x74, 0x55,
+	0x6e, 0x6b, 0x6e, 0x6f, 0x77, 0x6e, 0x10, 0x0f, 0x12, 0x0e, 0x0a, 0x0a, 0x43, 0x61, 0x74, 0x53,
+	0x61, 0x6c, 0x76, 0x61, 0x67, 0x65, 0x10, 0x10, 0x22, 0x36, 0x0a, 0x0a, 0x43, 0x6f, 0x6f, 0x72,
+	0x64, 0x69, 0x6e, 0x61, 0x74, 0x65, 0x12, 0x0c, 0x0a, 0x01, 0x78, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x01, 0x52, 0x01, 0x78, 0x12, 0x0c, 0x0a, 0x01, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52,
+	0x01, 0x79, 0x12, 0x0c, 0x0a, 0x01, 0x7a, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x52, 0x01, 0x7a,
+	0x22, 0x80, 0x01, 0x0a, 0x0f, 0x46, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x50, 0x72, 0x65, 0x73,
+	0x65, 0x6e, 0x63, 0x65, 0x12, 0x1d, 0x0a, 0x0a, 0x66, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x5f,
+	0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x09, 0x66, 0x61, 0x63, 0x74, 0x69, 0x6f,
+	0x6e, 0x49, 0x64, 0x12, 0x1c, 0x0a, 0x09, 0x69, 0x6e, 0x66, 0x6c, 0x75, 0x65, 0x6e, 0x63, 0x65,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x02, 0x52, 0x09, 0x69, 0x6e, 0x66, 0x6c, 0x75, 0x65, 0x6e, 0x63,
+	0x65, 0x12, 0x30, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x65, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28,
+	0x0e, 0x32, 0x14, 0x2e, 0x67, 0x6f, 0x6d, 0x73, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x2e, 0x53, 0x74,
+	0x61, 0x74, 0x65, 0x54, 0x79, 0x70, 0x65, 0x42, 0x02, 0x10, 0x01, 0x52, 0x06, 0x73, 0x74, 0x61,
+	0x74, 0x65, 0x73, 0x22, 0xae, 0x05, 0x0a, 0x06, 0x53, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x12, 0x0e,
+	0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x02, 0x69, 0x64, 0x12, 0x12,
+	0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61,
+	0x6d, 0x65, 0x12, 0x23, 0x0a, 0x0d, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x5f,
+	0x75, 0x74, 0x63, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0c, 0x74, 0x69, 0x6d, 0x65, 0x73,
+	0x74, 0x61, 0x6d, 0x70, 0x55, 0x74, 0x63, 0x12, 0x31, 0x0a, 0x08, 0x70, 0x6f, 0x73, 0x69, 0x74,
+	0x69, 0x6f, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x67, 0x6f, 0x6d, 0x73,
+	0x63, 0x68, 0x65, 0x6d, 0x61, 0x2e, 0x43, 0x6f, 0x6f, 0x72, 0x64, 0x69, 0x6e, 0x61, 0x74, 0x65,
+	0x52, 0x08, 0x70, 0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x1c, 0x0a, 0x09, 0x70, 0x6f,
+	0x70, 0x75, 0x6c, 0x61, 0x74, 0x65, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x70,
+	0x6f, 0x70, 0x75, 0x6c, 0x61, 0x74, 0x65, 0x64, 0x12, 0x21, 0x0a, 0x0c, 0x6e, 0x65, 0x65, 0x64,
+	0x73, 0x5f, 0x70, 0x65, 0x72, 0x6d, 0x69, 0x74, 0x18, 0x06, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0b,
+	0x6e, 0x65, 0x65, 0x64, 0x73, 0x50, 0x65, 0x72, 0x6d, 0x69, 0x74, 0x12, 0x3f, 0x0a, 0x0e, 0x73,
+	0x65, 0x63, 0x75, 0x72, 0x69, 0x74, 0x79, 0x5f, 0x6c, 0x65, 0x76, 0x65, 0x6c, 0x18, 0x07, 0x20,
+	0x01, 0x28, 0x0e, 0x32, 0x18, 0x2e, 0x67, 0x6f, 0x6d, 0x73, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x2e,
+	0x53, 0x65, 0x63, 0x75, 0x72, 0x69, 0x74, 0x79, 0x4c, 0x65, 0x76, 0x65, 0x6c, 0x52, 0x0d, 0x73,
+	0x65, 0x63, 0x75, 0x72, 0x69, 0x74, 0x79, 0x4c, 0x65, 0x76, 0x65, 0x6c, 0x12, 0x39, 0x0a, 0x0a,
+	0x67, 0x6f, 0x76, 0x65, 0x72, 0x6e, 0x6d, 0x65, 0x6e, 0x74, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0e,
+	0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6d, 0x73, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x2e, 0x47, 0x6f, 0x76,
+	0x65, 0x72, 0x6e, 0x6d, 0x65, 0x6e, 0x74, 0x54, 0x79, 0x70, 0x65, 0x52, 0x0a, 0x67, 0x6f, 0x76,
+	0x65, 0x72, 0x6e, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x39, 0x0a, 0x0a, 0x61, 0x6c, 0x6c, 0x65, 0x67,
+	0x69, 0x61, 0x6e, 0x63, 0x65, 0x18, 0x09, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x19, 0x2e, 0x67, 0x6f,
+	0x6d, 0x73, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x2e, 0x41, 0x6c, 0x6c, 0x65, 0x67, 0x69, 0x61, 0x6e,
+	0x63, 0x65, 0x54, 0x79, 0x70, 0x65, 0x52, 0x0a, 0x61, 0x6c, 0x6c, 0x65, 0x67, 0x69, 0x61, 0x6e,
+	0x63, 0x65, 0x12, 0x1e, 0x0a, 0x0a, 0x70, 0x6f, 0x70, 0x75, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x18, 0x0a, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0a, 0x70, 0x6f, 0x70, 0x75, 0x6c, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x12, 0x3f, 0x0a, 0x0f, 0x70, 0x72, 0x69, 0x6d, 0x61, 0x72, 0x79, 0x5f, 0x65, 0x63,
+	0x6f, 0x6e, 0x6f, 0x6d, 0x79, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x16, 0x2e, 0x67, 0x6f,
+	0x6d, 0x73, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x2e, 0x45, 0x63, 0x6f, 0x6e, 0x6f, 0x6d, 0x79, 0x54,
+	0x79, 0x70, 0x65, 0x52, 0x0e, 0x70, 0x72, 0x69, 0x6d, 0x61, 0x72, 0x79, 0x45, 0x63, 0x6f, 0x6e,
+	0x6f, 0x6d, 0x79, 0x12, 0x30, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x65, 0x73, 0x18, 0x0c, 0x20,
+	0x03, 0x28, 0x0e, 0x32, 0x14, 0x2e, 0x67, 0x6f, 0x6d, 0x73, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x2e,
+	0x53, 0x74, 0x61, 0x74, 0x65, 0x54, 0x79, 0x70, 0x65, 0x42, 0x02, 0x10, 0x01, 0x52, 0x06, 0x73,
+	0x74, 0x61, 0x74, 0x65, 0x73, 0x12, 0x34, 0x0a, 0x16, 0x63, 0x6f, 0x6e, 0x74, 0x72, 0x6f, 0x6c,
+	0x6c, 0x69, 0x6e, 0x67, 0x5f, 0x66, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x69, 0x64, 0x18,
+	0x0d, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x14, 0x63, 0x6f, 0x6e, 0x74, 0x72, 0x6f, 0x6c, 0x6c, 0x69,
+	0x6e, 0x67, 0x46, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x49, 0x64, 0x12, 0x2f, 0x0a, 0x13, 0x63,
+	0x6f, 0x6e, 0x74, 0x72, 0x6f, 0x6c, 0x6c, 0x69, 0x6e, 0x67, 0x5f, 0x66, 0x61, 0x63, 0x74, 0x69,
+	0x6f, 0x6e, 0x18, 0x0e, 0x20, 0x01, 0x28, 0x09, 0x52, 0x12, 0x63, 0x6f, 0x6e, 0x74, 0x72, 0x6f,
+	0x6c, 0x6c, 0x69, 0x6e, 0x67, 0x46, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x36, 0x0a, 0x08,
+	0x66, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x0f, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1a,
+	0x2e, 0x67, 0x6f, 0x6d, 0x73, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x2e, 0x46, 0x61, 0x63, 0x74, 0x69,
+	0x6f, 0x6e, 0x50, 0x72, 0x65, 0x73, 0x65, 0x6e, 0x63, 0x65, 0x52, 0x08, 0x66, 0x61, 0x63, 0x74,
+	0x69, 0x6f, 0x6e, 0x73, 0x22, 0xfa, 0x03, 0x0a, 0x08, 0x46, 0x61, 0x63, 0x69, 0x6c, 0x69, 0x74,
+	0x79, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x02, 0x69,
+	0x64, 0x12, 0x1b, 0x0a, 0x09, 0x73, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x5f, 0x69, 0x64, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x0d, 0x52, 0x08, 0x73, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x49, 0x64, 0x12, 0x12,
+	0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61,
+	0x6d, 0x65, 0x12, 0x23, 0x0a, 0x0d, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x5f,
+	0x75, 0x74, 0x63, 0x18, 0x04, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0c, 0x74, 0x69, 0x6d, 0x65, 0x73,
+	0x74, 0x61, 0x6d, 0x70, 0x55, 0x74, 0x63, 0x12, 0x3c, 0x0a, 0x0d, 0x66, 0x61, 0x63, 0x69, 0x6c,
+	0x69, 0x74, 0x79, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x17,
+	0x2e, 0x67, 0x6f, 0x6d, 0x73, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x2e, 0x46, 0x61, 0x63, 0x69, 0x6c,
+	0x69, 0x74, 0x79, 0x54, 0x79, 0x70, 0x65, 0x52, 0x0c, 0x66, 0x61, 0x63, 0x69, 0x6c, 0x69, 0x74,
+	0x79, 0x54, 0x79, 0x70, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x66, 0x65, 0x61, 0x74, 0x75, 0x72, 0x65,
+	0x73, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x08, 0x66, 0x65, 0x61, 0x74, 0x75, 0x72, 0x65,
+	0x73, 0x12, 0x20, 0x0a, 0x0c, 0x6c, 0x73, 0x5f, 0x66, 0x72, 0x6f, 0x6d, 0x5f, 0x73, 0x74, 0x61,
+	0x72, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0a, 0x6c, 0x73, 0x46, 0x72, 0x6f, 0x6d, 0x53,
+	0x74, 0x61, 0x72, 0x12, 0x39, 0x0a, 0x0a, 0x67, 0x6f, 0x76, 0x65, 0x72, 0x6e, 0x6d, 0x65, 0x6e,
+	0x74, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6d, 0x73, 0x63, 0x68,
+	0x65, 0x6d, 0x61, 0x2e, 0x47, 0x6f, 0x76, 0x65, 0x72, 0x6e, 0x6d, 0x65, 0x6e, 0x74, 0x54, 0x79,
+	0x70, 0x65, 0x52, 0x0a, 0x67, 0x6f, 0x76, 0x65, 0x72, 0x6e, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x39,
+	0x0a, 0x0a, 0x61, 0x6c, 0x6c, 0x65, 0x67, 0x69, 0x61, 0x6e, 0x63, 0x65, 0x18, 0x09, 0x20, 0x01,
+	0x28, 0x0e, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6d, 0x73, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x2e, 0x41,
+	0x6c, 0x6c, 0x65, 0x67, 0x69, 0x61, 0x6e, 0x63, 0x65, 0x54, 0x79, 0x70, 0x65, 0x52, 0x0a, 0x61,
+	0x6c, 0x6c, 0x65, 0x67, 0x69, 0x61, 0x6e, 0x63, 0x65, 0x12, 0x1b, 0x0a, 0x09, 0x6d, 0x61, 0x72,
+	0x6b, 0x65, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x04, 0x52, 0x08, 0x6d, 0x61,
+	0x72, 0x6b, 0x65, 0x74, 0x49, 0x64, 0x12, 0x38, 0x0a, 0x09, 0x65, 0x63, 0x6f, 0x6e, 0x6f, 0x6d,
+	0x69, 0x65, 0x73, 0x18, 0x0b, 0x20, 0x03, 0x28, 0x0e, 0x32, 0x16, 0x2e, 0x67, 0x6f, 0x6d, 0x73,
+	0x63, 0x68, 0x65, 0x6d, 0x61, 0x2e, 0x45, 0x63, 0x6f, 0x6e, 0x6f, 0x6d, 0x79, 0x54, 0x79, 0x70,
+	0x65, 0x42, 0x02, 0x10, 0x01, 0x52, 0x09, 0x65, 0x63, 0x6f, 0x6e, 0x6f, 0x6d, 0x69, 0x65, 0x73,
+	0x12, 0x12, 0x0a, 0x04, 0x62, 0x6f, 0x64, 0x79, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04,
+	0x62, 0x6f, 0x64, 0x79, 0x12, 0x2b, 0x0a, 0x11, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x74, 0x61, 0x72,
+	0x79, 0x5f, 0x6c, 0x61, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x08, 0x52,
+	0x10, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x74, 0x61, 0x72, 0x79, 0x4c, 0x61, 0x6e, 0x64, 0x69, 0x6e,
+	0x67, 0x22, 0xf0, 0x02, 0x0a, 0x10, 0x43, 0x6f, 0x6d, 0x6d, 0x6f, 0x64, 0x69, 0x74, 0x79, 0x4c,
+	0x69, 0x73, 0x74, 0x69, 0x6e, 0x67, 0x12, 0x21, 0x0a, 0x0c, 0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x64,
+	0x69, 0x74, 0x79, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0b, 0x63, 0x6f,
+	0x6d, 0x6d, 0x6f, 0x64, 0x69, 0x74, 0x79, 0x49, 0x64, 0x12, 0x21, 0x0a, 0x0c, 0x73, 0x75, 0x70,
+	0x70, 0x6c, 0x79, 0x5f, 0x75, 0x6e, 0x69, 0x74, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52,
+	0x0b, 0x73, 0x75, 0x70, 0x70, 0x6c, 0x79, 0x55, 0x6e, 0x69, 0x74, 0x73, 0x12, 0x25, 0x0a, 0x0e,
+	0x73, 0x75, 0x70, 0x70, 0x6c, 0x79, 0x5f, 0x63, 0x72, 0x65, 0x64, 0x69, 0x74, 0x73, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x0d, 0x52, 0x0d, 0x73, 0x75, 0x70, 0x70, 0x6c, 0x79, 0x43, 0x72, 0x65, 0x64,
+	0x69, 0x74, 0x73, 0x12, 0x21, 0x0a, 0x0c, 0x64, 0x65, 0x6d, 0x61, 0x6e, 0x64, 0x5f, 0x75, 0x6e,
+	0x69, 0x74, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0b, 0x64, 0x65, 0x6d, 0x61, 0x6e,
+	0x64, 0x55, 0x6e, 0x69, 0x74, 0x73, 0x12, 0x25, 0x0a, 0x0e, 0x64, 0x65, 0x6d, 0x61, 0x6e, 0x64,
+	0x5f, 0x63, 0x72, 0x65, 0x64, 0x69, 0x74, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0d,
+	0x64, 0x65, 0x6d, 0x61, 0x6e, 0x64, 0x43, 0x72, 0x65, 0x64, 0x69, 0x74, 0x73, 0x12, 0x23, 0x0a,
+	0x0d, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x5f, 0x75, 0x74, 0x63, 0x18, 0x06,
+	0x20, 0x01, 0x28, 0x04, 0x52, 0x0c, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x55,
+	0x74, 0x63, 0x12, 0x3f, 0x0a, 0x0e, 0x73, 0x75, 0x70, 0x70, 0x6c, 0x79, 0x5f, 0x62, 0x72, 0x61,
+	0x63, 0x6b, 0x65, 0x74, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x18, 0x2e, 0x67, 0x6f, 0x6d,
+	0x73, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x2e, 0x4d, 0x61, 0x72, 0x6b, 0x65, 0x74, 0x42, 0x72, 0x61,
+	0x63, 0x6b, 0x65, 0x74, 0x52, 0x0d, 0x73, 0x75, 0x70, 0x70, 0x6c, 0x79, 0x42, 0x72, 0x61, 0x63,
+	0x6b, 0x65, 0x74, 0x12, 0x3f, 0x0a, 0x0e, 0x64, 0x65, 0x6d, 0x61, 0x6e, 0x64, 0x5f, 0x62, 0x72,
+	0x61, 0x63, 0x6b, 0x65, 0x74, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x18, 0x2e, 0x67, 0x6f,
+	0x6d, 0x73, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x2e, 0x4d, 0x61, 0x72, 0x6b, 0x65, 0x74, 0x42, 0x72,
+	0x61, 0x63, 0x6b, 0x65, 0x74, 0x52, 0x0d, 0x64, 0x65, 0x6d, 0x61, 0x6e, 0x64, 0x42, 0x72, 0x61,
+	0x63, 0x6b, 0x65, 0x74, 0x22, 0x5a, 0x0a, 0x0f, 0x46, 0x61, 0x63, 0x69, 0x6c, 0x69, 0x74, 0x79,
+	0x4c, 0x69, 0x73, 0x74, 0x69, 0x6e, 0x67, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x0d, 0x52, 0x02, 0x69, 0x64, 0x12, 0x37, 0x0a, 0x08, 0x6c, 0x69, 0x73, 0x74, 0x69,
+	0x6e, 0x67, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x67, 0x6f, 0x6d, 0x73,
+	0x63, 0x68, 0x65, 0x6d, 0x61, 0x2e, 0x43, 0x6f, 0x6d, 0x6d, 0x6f, 0x64, 0x69, 0x74, 0x79, 0x4c,
+	0x69, 0x73, 0x74, 0x69, 0x6e, 0x67, 0x52, 0x08, 0x6c, 0x69, 0x73, 0x74, 0x69, 0x6e, 0x67, 0x73,
+	0x22, 0xd5, 0x01, 0x0a, 0x06, 0x4d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x12, 0x0e, 0x0a, 0x02, 0x69,
+	0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x02, 0x69, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x6e,
+	0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12,
+	0x23, 0x0a, 0x0d, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x5f, 0x75, 0x74, 0x63,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0c, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d,
+	0x70, 0x55, 0x74, 0x63, 0x12, 0x1a, 0x0a, 0x08, 0x63, 0x61, 0x74, 0x65, 0x67, 0x6f, 0x72, 0x79,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x63, 0x61, 0x74, 0x65, 0x67, 0x6f, 0x72, 0x79,
+	0x12, 0x14, 0x0a, 0x05, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x05, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x12, 0x1d, 0x0a, 0x0a, 0x73, 0x69, 0x7a, 0x65, 0x5f, 0x63,
+	0x6c, 0x61, 0x73, 0x73, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x09, 0x73, 0x69, 0x7a, 0x65,
+	0x43, 0x6c, 0x61, 0x73, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x72, 0x61, 0x74, 0x69, 0x6e, 0x67, 0x18,
+	0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x72, 0x61, 0x74, 0x69, 0x6e, 0x67, 0x12, 0x19, 0x0a,
+	0x08, 0x70, 0x72, 0x69, 0x63, 0x65, 0x5f, 0x63, 0x72, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0d, 0x52,
+	0x07, 0x70, 0x72, 0x69, 0x63, 0x65, 0x43, 0x72, 0x22, 0x6a, 0x0a, 0x04, 0x53, 0x68, 0x69, 0x70,
+	0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x02, 0x69, 0x64,
+	0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04,
 	0x6e, 0x61, 0x6d, 0x65, 0x12, 0x23, 0x0a, 0x0d, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d,
-	0x70, 0x5f, 0x75, 0x74, 0x63, 0x18, 0x04, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0c, 0x74, 0x69, 0x6d,
-	0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x55, 0x74, 0x63, 0x12, 0x3c, 0x0a, 0x0d, 0x66, 0x61, 0x63,
-	0x69, 0x6c, 0x69, 0x74, 0x79, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0e,
-	0x32, 0x17, 0x2e, 0x67, 0x6f, 0x6d, 0x73, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x2e, 0x46, 0x61, 0x63,
-	0x69, 0x6c, 0x69, 0x74, 0x79, 0x54, 0x79, 0x70, 0x65, 0x52, 0x0c, 0x66, 0x61, 0x63, 0x69, 0x6c,
-	0x69, 0x74, 0x79, 0x54, 0x79, 0x70, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x66, 0x65, 0x61, 0x74, 0x75,
-	0x72, 0x65, 0x73, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x08, 0x66, 0x65, 0x61, 0x74, 0x75,
-	0x72, 0x65, 0x73, 0x12, 0x20, 0x0a, 0x0c, 0x6c, 0x73, 0x5f, 0x66, 0x72, 0x6f, 0x6d, 0x5f, 0x73,
-	0x74, 0x61, 0x72, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0a, 0x6c, 0x73, 0x46, 0x72, 0x6f,
-	0x6d, 0x53, 0x74, 0x61, 0x72, 0x12, 0x39, 0x0a, 0x0a, 0x67, 0x6f, 0x76, 0x65, 0x72, 0x6e, 0x6d,
-	0x65, 0x6e, 0x74, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6d, 0x73,
-	0x63, 0x68, 0x65, 0x6d, 0x61, 0x2e, 0x47, 0x6f, 0x76, 0x65, 0x72, 0x6e, 0x6d, 0x65, 0x6e, 0x74,
-	0x54, 0x79, 0x70, 0x65, 0x52, 0x0a, 0x67, 0x6f, 0x76, 0x65, 0x72, 0x6e, 0x6d, 0x65, 0x6e, 0x74,
-	0x12, 0x39, 0x0a, 0x0a, 0x61, 0x6c, 0x6c, 0x65, 0x67, 0x69, 0x61, 0x6e, 0x63, 0x65, 0x18, 0x09,
-	0x20, 0x01, 0x28, 0x0e, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6d, 0x73, 0x63, 0x68, 0x65, 0x6d, 0x61,
-	0x2e, 0x41, 0x6c, 0x6c, 0x65, 0x67, 0x69, 0x61, 0x6e, 0x63, 0x65, 0x54, 0x79, 0x70, 0x65, 0x52,
-	0x0a, 0x61, 0x6c, 0x6c, 0x65, 0x67, 0x69, 0x61, 0x6e, 0x63, 0x65, 0x22, 0xee, 0x01, 0x0a, 0x10,
-	0x43, 0x6f, 0x6d, 0x6d, 0x6f, 0x64, 0x69, 0x74, 0x79, 0x4c, 0x69, 0x73, 0x74, 0x69, 0x6e, 0x67,
-	0x12, 0x21, 0x0a, 0x0c, 0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x64, 0x69, 0x74, 0x79, 0x5f, 0x69, 0x64,
-	0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0b, 0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x64, 0x69, 0x74,
-	0x79, 0x49, 0x64, 0x12, 0x21, 0x0a, 0x0c, 0x73, 0x75, 0x70, 0x70, 0x6c, 0x79, 0x5f, 0x75, 0x6e,
-	0x69, 0x74, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0b, 0x73, 0x75, 0x70, 0x70, 0x6c,
-	0x79, 0x55, 0x6e, 0x69, 0x74, 0x73, 0x12, 0x25, 0x0a, 0x0e, 0x73, 0x75, 0x70, 0x70, 0x6c, 0x79,
-	0x5f, 0x63, 0x72, 0x65, 0x64, 0x69, 0x74, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0d,
-	0x73, 0x75, 0x70, 0x70, 0x6c, 0x79, 0x43, 0x72, 0x65, 0x64, 0x69, 0x74, 0x73, 0x12, 0x21, 0x0a,
-	0x0c, 0x64, 0x65, 0x6d, 0x61, 0x6e, 0x64, 0x5f, 0x75, 0x6e, 0x69, 0x74, 0x73, 0x18, 0x04, 0x20,
-	0x01, 0x28, 0x0d, 0x52, 0x0b, 0x64, 0x65, 0x6d, 0x61, 0x6e, 0x64, 0x55, 0x6e, 0x69, 0x74, 0x73,
-	0x12, 0x25, 0x0a, 0x0e, 0x64, 0x65, 0x6d, 0x61, 0x6e, 0x64, 0x5f, 0x63, 0x72, 0x65, 0x64, 0x69,
-	0x74, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0d, 0x64, 0x65, 0x6d, 0x61, 0x6e, 0x64,
-	0x43, 0x72, 0x65, 0x64, 0x69, 0x74, 0x73, 0x12, 0x23, 0x0a, 0x0d, 0x74, 0x69, 0x6d, 0x65, 0x73,
-	0x74, 0x61, 0x6d, 0x70, 0x5f, 0x75, 0x74, 0x63, 0x18, 0x06, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0c,
-	0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x55, 0x74, 0x63, 0x22, 0x5a, 0x0a, 0x0f,
-	0x46, 0x61, 0x63, 0x69, 0x6c, 0x69, 0x74, 0x79, 0x4c, 0x69, 0x73, 0x74, 0x69, 0x6e, 0x67, 0x12,
-	0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x02, 0x69, 0x64, 0x12,
-	0x37, 0x0a, 0x08, 0x6c, 0x69, 0x73, 0x74, 0x69, 0x6e, 0x67, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28,
-	0x0b, 0x32, 0x1b, 0x2e, 0x67, 0x6f, 0x6d, 0x73, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x2e, 0x43, 0x6f,
-	0x6d, 0x6d, 0x6f, 0x64, 0x69, 0x74, 0x79, 0x4c, 0x69, 0x73, 0x74, 0x69, 0x6e, 0x67, 0x52, 0x08,
-	0x6c, 0x69, 0x73, 0x74, 0x69, 0x6e, 0x67, 0x73, 0x2a, 0xf7, 0x01, 0x0a, 0x0e, 0x47, 0x6f, 0x76,
-	0x65, 0x72, 0x6e, 0x6d, 0x65, 0x6e, 0x74, 0x54, 0x79, 0x70, 0x65, 0x12, 0x0b, 0x0a, 0x07, 0x47,
-	0x6f, 0x76, 0x4e, 0x6f, 0x6e, 0x65, 0x10, 0x00, 0x12, 0x0e, 0x0a, 0x0a, 0x47, 0x6f, 0x76, 0x41,
-	0x6e, 0x61, 0x72, 0x63, 0x68, 0x79, 0x10, 0x01, 0x12, 0x10, 0x0a, 0x0c, 0x47, 0x6f, 0x76, 0x43,
-	0x6f, 0x6d, 0x6d, 0x75, 0x6e, 0x69, 0x73, 0x6d, 0x10, 0x02, 0x12, 0x12, 0x0a, 0x0e, 0x47, 0x6f,
-	0x76, 0x43, 0x6f, 0x6e, 0x66, 0x65, 0x64, 0x65, 0x72, 0x61, 0x63, 0x79, 0x10, 0x03, 0x12, 0x12,
-	0x0a, 0x0e, 0x47, 0x6f, 0x76, 0x43, 0x6f, 0x6f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x76, 0x65,
-	0x10, 0x04, 0x12, 0x10, 0x0a, 0x0c, 0x47, 0x6f, 0x76, 0x43, 0x6f, 0x72, 0x70, 0x6f, 0x72, 0x61,
-	0x74, 0x65, 0x10, 0x05, 0x12, 0x10, 0x0a, 0x0c, 0x47, 0x6f, 0x76, 0x44, 0x65, 0x6d, 0x6f, 0x63,
-	0x72, 0x61, 0x63, 0x79, 0x10, 0x06, 0x12, 0x13, 0x0a, 0x0f, 0x47, 0x6f, 0x76, 0x44, 0x69, 0x63,
-	0x74, 0x61, 0x74, 0x6f, 0x72, 0x73, 0x68, 0x69, 0x70, 0x10, 0x07, 0x12, 0x0d, 0x0a, 0x09, 0x47,
-	0x6f, 0x76, 0x46, 0x65, 0x75, 0x64, 0x61, 0x6c, 0x10, 0x08, 0x12, 0x10, 0x0a, 0x0c, 0x47, 0x6f,
-	0x76, 0x50, 0x61, 0x74, 0x72, 0x6f, 0x6e, 0x61, 0x67, 0x65, 0x10, 0x09, 0x12, 0x0d, 0x0a, 0x09,
-	0x47, 0x6f, 0x76, 0x50, 0x72, 0x69, 0x73, 0x6f, 0x6e, 0x10, 0x0a, 0x12, 0x13, 0x0a, 0x0f, 0x47,
-	0x6f, 0x76, 0x50, 0x72, 0x69, 0x73, 0x6f, 0x6e, 0x43, 0x6f, 0x6c, 0x6f, 0x6e, 0x79, 0x10, 0x0b,
-	0x12, 0x10, 0x0a, 0x0c, 0x47, 0x6f, 0x76, 0x54, 0x68, 0x65, 0x6f, 0x63, 0x72, 0x61, 0x63, 0x79,
-	0x10, 0x0c, 0x2a, 0x89, 0x01, 0x0a, 0x0e, 0x41, 0x6c, 0x6c, 0x65, 0x67, 0x69, 0x61, 0x6e, 0x63,
-	0x65, 0x54, 0x79, 0x70, 0x65, 0x12, 0x0d, 0x0a, 0x09, 0x41, 0x6c, 0x6c, 0x65, 0x67, 0x4e, 0x6f,
-	0x6e, 0x65, 0x10, 0x00, 0x12, 0x11, 0x0a, 0x0d, 0x41, 0x6c, 0x6c, 0x65, 0x67, 0x41, 0x6c, 0x6c,
-	0x69, 0x61, 0x6e, 0x63, 0x65, 0x10, 0x01, 0x12, 0x0f, 0x0a, 0x0b, 0x41, 0x6c, 0x6c, 0x65, 0x67,
-	0x45, 0x6d, 0x70, 0x69, 0x72, 0x65, 0x10, 0x02, 0x12, 0x13, 0x0a, 0x0f, 0x41, 0x6c, 0x6c, 0x65,
-	0x67, 0x46, 0x65, 0x64, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x10, 0x03, 0x12, 0x14, 0x0a,
-	0x10, 0x41, 0x6c, 0x6c, 0x65, 0x67, 0x49, 0x6e, 0x64, 0x65, 0x70, 0x65, 0x6e, 0x64, 0x65, 0x6e,
-	0x74, 0x10, 0x04, 0x12, 0x19, 0x0a, 0x15, 0x41, 0x6c, 0x6c, 0x65, 0x67, 0x50, 0x69, 0x6c, 0x6f,
-	0x74, 0x73, 0x46, 0x65, 0x64, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x10, 0x05, 0x2a, 0x6d,
-	0x0a, 0x0d, 0x53, 0x65, 0x63, 0x75, 0x72, 0x69, 0x74, 0x79, 0x4c, 0x65, 0x76, 0x65, 0x6c, 0x12,
-	0x10, 0x0a, 0x0c, 0x53, 0x65, 0x63, 0x75, 0x72, 0x69, 0x74, 0x79, 0x4e, 0x6f, 0x6e, 0x65, 0x10,
-	0x00, 0x12, 0x13, 0x0a, 0x0f, 0x53, 0x65, 0x63, 0x75, 0x72, 0x69, 0x74, 0x79, 0x41, 0x6e, 0x61,
-	0x72, 0x63, 0x68, 0x79, 0x10, 0x01, 0x12, 0x0f, 0x0a, 0x0b, 0x53, 0x65, 0x63, 0x75, 0x72, 0x69,
-	0x74, 0x79, 0x4c, 0x6f, 0x77, 0x10, 0x02, 0x12, 0x12, 0x0a, 0x0e, 0x53, 0x65, 0x63, 0x75, 0x72,
-	0x69, 0x74, 0x79, 0x4d, 0x65, 0x64, 0x69, 0x75, 0x6d, 0x10, 0x03, 0x12, 0x10, 0x0a, 0x0c, 0x53,
-	0x65, 0x63, 0x75, 0x72, 0x69, 0x74, 0x79, 0x48, 0x69, 0x67, 0x68, 0x10, 0x04, 0x2a, 0xec, 0x02,
-	0x0a, 0x0c, 0x46, 0x61, 0x63, 0x69, 0x6c, 0x69, 0x74, 0x79, 0x54, 0x79, 0x70, 0x65, 0x12, 0x0a,
-	0x0a, 0x06, 0x46, 0x54, 0x4e, 0x6f, 0x6e, 0x65, 0x10, 0x00, 0x12, 0x15, 0x0a, 0x11, 0x46, 0x54,
-	0x43, 0x69, 0x76, 0x69, 0x6c, 0x69, 0x61, 0x6e, 0x4f, 0x75, 0x74, 0x70, 0x6f, 0x73, 0x74, 0x10,
-	0x01, 0x12, 0x17, 0x0a, 0x13, 0x46, 0x54, 0x43, 0x6f, 0x6d, 0x6d, 0x65, 0x72, 0x63, 0x69, 0x61,
-	0x6c, 0x4f, 0x75, 0x74, 0x70, 0x6f, 0x73, 0x74, 0x10, 0x02, 0x12, 0x16, 0x0a, 0x12, 0x46, 0x54,
-	0x43, 0x6f, 0x72, 0x69, 0x6f, 0x6c, 0x69, 0x73, 0x53, 0x74, 0x61, 0x72, 0x70, 0x6f, 0x72, 0x74,
-	0x10, 0x03, 0x12, 0x17, 0x0a, 0x13, 0x46, 0x54, 0x49, 0x6e, 0x64, 0x75, 0x73, 0x74, 0x72, 0x69,
-	0x61, 0x6c, 0x4f, 0x75, 0x74, 0x70, 0x6f, 0x73, 0x74, 0x10, 0x04, 0x12, 0x15, 0x0a, 0x11, 0x46,
-	0x54, 0x4d, 0x69, 0x6c, 0x69, 0x74, 0x61, 0x72, 0x79, 0x4f, 0x75, 0x74, 0x70, 0x6f, 0x73, 0x74,
-	0x10, 0x05, 0x12, 0x13, 0x0a, 0x0f, 0x46, 0x54, 0x4d, 0x69, 0x6e, 0x69, 0x6e, 0x67, 0x4f, 0x75,
-	0x74, 0x70, 0x6f, 0x73, 0x74, 0x10, 0x06, 0x12, 0x15, 0x0a, 0x11, 0x46, 0x54, 0x4f, 0x63, 0x65,
-	0x6c, 0x6c, 0x75, 0x73, 0x53, 0x74, 0x61, 0x72, 0x70, 0x6f, 0x72, 0x74, 0x10, 0x07, 0x12, 0x13,
-	0x0a, 0x0f, 0x46, 0x54, 0x4f, 0x72, 0x62, 0x69, 0x73, 0x53, 0x74, 0x61, 0x72, 0x70, 0x6f, 0x72,
-	0x74, 0x10, 0x08, 0x12, 0x17, 0x0a, 0x13, 0x46, 0x54, 0x53, 0x63, 0x69, 0x65, 0x6e, 0x74, 0x69,
-	0x66, 0x69, 0x63, 0x4f, 0x75, 0x74, 0x70, 0x6f, 0x73, 0x74, 0x10, 0x09, 0x12, 0x16, 0x0a, 0x12,
-	0x46, 0x54, 0x50, 0x6c, 0x61, 0x6e, 0x65, 0x74, 0x61, 0x72, 0x79, 0x4f, 0x75, 0x74, 0x70, 0x6f,
-	0x73, 0x74, 0x10, 0x0a, 0x12, 0x13, 0x0a, 0x0f, 0x46, 0x54, 0x50, 0x6c, 0x61, 0x6e, 0x65, 0x74,
-	0x61, 0x72, 0x79, 0x50, 0x6f, 0x72, 0x74, 0x10, 0x0b, 0x12, 0x19, 0x0a, 0x15, 0x46, 0x54, 0x50,
-	0x6c, 0x61, 0x6e, 0x65, 0x74, 0x61, 0x72, 0x79, 0x53, 0x65, 0x74, 0x74, 0x6c, 0x65, 0x6d, 0x65,
-	0x6e, 0x74, 0x10, 0x0c, 0x12, 0x0e, 0x0a, 0x0a, 0x46, 0x54, 0x4d, 0x65, 0x67, 0x61, 0x73, 0x68,
-	0x69, 0x70, 0x10, 0x0d, 0x12, 0x12, 0x0a, 0x0e, 0x46, 0x54, 0x41, 0x73, 0x74, 0x65, 0x72, 0x6f,
-	0x69, 0x64, 0x42, 0x61, 0x73, 0x65, 0x10, 0x0e, 0x12, 0x12, 0x0a, 0x0e, 0x46, 0x54, 0x46, 0x6c,
-	0x65, 0x65, 0x74, 0x43, 0x61, 0x72, 0x72, 0x69, 0x65, 0x72, 0x10, 0x0f, 0x2a, 0xcd, 0x01, 0x0a,
-	0x0a, 0x46, 0x65, 0x61, 0x74, 0x75, 0x72, 0x65, 0x42, 0x69, 0x74, 0x12, 0x0a, 0x0a, 0x06, 0x4d,
-	0x61, 0x72, 0x6b, 0x65, 0x74, 0x10, 0x00, 0x12, 0x0f, 0x0a, 0x0b, 0x42, 0x6c, 0x61, 0x63, 0x6b,
-	0x4d, 0x61, 0x72, 0x6b, 0x65, 0x74, 0x10, 0x01, 0x12, 0x0f, 0x0a, 0x0b, 0x43, 0x6f, 0x6d, 0x6d,
-	0x6f, 0x64, 0x69, 0x74, 0x69, 0x65, 0x73, 0x10, 0x02, 0x12, 0x0b, 0x0a, 0x07, 0x44, 0x6f, 0x63,
-	0x6b, 0x69, 0x6e, 0x67, 0x10, 0x03, 0x12, 0x09, 0x0a, 0x05, 0x46, 0x6c, 0x65, 0x65, 0x74, 0x10,
-	0x04, 0x12, 0x0c, 0x0a, 0x08, 0x4c, 0x61, 0x72, 0x67, 0x65, 0x50, 0x61, 0x64, 0x10, 0x05, 0x12,
-	0x0d, 0x0a, 0x09, 0x4d, 0x65, 0x64, 0x69, 0x75, 0x6d, 0x50, 0x61, 0x64, 0x10, 0x06, 0x12, 0x0e,
-	0x0a, 0x0a, 0x4f, 0x75, 0x74, 0x66, 0x69, 0x74, 0x74, 0x69, 0x6e, 0x67, 0x10, 0x07, 0x12, 0x0d,
-	0x0a, 0x09, 0x50, 0x6c, 0x61, 0x6e, 0x65, 0x74, 0x61, 0x72, 0x79, 0x10, 0x08, 0x12, 0x09, 0x0a,
-	0x05, 0x52, 0x65, 0x61, 0x72, 0x6d, 0x10, 0x09, 0x12, 0x0a, 0x0a, 0x06, 0x52, 0x65, 0x66, 0x75,
-	0x65, 0x6c, 0x10, 0x0a, 0x12, 0x0a, 0x0a, 0x06, 0x52, 0x65, 0x70, 0x61, 0x69, 0x72, 0x10, 0x0b,
-	0x12, 0x0c, 0x0a, 0x08, 0x53, 0x68, 0x69, 0x70, 0x79, 0x61, 0x72, 0x64, 0x10, 0x0c, 0x12, 0x0c,
-	0x0a, 0x08, 0x53, 0x6d, 0x61, 0x6c, 0x6c, 0x50, 0x61, 0x64, 0x10, 0x0d, 0x42, 0x10, 0x0a, 0x01,
-	0x2e, 0x5a, 0x0b, 0x2e, 0x3b, 0x67, 0x6f, 0x6d, 0x73, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x62, 0x06,
-	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x70, 0x5f, 0x75, 0x74, 0x63, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0c, 0x74, 0x69, 0x6d,
+	0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x55, 0x74, 0x63, 0x12, 0x19, 0x0a, 0x08, 0x70, 0x72, 0x69,
+	0x63, 0x65, 0x5f, 0x63, 0x72, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x07, 0x70, 0x72, 0x69,
+	0x63, 0x65, 0x43, 0x72, 0x22, 0x6c, 0x0a, 0x12, 0x46, 0x61, 0x63, 0x69, 0x6c, 0x69, 0x74, 0x79,
+	0x4f, 0x75, 0x74, 0x66, 0x69, 0x74, 0x74, 0x69, 0x6e, 0x67, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x02, 0x69, 0x64, 0x12, 0x23, 0x0a, 0x0d, 0x74, 0x69,
+	0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x5f, 0x75, 0x74, 0x63, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x04, 0x52, 0x0c, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x55, 0x74, 0x63, 0x12,
+	0x21, 0x0a, 0x0a, 0x6d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x5f, 0x69, 0x64, 0x73, 0x18, 0x03, 0x20,
+	0x03, 0x28, 0x0d, 0x42, 0x02, 0x10, 0x01, 0x52, 0x09, 0x6d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x49,
+	0x64, 0x73, 0x22, 0x66, 0x0a, 0x10, 0x46, 0x61, 0x63, 0x69, 0x6c, 0x69, 0x74, 0x79, 0x53, 0x68,
+	0x69, 0x70, 0x79, 0x61, 0x72, 0x64, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x0d, 0x52, 0x02, 0x69, 0x64, 0x12, 0x23, 0x0a, 0x0d, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74,
+	0x61, 0x6d, 0x70, 0x5f, 0x75, 0x74, 0x63, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0c, 0x74,
+	0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x55, 0x74, 0x63, 0x12, 0x1d, 0x0a, 0x08, 0x73,
+	0x68, 0x69, 0x70, 0x5f, 0x69, 0x64, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0d, 0x42, 0x02, 0x10,
+	0x01, 0x52, 0x07, 0x73, 0x68, 0x69, 0x70, 0x49, 0x64, 0x73, 0x2a, 0xf7, 0x01, 0x0a, 0x0e, 0x47,
+	0x6f, 0x76, 0x65, 0x72, 0x6e, 0x6d, 0x65, 0x6e, 0x74, 0x54, 0x79, 0x70, 0x65, 0x12, 0x0b, 0x0a,
+	0x07, 0x47, 0x6f, 0x76, 0x4e, 0x6f, 0x6e, 0x65, 0x10, 0x00, 0x12, 0x0e, 0x0a, 0x0a, 0x47, 0x6f,
+	0x76, 0x41, 0x6e, 0x61, 0x72, 0x63, 0x68, 0x79, 0x10, 0x01, 0x12, 0x10, 0x0a, 0x0c, 0x47, 0x6f,
+	0x76, 0x43, 0x6f, 0x6d, 0x6d, 0x75, 0x6e, 0x69, 0x73, 0x6d, 0x10, 0x02, 0x12, 0x12, 0x0a, 0x0e,
+	0x47, 0x6f, 0x76, 0x43, 0x6f, 0x6e, 0x66, 0x65, 0x64, 0x65, 0x72, 0x61, 0x63, 0x79, 0x10, 0x03,
+	0x12, 0x12, 0x0a, 0x0e, 0x47, 0x6f, 0x76, 0x43, 0x6f, 0x6f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69,
+	0x76, 0x65, 0x10, 0x04, 0x12, 0x10, 0x0a, 0x0c, 0x47, 0x6f, 0x76, 0x43, 0x6f, 0x72, 0x70, 0x6f,
+	0x72, 0x61, 0x74, 0x65, 0x10, 0x05, 0x12, 0x10, 0x0a, 0x0c, 0x47, 0x6f, 0x76, 0x44, 0x65, 0x6d,
+	0x6f, 0x63, 0x72, 0x61, 0x63, 0x79, 0x10, 0x06, 0x12, 0x13, 0x0a, 0x0f, 0x47, 0x6f, 0x76, 0x44,
+	0x69, 0x63, 0x74, 0x61, 0x74, 0x6f, 0x72, 0x73, 0x68, 0x69, 0x70, 0x10, 0x07, 0x12, 0x0d, 0x0a,
+	0x09, 0x47, 0x6f, 0x76, 0x46, 0x65, 0x75, 0x64, 0x61, 0x6c, 0x10, 0x08, 0x12, 0x10, 0x0a, 0x0c,
+	0x47, 0x6f, 0x76, 0x50, 0x61, 0x74, 0x72, 0x6f, 0x6e, 0x61, 0x67, 0x65, 0x10, 0x09, 0x12, 0x0d,
+	0x0a, 0x09, 0x47, 0x6f, 0x76, 0x50, 0x72, 0x69, 0x73, 0x6f, 0x6e, 0x10, 0x0a, 0x12, 0x13, 0x0a,
+	0x0f, 0x47, 0x6f, 0x76, 0x50, 0x72, 0x69, 0x73, 0x6f, 0x6e, 0x43, 0x6f, 0x6c, 0x6f, 0x6e, 0x79,
+	0x10, 0x0b, 0x12, 0x10, 0x0a, 0x0c, 0x47, 0x6f, 0x76, 0x54, 0x68, 0x65, 0x6f, 0x63, 0x72, 0x61,
+	0x63, 0x79, 0x10, 0x0c, 0x2a, 0x89, 0x01, 0x0a, 0x0e, 0x41, 0x6c, 0x6c, 0x65, 0x67, 0x69, 0x61,
+	0x6e, 0x63, 0x65, 0x54, 0x79, 0x70, 0x65, 0x12, 0x0d, 0x0a, 0x09, 0x41, 0x6c, 0x6c, 0x65, 0x67,
+	0x4e, 0x6f, 0x6e, 0x65, 0x10, 0x00, 0x12, 0x11, 0x0a, 0x0d, 0x41, 0x6c, 0x6c, 0x65, 0x67, 0x41,
+	0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x63, 0x65, 0x10, 0x01, 0x12, 0x0f, 0x0a, 0x0b, 0x41, 0x6c, 0x6c,
+	0x65, 0x67, 0x45, 0x6d, 0x70, 0x69, 0x72, 0x65, 0x10, 0x02, 0x12, 0x13, 0x0a, 0x0f, 0x41, 0x6c,
+	0x6c, 0x65, 0x67, 0x46, 0x65, 0x64, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x10, 0x03, 0x12,
+	0x14, 0x0a, 0x10, 0x41, 0x6c, 0x6c, 0x65, 0x67, 0x49, 0x6e, 0x64, 0x65, 0x70, 0x65, 0x6e, 0x64,
+	0x65, 0x6e, 0x74, 0x10, 0x04, 0x12, 0x19, 0x0a, 0x15, 0x41, 0x6c, 0x6c, 0x65, 0x67, 0x50, 0x69,
+	0x6c, 0x6f, 0x74, 0x73, 0x46, 0x65, 0x64, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x10, 0x05,
+	0x2a, 0x6d, 0x0a, 0x0d, 0x53, 0x65, 0x63, 0x75, 0x72, 0x69, 0x74, 0x79, 0x4c, 0x65, 0x76, 0x65,
+	0x6c, 0x12, 0x10, 0x0a, 0x0c, 0x53, 0x65, 0x63, 0x75, 0x72, 0x69, 0x74, 0x79, 0x4e, 0x6f, 0x6e,
+	0x65, 0x10, 0x00, 0x12, 0x13, 0x0a, 0x0f, 0x53, 0x65, 0x63, 0x75, 0x72, 0x69, 0x74, 0x79, 0x41,
+	0x6e, 0x61, 0x72, 0x63, 0x68, 0x79, 0x10, 0x01, 0x12, 0x0f, 0x0a, 0x0b, 0x53, 0x65, 0x63, 0x75,
+	0x72, 0x69, 0x74, 0x79, 0x4c, 0x6f, 0x77, 0x10, 0x02, 0x12, 0x12, 0x0a, 0x0e, 0x53, 0x65, 0x63,
+	0x75, 0x72, 0x69, 0x74, 0x79, 0x4d, 0x65, 0x64, 0x69, 0x75, 0x6d, 0x10, 0x03, 0x12, 0x10, 0x0a,
+	0x0c, 0x53, 0x65, 0x63, 0x75, 0x72, 0x69, 0x74, 0x79, 0x48, 0x69, 0x67, 0x68, 0x10, 0x04, 0x2a,
+	0xac, 0x02, 0x0a, 0x0b, 0x45, 0x63, 0x6f, 0x6e, 0x6f, 0x6d, 0x79, 0x54, 0x79, 0x70, 0x65, 0x12,
+	0x0b, 0x0a, 0x07, 0x45, 0x63, 0x6f, 0x4e, 0x6f, 0x6e, 0x65, 0x10, 0x00, 0x12, 0x12, 0x0a, 0x0e,
+	0x45, 0x63, 0x6f, 0x41, 0x67, 0x72, 0x69, 0x63, 0x75, 0x6c, 0x74, 0x75, 0x72, 0x65, 0x10, 0x01,
+	0x12, 0x0d, 0x0a, 0x09, 0x45, 0x63, 0x6f, 0x43, 0x6f, 0x6c, 0x6f, 0x6e, 0x79, 0x10, 0x02, 0x12,
+	0x11, 0x0a, 0x0d, 0x45, 0x63, 0x6f, 0x45, 0x78, 0x74, 0x72, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e,
+	0x10, 0x03, 0x12, 0x0f, 0x0a, 0x0b, 0x45, 0x63, 0x6f, 0x48, 0x69, 0x67, 0x68, 0x54, 0x65, 0x63,
+	0x68, 0x10, 0x04, 0x12, 0x11, 0x0a, 0x0d, 0x45, 0x63, 0x6f, 0x49, 0x6e, 0x64, 0x75, 0x73, 0x74,
+	0x72, 0x69, 0x61, 0x6c, 0x10, 0x05, 0x12, 0x0f, 0x0a, 0x0b, 0x45, 0x63, 0x6f, 0x4d, 0x69, 0x6c,
+	0x69, 0x74, 0x61, 0x72, 0x79, 0x10, 0x06, 0x12, 0x0f, 0x0a, 0x0b, 0x45, 0x63, 0x6f, 0x52, 0x65,
+	0x66, 0x69, 0x6e, 0x65, 0x72, 0x79, 0x10, 0x07, 0x12, 0x0e, 0x0a, 0x0a, 0x45, 0x63, 0x6f, 0x53,
+	0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x10, 0x08, 0x12, 0x13, 0x0a, 0x0f, 0x45, 0x63, 0x6f, 0x54,
+	0x65, 0x72, 0x72, 0x61, 0x66, 0x6f, 0x72, 0x6d, 0x69, 0x6e, 0x67, 0x10, 0x09, 0x12, 0x0e, 0x0a,
+	0x0a, 0x45, 0x63, 0x6f, 0x54, 0x6f, 0x75, 0x72, 0x69, 0x73, 0x6d, 0x10, 0x0a, 0x12, 0x0d, 0x0a,
+	0x09, 0x45, 0x63, 0x6f, 0x50, 0x72, 0x69, 0x73, 0x6f, 0x6e, 0x10, 0x0b, 0x12, 0x0e, 0x0a, 0x0a,
+	0x45, 0x63, 0x6f, 0x44, 0x61, 0x6d, 0x61, 0x67, 0x65, 0x64, 0x10, 0x0c, 0x12, 0x0d, 0x0a, 0x09,
+	0x45, 0x63, 0x6f, 0x52, 0x65, 0x73, 0x63, 0x75, 0x65, 0x10, 0x0d, 0x12, 0x0d, 0x0a, 0x09, 0x45,
+	0x63, 0x6f, 0x52, 0x65, 0x70, 0x61, 0x69, 0x72, 0x10, 0x0e, 0x12, 0x0e, 0x0a, 0x0a, 0x45, 0x63,
+	0x6f, 0x43, 0x61, 0x72, 0x72, 0x69, 0x65, 0x72, 0x10, 0x0f, 0x12, 0x12, 0x0a, 0x0e, 0x45, 0x63,
+	0x6f, 0x45, 0x6e, 0x67, 0x69, 0x6e, 0x65, 0x65, 0x72, 0x69, 0x6e, 0x67, 0x10, 0x10, 0x2a, 0xb0,
+	0x04, 0x0a, 0x09, 0x53, 0x74, 0x61, 0x74, 0x65, 0x54, 0x79, 0x70, 0x65, 0x12, 0x0d, 0x0a, 0x09,
+	0x53, 0x74, 0x61, 0x74, 0x65, 0x4e, 0x6f, 0x6e, 0x65, 0x10, 0x00, 0x12, 0x0d, 0x0a, 0x09, 0x53,
+	0x74, 0x61, 0x74, 0x65, 0x42, 0x6f, 0x6f, 0x6d, 0x10, 0x01, 0x12, 0x0d, 0x0a, 0x09, 0x53, 0x74,
+	0x61, 0x74, 0x65, 0x42, 0x75, 0x73, 0x74, 0x10, 0x02, 0x12, 0x14, 0x0a, 0x10, 0x53, 0x74, 0x61,
+	0x74, 0x65, 0x43, 0x69, 0x76, 0x69, 0x6c, 0x55, 0x6e, 0x72, 0x65, 0x73, 0x74, 0x10, 0x03, 0x12,
+	0x11, 0x0a, 0x0d, 0x53, 0x74, 0x61, 0x74, 0x65, 0x43, 0x69, 0x76, 0x69, 0x6c, 0x57, 0x61, 0x72,
+	0x10, 0x04, 0x12, 0x11, 0x0a, 0x0d, 0x53, 0x74, 0x61, 0x74, 0x65, 0x45, 0x6c, 0x65, 0x63, 0x74,
+	0x69, 0x6f, 0x6e, 0x10, 0x05, 0x12, 0x12, 0x0a, 0x0e, 0x53, 0x74, 0x61, 0x74, 0x65, 0x45, 0x78,
+	0x70, 0x61, 0x6e, 0x73, 0x69, 0x6f, 0x6e, 0x10, 0x06, 0x12, 0x0f, 0x0a, 0x0b, 0x53, 0x74, 0x61,
+	0x74, 0x65, 0x46, 0x61, 0x6d, 0x69, 0x6e, 0x65, 0x10, 0x07, 0x12, 0x13, 0x0a, 0x0f, 0x53, 0x74,
+	0x61, 0x74, 0x65, 0x49, 0x6e, 0x76, 0x65, 0x73, 0x74, 0x6d, 0x65, 0x6e, 0x74, 0x10, 0x08, 0x12,
+	0x11, 0x0a, 0x0d, 0x53, 0x74, 0x61, 0x74, 0x65, 0x4c, 0x6f, 0x63, 0x6b, 0x64, 0x6f, 0x77, 0x6e,
+	0x10, 0x09, 0x12, 0x11, 0x0a, 0x0d, 0x53, 0x74, 0x61, 0x74, 0x65, 0x4f, 0x75, 0x74, 0x62, 0x72,
+	0x65, 0x61, 0x6b, 0x10, 0x0a, 0x12, 0x10, 0x0a, 0x0c, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x65,
+	0x74, 0x72, 0x65, 0x61, 0x74, 0x10, 0x0b, 0x12, 0x0c, 0x0a, 0x08, 0x53, 0x74, 0x61, 0x74, 0x65,
+	0x57, 0x61, 0x72, 0x10, 0x0c, 0x12, 0x15, 0x0a, 0x11, 0x53, 0x74, 0x61, 0x74, 0x65, 0x43, 0x69,
+	0x76, 0x69, 0x6c, 0x4c, 0x69, 0x62, 0x65, 0x72, 0x74, 0x79, 0x10, 0x0d, 0x12, 0x15, 0x0a, 0x11,
+	0x53, 0x74, 0x61, 0x74, 0x65, 0x50, 0x69, 0x72, 0x61, 0x74, 0x65, 0x41, 0x74, 0x74, 0x61, 0x63,
+	0x6b, 0x10, 0x0e, 0x12, 0x0f, 0x0a, 0x0b, 0x53, 0x74, 0x61, 0x74, 0x65, 0x42, 0x6c, 0x69, 0x67,
+	0x68, 0x74, 0x10, 0x0f, 0x12, 0x10, 0x0a, 0x0c, 0x53, 0x74, 0x61, 0x74, 0x65, 0x44, 0x72, 0x6f,
+	0x75, 0x67, 0x68, 0x74, 0x10, 0x10, 0x12, 0x1e, 0x0a, 0x1a, 0x53, 0x74, 0x61, 0x74, 0x65, 0x49,
+	0x6e, 0x66, 0x72, 0x61, 0x73, 0x74, 0x72, 0x75, 0x63, 0x74, 0x75, 0x72, 0x65, 0x46, 0x61, 0x69,
+	0x6c, 0x75, 0x72, 0x65, 0x10, 0x11, 0x12, 0x18, 0x0a, 0x14, 0x53, 0x74, 0x61, 0x74, 0x65, 0x4e,
+	0x61, 0x74, 0x75, 0x72, 0x61, 0x6c, 0x44, 0x69, 0x73, 0x61, 0x73, 0x74, 0x65, 0x72, 0x10, 0x12,
+	0x12, 0x16, 0x0a, 0x12, 0x53, 0x74, 0x61, 0x74, 0x65, 0x50, 0x75, 0x62, 0x6c, 0x69, 0x63, 0x48,
+	0x6f, 0x6c, 0x69, 0x64, 0x61, 0x79, 0x10, 0x13, 0x12, 0x12, 0x0a, 0x0e, 0x53, 0x74, 0x61, 0x74,
+	0x65, 0x54, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x69, 0x73, 0x6d, 0x10, 0x14, 0x12, 0x10, 0x0a, 0x0c,
+	0x53, 0x74, 0x61, 0x74, 0x65, 0x43, 0x6f, 0x6c, 0x64, 0x57, 0x61, 0x72, 0x10, 0x15, 0x12, 0x15,
+	0x0a, 0x11, 0x53, 0x74, 0x61, 0x74, 0x65, 0x43, 0x6f, 0x6c, 0x6f, 0x6e, 0x69, 0x73, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x10, 0x16, 0x12, 0x16, 0x0a, 0x12, 0x53, 0x74, 0x61, 0x74, 0x65, 0x48, 0x69,
+	0x73, 0x74, 0x6f, 0x72, 0x69, 0x63, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x10, 0x17, 0x12, 0x13, 0x0a,
+	0x0f, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x65, 0x76, 0x6f, 0x6c, 0x75, 0x74, 0x69, 0x6f, 0x6e,
+	0x10, 0x18, 0x12, 0x1a, 0x0a, 0x16, 0x53, 0x74, 0x61, 0x74, 0x65, 0x54, 0x65, 0x63, 0x68, 0x6e,
+	0x6f, 0x6c, 0x6f, 0x67, 0x69, 0x63, 0x61, 0x6c, 0x4c, 0x65, 0x61, 0x70, 0x10, 0x19, 0x12, 0x11,
+	0x0a, 0x0d, 0x53, 0x74, 0x61, 0x74, 0x65, 0x54, 0x72, 0x61, 0x64, 0x65, 0x57, 0x61, 0x72, 0x10,
+	0x1a, 0x2a, 0xec, 0x02, 0x0a, 0x0c, 0x46, 0x61, 0x63, 0x69, 0x6c, 0x69, 0x74, 0x79, 0x54, 0x79,
+	0x70, 0x65, 0x12, 0x0a, 0x0a, 0x06, 0x46, 0x54, 0x4e, 0x6f, 0x6e, 0x65, 0x10, 0x00, 0x12, 0x15,
+	0x0a, 0x11, 0x46, 0x54, 0x43, 0x69, 0x76, 0x69, 0x6c, 0x69, 0x61, 0x6e, 0x4f, 0x75, 0x74, 0x70,
+	0x6f, 0x73, 0x74, 0x10, 0x01, 0x12, 0x17, 0x0a, 0x13, 0x46, 0x54, 0x43, 0x6f, 0x6d, 0x6d, 0x65,
+	0x72, 0x63, 0x69, 0x61, 0x6c, 0x4f, 0x75, 0x74, 0x70, 0x6f, 0x73, 0x74, 0x10, 0x02, 0x12, 0x16,
+	0x0a, 0x12, 0x46, 0x54, 0x43, 0x6f, 0x72, 0x69, 0x6f, 0x6c, 0x69, 0x73, 0x53, 0x74, 0x61, 0x72,
+	0x70, 0x6f, 0x72, 0x74, 0x10, 0x03, 0x12, 0x17, 0x0a, 0x13, 0x46, 0x54, 0x49, 0x6e, 0x64, 0x75,
+	0x73, 0x74, 0x72, 0x69, 0x61, 0x6c, 0x4f, 0x75, 0x74, 0x70, 0x6f, 0x73, 0x74, 0x10, 0x04, 0x12,
+	0x15, 0x0a, 0x11, 0x46, 0x54, 0x4d, 0x69, 0x6c, 0x69, 0x74, 0x61, 0x72, 0x79, 0x4f, 0x75, 0x74,
+	0x70, 0x6f, 0x73, 0x74, 0x10, 0x05, 0x12, 0x13, 0x0a, 0x0f, 0x46, 0x54, 0x4d, 0x69, 0x6e, 0x69,
+	0x6e, 0x67, 0x4f, 0x75, 0x74, 0x70, 0x6f, 0x73, 0x74, 0x10, 0x06, 0x12, 0x15, 0x0a, 0x11, 0x46,
+	0x54, 0x4f, 0x63, 0x65, 0x6c, 0x6c, 0x75, 0x73, 0x53, 0x74, 0x61, 0x72, 0x70, 0x6f, 0x72, 0x74,
+	0x10, 0x07, 0x12, 0x13, 0x0a, 0x0f, 0x46, 0x54, 0x4f, 0x72, 0x62, 0x69, 0x73, 0x53, 0x74, 0x61,
+	0x72, 0x70, 0x6f, 0x72, 0x74, 0x10, 0x08, 0x12, 0x17, 0x0a, 0x13, 0x46, 0x54, 0x53, 0x63, 0x69,
+	0x65, 0x6e, 0x74, 0x69, 0x66, 0x69, 0x63, 0x4f, 0x75, 0x74, 0x70, 0x6f, 0x73, 0x74, 0x10, 0x09,
+	0x12, 0x16, 0x0a, 0x12, 0x46, 0x54, 0x50, 0x6c, 0x61, 0x6e, 0x65, 0x74, 0x61, 0x72, 0x79, 0x4f,
+	0x75, 0x74, 0x70, 0x6f, 0x73, 0x74, 0x10, 0x0a, 0x12, 0x13, 0x0a, 0x0f, 0x46, 0x54, 0x50, 0x6c,
+	0x61, 0x6e, 0x65, 0x74, 0x61, 0x72, 0x79, 0x50, 0x6f, 0x72, 0x74, 0x10, 0x0b, 0x12, 0x19, 0x0a,
+	0x15, 0x46, 0x54, 0x50, 0x6c, 0x61, 0x6e, 0x65, 0x74, 0x61, 0x72, 0x79, 0x53, 0x65, 0x74, 0x74,
+	0x6c, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x10, 0x0c, 0x12, 0x0e, 0x0a, 0x0a, 0x46, 0x54, 0x4d, 0x65,
+	0x67, 0x61, 0x73, 0x68, 0x69, 0x70, 0x10, 0x0d, 0x12, 0x12, 0x0a, 0x0e, 0x46, 0x54, 0x41, 0x73,
+	0x74, 0x65, 0x72, 0x6f, 0x69, 0x64, 0x42, 0x61, 0x73, 0x65, 0x10, 0x0e, 0x12, 0x12, 0x0a, 0x0e,
+	0x46, 0x54, 0x46, 0x6c, 0x65, 0x65, 0x74, 0x43, 0x61, 0x72, 0x72, 0x69, 0x65, 0x72, 0x10, 0x0f,
+	0x2a, 0xcd, 0x01, 0x0a, 0x0a, 0x46, 0x65, 0x61, 0x74, 0x75, 0x72, 0x65, 0x42, 0x69, 0x74, 0x12,
+	0x0a, 0x0a, 0x06, 0x4d, 0x61, 0x72, 0x6b, 0x65, 0x74, 0x10, 0x00, 0x12, 0x0f, 0x0a, 0x0b, 0x42,
+	0x6c, 0x61, 0x63, 0x6b, 0x4d, 0x61, 0x72, 0x6b, 0x65, 0x74, 0x10, 0x01, 0x12, 0x0f, 0x0a, 0x0b,
+	0x43, 0x6f, 0x6d, 0x6d, 0x6f, 0x64, 0x69, 0x74, 0x69, 0x65, 0x73, 0x10, 0x02, 0x12, 0x0b, 0x0a,
+	0x07, 0x44, 0x6f, 0x63, 0x6b, 0x69, 0x6e, 0x67, 0x10, 0x03, 0x12, 0x09, 0x0a, 0x05, 0x46, 0x6c,
+	0x65, 0x65, 0x74, 0x10, 0x04, 0x12, 0x0c, 0x0a, 0x08, 0x4c, 0x61, 0x72, 0x67, 0x65, 0x50, 0x61,
+	0x64, 0x10, 0x05, 0x12, 0x0d, 0x0a, 0x09, 0x4d, 0x65, 0x64, 0x69, 0x75, 0x6d, 0x50, 0x61, 0x64,
+	0x10, 0x06, 0x12, 0x0e, 0x0a, 0x0a, 0x4f, 0x75, 0x74, 0x66, 0x69, 0x74, 0x74, 0x69, 0x6e, 0x67,
+	0x10, 0x07, 0x12, 0x0d, 0x0a, 0x09, 0x50, 0x6c, 0x61, 0x6e, 0x65, 0x74, 0x61, 0x72, 0x79, 0x10,
+	0x08, 0x12, 0x09, 0x0a, 0x05, 0x52, 0x65, 0x61, 0x72, 0x6d, 0x10, 0x09, 0x12, 0x0a, 0x0a, 0x06,
+	0x52, 0x65, 0x66, 0x75, 0x65, 0x6c, 0x10, 0x0a, 0x12, 0x0a, 0x0a, 0x06, 0x52, 0x65, 0x70, 0x61,
+	0x69, 0x72, 0x10, 0x0b, 0x12, 0x0c, 0x0a, 0x08, 0x53, 0x68, 0x69, 0x70, 0x79, 0x61, 0x72, 0x64,
+	0x10, 0x0c, 0x12, 0x0c, 0x0a, 0x08, 0x53, 0x6d, 0x61, 0x6c, 0x6c, 0x50, 0x61, 0x64, 0x10, 0x0d,
+	0x2a, 0x68, 0x0a, 0x0d, 0x4d, 0x61, 0x72, 0x6b, 0x65, 0x74, 0x42, 0x72, 0x61, 0x63, 0x6b, 0x65,
+	0x74, 0x12, 0x12, 0x0a, 0x0e, 0x42, 0x72, 0x61, 0x63, 0x6b, 0x65, 0x74, 0x55, 0x6e, 0x6b, 0x6e,
+	0x6f, 0x77, 0x6e, 0x10, 0x00, 0x12, 0x0f, 0x0a, 0x0b, 0x42, 0x72, 0x61, 0x63, 0x6b, 0x65, 0x74,
+	0x4e, 0x6f, 0x6e, 0x65, 0x10, 0x01, 0x12, 0x0e, 0x0a, 0x0a, 0x42, 0x72, 0x61, 0x63, 0x6b, 0x65,
+	0x74, 0x4c, 0x6f, 0x77, 0x10, 0x02, 0x12, 0x11, 0x0a, 0x0d, 0x42, 0x72, 0x61, 0x63, 0x6b, 0x65,
+	0x74, 0x4d, 0x65, 0x64, 0x69, 0x75, 0x6d, 0x10, 0x03, 0x12, 0x0f, 0x0a, 0x0b, 0x42, 0x72, 0x61,
+	0x63, 0x6b, 0x65, 0x74, 0x48, 0x69, 0x67, 0x68, 0x10, 0x04, 0x42, 0x10, 0x0a, 0x01, 0x2e, 0x5a,
+	0x0b, 0x2e, 0x3b, 0x67, 0x6f, 0x6d, 0x73, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x62, 0x06, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -1397,42 +2320,57 @@ func file_gomschema_proto_rawDescGZIP() []byte {
 	return file_gomschema_proto_rawDescData
 }
 
-var file_gomschema_proto_enumTypes = make([]protoimpl.EnumInfo, 7)
-var file_gomschema_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_gomschema_proto_enumTypes = make([]protoimpl.EnumInfo, 10)
+var file_gomschema_proto_msgTypes = make([]protoimpl.MessageInfo, 13)
 var file_gomschema_proto_goTypes = []interface{}{
-	(GovernmentType)(0),      // 0: gomschema.GovernmentType
-	(AllegianceType)(0),      // 1: gomschema.AllegianceType
-	(SecurityLevel)(0),       // 2: gomschema.SecurityLevel
-	(FacilityType)(0),        // 3: gomschema.FacilityType
-	(FeatureBit)(0),          // 4: gomschema.FeatureBit
-	(Header_Type)(0),         // 5: gomschema.Header.Type
-	(Commodity_Category)(0),  // 6: gomschema.Commodity.Category
-	(*Header)(nil),           // 7: gomschema.Header
-	(*Commodity)(nil),        // 8: gomschema.Commodity
-	(*Coordinate)(nil),       // 9: gomschema.Coordinate
-	(*System)(nil),           // 10: gomschema.System
-	(*Facility)(nil),         // 11: gomschema.Facility
-	(*CommodityListing)(nil), // 12: gomschema.CommodityListing
-	(*FacilityListing)(nil),  // 13: gomschema.FacilityListing
-	nil,                      // 14: gomschema.Header.UserdataEntry
+	(GovernmentType)(0),        // 0: gomschema.GovernmentType
+	(AllegianceType)(0),        // 1: gomschema.AllegianceType
+	(SecurityLevel)(0),         // 2: gomschema.SecurityLevel
+	(EconomyType)(0),           // 3: gomschema.EconomyType
+	(StateType)(0),             // 4: gomschema.StateType
+	(FacilityType)(0),          // 5: gomschema.FacilityType
+	(FeatureBit)(0),            // 6: gomschema.FeatureBit
+	(MarketBracket)(0),         // 7: gomschema.MarketBracket
+	(Header_Type)(0),           // 8: gomschema.Header.Type
+	(Commodity_Category)(0),    // 9: gomschema.Commodity.Category
+	(*Header)(nil),             // 10: gomschema.Header
+	(*Commodity)(nil),          // 11: gomschema.Commodity
+	(*Coordinate)(nil),         // 12: gomschema.Coordinate
+	(*FactionPresence)(nil),    // 13: gomschema.FactionPresence
+	(*System)(nil),             // 14: gomschema.System
+	(*Facility)(nil),           // 15: gomschema.Facility
+	(*CommodityListing)(nil),   // 16: gomschema.CommodityListing
+	(*FacilityListing)(nil),    // 17: gomschema.FacilityListing
+	(*Module)(nil),             // 18: gomschema.Module
+	(*Ship)(nil),               // 19: gomschema.Ship
+	(*FacilityOutfitting)(nil), // 20: gomschema.FacilityOutfitting
+	(*FacilityShipyard)(nil),   // 21: gomschema.FacilityShipyard
+	nil,                        // 22: gomschema.Header.UserdataEntry
 }
 var file_gomschema_proto_depIdxs = []int32{
-	5,  // 0: gomschema.Header.header_type:type_name -> gomschema.Header.Type
-	14, // 1: gomschema.Header.userdata:type_name -> gomschema.Header.UserdataEntry
-	6,  // 2: gomschema.Commodity.category_id:type_name -> gomschema.Commodity.Category
-	9,  // 3: gomschema.System.position:type_name -> gomschema.Coordinate
-	2,  // 4: gomschema.System.security_level:type_name -> gomschema.SecurityLevel
-	0,  // 5: gomschema.System.government:type_name -> gomschema.GovernmentType
-	1,  // 6: gomschema.System.allegiance:type_name -> gomschema.AllegianceType
-	3,  // 7: gomschema.Facility.facility_type:type_name -> gomschema.FacilityType
-	0,  // 8: gomschema.Facility.government:type_name -> gomschema.GovernmentType
-	1,  // 9: gomschema.Facility.allegiance:type_name -> gomschema.AllegianceType
-	12, // 10: gomschema.FacilityListing.listings:type_name -> gomschema.CommodityListing
-	11, // [11:11] is the sub-list for method output_type
-	11, // [11:11] is the sub-list for method input_type
-	11, // [11:11] is the sub-list for extension type_name
-	11, // [11:11] is the sub-list for extension extendee
-	0,  // [0:11] is the sub-list for field type_name
+	8,  // 0: gomschema.Header.header_type:type_name -> gomschema.Header.Type
+	22, // 1: gomschema.Header.userdata:type_name -> gomschema.Header.UserdataEntry
+	9,  // 2: gomschema.Commodity.category_id:type_name -> gomschema.Commodity.Category
+	4,  // 3: gomschema.FactionPresence.states:type_name -> gomschema.StateType
+	12, // 4: gomschema.System.position:type_name -> gomschema.Coordinate
+	2,  // 5: gomschema.System.security_level:type_name -> gomschema.SecurityLevel
+	0,  // 6: gomschema.System.government:type_name -> gomschema.GovernmentType
+	1,  // 7: gomschema.System.allegiance:type_name -> gomschema.AllegianceType
+	3,  // 8: gomschema.System.primary_economy:type_name -> gomschema.EconomyType
+	4,  // 9: gomschema.System.states:type_name -> gomschema.StateType
+	13, // 10: gomschema.System.factions:type_name -> gomschema.FactionPresence
+	5,  // 11: gomschema.Facility.facility_type:type_name -> gomschema.FacilityType
+	0,  // 12: gomschema.Facility.government:type_name -> gomschema.GovernmentType
+	1,  // 13: gomschema.Facility.allegiance:type_name -> gomschema.AllegianceType
+	3,  // 14: gomschema.Facility.economies:type_name -> gomschema.EconomyType
+	7,  // 15: gomschema.CommodityListing.supply_bracket:type_name -> gomschema.MarketBracket
+	7,  // 16: gomschema.CommodityListing.demand_bracket:type_name -> gomschema.MarketBracket
+	16, // 17: gomschema.FacilityListing.listings:type_name -> gomschema.CommodityListing
+	18, // [18:18] is the sub-list for method output_type
+	18, // [18:18] is the sub-list for method input_type
+	18, // [18:18] is the sub-list for extension type_name
+	18, // [18:18] is the sub-list for extension extendee
+	0,  // [0:18] is the sub-list for field type_name
 }
 
 func init() { file_gomschema_proto_init() }
@@ -1478,7 +2416,7 @@ func file_gomschema_proto_init() {
 			}
 		}
 		file_gomschema_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*System); i {
+			switch v := v.(*FactionPresence); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1490,7 +2428,7 @@ func file_gomschema_proto_init() {
 			}
 		}
 		file_gomschema_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Facility); i {
+			switch v := v.(*System); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1502,7 +2440,7 @@ func file_gomschema_proto_init() {
 			}
 		}
 		file_gomschema_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*CommodityListing); i {
+			switch v := v.(*Facility); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1514,6 +2452,18 @@ func file_gomschema_proto_init() {
 			}
 		}
 		file_gomschema_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CommodityListing); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_gomschema_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*FacilityListing); i {
 			case 0:
 				return &v.state
@@ -1525,14 +2475,62 @@ func file_gomschema_proto_init() {
 				return nil
 			}
 		}
+		file_gomschema_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Module); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_gomschema_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Ship); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_gomschema_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*FacilityOutfitting); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_gomschema_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*FacilityShipyard); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_gomschema_proto_rawDesc,
-			NumEnums:      7,
-			NumMessages:   8,
+			NumEnums:      10,
+			NumMessages:   13,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
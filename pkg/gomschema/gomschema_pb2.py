@@ -20,7 +20,7 @@ DESCRIPTOR = _descriptor.FileDescriptor(
   package='gomschema',
   syntax='proto3',
   serialized_options=_b('\n\001.Z\013.;gomschema'),
-  serialized_pb=_b('\n\x0fgomschema.proto\x12\tgomschema\"\x9f\x02\n\x06Header\x12+\n\x0bheader_type\x18\x01 \x01(\x0e\x32\x16.gomschema.Header.Type\x12\x11\n\x05sizes\x18\x02 \x03(\rB\x02\x10\x01\x12\x0e\n\x06source\x18\x04 \x01(\t\x12\x31\n\x08userdata\x18\x05 \x03(\x0b\x32\x1f.gomschema.Header.UserdataEntry\x1a/\n\rUserdataEntry\x12\x0b\n\x03key\x18\x01 \x01(\t\x12\r\n\x05value\x18\x02 \x01(\x0c:\x02\x38\x01\"[\n\x04Type\x12\x0c\n\x08\x43Invalid\x10\x00\x12\x0b\n\x07\x43Header\x10\x01\x12\x0e\n\nCCommodity\x10\x02\x12\x0b\n\x07\x43System\x10\x03\x12\r\n\tCFacility\x10\x04\x12\x0c\n\x08\x43Listing\x10\x05J\x04\x08\x03\x10\x04\"\xe5\x03\n\tCommodity\x12\n\n\x02id\x18\x01 \x01(\r\x12\x0c\n\x04name\x18\x02 \x01(\t\x12\x15\n\rtimestamp_utc\x18\x03 \x01(\x04\x12\x32\n\x0b\x63\x61tegory_id\x18\x04 \x01(\x0e\x32\x1d.gomschema.Commodity.Category\x12\x0f\n\x07is_rare\x18\x05 \x01(\x08\x12\x19\n\x11is_non_marketable\x18\x06 \x01(\x08\x12\x12\n\naverage_cr\x18\x07 \x01(\r\"\xb2\x02\n\x08\x43\x61tegory\x12\x0b\n\x07\x43\x61tNone\x10\x00\x12\x10\n\x0c\x43\x61tChemicals\x10\x01\x12\x14\n\x10\x43\x61tConsumerItems\x10\x02\x12\x11\n\rCatLegalDrugs\x10\x03\x12\x0c\n\x08\x43\x61tFoods\x10\x04\x12\x1a\n\x16\x43\x61tIndustrialMaterials\x10\x05\x12\x10\n\x0c\x43\x61tMachinery\x10\x06\x12\x10\n\x0c\x43\x61tMedicines\x10\x07\x12\r\n\tCatMetals\x10\x08\x12\x0f\n\x0b\x43\x61tMinerals\x10\t\x12\x0e\n\nCatSlavery\x10\n\x12\x11\n\rCatTechnology\x10\x0b\x12\x0f\n\x0b\x43\x61tTextiles\x10\x0c\x12\x0c\n\x08\x43\x61tWaste\x10\r\x12\x0e\n\nCatWeapons\x10\x0e\x12\x0e\n\nCatUnknown\x10\x0f\x12\x0e\n\nCatSalvage\x10\x10\"-\n\nCoordinate\x12\t\n\x01x\x18\x01 \x01(\x01\x12\t\n\x01y\x18\x02 \x01(\x01\x12\t\n\x01z\x18\x03 \x01(\x01\"\x9b\x02\n\x06System\x12\n\n\x02id\x18\x01 \x01(\r\x12\x0c\n\x04name\x18\x02 \x01(\t\x12\x15\n\rtimestamp_utc\x18\x03 \x01(\x04\x12\'\n\x08position\x18\x04 \x01(\x0b\x32\x15.gomschema.Coordinate\x12\x11\n\tpopulated\x18\x05 \x01(\x08\x12\x14\n\x0cneeds_permit\x18\x06 \x01(\x08\x12\x30\n\x0esecurity_level\x18\x07 \x01(\x0e\x32\x18.gomschema.SecurityLevel\x12-\n\ngovernment\x18\x08 \x01(\x0e\x32\x19.gomschema.GovernmentType\x12-\n\nallegiance\x18\t \x01(\x0e\x32\x19.gomschema.AllegianceType\"\x84\x02\n\x08\x46\x61\x63ility\x12\n\n\x02id\x18\x01 \x01(\r\x12\x11\n\tsystem_id\x18\x02 \x01(\r\x12\x0c\n\x04name\x18\x03 \x01(\t\x12\x15\n\rtimestamp_utc\x18\x04 \x01(\x04\x12.\n\rfacility_type\x18\x05 \x01(\x0e\x32\x17.gomschema.FacilityType\x12\x10\n\x08\x66\x65\x61tures\x18\x06 \x01(\r\x12\x14\n\x0cls_from_star\x18\x07 \x01(\r\x12-\n\ngovernment\x18\x08 \x01(\x0e\x32\x19.gomschema.GovernmentType\x12-\n\nallegiance\x18\t \x01(\x0e\x32\x19.gomschema.AllegianceType\"\x9b\x01\n\x10\x43ommodityListing\x12\x14\n\x0c\x63ommodity_id\x18\x01 \x01(\r\x12\x14\n\x0csupply_units\x18\x02 \x01(\r\x12\x16\n\x0esupply_credits\x18\x03 \x01(\r\x12\x14\n\x0c\x64\x65mand_units\x18\x04 \x01(\r\x12\x16\n\x0e\x64\x65mand_credits\x18\x05 \x01(\r\x12\x15\n\rtimestamp_utc\x18\x06 \x01(\x04\"L\n\x0f\x46\x61\x63ilityListing\x12\n\n\x02id\x18\x01 \x01(\r\x12-\n\x08listings\x18\x02 \x03(\x0b\x32\x1b.gomschema.CommodityListing*\xf7\x01\n\x0eGovernmentType\x12\x0b\n\x07GovNone\x10\x00\x12\x0e\n\nGovAnarchy\x10\x01\x12\x10\n\x0cGovCommunism\x10\x02\x12\x12\n\x0eGovConfederacy\x10\x03\x12\x12\n\x0eGovCooperative\x10\x04\x12\x10\n\x0cGovCorporate\x10\x05\x12\x10\n\x0cGovDemocracy\x10\x06\x12\x13\n\x0fGovDictatorship\x10\x07\x12\r\n\tGovFeudal\x10\x08\x12\x10\n\x0cGovPatronage\x10\t\x12\r\n\tGovPrison\x10\n\x12\x13\n\x0fGovPrisonColony\x10\x0b\x12\x10\n\x0cGovTheocracy\x10\x0c*\x89\x01\n\x0e\x41llegianceType\x12\r\n\tAllegNone\x10\x00\x12\x11\n\rAllegAlliance\x10\x01\x12\x0f\n\x0b\x41llegEmpire\x10\x02\x12\x13\n\x0f\x41llegFederation\x10\x03\x12\x14\n\x10\x41llegIndependent\x10\x04\x12\x19\n\x15\x41llegPilotsFederation\x10\x05*m\n\rSecurityLevel\x12\x10\n\x0cSecurityNone\x10\x00\x12\x13\n\x0fSecurityAnarchy\x10\x01\x12\x0f\n\x0bSecurityLow\x10\x02\x12\x12\n\x0eSecurityMedium\x10\x03\x12\x10\n\x0cSecurityHigh\x10\x04*\xec\x02\n\x0c\x46\x61\x63ilityType\x12\n\n\x06\x46TNone\x10\x00\x12\x15\n\x11\x46TCivilianOutpost\x10\x01\x12\x17\n\x13\x46TCommercialOutpost\x10\x02\x12\x16\n\x12\x46TCoriolisStarport\x10\x03\x12\x17\n\x13\x46TIndustrialOutpost\x10\x04\x12\x15\n\x11\x46TMilitaryOutpost\x10\x05\x12\x13\n\x0f\x46TMiningOutpost\x10\x06\x12\x15\n\x11\x46TOcellusStarport\x10\x07\x12\x13\n\x0f\x46TOrbisStarport\x10\x08\x12\x17\n\x13\x46TScientificOutpost\x10\t\x12\x16\n\x12\x46TPlanetaryOutpost\x10\n\x12\x13\n\x0f\x46TPlanetaryPort\x10\x0b\x12\x19\n\x15\x46TPlanetarySettlement\x10\x0c\x12\x0e\n\nFTMegaship\x10\r\x12\x12\n\x0e\x46TAsteroidBase\x10\x0e\x12\x12\n\x0e\x46TFleetCarrier\x10\x0f*\xcd\x01\n\nFeatureBit\x12\n\n\x06Market\x10\x00\x12\x0f\n\x0b\x42lackMarket\x10\x01\x12\x0f\n\x0b\x43ommodities\x10\x02\x12\x0b\n\x07\x44ocking\x10\x03\x12\t\n\x05\x46leet\x10\x04\x12\x0c\n\x08LargePad\x10\x05\x12\r\n\tMediumPad\x10\x06\x12\x0e\n\nOutfitting\x10\x07\x12\r\n\tPlanetary\x10\x08\x12\t\n\x05Rearm\x10\t\x12\n\n\x06Refuel\x10\n\x12\n\n\x06Repair\x10\x0b\x12\x0c\n\x08Shipyard\x10\x0c\x12\x0c\n\x08SmallPad\x10\rB\x10\n\x01.Z\x0b.;gomschemab\x06proto3')
+  serialized_pb=_b('\n\x0fgomschema.proto\x12\tgomschema\"\xd8\x02\n\x06Header\x12+\n\x0bheader_type\x18\x01 \x01(\x0e\x32\x16.gomschema.Header.Type\x12\x11\n\x05sizes\x18\x02 \x03(\rB\x02\x10\x01\x12\x0e\n\x06source\x18\x04 \x01(\t\x12\x31\n\x08userdata\x18\x05 \x03(\x0b\x32\x1f.gomschema.Header.UserdataEntry\x1a/\n\rUserdataEntry\x12\x0b\n\x03key\x18\x01 \x01(\t\x12\r\n\x05value\x18\x02 \x01(\x0c:\x02\x38\x01\"\x93\x01\n\x04Type\x12\x0c\n\x08\x43Invalid\x10\x00\x12\x0b\n\x07\x43Header\x10\x01\x12\x0e\n\nCCommodity\x10\x02\x12\x0b\n\x07\x43System\x10\x03\x12\r\n\tCFacility\x10\x04\x12\x0c\n\x08\x43Listing\x10\x05\x12\x0b\n\x07\x43Module\x10\x06\x12\t\n\x05\x43Ship\x10\x07\x12\x0f\n\x0b\x43Outfitting\x10\x08\x12\r\n\tCShipyard\x10\tJ\x04\x08\x03\x10\x04\"\xe5\x03\n\tCommodity\x12\n\n\x02id\x18\x01 \x01(\r\x12\x0c\n\x04name\x18\x02 \x01(\t\x12\x15\n\rtimestamp_utc\x18\x03 \x01(\x04\x12\x32\n\x0b\x63\x61tegory_id\x18\x04 \x01(\x0e\x32\x1d.gomschema.Commodity.Category\x12\x0f\n\x07is_rare\x18\x05 \x01(\x08\x12\x19\n\x11is_non_marketable\x18\x06 \x01(\x08\x12\x12\n\naverage_cr\x18\x07 \x01(\r\"\xb2\x02\n\x08\x43\x61tegory\x12\x0b\n\x07\x43\x61tNone\x10\x00\x12\x10\n\x0c\x43\x61tChemicals\x10\x01\x12\x14\n\x10\x43\x61tConsumerItems\x10\x02\x12\x11\n\rCatLegalDrugs\x10\x03\x12\x0c\n\x08\x43\x61tFoods\x10\x04\x12\x1a\n\x16\x43\x61tIndustrialMaterials\x10\x05\x12\x10\n\x0c\x43\x61tMachinery\x10\x06\x12\x10\n\x0c\x43\x61tMedicines\x10\x07\x12\r\n\tCatMetals\x10\x08\x12\x0f\n\x0b\x43\x61tMinerals\x10\t\x12\x0e\n\nCatSlavery\x10\n\x12\x11\n\rCatTechnology\x10\x0b\x12\x0f\n\x0b\x43\x61tTextiles\x10\x0c\x12\x0c\n\x08\x43\x61tWaste\x10\r\x12\x0e\n\nCatWeapons\x10\x0e\x12\x0e\n\nCatUnknown\x10\x0f\x12\x0e\n\nCatSalvage\x10\x10\"-\n\nCoordinate\x12\t\n\x01x\x18\x01 \x01(\x01\x12\t\n\x01y\x18\x02 \x01(\x01\x12\t\n\x01z\x18\x03 \x01(\x01\"b\n\x0f\x46\x61\x63tionPresence\x12\x12\n\nfaction_id\x18\x01 \x01(\r\x12\x11\n\tinfluence\x18\x02 \x01(\x02\x12(\n\x06states\x18\x03 \x03(\x0e\x32\x14.gomschema.StateTypeB\x02\x10\x01\"\xf5\x03\n\x06System\x12\n\n\x02id\x18\x01 \x01(\r\x12\x0c\n\x04name\x18\x02 \x01(\t\x12\x15\n\rtimestamp_utc\x18\x03 \x01(\x04\x12\'\n\x08position\x18\x04 \x01(\x0b\x32\x15.gomschema.Coordinate\x12\x11\n\tpopulated\x18\x05 \x01(\x08\x12\x14\n\x0cneeds_permit\x18\x06 \x01(\x08\x12\x30\n\x0esecurity_level\x18\x07 \x01(\x0e\x32\x18.gomschema.SecurityLevel\x12-\n\ngovernment\x18\x08 \x01(\x0e\x32\x19.gomschema.GovernmentType\x12-\n\nallegiance\x18\t \x01(\x0e\x32\x19.gomschema.AllegianceType\x12\x12\n\npopulation\x18\n \x01(\x04\x12/\n\x0fprimary_economy\x18\x0b \x01(\x0e\x32\x16.gomschema.EconomyType\x12(\n\x06states\x18\x0c \x03(\x0e\x32\x14.gomschema.StateTypeB\x02\x10\x01\x12\x1e\n\x16\x63ontrolling_faction_id\x18\r \x01(\r\x12\x1b\n\x13\x63ontrolling_faction\x18\x0e \x01(\t\x12,\n\x08\x66\x61\x63tions\x18\x0f \x03(\x0b\x32\x1a.gomschema.FactionPresence\"\xef\x02\n\x08\x46\x61\x63ility\x12\n\n\x02id\x18\x01 \x01(\r\x12\x11\n\tsystem_id\x18\x02 \x01(\r\x12\x0c\n\x04name\x18\x03 \x01(\t\x12\x15\n\rtimestamp_utc\x18\x04 \x01(\x04\x12.\n\rfacility_type\x18\x05 \x01(\x0e\x32\x17.gomschema.FacilityType\x12\x10\n\x08\x66\x65\x61tures\x18\x06 \x01(\r\x12\x14\n\x0cls_from_star\x18\x07 \x01(\r\x12-\n\ngovernment\x18\x08 \x01(\x0e\x32\x19.gomschema.GovernmentType\x12-\n\nallegiance\x18\t \x01(\x0e\x32\x19.gomschema.AllegianceType\x12\x11\n\tmarket_id\x18\n \x01(\x04\x12-\n\teconomies\x18\x0b \x03(\x0e\x32\x16.gomschema.EconomyTypeB\x02\x10\x01\x12\x0c\n\x04\x62ody\x18\x0c \x01(\t\x12\x19\n\x11planetary_landing\x18\r \x01(\x08\"\xff\x01\n\x10\x43ommodityListing\x12\x14\n\x0c\x63ommodity_id\x18\x01 \x01(\r\x12\x14\n\x0csupply_units\x18\x02 \x01(\r\x12\x16\n\x0esupply_credits\x18\x03 \x01(\r\x12\x14\n\x0c\x64\x65mand_units\x18\x04 \x01(\r\x12\x16\n\x0e\x64\x65mand_credits\x18\x05 \x01(\r\x12\x15\n\rtimestamp_utc\x18\x06 \x01(\x04\x12\x30\n\x0esupply_bracket\x18\x07 \x01(\x0e\x32\x18.gomschema.MarketBracket\x12\x30\n\x0e\x64\x65mand_bracket\x18\x08 \x01(\x0e\x32\x18.gomschema.MarketBracket\"L\n\x0f\x46\x61\x63ilityListing\x12\n\n\x02id\x18\x01 \x01(\r\x12-\n\x08listings\x18\x02 \x03(\x0b\x32\x1b.gomschema.CommodityListing\"\x90\x01\n\x06Module\x12\n\n\x02id\x18\x01 \x01(\r\x12\x0c\n\x04name\x18\x02 \x01(\t\x12\x15\n\rtimestamp_utc\x18\x03 \x01(\x04\x12\x10\n\x08\x63\x61tegory\x18\x04 \x01(\t\x12\r\n\x05group\x18\x05 \x01(\t\x12\x12\n\nsize_class\x18\x06 \x01(\r\x12\x0e\n\x06rating\x18\x07 \x01(\t\x12\x10\n\x08price_cr\x18\x08 \x01(\r\"I\n\x04Ship\x12\n\n\x02id\x18\x01 \x01(\r\x12\x0c\n\x04name\x18\x02 \x01(\t\x12\x15\n\rtimestamp_utc\x18\x03 \x01(\x04\x12\x10\n\x08price_cr\x18\x04 \x01(\r\"O\n\x12\x46\x61\x63ilityOutfitting\x12\n\n\x02id\x18\x01 \x01(\r\x12\x15\n\rtimestamp_utc\x18\x02 \x01(\x04\x12\x16\n\nmodule_ids\x18\x03 \x03(\rB\x02\x10\x01\"K\n\x10\x46\x61\x63ilityShipyard\x12\n\n\x02id\x18\x01 \x01(\r\x12\x15\n\rtimestamp_utc\x18\x02 \x01(\x04\x12\x14\n\x08ship_ids\x18\x03 \x03(\rB\x02\x10\x01*\xf7\x01\n\x0eGovernmentType\x12\x0b\n\x07GovNone\x10\x00\x12\x0e\n\nGovAnarchy\x10\x01\x12\x10\n\x0cGovCommunism\x10\x02\x12\x12\n\x0eGovConfederacy\x10\x03\x12\x12\n\x0eGovCooperative\x10\x04\x12\x10\n\x0cGovCorporate\x10\x05\x12\x10\n\x0cGovDemocracy\x10\x06\x12\x13\n\x0fGovDictatorship\x10\x07\x12\r\n\tGovFeudal\x10\x08\x12\x10\n\x0cGovPatronage\x10\t\x12\r\n\tGovPrison\x10\n\x12\x13\n\x0fGovPrisonColony\x10\x0b\x12\x10\n\x0cGovTheocracy\x10\x0c*\x89\x01\n\x0e\x41llegianceType\x12\r\n\tAllegNone\x10\x00\x12\x11\n\rAllegAlliance\x10\x01\x12\x0f\n\x0b\x41llegEmpire\x10\x02\x12\x13\n\x0f\x41llegFederation\x10\x03\x12\x14\n\x10\x41llegIndependent\x10\x04\x12\x19\n\x15\x41llegPilotsFederation\x10\x05*m\n\rSecurityLevel\x12\x10\n\x0cSecurityNone\x10\x00\x12\x13\n\x0fSecurityAnarchy\x10\x01\x12\x0f\n\x0bSecurityLow\x10\x02\x12\x12\n\x0eSecurityMedium\x10\x03\x12\x10\n\x0cSecurityHigh\x10\x04*\xac\x02\n\x0b\x45\x63onomyType\x12\x0b\n\x07\x45\x63oNone\x10\x00\x12\x12\n\x0e\x45\x63oAgriculture\x10\x01\x12\r\n\tEcoColony\x10\x02\x12\x11\n\rEcoExtraction\x10\x03\x12\x0f\n\x0b\x45\x63oHighTech\x10\x04\x12\x11\n\rEcoIndustrial\x10\x05\x12\x0f\n\x0b\x45\x63oMilitary\x10\x06\x12\x0f\n\x0b\x45\x63oRefinery\x10\x07\x12\x0e\n\nEcoService\x10\x08\x12\x13\n\x0f\x45\x63oTerraforming\x10\t\x12\x0e\n\nEcoTourism\x10\n\x12\r\n\tEcoPrison\x10\x0b\x12\x0e\n\nEcoDamaged\x10\x0c\x12\r\n\tEcoRescue\x10\r\x12\r\n\tEcoRepair\x10\x0e\x12\x0e\n\nEcoCarrier\x10\x0f\x12\x12\n\x0e\x45\x63oEngineering\x10\x10*\xb0\x04\n\tStateType\x12\r\n\tStateNone\x10\x00\x12\r\n\tStateBoom\x10\x01\x12\r\n\tStateBust\x10\x02\x12\x14\n\x10StateCivilUnrest\x10\x03\x12\x11\n\rStateCivilWar\x10\x04\x12\x11\n\rStateElection\x10\x05\x12\x12\n\x0eStateExpansion\x10\x06\x12\x0f\n\x0bStateFamine\x10\x07\x12\x13\n\x0fStateInvestment\x10\x08\x12\x11\n\rStateLockdown\x10\t\x12\x11\n\rStateOutbreak\x10\n\x12\x10\n\x0cStateRetreat\x10\x0b\x12\x0c\n\x08StateWar\x10\x0c\x12\x15\n\x11StateCivilLiberty\x10\r\x12\x15\n\x11StatePirateAttack\x10\x0e\x12\x0f\n\x0bStateBlight\x10\x0f\x12\x10\n\x0cStateDrought\x10\x10\x12\x1e\n\x1aStateInfrastructureFailure\x10\x11\x12\x18\n\x14StateNaturalDisaster\x10\x12\x12\x16\n\x12StatePublicHoliday\x10\x13\x12\x12\n\x0eStateTerrorism\x10\x14\x12\x10\n\x0cStateColdWar\x10\x15\x12\x15\n\x11StateColonisation\x10\x16\x12\x16\n\x12StateHistoricEvent\x10\x17\x12\x13\n\x0fStateRevolution\x10\x18\x12\x1a\n\x16StateTechnologicalLeap\x10\x19\x12\x11\n\rStateTradeWar\x10\x1a*\xec\x02\n\x0c\x46\x61\x63ilityType\x12\n\n\x06\x46TNone\x10\x00\x12\x15\n\x11\x46TCivilianOutpost\x10\x01\x12\x17\n\x13\x46TCommercialOutpost\x10\x02\x12\x16\n\x12\x46TCoriolisStarport\x10\x03\x12\x17\n\x13\x46TIndustrialOutpost\x10\x04\x12\x15\n\x11\x46TMilitaryOutpost\x10\x05\x12\x13\n\x0f\x46TMiningOutpost\x10\x06\x12\x15\n\x11\x46TOcellusStarport\x10\x07\x12\x13\n\x0f\x46TOrbisStarport\x10\x08\x12\x17\n\x13\x46TScientificOutpost\x10\t\x12\x16\n\x12\x46TPlanetaryOutpost\x10\n\x12\x13\n\x0f\x46TPlanetaryPort\x10\x0b\x12\x19\n\x15\x46TPlanetarySettlement\x10\x0c\x12\x0e\n\nFTMegaship\x10\r\x12\x12\n\x0e\x46TAsteroidBase\x10\x0e\x12\x12\n\x0e\x46TFleetCarrier\x10\x0f*\xcd\x01\n\nFeatureBit\x12\n\n\x06Market\x10\x00\x12\x0f\n\x0b\x42lackMarket\x10\x01\x12\x0f\n\x0b\x43ommodities\x10\x02\x12\x0b\n\x07\x44ocking\x10\x03\x12\t\n\x05\x46leet\x10\x04\x12\x0c\n\x08LargePad\x10\x05\x12\r\n\tMediumPad\x10\x06\x12\x0e\n\nOutfitting\x10\x07\x12\r\n\tPlanetary\x10\x08\x12\t\n\x05Rearm\x10\t\x12\n\n\x06Refuel\x10\n\x12\n\n\x06Repair\x10\x0b\x12\x0c\n\x08Shipyard\x10\x0c\x12\x0c\n\x08SmallPad\x10\r*h\n\rMarketBracket\x12\x12\n\x0e\x42racketUnknown\x10\x00\x12\x0f\n\x0b\x42racketNone\x10\x01\x12\x0e\n\nBracketLow\x10\x02\x12\x11\n\rBracketMedium\x10\x03\x12\x0f\n\x0b\x42racketHigh\x10\x04\x42\x10\n\x01.Z\x0b.;gomschemab\x06proto3')
 )
 
 _GOVERNMENTTYPE = _descriptor.EnumDescriptor(
@@ -84,8 +84,8 @@ _GOVERNMENTTYPE = _descriptor.EnumDescriptor(
   ],
   containing_type=None,
   serialized_options=None,
-  serialized_start=1641,
-  serialized_end=1888,
+  serialized_start=2603,
+  serialized_end=2850,
 )
 _sym_db.RegisterEnumDescriptor(_GOVERNMENTTYPE)
 
@@ -123,8 +123,8 @@ _ALLEGIANCETYPE = _descriptor.EnumDescriptor(
   ],
   containing_type=None,
   serialized_options=None,
-  serialized_start=1891,
-  serialized_end=2028,
+  serialized_start=2853,
+  serialized_end=2990,
 )
 _sym_db.RegisterEnumDescriptor(_ALLEGIANCETYPE)
 
@@ -158,12 +158,218 @@ _SECURITYLEVEL = _descriptor.EnumDescriptor(
   ],
   containing_type=None,
   serialized_options=None,
-  serialized_start=2030,
-  serialized_end=2139,
+  serialized_start=2992,
+  serialized_end=3101,
 )
 _sym_db.RegisterEnumDescriptor(_SECURITYLEVEL)
 
 SecurityLevel = enum_type_wrapper.EnumTypeWrapper(_SECURITYLEVEL)
+_ECONOMYTYPE = _descriptor.EnumDescriptor(
+  name='EconomyType',
+  full_name='gomschema.EconomyType',
+  filename=None,
+  file=DESCRIPTOR,
+  values=[
+    _descriptor.EnumValueDescriptor(
+      name='EcoNone', index=0, number=0,
+      serialized_options=None,
+      type=None),
+    _descriptor.EnumValueDescriptor(
+      name='EcoAgriculture', index=1, number=1,
+      serialized_options=None,
+      type=None),
+    _descriptor.EnumValueDescriptor(
+      name='EcoColony', index=2, number=2,
+      serialized_options=None,
+      type=None),
+    _descriptor.EnumValueDescriptor(
+      name='EcoExtraction', index=3, number=3,
+      serialized_options=None,
+      type=None),
+    _descriptor.EnumValueDescriptor(
+      name='EcoHighTech', index=4, number=4,
+      serialized_options=None,
+      type=None),
+    _descriptor.EnumValueDescriptor(
+      name='EcoIndustrial', index=5, number=5,
+      serialized_options=None,
+      type=None),
+    _descriptor.EnumValueDescriptor(
+      name='EcoMilitary', index=6, number=6,
+      serialized_options=None,
+      type=None),
+    _descriptor.EnumValueDescriptor(
+      name='EcoRefinery', index=7, number=7,
+      serialized_options=None,
+      type=None),
+    _descriptor.EnumValueDescriptor(
+      name='EcoService', index=8, number=8,
+      serialized_options=None,
+      type=None),
+    _descriptor.EnumValueDescriptor(
+      name='EcoTerraforming', index=9, number=9,
+      serialized_options=None,
+      type=None),
+    _descriptor.EnumValueDescriptor(
+      name='EcoTourism', index=10, number=10,
+      serialized_options=None,
+      type=None),
+    _descriptor.EnumValueDescriptor(
+      name='EcoPrison', index=11, number=11,
+      serialized_options=None,
+      type=None),
+    _descriptor.EnumValueDescriptor(
+      name='EcoDamaged', index=12, number=12,
+      serialized_options=None,
+      type=None),
+    _descriptor.EnumValueDescriptor(
+      name='EcoRescue', index=13, number=13,
+      serialized_options=None,
+      type=None),
+    _descriptor.EnumValueDescriptor(
+      name='EcoRepair', index=14, number=14,
+      serialized_options=None,
+      type=None),
+    _descriptor.EnumValueDescriptor(
+      name='EcoCarrier', index=15, number=15,
+      serialized_options=None,
+      type=None),
+    _descriptor.EnumValueDescriptor(
+      name='EcoEngineering', index=16, number=16,
+      serialized_options=None,
+      type=None),
+  ],
+  containing_type=None,
+  serialized_options=None,
+  serialized_start=3104,
+  serialized_end=3404,
+)
+_sym_db.RegisterEnumDescriptor(_ECONOMYTYPE)
+
+EconomyType = enum_type_wrapper.EnumTypeWrapper(_ECONOMYTYPE)
+_STATETYPE = _descriptor.EnumDescriptor(
+  name='StateType',
+  full_name='gomschema.StateType',
+  filename=None,
+  file=DESCRIPTOR,
+  values=[
+    _descriptor.EnumValueDescriptor(
+      name='StateNone', index=0, number=0,
+      serialized_options=None,
+      type=None),
+    _descriptor.EnumValueDescriptor(
+      name='StateBoom', index=1, number=1,
+      serialized_options=None,
+      type=None),
+    _descriptor.EnumValueDescriptor(
+      name='StateBust', index=2, number=2,
+      serialized_options=None,
+      type=None),
+    _descriptor.EnumValueDescriptor(
+      name='StateCivilUnrest', index=3, number=3,
+      serialized_options=None,
+      type=None),
+    _descriptor.EnumValueDescriptor(
+      name='StateCivilWar', index=4, number=4,
+      serialized_options=None,
+      type=None),
+    _descriptor.EnumValueDescriptor(
+      name='StateElection', index=5, number=5,
+      serialized_options=None,
+      type=None),
+    _descriptor.EnumValueDescriptor(
+      name='StateExpansion', index=6, number=6,
+      serialized_options=None,
+      type=None),
+    _descriptor.EnumValueDescriptor(
+      name='StateFamine', index=7, number=7,
+      serialized_options=None,
+      type=None),
+    _descriptor.EnumValueDescriptor(
+      name='StateInvestment', index=8, number=8,
+      serialized_options=None,
+      type=None),
+    _descriptor.EnumValueDescriptor(
+      name='StateLockdown', index=9, number=9,
+      serialized_options=None,
+      type=None),
+    _descriptor.EnumValueDescriptor(
+      name='StateOutbreak', index=10, number=10,
+      serialized_options=None,
+      type=None),
+    _descriptor.EnumValueDescriptor(
+      name='StateRetreat', index=11, number=11,
+      serialized_options=None,
+      type=None),
+    _descriptor.EnumValueDescriptor(
+      name='StateWar', index=12, number=12,
+      serialized_options=None,
+      type=None),
+    _descriptor.EnumValueDescriptor(
+      name='StateCivilLiberty', index=13, number=13,
+      serialized_options=None,
+      type=None),
+    _descriptor.EnumValueDescriptor(
+      name='StatePirateAttack', index=14, number=14,
+      serialized_options=None,
+      type=None),
+    _descriptor.EnumValueDescriptor(
+      name='StateBlight', index=15, number=15,
+      serialized_options=None,
+      type=None),
+    _descriptor.EnumValueDescriptor(
+      name='StateDrought', index=16, number=16,
+      serialized_options=None,
+      type=None),
+    _descriptor.EnumValueDescriptor(
+      name='StateInfrastructureFailure', index=17, number=17,
+      serialized_options=None,
+      type=None),
+    _descriptor.EnumValueDescriptor(
+      name='StateNaturalDisaster', index=18, number=18,
+      serialized_options=None,
+      type=None),
+    _descriptor.EnumValueDescriptor(
+      name='StatePublicHoliday', index=19, number=19,
+      serialized_options=None,
+      type=None),
+    _descriptor.EnumValueDescriptor(
+      name='StateTerrorism', index=20, number=20,
+      serialized_options=None,
+      type=None),
+    _descriptor.EnumValueDescriptor(
+      name='StateColdWar', index=21, number=21,
+      serialized_options=None,
+      type=None),
+    _descriptor.EnumValueDescriptor(
+      name='StateColonisation', index=22, number=22,
+      serialized_options=None,
+      type=None),
+    _descriptor.EnumValueDescriptor(
+      name='StateHistoricEvent', index=23, number=23,
+      serialized_options=None,
+      type=None),
+    _descriptor.EnumValueDescriptor(
+      name='StateRevolution', index=24, number=24,
+      serialized_options=None,
+      type=None),
+    _descriptor.EnumValueDescriptor(
+      name='StateTechnologicalLeap', index=25, number=25,
+      serialized_options=None,
+      type=None),
+    _descriptor.EnumValueDescriptor(
+      name='StateTradeWar', index=26, number=26,
+      serialized_options=None,
+      type=None),
+  ],
+  containing_type=None,
+  serialized_options=None,
+  serialized_start=3407,
+  serialized_end=3967,
+)
+_sym_db.RegisterEnumDescriptor(_STATETYPE)
+
+StateType = enum_type_wrapper.EnumTypeWrapper(_STATETYPE)
 _FACILITYTYPE = _descriptor.EnumDescriptor(
   name='FacilityType',
   full_name='gomschema.FacilityType',
@@ -237,8 +443,8 @@ _FACILITYTYPE = _descriptor.EnumDescriptor(
   ],
   containing_type=None,
   serialized_options=None,
-  serialized_start=2142,
-  serialized_end=2506,
+  serialized_start=3970,
+  serialized_end=4334,
 )
 _sym_db.RegisterEnumDescriptor(_FACILITYTYPE)
 
@@ -308,12 +514,47 @@ _FEATUREBIT = _descriptor.EnumDescriptor(
   ],
   containing_type=None,
   serialized_options=None,
-  serialized_start=2509,
-  serialized_end=2714,
+  serialized_start=4337,
+  serialized_end=4542,
 )
 _sym_db.RegisterEnumDescriptor(_FEATUREBIT)
 
 FeatureBit = enum_type_wrapper.EnumTypeWrapper(_FEATUREBIT)
+_MARKETBRACKET = _descriptor.EnumDescriptor(
+  name='MarketBracket',
+  full_name='gomschema.MarketBracket',
+  filename=None,
+  file=DESCRIPTOR,
+  values=[
+    _descriptor.EnumValueDescriptor(
+      name='BracketUnknown', index=0, number=0,
+      serialized_options=None,
+      type=None),
+    _descriptor.EnumValueDescriptor(
+      name='BracketNone', index=1, number=1,
+      serialized_options=None,
+      type=None),
+    _descriptor.EnumValueDescriptor(
+      name='BracketLow', index=2, number=2,
+      serialized_options=None,
+      type=None),
+    _descriptor.EnumValueDescriptor(
+      name='BracketMedium', index=3, number=3,
+      serialized_options=None,
+      type=None),
+    _descriptor.EnumValueDescriptor(
+      name='BracketHigh', index=4, number=4,
+      serialized_options=None,
+      type=None),
+  ],
+  containing_type=None,
+  serialized_options=None,
+  serialized_start=4544,
+  serialized_end=4648,
+)
+_sym_db.RegisterEnumDescriptor(_MARKETBRACKET)
+
+MarketBracket = enum_type_wrapper.EnumTypeWrapper(_MARKETBRACKET)
 GovNone = 0
 GovAnarchy = 1
 GovCommunism = 2
@@ -338,6 +579,50 @@ SecurityAnarchy = 1
 SecurityLow = 2
 SecurityMedium = 3
 SecurityHigh = 4
+EcoNone = 0
+EcoAgriculture = 1
+EcoColony = 2
+EcoExtraction = 3
+EcoHighTech = 4
+EcoIndustrial = 5
+EcoMilitary = 6
+EcoRefinery = 7
+EcoService = 8
+EcoTerraforming = 9
+EcoTourism = 10
+EcoPrison = 11
+EcoDamaged = 12
+EcoRescue = 13
+EcoRepair = 14
+EcoCarrier = 15
+EcoEngineering = 16
+StateNone = 0
+StateBoom = 1
+StateBust = 2
+StateCivilUnrest = 3
+StateCivilWar = 4
+StateElection = 5
+StateExpansion = 6
+StateFamine = 7
+StateInvestment = 8
+StateLockdown = 9
+StateOutbreak = 10
+StateRetreat = 11
+StateWar = 12
+StateCivilLiberty = 13
+StatePirateAttack = 14
+StateBlight = 15
+StateDrought = 16
+StateInfrastructureFailure = 17
+StateNaturalDisaster = 18
+StatePublicHoliday = 19
+StateTerrorism = 20
+StateColdWar = 21
+StateColonisation = 22
+StateHistoricEvent = 23
+StateRevolution = 24
+StateTechnologicalLeap = 25
+StateTradeWar = 26
 FTNone = 0
 FTCivilianOutpost = 1
 FTCommercialOutpost = 2
@@ -368,6 +653,11 @@ Refuel = 10
 Repair = 11
 Shipyard = 12
 SmallPad = 13
+BracketUnknown = 0
+BracketNone = 1
+BracketLow = 2
+BracketMedium = 3
+BracketHigh = 4
 
 
 _HEADER_TYPE = _descriptor.EnumDescriptor(
@@ -400,11 +690,27 @@ _HEADER_TYPE = _descriptor.EnumDescriptor(
       name='CListing', index=5, number=5,
       serialized_options=None,
       type=None),
+    _descriptor.EnumValueDescriptor(
+      name='CModule', index=6, number=6,
+      serialized_options=None,
+      type=None),
+    _descriptor.EnumValueDescriptor(
+      name='CShip', index=7, number=7,
+      serialized_options=None,
+      type=None),
+    _descriptor.EnumValueDescriptor(
+      name='COutfitting', index=8, number=8,
+      serialized_options=None,
+      type=None),
+    _descriptor.EnumValueDescriptor(
+      name='CShipyard', index=9, number=9,
+      serialized_options=None,
+      type=None),
   ],
   containing_type=None,
   serialized_options=None,
-  serialized_start=221,
-  serialized_end=312,
+  serialized_start=222,
+  serialized_end=369,
 )
 _sym_db.RegisterEnumDescriptor(_HEADER_TYPE)
 
@@ -485,8 +791,8 @@ _COMMODITY_CATEGORY = _descriptor.EnumDescriptor(
   ],
   containing_type=None,
   serialized_options=None,
-  serialized_start=500,
-  serialized_end=806,
+  serialized_start=557,
+  serialized_end=863,
 )
 _sym_db.RegisterEnumDescriptor(_COMMODITY_CATEGORY)
 
@@ -577,7 +883,7 @@ _HEADER = _descriptor.Descriptor(
   oneofs=[
   ],
   serialized_start=31,
-  serialized_end=318,
+  serialized_end=375,
 )
 
 
@@ -650,8 +956,8 @@ _COMMODITY = _descriptor.Descriptor(
   extension_ranges=[],
   oneofs=[
   ],
-  serialized_start=321,
-  serialized_end=806,
+  serialized_start=378,
+  serialized_end=863,
 )
 
 
@@ -695,8 +1001,53 @@ _COORDINATE = _descriptor.Descriptor(
   extension_ranges=[],
   oneofs=[
   ],
-  serialized_start=808,
-  serialized_end=853,
+  serialized_start=865,
+  serialized_end=910,
+)
+
+
+_FACTIONPRESENCE = _descriptor.Descriptor(
+  name='FactionPresence',
+  full_name='gomschema.FactionPresence',
+  filename=None,
+  file=DESCRIPTOR,
+  containing_type=None,
+  fields=[
+    _descriptor.FieldDescriptor(
+      name='faction_id', full_name='gomschema.FactionPresence.faction_id', index=0,
+      number=1, type=13, cpp_type=3, label=1,
+      has_default_value=False, default_value=0,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='influence', full_name='gomschema.FactionPresence.influence', index=1,
+      number=2, type=2, cpp_type=6, label=1,
+      has_default_value=False, default_value=float(0),
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='states', full_name='gomschema.FactionPresence.states', index=2,
+      number=3, type=14, cpp_type=8, label=3,
+      has_default_value=False, default_value=[],
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=_b('\020\001'), file=DESCRIPTOR),
+  ],
+  extensions=[
+  ],
+  nested_types=[],
+  enum_types=[
+  ],
+  serialized_options=None,
+  is_extendable=False,
+  syntax='proto3',
+  extension_ranges=[],
+  oneofs=[
+  ],
+  serialized_start=912,
+  serialized_end=1010,
 )
 
 
@@ -770,6 +1121,48 @@ _SYSTEM = _descriptor.Descriptor(
       message_type=None, enum_type=None, containing_type=None,
       is_extension=False, extension_scope=None,
       serialized_options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='population', full_name='gomschema.System.population', index=9,
+      number=10, type=4, cpp_type=4, label=1,
+      has_default_value=False, default_value=0,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='primary_economy', full_name='gomschema.System.primary_economy', index=10,
+      number=11, type=14, cpp_type=8, label=1,
+      has_default_value=False, default_value=0,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='states', full_name='gomschema.System.states', index=11,
+      number=12, type=14, cpp_type=8, label=3,
+      has_default_value=False, default_value=[],
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=_b('\020\001'), file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='controlling_faction_id', full_name='gomschema.System.controlling_faction_id', index=12,
+      number=13, type=13, cpp_type=3, label=1,
+      has_default_value=False, default_value=0,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='controlling_faction', full_name='gomschema.System.controlling_faction', index=13,
+      number=14, type=9, cpp_type=9, label=1,
+      has_default_value=False, default_value=_b("").decode('utf-8'),
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='factions', full_name='gomschema.System.factions', index=14,
+      number=15, type=11, cpp_type=10, label=3,
+      has_default_value=False, default_value=[],
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=None, file=DESCRIPTOR),
   ],
   extensions=[
   ],
@@ -782,8 +1175,8 @@ _SYSTEM = _descriptor.Descriptor(
   extension_ranges=[],
   oneofs=[
   ],
-  serialized_start=856,
-  serialized_end=1139,
+  serialized_start=1013,
+  serialized_end=1514,
 )
 
 
@@ -857,6 +1250,34 @@ _FACILITY = _descriptor.Descriptor(
       message_type=None, enum_type=None, containing_type=None,
       is_extension=False, extension_scope=None,
       serialized_options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='market_id', full_name='gomschema.Facility.market_id', index=9,
+      number=10, type=4, cpp_type=4, label=1,
+      has_default_value=False, default_value=0,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='economies', full_name='gomschema.Facility.economies', index=10,
+      number=11, type=14, cpp_type=8, label=3,
+      has_default_value=False, default_value=[],
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=_b('\020\001'), file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='body', full_name='gomschema.Facility.body', index=11,
+      number=12, type=9, cpp_type=9, label=1,
+      has_default_value=False, default_value=_b("").decode('utf-8'),
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='planetary_landing', full_name='gomschema.Facility.planetary_landing', index=12,
+      number=13, type=8, cpp_type=7, label=1,
+      has_default_value=False, default_value=False,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=None, file=DESCRIPTOR),
   ],
   extensions=[
   ],
@@ -869,8 +1290,8 @@ _FACILITY = _descriptor.Descriptor(
   extension_ranges=[],
   oneofs=[
   ],
-  serialized_start=1142,
-  serialized_end=1402,
+  serialized_start=1517,
+  serialized_end=1884,
 )
 
 
@@ -923,6 +1344,20 @@ _COMMODITYLISTING = _descriptor.Descriptor(
       message_type=None, enum_type=None, containing_type=None,
       is_extension=False, extension_scope=None,
       serialized_options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='supply_bracket', full_name='gomschema.CommodityListing.supply_bracket', index=6,
+      number=7, type=14, cpp_type=8, label=1,
+      has_default_value=False, default_value=0,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='demand_bracket', full_name='gomschema.CommodityListing.demand_bracket', index=7,
+      number=8, type=14, cpp_type=8, label=1,
+      has_default_value=False, default_value=0,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=None, file=DESCRIPTOR),
   ],
   extensions=[
   ],
@@ -935,8 +1370,8 @@ _COMMODITYLISTING = _descriptor.Descriptor(
   extension_ranges=[],
   oneofs=[
   ],
-  serialized_start=1405,
-  serialized_end=1560,
+  serialized_start=1887,
+  serialized_end=2142,
 )
 
 
@@ -973,8 +1408,230 @@ _FACILITYLISTING = _descriptor.Descriptor(
   extension_ranges=[],
   oneofs=[
   ],
-  serialized_start=1562,
-  serialized_end=1638,
+  serialized_start=2144,
+  serialized_end=2220,
+)
+
+
+_MODULE = _descriptor.Descriptor(
+  name='Module',
+  full_name='gomschema.Module',
+  filename=None,
+  file=DESCRIPTOR,
+  containing_type=None,
+  fields=[
+    _descriptor.FieldDescriptor(
+      name='id', full_name='gomschema.Module.id', index=0,
+      number=1, type=13, cpp_type=3, label=1,
+      has_default_value=False, default_value=0,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='name', full_name='gomschema.Module.name', index=1,
+      number=2, type=9, cpp_type=9, label=1,
+      has_default_value=False, default_value=_b("").decode('utf-8'),
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='timestamp_utc', full_name='gomschema.Module.timestamp_utc', index=2,
+      number=3, type=4, cpp_type=4, label=1,
+      has_default_value=False, default_value=0,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='category', full_name='gomschema.Module.category', index=3,
+      number=4, type=9, cpp_type=9, label=1,
+      has_default_value=False, default_value=_b("").decode('utf-8'),
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='group', full_name='gomschema.Module.group', index=4,
+      number=5, type=9, cpp_type=9, label=1,
+      has_default_value=False, default_value=_b("").decode('utf-8'),
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='size_class', full_name='gomschema.Module.size_class', index=5,
+      number=6, type=13, cpp_type=3, label=1,
+      has_default_value=False, default_value=0,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='rating', full_name='gomschema.Module.rating', index=6,
+      number=7, type=9, cpp_type=9, label=1,
+      has_default_value=False, default_value=_b("").decode('utf-8'),
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='price_cr', full_name='gomschema.Module.price_cr', index=7,
+      number=8, type=13, cpp_type=3, label=1,
+      has_default_value=False, default_value=0,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=None, file=DESCRIPTOR),
+  ],
+  extensions=[
+  ],
+  nested_types=[],
+  enum_types=[
+  ],
+  serialized_options=None,
+  is_extendable=False,
+  syntax='proto3',
+  extension_ranges=[],
+  oneofs=[
+  ],
+  serialized_start=2223,
+  serialized_end=2367,
+)
+
+
+_SHIP = _descriptor.Descriptor(
+  name='Ship',
+  full_name='gomschema.Ship',
+  filename=None,
+  file=DESCRIPTOR,
+  containing_type=None,
+  fields=[
+    _descriptor.FieldDescriptor(
+      name='id', full_name='gomschema.Ship.id', index=0,
+      number=1, type=13, cpp_type=3, label=1,
+      has_default_value=False, default_value=0,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='name', full_name='gomschema.Ship.name', index=1,
+      number=2, type=9, cpp_type=9, label=1,
+      has_default_value=False, default_value=_b("").decode('utf-8'),
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='timestamp_utc', full_name='gomschema.Ship.timestamp_utc', index=2,
+      number=3, type=4, cpp_type=4, label=1,
+      has_default_value=False, default_value=0,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='price_cr', full_name='gomschema.Ship.price_cr', index=3,
+      number=4, type=13, cpp_type=3, label=1,
+      has_default_value=False, default_value=0,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=None, file=DESCRIPTOR),
+  ],
+  extensions=[
+  ],
+  nested_types=[],
+  enum_types=[
+  ],
+  serialized_options=None,
+  is_extendable=False,
+  syntax='proto3',
+  extension_ranges=[],
+  oneofs=[
+  ],
+  serialized_start=2369,
+  serialized_end=2442,
+)
+
+
+_FACILITYOUTFITTING = _descriptor.Descriptor(
+  name='FacilityOutfitting',
+  full_name='gomschema.FacilityOutfitting',
+  filename=None,
+  file=DESCRIPTOR,
+  containing_type=None,
+  fields=[
+    _descriptor.FieldDescriptor(
+      name='id', full_name='gomschema.FacilityOutfitting.id', index=0,
+      number=1, type=13, cpp_type=3, label=1,
+      has_default_value=False, default_value=0,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='timestamp_utc', full_name='gomschema.FacilityOutfitting.timestamp_utc', index=1,
+      number=2, type=4, cpp_type=4, label=1,
+      has_default_value=False, default_value=0,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='module_ids', full_name='gomschema.FacilityOutfitting.module_ids', index=2,
+      number=3, type=13, cpp_type=3, label=3,
+      has_default_value=False, default_value=[],
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=_b('\020\001'), file=DESCRIPTOR),
+  ],
+  extensions=[
+  ],
+  nested_types=[],
+  enum_types=[
+  ],
+  serialized_options=None,
+  is_extendable=False,
+  syntax='proto3',
+  extension_ranges=[],
+  oneofs=[
+  ],
+  serialized_start=2444,
+  serialized_end=2523,
+)
+
+
+_FACILITYSHIPYARD = _descriptor.Descriptor(
+  name='FacilityShipyard',
+  full_name='gomschema.FacilityShipyard',
+  filename=None,
+  file=DESCRIPTOR,
+  containing_type=None,
+  fields=[
+    _descriptor.FieldDescriptor(
+      name='id', full_name='gomschema.FacilityShipyard.id', index=0,
+      number=1, type=13, cpp_type=3, label=1,
+      has_default_value=False, default_value=0,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='timestamp_utc', full_name='gomschema.FacilityShipyard.timestamp_utc', index=1,
+      number=2, type=4, cpp_type=4, label=1,
+      has_default_value=False, default_value=0,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='ship_ids', full_name='gomschema.FacilityShipyard.ship_ids', index=2,
+      number=3, type=13, cpp_type=3, label=3,
+      has_default_value=False, default_value=[],
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=_b('\020\001'), file=DESCRIPTOR),
+  ],
+  extensions=[
+  ],
+  nested_types=[],
+  enum_types=[
+  ],
+  serialized_options=None,
+  is_extendable=False,
+  syntax='proto3',
+  extension_ranges=[],
+  oneofs=[
+  ],
+  serialized_start=2525,
+  serialized_end=2600,
 )
 
 _HEADER_USERDATAENTRY.containing_type = _HEADER
@@ -983,26 +1640,41 @@ _HEADER.fields_by_name['userdata'].message_type = _HEADER_USERDATAENTRY
 _HEADER_TYPE.containing_type = _HEADER
 _COMMODITY.fields_by_name['category_id'].enum_type = _COMMODITY_CATEGORY
 _COMMODITY_CATEGORY.containing_type = _COMMODITY
+_FACTIONPRESENCE.fields_by_name['states'].enum_type = _STATETYPE
 _SYSTEM.fields_by_name['position'].message_type = _COORDINATE
 _SYSTEM.fields_by_name['security_level'].enum_type = _SECURITYLEVEL
 _SYSTEM.fields_by_name['government'].enum_type = _GOVERNMENTTYPE
 _SYSTEM.fields_by_name['allegiance'].enum_type = _ALLEGIANCETYPE
+_SYSTEM.fields_by_name['primary_economy'].enum_type = _ECONOMYTYPE
+_SYSTEM.fields_by_name['states'].enum_type = _STATETYPE
+_SYSTEM.fields_by_name['factions'].message_type = _FACTIONPRESENCE
 _FACILITY.fields_by_name['facility_type'].enum_type = _FACILITYTYPE
 _FACILITY.fields_by_name['government'].enum_type = _GOVERNMENTTYPE
 _FACILITY.fields_by_name['allegiance'].enum_type = _ALLEGIANCETYPE
+_FACILITY.fields_by_name['economies'].enum_type = _ECONOMYTYPE
+_COMMODITYLISTING.fields_by_name['supply_bracket'].enum_type = _MARKETBRACKET
+_COMMODITYLISTING.fields_by_name['demand_bracket'].enum_type = _MARKETBRACKET
 _FACILITYLISTING.fields_by_name['listings'].message_type = _COMMODITYLISTING
 DESCRIPTOR.message_types_by_name['Header'] = _HEADER
 DESCRIPTOR.message_types_by_name['Commodity'] = _COMMODITY
 DESCRIPTOR.message_types_by_name['Coordinate'] = _COORDINATE
+DESCRIPTOR.message_types_by_name['FactionPresence'] = _FACTIONPRESENCE
 DESCRIPTOR.message_types_by_name['System'] = _SYSTEM
 DESCRIPTOR.message_types_by_name['Facility'] = _FACILITY
 DESCRIPTOR.message_types_by_name['CommodityListing'] = _COMMODITYLISTING
 DESCRIPTOR.message_types_by_name['FacilityListing'] = _FACILITYLISTING
+DESCRIPTOR.message_types_by_name['Module'] = _MODULE
+DESCRIPTOR.message_types_by_name['Ship'] = _SHIP
+DESCRIPTOR.message_types_by_name['FacilityOutfitting'] = _FACILITYOUTFITTING
+DESCRIPTOR.message_types_by_name['FacilityShipyard'] = _FACILITYSHIPYARD
 DESCRIPTOR.enum_types_by_name['GovernmentType'] = _GOVERNMENTTYPE
 DESCRIPTOR.enum_types_by_name['AllegianceType'] = _ALLEGIANCETYPE
 DESCRIPTOR.enum_types_by_name['SecurityLevel'] = _SECURITYLEVEL
+DESCRIPTOR.enum_types_by_name['EconomyType'] = _ECONOMYTYPE
+DESCRIPTOR.enum_types_by_name['StateType'] = _STATETYPE
 DESCRIPTOR.enum_types_by_name['FacilityType'] = _FACILITYTYPE
 DESCRIPTOR.enum_types_by_name['FeatureBit'] = _FEATUREBIT
+DESCRIPTOR.enum_types_by_name['MarketBracket'] = _MARKETBRACKET
 _sym_db.RegisterFileDescriptor(DESCRIPTOR)
 
 Header = _reflection.GeneratedProtocolMessageType('Header', (_message.Message,), dict(
@@ -1034,6 +1706,13 @@ Coordinate = _reflection.GeneratedProtocolMessageType('Coordinate', (_message.Me
   ))
 _sym_db.RegisterMessage(Coordinate)
 
+FactionPresence = _reflection.GeneratedProtocolMessageType('FactionPresence', (_message.Message,), dict(
+  DESCRIPTOR = _FACTIONPRESENCE,
+  __module__ = 'gomschema_pb2'
+  # @@protoc_insertion_point(class_scope:gomschema.FactionPresence)
+  ))
+_sym_db.RegisterMessage(FactionPresence)
+
 System = _reflection.GeneratedProtocolMessageType('System', (_message.Message,), dict(
   DESCRIPTOR = _SYSTEM,
   __module__ = 'gomschema_pb2'
@@ -1062,8 +1741,41 @@ FacilityListing = _reflection.GeneratedProtocolMessageType('FacilityListing', (_
   ))
 _sym_db.RegisterMessage(FacilityListing)
 
+Module = _reflection.GeneratedProtocolMessageType('Module', (_message.Message,), dict(
+  DESCRIPTOR = _MODULE,
+  __module__ = 'gomschema_pb2'
+  # @@protoc_insertion_point(class_scope:gomschema.Module)
+  ))
+_sym_db.RegisterMessage(Module)
+
+Ship = _reflection.GeneratedProtocolMessageType('Ship', (_message.Message,), dict(
+  DESCRIPTOR = _SHIP,
+  __module__ = 'gomschema_pb2'
+  # @@protoc_insertion_point(class_scope:gomschema.Ship)
+  ))
+_sym_db.RegisterMessage(Ship)
+
+FacilityOutfitting = _reflection.GeneratedProtocolMessageType('FacilityOutfitting', (_message.Message,), dict(
+  DESCRIPTOR = _FACILITYOUTFITTING,
+  __module__ = 'gomschema_pb2'
+  # @@protoc_insertion_point(class_scope:gomschema.FacilityOutfitting)
+  ))
+_sym_db.RegisterMessage(FacilityOutfitting)
+
+FacilityShipyard = _reflection.GeneratedProtocolMessageType('FacilityShipyard', (_message.Message,), dict(
+  DESCRIPTOR = _FACILITYSHIPYARD,
+  __module__ = 'gomschema_pb2'
+  # @@protoc_insertion_point(class_scope:gomschema.FacilityShipyard)
+  ))
+_sym_db.RegisterMessage(FacilityShipyard)
+
 
 DESCRIPTOR._options = None
 _HEADER_USERDATAENTRY._options = None
 _HEADER.fields_by_name['sizes']._options = None
+_FACTIONPRESENCE.fields_by_name['states']._options = None
+_SYSTEM.fields_by_name['states']._options = None
+_FACILITY.fields_by_name['economies']._options = None
+_FACILITYOUTFITTING.fields_by_name['module_ids']._options = None
+_FACILITYSHIPYARD.fields_by_name['ship_ids']._options = None
 # @@protoc_insertion_point(module_scope)
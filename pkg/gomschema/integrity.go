@@ -0,0 +1,138 @@
+package gomschema
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"sort"
+)
+
+// Checksum identifies how the messages of a GOM stream are checksummed.
+type Checksum int
+
+const (
+	ChecksumNone Checksum = iota
+	ChecksumCRC32C
+	ChecksumSHA256
+)
+
+// Header userdata keys of the checksums and signature. Checksums are of the
+// uncompressed messages; the signature also covers the rest of the header.
+const (
+	ChecksumCRC32CKey   = "checksum-crc32c"
+	ChecksumSHA256Key   = "checksum-sha256"
+	SignatureKey        = "signature-ed25519"
+	SignerPublicKeyKey  = "signer-ed25519"
+	signingMessageMagic = "GOMS"
+)
+
+// integrityKeys are the userdata keys a writer replaces.
+var integrityKeys = []string{ChecksumCRC32CKey, ChecksumSHA256Key, SignatureKey, SignerPublicKeyKey}
+
+// ErrChecksumMismatch indicates the messages aren't what was written.
+var ErrChecksumMismatch = errors.New("checksum mismatch")
+
+// ErrBadSignature indicates the signature doesn't match the stream.
+var ErrBadSignature = errors.New("bad signature")
+
+var crc32cTable = crc32.MakeTable(crc32.Castagnoli)
+
+func crc32cSum(payload []byte) []byte {
+	sum := make([]byte, 4)
+	binary.BigEndian.PutUint32(sum, crc32.Checksum(payload, crc32cTable))
+	return sum
+}
+
+func sha256Sum(payload []byte) []byte {
+	sum := sha256.Sum256(payload)
+	return sum[:]
+}
+
+// signingMessage is what a signature signs: everything in the header bar the
+// signature itself, and a digest of the messages.
+func signingMessage(header *Header, digest []byte) []byte {
+	// Writes to a bytes.Buffer can't fail.
+	var message bytes.Buffer
+	field := func(data []byte) {
+		_ = binary.Write(&message, binary.BigEndian, uint32(len(data)))
+		message.Write(data)
+	}
+	message.WriteString(signingMessageMagic)
+	_ = binary.Write(&message, binary.BigEndian, int32(header.HeaderType))
+	field([]byte(header.Source))
+	_ = binary.Write(&message, binary.BigEndian, uint32(len(header.Sizes)))
+	_ = binary.Write(&message, binary.BigEndian, header.Sizes)
+	keys := make([]string, 0, len(header.Userdata))
+	for key := range header.Userdata {
+		if key != SignatureKey {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		field([]byte(key))
+		field(header.Userdata[key])
+	}
+	field(digest)
+	return message.Bytes()
+}
+
+// addIntegrity records the requested checksum of payload, and signs the
+// header, in the header's userdata.
+func addIntegrity(header *Header, payload []byte, checksum Checksum, key ed25519.PrivateKey) {
+	for _, name := range integrityKeys {
+		delete(header.Userdata, name)
+	}
+	if checksum == ChecksumNone && key == nil {
+		return
+	}
+	if header.Userdata == nil {
+		header.Userdata = make(map[string][]byte)
+	}
+	switch checksum {
+	case ChecksumCRC32C:
+		header.Userdata[ChecksumCRC32CKey] = crc32cSum(payload)
+	case ChecksumSHA256:
+		header.Userdata[ChecksumSHA256Key] = sha256Sum(payload)
+	}
+	if key != nil {
+		header.Userdata[SignerPublicKeyKey] = key.Public().(ed25519.PublicKey)
+		header.Userdata[SignatureKey] = ed25519.Sign(key, signingMessage(header, sha256Sum(payload)))
+	}
+}
+
+// hasIntegrity returns true if the header carries a checksum or signature.
+func hasIntegrity(header *Header) bool {
+	for _, name := range integrityKeys {
+		if _, present := header.Userdata[name]; present {
+			return true
+		}
+	}
+	return false
+}
+
+// verifyIntegrity checks payload against the checksums and signature in header.
+func verifyIntegrity(header *Header, payload []byte) error {
+	if sum, present := header.Userdata[ChecksumCRC32CKey]; present && !bytes.Equal(sum, crc32cSum(payload)) {
+		return fmt.Errorf("%w: crc32c", ErrChecksumMismatch)
+	}
+	if sum, present := header.Userdata[ChecksumSHA256Key]; present && !bytes.Equal(sum, sha256Sum(payload)) {
+		return fmt.Errorf("%w: sha256", ErrChecksumMismatch)
+	}
+	signature, signed := header.Userdata[SignatureKey]
+	signer, named := header.Userdata[SignerPublicKeyKey]
+	if !signed && !named {
+		return nil
+	}
+	if len(signer) != ed25519.PublicKeySize || len(signature) != ed25519.SignatureSize {
+		return fmt.Errorf("%w: malformed signature or signer", ErrBadSignature)
+	}
+	if !ed25519.Verify(signer, signingMessage(header, sha256Sum(payload)), signature) {
+		return ErrBadSignature
+	}
+	return nil
+}
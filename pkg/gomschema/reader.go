@@ -1,6 +1,9 @@
 package gomschema
 
 import (
+	"bufio"
+	"bytes"
+	"crypto/ed25519"
 	"errors"
 	"fmt"
 	"io"
@@ -17,15 +20,21 @@ type Consumer func(proto.Message, uint) error
 
 // GOMFile is a simple class for reading
 type GOMFile struct {
-	source io.Reader
-	header *Header
-	item   proto.Message
+	source  io.Reader
+	header  *Header
+	item    proto.Message
+	closers []io.Closer // Decompressors to release on Close.
 }
 
 func (f *GOMFile) Item() *proto.Message {
 	return &f.item
 }
 
+// Header returns the header that was read from the stream.
+func (f *GOMFile) Header() *Header {
+	return f.header
+}
+
 // getMessageType will identify which type of GOM message the header represents.
 func getMessageType(header *Header) proto.Message {
 	switch header.HeaderType {
@@ -41,13 +50,61 @@ func getMessageType(header *Header) proto.Message {
 	case Header_CListing:
 		return &FacilityListing{}
 
+	case Header_CModule:
+		return &Module{}
+
+	case Header_CShip:
+		return &Ship{}
+
+	case Header_COutfitting:
+		return &FacilityOutfitting{}
+
+	case Header_CShipyard:
+		return &FacilityShipyard{}
+
 	default:
 		return nil
 	}
 }
 
-// readAll is a helper to apply a callback to all messages in a gomfile.
+// Signer returns the public key the stream claims to be signed with, or nil
+// if it isn't signed. The signature itself is verified by Read.
+func (f *GOMFile) Signer() ed25519.PublicKey {
+	if signer := f.header.Userdata[SignerPublicKeyKey]; len(signer) == ed25519.PublicKeySize {
+		return signer
+	}
+	return nil
+}
+
+// readVerifiedPayload reads all of the messages, returning them only if they
+// match the header's checksums and signature.
+func readVerifiedPayload(f *GOMFile) ([]byte, error) {
+	var total uint64
+	for _, size := range f.header.Sizes {
+		total += uint64(size)
+	}
+	payload := make([]byte, total)
+	if _, err := io.ReadFull(f.source, payload); err != nil {
+		return nil, err
+	}
+	if err := verifyIntegrity(f.header, payload); err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
+
+// readAll is a helper to apply a callback to all messages in a gomfile. When
+// the header carries a checksum or signature, all of the messages are read
+// and verified before any are passed to the callback.
 func readAll(f *GOMFile, consumer Consumer) error {
+	source := f.source
+	if hasIntegrity(f.header) {
+		payload, err := readVerifiedPayload(f)
+		if err != nil {
+			return err
+		}
+		source = bytes.NewReader(payload)
+	}
 	buffer := make([]byte, 256)
 
 	for idx, size := range f.header.Sizes {
@@ -57,7 +114,7 @@ func readAll(f *GOMFile, consumer Consumer) error {
 
 		// Adjust the *size* of the buffer to only what we expect to read.
 		buffer = buffer[:size]
-		read, err := io.ReadFull(f.source, buffer)
+		read, err := io.ReadFull(source, buffer)
 		if uint32(read) != size {
 			err = io.ErrUnexpectedEOF
 		}
@@ -118,14 +175,37 @@ func readHeader(source io.Reader, size uint64) (header *Header, err error) {
 }
 
 // OpenGOMFile will consume a .gom file header from an io.Reader and return a GOMFile
-// object based on reading the header message in the source.
+// object based on reading the header message in the source. Streams that are
+// gzip or zstd compressed, such as .gom.gz and .gom.zst files, are recognized
+// by their magic bytes and decompressed as they are read.
 // See also GOMFile.Load().
-func OpenGOMFile(source io.Reader) (*GOMFile, error) {
+func OpenGOMFile(source io.Reader) (file *GOMFile, err error) {
 	// File layout:
 	// Byte 0   1   2   3   4   5   6   7   8   9   a   b   c
 	//    | G | O | M | D | n | n | n | n | n | n | n | n | n | <proto header> | <messages>
+	//
+	// The messages are compressed when the header's userdata names a
+	// PayloadCompressionKey.
+
+	var closers []io.Closer
+	defer func() {
+		if err != nil {
+			for _, closer := range closers {
+				_ = closer.Close()
+			}
+		}
+	}()
+	buffered := bufio.NewReader(source)
+	source = buffered
+	if compression := detectCompression(buffered); compression != CompressionNone {
+		decompressor, err := NewDecompressor(buffered, compression)
+		if err != nil {
+			return nil, err
+		}
+		closers = append(closers, decompressor)
+		source = decompressor
+	}
 
-	var err error
 	if err = readMagic(source); err != nil {
 		return nil, err
 	}
@@ -141,11 +221,27 @@ func OpenGOMFile(source io.Reader) (*GOMFile, error) {
 	if item = getMessageType(header); item == nil {
 		return nil, fmt.Errorf("cannot load %s headers", Header_Type_name[int32(header.HeaderType)])
 	}
-	return &GOMFile{source: source, header: header, item: item}, nil
+	if name, compressed := header.Userdata[PayloadCompressionKey]; compressed {
+		compression, err := ParseCompression(string(name))
+		if err != nil {
+			return nil, err
+		}
+		decompressor, err := NewDecompressor(source, compression)
+		if err != nil {
+			return nil, err
+		}
+		closers = append(closers, decompressor)
+		source = decompressor
+	}
+	return &GOMFile{source: source, header: header, item: item, closers: closers}, nil
 }
 
 // Close will release resources used by a GOMFile.
 func (f *GOMFile) Close() {
+	for idx := len(f.closers) - 1; idx >= 0; idx-- {
+		_ = f.closers[idx].Close()
+	}
+	f.closers = nil
 	f.source = nil
 	f.header = nil
 	f.item = nil
@@ -9,6 +9,10 @@ HEADER_CLASS_MAP = {
         'CSystem':          gom.System,
         'CFacility':        gom.Facility,
         'CListing':         gom.FacilityListing,
+        'CModule':          gom.Module,
+        'CShip':            gom.Ship,
+        'COutfitting':      gom.FacilityOutfitting,
+        'CShipyard':        gom.FacilityShipyard,
         }
 
 def read_gom_file(fullpath):
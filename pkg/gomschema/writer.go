@@ -0,0 +1,148 @@
+package gomschema
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"fmt"
+	"io"
+	"os"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// GOMWriter accumulates messages so they can be written out as a GOM stream.
+// The header has to list the size of every message, so the messages are
+// buffered until WriteTo is called.
+type GOMWriter struct {
+	header             *Header
+	payload            bytes.Buffer
+	payloadCompression Compression
+	checksum           Checksum
+	signingKey         ed25519.PrivateKey
+}
+
+// NewGOMWriter creates a writer for messages of the given header type, with
+// an optional description of where the data came from.
+func NewGOMWriter(headerType Header_Type, source string) *GOMWriter {
+	return &GOMWriter{header: &Header{HeaderType: headerType, Source: source}}
+}
+
+// Header returns the header that will be written, so callers can attach userdata.
+func (w *GOMWriter) Header() *Header {
+	return w.header
+}
+
+// Count returns the number of messages added so far.
+func (w *GOMWriter) Count() int {
+	return len(w.header.Sizes)
+}
+
+// SetPayloadCompression has the messages compressed within the stream, which
+// the header records so that readers know to decompress them.
+func (w *GOMWriter) SetPayloadCompression(compression Compression) {
+	w.payloadCompression = compression
+}
+
+// SetChecksum has a checksum of the messages recorded in the header, which
+// readers verify before passing on any of the messages.
+func (w *GOMWriter) SetChecksum(checksum Checksum) {
+	w.checksum = checksum
+}
+
+// Sign has the stream signed with key, so readers can tell who published it
+// and that nothing has changed since.
+func (w *GOMWriter) Sign(key ed25519.PrivateKey) {
+	w.signingKey = key
+}
+
+// AddData appends an already-marshaled message to the stream.
+func (w *GOMWriter) AddData(data []byte) {
+	w.header.Sizes = append(w.header.Sizes, uint32(len(data)))
+	w.payload.Write(data)
+}
+
+// AddMessage marshals and appends a message to the stream.
+func (w *GOMWriter) AddMessage(message proto.Message) error {
+	data, err := proto.Marshal(message)
+	if err != nil {
+		return err
+	}
+	w.AddData(data)
+	return nil
+}
+
+// payloadBytes returns the messages as they are to be written.
+func (w *GOMWriter) payloadBytes() ([]byte, error) {
+	if w.payloadCompression == CompressionNone {
+		delete(w.header.Userdata, PayloadCompressionKey)
+		return w.payload.Bytes(), nil
+	}
+	var compressed bytes.Buffer
+	compressor, err := NewCompressor(&compressed, w.payloadCompression)
+	if err == nil {
+		_, err = compressor.Write(w.payload.Bytes())
+		if closeErr := compressor.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if w.header.Userdata == nil {
+		w.header.Userdata = make(map[string][]byte)
+	}
+	w.header.Userdata[PayloadCompressionKey] = []byte(w.payloadCompression.String())
+	return compressed.Bytes(), nil
+}
+
+// WriteTo writes the magic, header and all of the accumulated messages to dest.
+func (w *GOMWriter) WriteTo(dest io.Writer) (int64, error) {
+	payload, err := w.payloadBytes()
+	if err != nil {
+		return 0, err
+	}
+	addIntegrity(w.header, w.payload.Bytes(), w.checksum, w.signingKey)
+	headerBytes, err := proto.Marshal(w.header)
+	if err != nil {
+		return 0, err
+	}
+	prefix := fmt.Sprintf("%s%08x", MAGIC, len(headerBytes))
+	written := int64(0)
+	for _, chunk := range [][]byte{[]byte(prefix), headerBytes, payload} {
+		n, err := dest.Write(chunk)
+		written += int64(n)
+		if err != nil {
+			return written, err
+		}
+	}
+	return written, nil
+}
+
+// WriteCompressed writes the stream to dest compressed as a whole, as in
+// .gom.gz and .gom.zst files.
+func (w *GOMWriter) WriteCompressed(dest io.Writer, compression Compression) error {
+	compressor, err := NewCompressor(dest, compression)
+	if err != nil {
+		return err
+	}
+	_, err = w.WriteTo(compressor)
+	if closeErr := compressor.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// WriteFile writes the stream to pathname, compressed according to its
+// extension: see CompressionForFilename.
+func (w *GOMWriter) WriteFile(pathname string) (err error) {
+	file, err := os.Create(pathname)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+	return w.WriteCompressed(file, CompressionForFilename(pathname))
+}
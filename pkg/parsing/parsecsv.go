@@ -23,7 +23,8 @@ func getFieldOrder(fields []string, line string) ([]int, int) {
 	headers := strings.Split(line, ",")
 	for _, fieldName := range fields {
 		for headerNo, headerName := range headers {
-			if strings.EqualFold(fieldName, headerName) {
+			// Headings may be quoted, with either kind of quote.
+			if strings.EqualFold(fieldName, strings.Trim(headerName, "\"'")) {
 				fieldOrder = append(fieldOrder, headerNo)
 				break
 			}
@@ -11,7 +11,7 @@ import (
 )
 
 // Helper that does a non-blocking check for whether a channel is closed.
-func isChannelClosed(channel <-chan [][]byte) bool {
+func isChannelClosed(channel <-chan []string) bool {
 	select {
 	case _, ok := <-channel:
 		return !ok
@@ -61,6 +61,14 @@ func Test_getFieldOrder(t *testing.T) {
 			assert.Equal(t, 5, fields)
 		}
 	})
+
+	t.Run("Quoted", func(t *testing.T) {
+		fieldOrder, fields := getFieldOrder([]string{"unq:name", "pos_x"}, `'unq:name',"pos_x"`)
+		if assert.NotNil(t, fieldOrder) {
+			assert.Equal(t, []int{0, 1}, fieldOrder)
+			assert.Equal(t, 2, fields)
+		}
+	})
 }
 
 func TestParseCSV(t *testing.T) {
@@ -109,15 +117,15 @@ func TestParseCSV(t *testing.T) {
 		assert.Nil(t, err)
 		assert.NotNil(t, channel)
 
-		var result [][]byte = nil
+		var result []string = nil
 		go func() {
 			result = <-channel
 		}()
 
 		assert.Eventually(t, func() bool { return result != nil }, time.Millisecond*100, time.Microsecond*50)
 		if assert.Len(t, result, 2) {
-			assert.Equal(t, []byte("1"), result[0])
-			assert.Equal(t, []byte("number two"), result[1])
+			assert.Equal(t, "1", result[0])
+			assert.Equal(t, "number two", result[1])
 		}
 
 		assert.Eventually(t, func() bool { return isChannelClosed(channel) }, time.Millisecond*100, time.Millisecond*50)
@@ -129,15 +137,15 @@ func TestParseCSV(t *testing.T) {
 		require.Nil(t, err)
 		assert.NotNil(t, channel)
 
-		var result [][]byte = nil
+		var result []string = nil
 		go func() {
 			result = <-channel
 		}()
 
 		assert.Eventually(t, func() bool { return result != nil }, time.Millisecond*50, time.Microsecond*20)
 		if assert.Len(t, result, 2) {
-			assert.Equal(t, []byte("1"), result[0])
-			assert.Equal(t, []byte("2"), result[1])
+			assert.Equal(t, "1", result[0])
+			assert.Equal(t, "2", result[1])
 		}
 
 		result = nil
@@ -147,8 +155,8 @@ func TestParseCSV(t *testing.T) {
 
 		assert.Eventually(t, func() bool { return result != nil }, time.Millisecond*50, time.Microsecond*20)
 		if assert.Len(t, result, 2) {
-			assert.Equal(t, []byte("first"), result[0])
-			assert.Equal(t, []byte("second"), result[1])
+			assert.Equal(t, "first", result[0])
+			assert.Equal(t, "second", result[1])
 		}
 
 		assert.Eventually(t, func() bool { return isChannelClosed(channel) }, time.Millisecond*50, time.Millisecond*20)
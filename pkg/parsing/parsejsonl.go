@@ -88,3 +88,21 @@ func parseJSONLines(lines <-chan string, fields []string) <-chan []*gjson.Result
 func ParseJSONLines(source io.Reader, fields []string) <-chan []*gjson.Result {
 	return parseJSONLines(getJSONLines(source), fields)
 }
+
+// ParseJSONObjects will consume a JSONL source and return each line that
+// holds a JSON dictionary, for sources like event logs where the fields vary
+// from line to line.
+func ParseJSONObjects(source io.Reader) <-chan *gjson.Result {
+	channel := make(chan *gjson.Result, 1)
+	go func() {
+		defer close(channel)
+		for line := range getJSONLines(source) {
+			result := gjson.Parse(line)
+			if result.IsObject() {
+				channel <- &result
+			}
+		}
+	}()
+
+	return channel
+}
@@ -188,7 +188,7 @@ func TestParseJSONLines(t *testing.T) {
 
 // Check that we can actually parse some sample data.
 func Test_ParseSystemsJSONL(t *testing.T) {
-	file, err := os.Open("testdata/systems_populated.jsonl")
+	file, err := os.Open("../../testdata/systems_populated.jsonl")
 	require.Nil(t, err)
 	defer file.Close()
 
@@ -203,3 +203,23 @@ func Test_ParseSystemsJSONL(t *testing.T) {
 	assert.Len(t, logged, 0)
 	assert.Len(t, results, 10)
 }
+
+func TestParseJSONObjects(t *testing.T) {
+	input := strings.Join([]string{
+		`{"event":"FSDJump","StarSystem":"Sol"}`,
+		`badnews`,
+		`[1]`,
+		`{"event":"Docked"}`,
+	}, "\n")
+	var objects = make([]*gjson.Result, 0, 4)
+	logged := captureLogOutput(func() {
+		for object := range ParseJSONObjects(strings.NewReader(input)) {
+			objects = append(objects, object)
+		}
+	})
+	assert.Len(t, logged, 1)
+	if assert.Len(t, objects, 2) {
+		assert.Equal(t, "Sol", objects[0].Get("StarSystem").String())
+		assert.Equal(t, "Docked", objects[1].Get("event").String())
+	}
+}
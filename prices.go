@@ -0,0 +1,249 @@
+package main
+
+// TradeDangerous ".prices" files list markets station by station:
+//
+//	@ SOL/Galileo
+//	   + Metals
+//	      Gold     9400   9200     1200H        -  2020-08-01 12:00:00
+//
+// Each item line gives what the station pays for the item, what it asks
+// for it, the demand and the supply, and optionally when it was seen. The
+// demand and supply are units followed by a bracket - L, M or H, or ? when
+// unknown - or "-" when there is none and "?" when nothing is known.
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	gom "github.com/kfsone/gomenacing/pkg/gomschema"
+)
+
+// ErrInvalidPrices indicates malformed .prices data.
+var ErrInvalidPrices = errors.New("invalid .prices data")
+
+// pricesTimeFormat is the layout of .prices timestamps, which are UTC.
+const pricesTimeFormat = "2006-01-02 15:04:05"
+
+// pricesBrackets are the bracket suffixes used by .prices files.
+var pricesBrackets = map[byte]gom.MarketBracket{
+	'?': gom.MarketBracket_BracketUnknown,
+	'L': gom.MarketBracket_BracketLow,
+	'M': gom.MarketBracket_BracketMedium,
+	'H': gom.MarketBracket_BracketHigh,
+}
+
+// parsePricesLevel parses a demand or supply column.
+func parsePricesLevel(token string) (units uint32, bracket gom.MarketBracket, err error) {
+	switch token {
+	case "-", "0":
+		return 0, gom.MarketBracket_BracketNone, nil
+	case "?":
+		return 0, gom.MarketBracket_BracketUnknown, nil
+	}
+	if suffix, exists := pricesBrackets[strings.ToUpper(token)[len(token)-1]]; exists {
+		bracket, token = suffix, token[:len(token)-1]
+	}
+	if token == "?" {
+		return 0, bracket, nil
+	}
+	count, err := strconv.ParseUint(token, 10, 32)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid units: %s", token)
+	}
+	return uint32(count), bracket, nil
+}
+
+// formatPricesLevel is the inverse of parsePricesLevel.
+func formatPricesLevel(units uint32, bracket gom.MarketBracket) string {
+	switch {
+	case units == 0 && bracket == gom.MarketBracket_BracketUnknown:
+		return "?"
+	case units == 0:
+		return "-"
+	}
+	for suffix, value := range pricesBrackets {
+		if value == bracket {
+			return fmt.Sprintf("%d%c", units, suffix)
+		}
+	}
+	return fmt.Sprintf("%d?", units)
+}
+
+// parsePricesItem parses an item line into a listing, returning the item name.
+func parsePricesItem(line string, defaultTimestamp uint64) (string, *gom.CommodityListing, error) {
+	fields := strings.Fields(line)
+	listing := &gom.CommodityListing{TimestampUtc: defaultTimestamp}
+	if len(fields) >= 7 {
+		if when, err := time.Parse(pricesTimeFormat, strings.Join(fields[len(fields)-2:], " ")); err == nil {
+			listing.TimestampUtc = uint64(when.Unix())
+			fields = fields[:len(fields)-2]
+		}
+	}
+	if len(fields) < 5 {
+		return "", nil, errors.New("expected <item> <paying> <asking> <demand> <supply> [<timestamp>]")
+	}
+	columns := fields[len(fields)-4:]
+	paying, err := strconv.ParseUint(columns[0], 10, 32)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid price: %s", columns[0])
+	}
+	asking, err := strconv.ParseUint(columns[1], 10, 32)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid price: %s", columns[1])
+	}
+	listing.DemandCredits, listing.SupplyCredits = uint32(paying), uint32(asking)
+	if listing.DemandUnits, listing.DemandBracket, err = parsePricesLevel(columns[2]); err != nil {
+		return "", nil, err
+	}
+	if listing.SupplyUnits, listing.SupplyBracket, err = parsePricesLevel(columns[3]); err != nil {
+		return "", nil, err
+	}
+	return strings.Join(fields[:len(fields)-4], " "), listing, nil
+}
+
+// ReadPrices parses a .prices source, calling callback with the listings of
+// each station in turn. Items without a timestamp are dated defaultTimestamp.
+// Unknown stations and items are skipped, subject to FilterError.
+func (sdb *SystemDatabase) ReadPrices(source io.Reader, defaultTimestamp uint64, callback func(*gom.FacilityListing) error) error {
+	var current *gom.FacilityListing
+	inStation := false
+	flush := func() error {
+		if current == nil {
+			return nil
+		}
+		listing := current
+		current = nil
+		return callback(listing)
+	}
+
+	scanner := bufio.NewScanner(source)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := scanner.Text()
+		if comment := strings.IndexByte(line, '#'); comment >= 0 {
+			line = line[:comment]
+		}
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "":
+			continue
+
+		case line[0] == '@':
+			if err := flush(); err != nil {
+				return err
+			}
+			inStation = true
+			name := strings.TrimSpace(line[1:])
+			separator := strings.Index(name, "/")
+			if separator < 0 {
+				return fmt.Errorf("line %d: %w: expected @ <system>/<station>: %s", lineNo, ErrInvalidPrices, name)
+			}
+			facility := (*Facility)(nil)
+			if system := sdb.GetSystem(strings.TrimSpace(name[:separator])); system != nil {
+				facility = system.GetFacility(strings.TrimSpace(name[separator+1:]))
+			}
+			if facility == nil {
+				if err := FilterError(fmt.Errorf("%w: station: %s", ErrUnknownEntity, name)); err != nil {
+					return fmt.Errorf("line %d: %w", lineNo, err)
+				}
+				continue
+			}
+			current = &gom.FacilityListing{Id: uint32(facility.ID)}
+
+		case !inStation:
+			return fmt.Errorf("line %d: %w: expected @ <system>/<station> before: %s", lineNo, ErrInvalidPrices, line)
+
+		case line[0] == '+':
+			// Category headings are only there to help the reader.
+			continue
+
+		default:
+			name, listing, err := parsePricesItem(line, defaultTimestamp)
+			if err != nil {
+				return fmt.Errorf("line %d: %w: %s", lineNo, ErrInvalidPrices, err)
+			}
+			if current == nil {
+				continue
+			}
+			commodity := sdb.GetCommodity(name)
+			if commodity == nil {
+				if err := FilterError(fmt.Errorf("%w: item: %s", ErrUnknownEntity, name)); err != nil {
+					return fmt.Errorf("line %d: %w", lineNo, err)
+				}
+				continue
+			}
+			listing.CommodityId = uint32(commodity.ID)
+			current.Listings = append(current.Listings, listing)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return flush()
+}
+
+// categoryName is the display name of a category, e.g. "Consumer Items".
+func categoryName(category gom.Commodity_Category) string {
+	name := strings.TrimPrefix(category.String(), "Cat")
+	var words strings.Builder
+	for idx, char := range name {
+		if idx > 0 && unicode.IsUpper(char) {
+			words.WriteByte(' ')
+		}
+		words.WriteRune(char)
+	}
+	return words.String()
+}
+
+// WritePrices writes the listings of each facility in .prices format, items
+// grouped by category.
+func (sdb *SystemDatabase) WritePrices(w io.Writer, facilities []*Facility) error {
+	if _, err := fmt.Fprintf(w, "# %-28s %7s %7s %11s %11s  %s\n", "<item name>", "paying", "asking", "demand", "supply", "timestamp"); err != nil {
+		return err
+	}
+	for _, facility := range facilities {
+		listings := make([]*Listing, 0, len(facility.listings))
+		for _, listing := range facility.listings {
+			if sdb.GetCommodityByID(listing.CommodityID) != nil {
+				listings = append(listings, listing)
+			}
+		}
+		sort.Slice(listings, func(i, j int) bool {
+			lhs, rhs := sdb.GetCommodityByID(listings[i].CommodityID), sdb.GetCommodityByID(listings[j].CommodityID)
+			if lhs.CategoryID != rhs.CategoryID {
+				return categoryName(lhs.CategoryID) < categoryName(rhs.CategoryID)
+			}
+			return lhs.DbName < rhs.DbName
+		})
+
+		if _, err := fmt.Fprintf(w, "\n@ %s/%s\n", strings.ToUpper(facility.System.Name()), facility.DbName); err != nil {
+			return err
+		}
+		category := gom.Commodity_Category(-1)
+		for _, listing := range listings {
+			commodity := sdb.GetCommodityByID(listing.CommodityID)
+			if commodity.CategoryID != category {
+				category = commodity.CategoryID
+				if _, err := fmt.Fprintf(w, "   + %s\n", categoryName(category)); err != nil {
+					return err
+				}
+			}
+			timestamp := ""
+			if listing.TimestampUtc != 0 {
+				timestamp = time.Unix(int64(listing.TimestampUtc), 0).UTC().Format(pricesTimeFormat)
+			}
+			line := fmt.Sprintf("      %-28s %7d %7d %11s %11s  %s", commodity.DbName, listing.StationPays, listing.StationAsks,
+				formatPricesLevel(listing.Demand, listing.DemandBracket), formatPricesLevel(listing.Supply, listing.SupplyBracket), timestamp)
+			if _, err := fmt.Fprintln(w, strings.TrimRight(line, " ")); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
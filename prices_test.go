@@ -0,0 +1,167 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	gom "github.com/kfsone/gomenacing/pkg/gomschema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testPrices = `# Exported by TradeDangerous
+@ SOL/Galileo
+   + Metals
+      Gold                  9400   9200     1200H        -  2020-08-01 12:00:00
+      Unobtainium              1      1         ?        ?
+   + Chemicals
+      Mineral Oil            140    150         -    5000M
+@ NOWHERE/Void Station
+      Gold                     1      1         -        -
+@ ALPHA CENTAURI/Hutton Orbital
+      Gold                  9800      0      12?         0
+`
+
+func Test_parsePricesLevel(t *testing.T) {
+	tests := []struct {
+		token   string
+		units   uint32
+		bracket gom.MarketBracket
+	}{
+		{"-", 0, gom.MarketBracket_BracketNone},
+		{"0", 0, gom.MarketBracket_BracketNone},
+		{"?", 0, gom.MarketBracket_BracketUnknown},
+		{"1200H", 1200, gom.MarketBracket_BracketHigh},
+		{"30m", 30, gom.MarketBracket_BracketMedium},
+		{"5L", 5, gom.MarketBracket_BracketLow},
+		{"12?", 12, gom.MarketBracket_BracketUnknown},
+		{"77", 77, gom.MarketBracket_BracketUnknown},
+		{"?H", 0, gom.MarketBracket_BracketHigh},
+	}
+	for _, test := range tests {
+		units, bracket, err := parsePricesLevel(test.token)
+		if assert.Nil(t, err, test.token) {
+			assert.Equal(t, test.units, units, test.token)
+			assert.Equal(t, test.bracket, bracket, test.token)
+		}
+	}
+	_, _, err := parsePricesLevel("lotsH")
+	assert.Error(t, err)
+
+	assert.Equal(t, "-", formatPricesLevel(0, gom.MarketBracket_BracketNone))
+	assert.Equal(t, "?", formatPricesLevel(0, gom.MarketBracket_BracketUnknown))
+	assert.Equal(t, "1200H", formatPricesLevel(1200, gom.MarketBracket_BracketHigh))
+	assert.Equal(t, "12?", formatPricesLevel(12, gom.MarketBracket_BracketUnknown))
+}
+
+func Test_categoryName(t *testing.T) {
+	assert.Equal(t, "Metals", categoryName(gom.Commodity_CatMetals))
+	assert.Equal(t, "Consumer Items", categoryName(gom.Commodity_CatConsumerItems))
+}
+
+func TestSystemDatabase_ReadPrices(t *testing.T) {
+	sdb := NewSystemDatabase(nil)
+	require.Nil(t, sdb.newCommodity(&gom.Commodity{Id: 1, Name: "Gold", CategoryId: gom.Commodity_CatMetals}))
+	require.Nil(t, sdb.newCommodity(&gom.Commodity{Id: 2, Name: "Mineral Oil", CategoryId: gom.Commodity_CatChemicals}))
+	require.Nil(t, sdb.newSystem(&gom.System{Id: 1, Name: "Sol", Position: &gom.Coordinate{}}))
+	require.Nil(t, sdb.newSystem(&gom.System{Id: 2, Name: "Alpha Centauri", Position: &gom.Coordinate{X: 4.4}}))
+	require.Nil(t, sdb.newFacility(&gom.Facility{Id: 1, SystemId: 1, Name: "Galileo"}))
+	require.Nil(t, sdb.newFacility(&gom.Facility{Id: 2, SystemId: 2, Name: "Hutton Orbital"}))
+	listings := make([]*gom.FacilityListing, 0, 2)
+	err := sdb.ReadPrices(strings.NewReader(testPrices), 1000, func(listing *gom.FacilityListing) error {
+		listings = append(listings, listing)
+		return nil
+	})
+	require.Nil(t, err)
+	require.Len(t, listings, 2)
+
+	assert.EqualValues(t, 1, listings[0].Id)
+	require.Len(t, listings[0].Listings, 2)
+	assert.Equal(t, &gom.CommodityListing{
+		CommodityId: 1, DemandCredits: 9400, SupplyCredits: 9200, DemandUnits: 1200, DemandBracket: gom.MarketBracket_BracketHigh,
+		SupplyBracket: gom.MarketBracket_BracketNone, TimestampUtc: 1596283200,
+	}, listings[0].Listings[0])
+	assert.Equal(t, &gom.CommodityListing{
+		CommodityId: 2, DemandCredits: 140, SupplyCredits: 150, DemandBracket: gom.MarketBracket_BracketNone,
+		SupplyUnits: 5000, SupplyBracket: gom.MarketBracket_BracketMedium, TimestampUtc: 1000,
+	}, listings[0].Listings[1])
+	assert.EqualValues(t, 2, listings[1].Id)
+
+	for _, invalid := range []string{"Gold 1 1 - -\n", "@ Sol\n", "@ Sol/Galileo\nGold 1 1\n", "@ Sol/Galileo\nGold one 1 - -\n"} {
+		err = sdb.ReadPrices(strings.NewReader(invalid), 0, func(*gom.FacilityListing) error { return nil })
+		assert.True(t, errors.Is(err, ErrInvalidPrices), invalid)
+	}
+}
+
+func TestSystemDatabase_WritePrices(t *testing.T) {
+	sdb, reread := NewSystemDatabase(nil), NewSystemDatabase(nil)
+	for _, target := range []*SystemDatabase{sdb, reread} {
+		require.Nil(t, target.newCommodity(&gom.Commodity{Id: 1, Name: "Gold", CategoryId: gom.Commodity_CatMetals}))
+		require.Nil(t, target.newCommodity(&gom.Commodity{Id: 2, Name: "Mineral Oil", CategoryId: gom.Commodity_CatChemicals}))
+		require.Nil(t, target.newSystem(&gom.System{Id: 1, Name: "Sol", Position: &gom.Coordinate{}}))
+		require.Nil(t, target.newSystem(&gom.System{Id: 2, Name: "Alpha Centauri", Position: &gom.Coordinate{X: 4.4}}))
+		require.Nil(t, target.newFacility(&gom.Facility{Id: 1, SystemId: 1, Name: "Galileo"}))
+		require.Nil(t, target.newFacility(&gom.Facility{Id: 2, SystemId: 2, Name: "Hutton Orbital"}))
+	}
+	require.Nil(t, sdb.ReadPrices(strings.NewReader(testPrices), 0, func(listing *gom.FacilityListing) error {
+		return sdb.newListings(listing)
+	}))
+
+	var output bytes.Buffer
+	galileo, hutton := sdb.GetFacilityByID(1), sdb.GetFacilityByID(2)
+	require.Nil(t, sdb.WritePrices(&output, []*Facility{galileo, hutton}))
+	assert.Contains(t, output.String(), "@ SOL/Galileo\n   + Chemicals\n      Mineral Oil")
+	assert.Contains(t, output.String(), "   + Metals\n      Gold                            9400    9200       1200H           -  2020-08-01 12:00:00\n")
+
+	// What's written reads back the same.
+	require.Nil(t, reread.ReadPrices(&output, 0, func(listing *gom.FacilityListing) error {
+		return reread.newListings(listing)
+	}))
+	assert.Equal(t, galileo.listings, reread.GetFacilityByID(1).listings)
+	assert.Equal(t, hutton.listings, reread.GetFacilityByID(2).listings)
+}
+
+func TestRepl_Prices(t *testing.T) {
+	testDir := GetTestDir()
+	defer testDir.Close()
+	db, err := OpenDatabase(testDir.Path(), "prices.db")
+	require.Nil(t, err)
+	defer db.Close()
+
+	sdb := NewSystemDatabase(nil)
+	require.Nil(t, sdb.newCommodity(&gom.Commodity{Id: 1, Name: "Gold", CategoryId: gom.Commodity_CatMetals}))
+	require.Nil(t, sdb.newCommodity(&gom.Commodity{Id: 2, Name: "Mineral Oil", CategoryId: gom.Commodity_CatChemicals}))
+	require.Nil(t, sdb.newSystem(&gom.System{Id: 1, Name: "Sol", Position: &gom.Coordinate{}}))
+	require.Nil(t, sdb.newSystem(&gom.System{Id: 2, Name: "Alpha Centauri", Position: &gom.Coordinate{X: 4.4}}))
+	require.Nil(t, sdb.newFacility(&gom.Facility{Id: 1, SystemId: 1, Name: "Galileo"}))
+	require.Nil(t, sdb.newFacility(&gom.Facility{Id: 2, SystemId: 2, Name: "Hutton Orbital"}))
+
+	var output bytes.Buffer
+	repl := &Repl{db: db, sdb: sdb, out: &output}
+	pathname := filepath.Join(testDir.Path(), "test.prices")
+	require.Nil(t, ioutil.WriteFile(pathname, []byte(testPrices), 0644))
+
+	cmdImport(repl, []string{pathname}, nil)
+	assert.Contains(t, output.String(), "read 2 stations.")
+	assert.Len(t, repl.sdb.GetFacilityByID(1).listings, 2)
+
+	output.Reset()
+	exported := filepath.Join(testDir.Path(), "export.prices")
+	cmdExportPrices(repl, strings.Fields("sol/galileo, Alpha Centauri/Hutton Orbital to "+exported), nil)
+	assert.Contains(t, output.String(), "Wrote 2 stations to")
+	data, err := ioutil.ReadFile(exported)
+	require.Nil(t, err)
+	assert.Contains(t, string(data), "@ ALPHA CENTAURI/Hutton Orbital")
+
+	output.Reset()
+	cmdExportPrices(repl, strings.Fields("sol/galileo"), nil)
+	assert.Contains(t, output.String(), "@ SOL/Galileo")
+
+	output.Reset()
+	cmdExportPrices(repl, strings.Fields("sol/nowhere"), nil)
+	assert.Contains(t, output.String(), "Unrecognized station: sol/nowhere")
+}
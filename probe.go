@@ -2,7 +2,106 @@ package main
 
 // A "probe" caches values from searches of the GoM database, such as "neighbors within N ly", etc.
 
-//type NeighborDistances map[*System]float64
-//type Probe struct {
-//	neighbors	map[*System]NeighborDistances
-//}
+import (
+	"container/list"
+	"sort"
+
+	flag "github.com/spf13/pflag"
+)
+
+// ProbeCacheSize is the number of origin systems whose neighbors are remembered.
+var ProbeCacheSize = flag.Int("probecache", 64, "Number of 'systems within range' searches to cache.")
+
+// NeighborDistances is the result of a range search: every system within
+// Radius ly of an origin, nearest first.
+type NeighborDistances struct {
+	Radius    float64
+	Neighbors []Neighbor
+}
+
+// Within returns the prefix of the neighbors that lie within radius, which
+// must not exceed the radius that was searched.
+func (nd *NeighborDistances) Within(radius float64) []Neighbor {
+	radiusSq := NewSquareFloat(radius)
+	count := sort.Search(len(nd.Neighbors), func(i int) bool { return nd.Neighbors[i].DistSq > radiusSq })
+	return nd.Neighbors[:count]
+}
+
+type probeEntry struct {
+	origin    EntityID
+	distances NeighborDistances
+}
+
+// Probe is a bounded, least-recently-used cache of range searches, keyed by
+// origin system. Only the widest search from each origin is kept, since any
+// narrower search can be answered from it.
+//
+// Any system being added or moved invalidates the entire cache.
+type Probe struct {
+	index    *SpatialIndex
+	capacity int
+	entries  map[EntityID]*list.Element
+	recency  *list.List // Most recently used at the front.
+	Hits     int
+	Misses   int
+}
+
+// NewProbe creates a probe over index which remembers up to capacity origins.
+func NewProbe(index *SpatialIndex, capacity int) *Probe {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &Probe{
+		index:    index,
+		capacity: capacity,
+		entries:  make(map[EntityID]*list.Element, capacity),
+		recency:  list.New(),
+	}
+}
+
+// Len returns the number of origins currently cached.
+func (p *Probe) Len() int {
+	return p.recency.Len()
+}
+
+// Invalidate discards all cached searches.
+func (p *Probe) Invalidate() {
+	if p.recency.Len() == 0 {
+		return
+	}
+	p.entries = make(map[EntityID]*list.Element, p.capacity)
+	p.recency.Init()
+}
+
+// Neighbors returns the systems within radius ly of origin, nearest first,
+// including origin itself. The returned slice is shared and must not be modified.
+func (p *Probe) Neighbors(origin *System, radius float64) []Neighbor {
+	if element, exists := p.entries[origin.ID]; exists {
+		entry := element.Value.(*probeEntry)
+		if entry.distances.Radius >= radius {
+			p.Hits++
+			p.recency.MoveToFront(element)
+			return entry.distances.Within(radius)
+		}
+		// A wider search replaces the narrower one.
+		p.recency.Remove(element)
+		delete(p.entries, origin.ID)
+	}
+	p.Misses++
+
+	neighbors := make([]Neighbor, 0, 64)
+	p.index.WithinRadius(*origin.Position(), radius, func(system *System, distSq SquareFloat) bool {
+		neighbors = append(neighbors, Neighbor{System: system, DistSq: distSq})
+		return true
+	})
+	sort.Slice(neighbors, func(i, j int) bool { return neighbors[i].DistSq < neighbors[j].DistSq })
+
+	entry := &probeEntry{origin: origin.ID, distances: NeighborDistances{Radius: radius, Neighbors: neighbors}}
+	p.entries[origin.ID] = p.recency.PushFront(entry)
+	for p.recency.Len() > p.capacity {
+		oldest := p.recency.Back()
+		delete(p.entries, oldest.Value.(*probeEntry).origin)
+		p.recency.Remove(oldest)
+	}
+	return neighbors
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/kfsone/gomenacing/pkg/gomschema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNeighborDistances_Within(t *testing.T) {
+	nd := NeighborDistances{Radius: 10, Neighbors: []Neighbor{
+		{DistSq: 0}, {DistSq: 4}, {DistSq: 25}, {DistSq: 100},
+	}}
+	assert.Len(t, nd.Within(10), 4)
+	assert.Len(t, nd.Within(5), 3)
+	assert.Len(t, nd.Within(4.9), 2)
+	assert.Len(t, nd.Within(0), 1)
+}
+
+func TestProbe_Neighbors(t *testing.T) {
+	sdb := makeTestGalaxy(2000, 200, 9)
+	probe := NewProbe(sdb.spatial, 2)
+	origin := sdb.systemsByID[1]
+
+	neighbors := probe.Neighbors(origin, 30)
+	require.NotEmpty(t, neighbors)
+	assert.Equal(t, origin, neighbors[0].System)
+	for idx := 1; idx < len(neighbors); idx++ {
+		assert.True(t, neighbors[idx-1].DistSq <= neighbors[idx].DistSq)
+	}
+	assert.Len(t, neighbors, len(bruteForceWithin(sdb, *origin.Position(), 30)))
+	assert.Equal(t, 0, probe.Hits)
+	assert.Equal(t, 1, probe.Misses)
+
+	// Narrower searches are served from the wider one.
+	narrow := probe.Neighbors(origin, 15)
+	assert.Len(t, narrow, len(bruteForceWithin(sdb, *origin.Position(), 15)))
+	assert.Equal(t, 1, probe.Hits)
+
+	// Wider searches replace it.
+	wide := probe.Neighbors(origin, 45)
+	assert.Len(t, wide, len(bruteForceWithin(sdb, *origin.Position(), 45)))
+	assert.Equal(t, 2, probe.Misses)
+	assert.Equal(t, 1, probe.Len())
+
+	// The least recently used origin is evicted.
+	probe.Neighbors(sdb.systemsByID[2], 10)
+	probe.Neighbors(origin, 10)
+	probe.Neighbors(sdb.systemsByID[3], 10)
+	assert.Equal(t, 2, probe.Len())
+	assert.Contains(t, probe.entries, origin.ID)
+	assert.NotContains(t, probe.entries, EntityID(2))
+
+	probe.Invalidate()
+	assert.Zero(t, probe.Len())
+}
+
+func TestSystemDatabase_probeInvalidation(t *testing.T) {
+	testDir := GetTestDir()
+	defer testDir.Close()
+	db, err := OpenDatabase(testDir.Path(), "probe.db")
+	require.Nil(t, err)
+	defer db.Close()
+	schema, err := db.Systems()
+	require.Nil(t, err)
+	defer func() { failOnError(schema.Close()) }()
+
+	sdb := NewSystemDatabase(db)
+	sol := &gomschema.System{Id: 1, Name: "Sol", Position: &gomschema.Coordinate{}, TimestampUtc: 1}
+	require.Nil(t, sdb.updateSystem(sol, schema, attribution{source: "test"}))
+	require.Nil(t, sdb.updateSystem(&gomschema.System{Id: 2, Name: "Far", Position: &gomschema.Coordinate{X: 20}, TimestampUtc: 1}, schema, attribution{source: "test"}))
+
+	countNear := func() int {
+		count := 0
+		_, err := sdb.getSystemsWithinRange(sdb.systemsByID[1], 10, func(*System, SquareFloat) bool {
+			count++
+			return true
+		})
+		require.Nil(t, err)
+		return count
+	}
+	assert.Equal(t, 1, countNear())
+	assert.Equal(t, 1, sdb.probe.Len())
+
+	// An update that doesn't move anything keeps the cache.
+	sol.TimestampUtc, sol.Populated = 2, true
+	require.Nil(t, sdb.updateSystem(sol, schema, attribution{source: "test"}))
+	assert.Equal(t, 1, sdb.probe.Len())
+
+	// Adding a system invalidates it.
+	require.Nil(t, sdb.updateSystem(&gomschema.System{Id: 3, Name: "Near", Position: &gomschema.Coordinate{Y: 5}, TimestampUtc: 1}, schema, attribution{source: "test"}))
+	assert.Zero(t, sdb.probe.Len())
+	assert.Equal(t, 2, countNear())
+
+	// As does moving one.
+	require.Nil(t, sdb.updateSystem(&gomschema.System{Id: 2, Name: "Far", Position: &gomschema.Coordinate{X: 2}, TimestampUtc: 2}, schema, attribution{source: "test"}))
+	assert.Zero(t, sdb.probe.Len())
+	assert.Equal(t, 3, countNear())
+}
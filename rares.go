@@ -0,0 +1,171 @@
+package main
+
+import (
+	"sort"
+)
+
+// RareSaleDistance is how far, in ly, a rare has to be carried from where it
+// was bought before it sells at full price.
+const RareSaleDistance = 150.0
+
+// RareSource is a facility selling a rare commodity.
+type RareSource struct {
+	Commodity *Commodity
+	Facility  *Facility
+	Listing   *Listing
+}
+
+// RareGain estimates the per-unit profit of selling a rare distanceLy from
+// its source: nothing short of RareSaleDistance, where the loop planner keeps
+// hold of it, and the galactic average price less what it cost beyond.
+func RareGain(rare RareSource, distanceLy float64) int64 {
+	markup := int64(rare.Commodity.AverageCr) - int64(rare.Listing.StationAsks)
+	if markup <= 0 || distanceLy < RareSaleDistance {
+		return 0
+	}
+	return markup
+}
+
+// FindRareSources returns the facilities within rangeLy of origin that are
+// selling rare goods, nearest first, as of now.
+func (sdb *SystemDatabase) FindRareSources(origin *System, rangeLy float64, now uint64) ([]RareSource, error) {
+	sources := make([]RareSource, 0, 16)
+	_, err := sdb.getSystemsWithinRange(origin, rangeLy, func(system *System, _ SquareFloat) bool {
+		start := len(sources)
+		for _, facility := range system.facilities {
+			for commodityID, listing := range facility.listings {
+				if listing.StationAsks == 0 || sdb.isStale(listing.TimestampUtc, now) {
+					continue
+				}
+				if commodity := sdb.GetCommodityByID(commodityID); commodity != nil && commodity.IsRare {
+					sources = append(sources, RareSource{Commodity: commodity, Facility: facility, Listing: listing})
+				}
+			}
+		}
+		// Listings are a map, so settle the order within the system.
+		found := sources[start:]
+		sort.Slice(found, func(i, j int) bool {
+			if found[i].Facility.ID != found[j].Facility.ID {
+				return found[i].Facility.ID < found[j].Facility.ID
+			}
+			return found[i].Commodity.ID < found[j].Commodity.ID
+		})
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sources, nil
+}
+
+// RareStop is a facility on a rare loop, the rares to buy there and the ones
+// carried far enough to be sold there.
+type RareStop struct {
+	Facility *Facility
+	// DistanceLy is how far the stop is from the previous one; for the first
+	// stop, that's the leg closing the loop.
+	DistanceLy float64
+	Buy        []RareSource
+	Sell       []RareSource
+	// GainCr is the per-unit profit of this stop's sales.
+	GainCr int64
+}
+
+// RareLoop is a circuit of rare sources, ending back at the first stop.
+type RareLoop struct {
+	Stops []RareStop
+	// DistanceLy is the straight-line length of the whole circuit.
+	DistanceLy float64
+	// GainCr is the profit from selling one unit of each rare on the loop.
+	GainCr int64
+	// Unsold lists rares that never got far enough from their source.
+	Unsold []RareSource
+}
+
+func facilityDistance(from, to *Facility) float64 {
+	return Distance(from.System, to.System).Root()
+}
+
+// sellRares moves the rares that can be sold at stop out of cargo.
+func sellRares(stop *RareStop, cargo []RareSource) []RareSource {
+	kept := cargo[:0]
+	for _, rare := range cargo {
+		if distance := facilityDistance(rare.Facility, stop.Facility); distance >= RareSaleDistance {
+			stop.Sell = append(stop.Sell, rare)
+			stop.GainCr += RareGain(rare, distance)
+		} else {
+			kept = append(kept, rare)
+		}
+	}
+	return kept
+}
+
+// PlanRareLoop plans a loop of up to maxStops stops through the facilities in
+// sources, starting at the first. At each stop the rares on sale are bought
+// and any that are RareSaleDistance from their source are sold. The next stop
+// is whichever unvisited source can sell the most of the cargo, nearest first.
+func PlanRareLoop(sources []RareSource, maxStops int) *RareLoop {
+	if len(sources) == 0 || maxStops <= 0 {
+		return nil
+	}
+
+	// Group the rares by where they are sold, keeping the order of first appearance.
+	byFacility := make(map[*Facility][]RareSource)
+	facilities := make([]*Facility, 0, len(sources))
+	for _, source := range sources {
+		if _, seen := byFacility[source.Facility]; !seen {
+			facilities = append(facilities, source.Facility)
+		}
+		byFacility[source.Facility] = append(byFacility[source.Facility], source)
+	}
+
+	loop := &RareLoop{}
+	visited := make(map[*Facility]bool, maxStops)
+	cargo := make([]RareSource, 0, len(sources))
+	current := facilities[0]
+	var previous *Facility
+	for current != nil && len(loop.Stops) < maxStops {
+		stop := RareStop{Facility: current}
+		if previous != nil {
+			stop.DistanceLy = facilityDistance(previous, current)
+		}
+		cargo = sellRares(&stop, cargo)
+		stop.Buy = byFacility[current]
+		cargo = append(cargo, stop.Buy...)
+		visited[current] = true
+		loop.Stops = append(loop.Stops, stop)
+		loop.DistanceLy += stop.DistanceLy
+		loop.GainCr += stop.GainCr
+
+		previous, current = current, nil
+		bestSales, bestDistance := -1, 0.0
+		for _, candidate := range facilities {
+			if visited[candidate] {
+				continue
+			}
+			sales := 0
+			for _, rare := range cargo {
+				if facilityDistance(rare.Facility, candidate) >= RareSaleDistance {
+					sales++
+				}
+			}
+			distance := facilityDistance(previous, candidate)
+			if sales > bestSales || (sales == bestSales && distance < bestDistance) {
+				current, bestSales, bestDistance = candidate, sales, distance
+			}
+		}
+	}
+
+	// Close the loop by returning to the start, selling anything that can be.
+	if len(loop.Stops) > 1 {
+		first := &loop.Stops[0]
+		closing := facilityDistance(previous, first.Facility)
+		first.DistanceLy = closing
+		loop.DistanceLy += closing
+		before := first.GainCr
+		cargo = sellRares(first, cargo)
+		loop.GainCr += first.GainCr - before
+	}
+	loop.Unsold = cargo
+	return loop
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	gom "github.com/kfsone/gomenacing/pkg/gomschema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRareGain(t *testing.T) {
+	rare := RareSource{Commodity: &Commodity{AverageCr: 5000}, Listing: &Listing{StationAsks: 1000}}
+	assert.EqualValues(t, 0, RareGain(rare, 75))
+	assert.EqualValues(t, 4000, RareGain(rare, RareSaleDistance))
+	assert.EqualValues(t, 4000, RareGain(rare, 200))
+	rare.Listing = &Listing{StationAsks: 6000}
+	assert.EqualValues(t, 0, RareGain(rare, 200))
+}
+
+func TestSystemDatabase_FindRareSources(t *testing.T) {
+	sdb := NewSystemDatabase(nil)
+	require.Nil(t, sdb.newCommodity(&gom.Commodity{Id: 1, Name: "Lave Brandy", IsRare: true, AverageCr: 5000}))
+	require.Nil(t, sdb.newCommodity(&gom.Commodity{Id: 2, Name: "Tea", AverageCr: 1500}))
+	require.Nil(t, sdb.newCommodity(&gom.Commodity{Id: 3, Name: "Leestian Evil Juice", IsRare: true, AverageCr: 3000}))
+	require.Nil(t, sdb.newCommodity(&gom.Commodity{Id: 4, Name: "Azure Milk", IsRare: true, AverageCr: 2000}))
+	require.Nil(t, sdb.newSystem(&gom.System{Id: 1, Name: "Lave", Position: &gom.Coordinate{X: 0}}))
+	require.Nil(t, sdb.newSystem(&gom.System{Id: 2, Name: "Diso", Position: &gom.Coordinate{X: 10}}))
+	require.Nil(t, sdb.newSystem(&gom.System{Id: 3, Name: "Leesti", Position: &gom.Coordinate{X: 200}}))
+	require.Nil(t, sdb.newSystem(&gom.System{Id: 4, Name: "Azure", Position: &gom.Coordinate{X: 205}}))
+	require.Nil(t, sdb.newFacility(&gom.Facility{Id: 1, SystemId: 1, Name: "Port"}))
+	require.Nil(t, sdb.newFacility(&gom.Facility{Id: 2, SystemId: 2, Name: "Port"}))
+	require.Nil(t, sdb.newFacility(&gom.Facility{Id: 3, SystemId: 3, Name: "Port"}))
+	require.Nil(t, sdb.newFacility(&gom.Facility{Id: 4, SystemId: 4, Name: "Port"}))
+	require.Nil(t, sdb.newListings(&gom.FacilityListing{Id: 1, Listings: []*gom.CommodityListing{{CommodityId: 1, SupplyUnits: 10, SupplyCredits: 1000}, {CommodityId: 2, SupplyUnits: 10, SupplyCredits: 1000}}}))
+	require.Nil(t, sdb.newListings(&gom.FacilityListing{Id: 2, Listings: []*gom.CommodityListing{{CommodityId: 2, SupplyUnits: 10, SupplyCredits: 1000}}}))
+	require.Nil(t, sdb.newListings(&gom.FacilityListing{Id: 3, Listings: []*gom.CommodityListing{{CommodityId: 3, SupplyUnits: 10, SupplyCredits: 1000}}}))
+	require.Nil(t, sdb.newListings(&gom.FacilityListing{Id: 4, Listings: []*gom.CommodityListing{{CommodityId: 4, SupplyUnits: 10, SupplyCredits: 500}}}))
+
+	sources, err := sdb.FindRareSources(sdb.GetSystem("Lave"), 50, 0)
+	require.Nil(t, err)
+	require.Len(t, sources, 1)
+	assert.Equal(t, "Lave Brandy", sources[0].Commodity.DbName)
+
+	sources, err = sdb.FindRareSources(sdb.GetSystem("Lave"), 300, 0)
+	require.Nil(t, err)
+	require.Len(t, sources, 3)
+	assert.Equal(t, "Leestian Evil Juice", sources[1].Commodity.DbName)
+	assert.Equal(t, "Azure Milk", sources[2].Commodity.DbName)
+
+	_, err = sdb.FindRareSources(sdb.GetSystem("Lave"), 0, 0)
+	assert.Error(t, err)
+}
+
+func TestPlanRareLoop(t *testing.T) {
+	sdb := NewSystemDatabase(nil)
+	require.Nil(t, sdb.newCommodity(&gom.Commodity{Id: 1, Name: "Lave Brandy", IsRare: true, AverageCr: 5000}))
+	require.Nil(t, sdb.newCommodity(&gom.Commodity{Id: 2, Name: "Tea", AverageCr: 1500}))
+	require.Nil(t, sdb.newCommodity(&gom.Commodity{Id: 3, Name: "Leestian Evil Juice", IsRare: true, AverageCr: 3000}))
+	require.Nil(t, sdb.newCommodity(&gom.Commodity{Id: 4, Name: "Azure Milk", IsRare: true, AverageCr: 2000}))
+	require.Nil(t, sdb.newSystem(&gom.System{Id: 1, Name: "Lave", Position: &gom.Coordinate{X: 0}}))
+	require.Nil(t, sdb.newSystem(&gom.System{Id: 2, Name: "Diso", Position: &gom.Coordinate{X: 10}}))
+	require.Nil(t, sdb.newSystem(&gom.System{Id: 3, Name: "Leesti", Position: &gom.Coordinate{X: 200}}))
+	require.Nil(t, sdb.newSystem(&gom.System{Id: 4, Name: "Azure", Position: &gom.Coordinate{X: 205}}))
+	require.Nil(t, sdb.newFacility(&gom.Facility{Id: 1, SystemId: 1, Name: "Port"}))
+	require.Nil(t, sdb.newFacility(&gom.Facility{Id: 2, SystemId: 2, Name: "Port"}))
+	require.Nil(t, sdb.newFacility(&gom.Facility{Id: 3, SystemId: 3, Name: "Port"}))
+	require.Nil(t, sdb.newFacility(&gom.Facility{Id: 4, SystemId: 4, Name: "Port"}))
+	require.Nil(t, sdb.newListings(&gom.FacilityListing{Id: 1, Listings: []*gom.CommodityListing{{CommodityId: 1, SupplyUnits: 10, SupplyCredits: 1000}, {CommodityId: 2, SupplyUnits: 10, SupplyCredits: 1000}}}))
+	require.Nil(t, sdb.newListings(&gom.FacilityListing{Id: 2, Listings: []*gom.CommodityListing{{CommodityId: 2, SupplyUnits: 10, SupplyCredits: 1000}}}))
+	require.Nil(t, sdb.newListings(&gom.FacilityListing{Id: 3, Listings: []*gom.CommodityListing{{CommodityId: 3, SupplyUnits: 10, SupplyCredits: 1000}}}))
+	require.Nil(t, sdb.newListings(&gom.FacilityListing{Id: 4, Listings: []*gom.CommodityListing{{CommodityId: 4, SupplyUnits: 10, SupplyCredits: 500}}}))
+	assert.Nil(t, PlanRareLoop(nil, 4))
+
+	sources, err := sdb.FindRareSources(sdb.GetSystem("Lave"), 300, 0)
+	require.Nil(t, err)
+	loop := PlanRareLoop(sources, 4)
+	require.NotNil(t, loop)
+	require.Len(t, loop.Stops, 3)
+
+	// Buy brandy at Lave, sell it at Leesti and buy juice, buy milk at Azure, and
+	// sell both back at Lave.
+	assert.Equal(t, "Lave", loop.Stops[0].Facility.System.DbName)
+	assert.Equal(t, "Leesti", loop.Stops[1].Facility.System.DbName)
+	assert.Equal(t, "Azure", loop.Stops[2].Facility.System.DbName)
+	require.Len(t, loop.Stops[1].Sell, 1)
+	assert.Equal(t, "Lave Brandy", loop.Stops[1].Sell[0].Commodity.DbName)
+	assert.Empty(t, loop.Stops[2].Sell)
+	assert.Len(t, loop.Stops[0].Sell, 2)
+	assert.Empty(t, loop.Unsold)
+	assert.InDelta(t, 410, loop.DistanceLy, 0.001)
+	assert.EqualValues(t, 4000+2000+1500, loop.GainCr)
+
+	// Too short a loop leaves rares unsold.
+	loop = PlanRareLoop(sources, 1)
+	require.Len(t, loop.Stops, 1)
+	assert.Len(t, loop.Unsold, 1)
+}
+
+func TestRepl_RareLoop(t *testing.T) {
+	sdb := NewSystemDatabase(nil)
+	require.Nil(t, sdb.newCommodity(&gom.Commodity{Id: 1, Name: "Lave Brandy", IsRare: true, AverageCr: 5000}))
+	require.Nil(t, sdb.newCommodity(&gom.Commodity{Id: 2, Name: "Tea", AverageCr: 1500}))
+	require.Nil(t, sdb.newCommodity(&gom.Commodity{Id: 3, Name: "Leestian Evil Juice", IsRare: true, AverageCr: 3000}))
+	require.Nil(t, sdb.newCommodity(&gom.Commodity{Id: 4, Name: "Azure Milk", IsRare: true, AverageCr: 2000}))
+	require.Nil(t, sdb.newSystem(&gom.System{Id: 1, Name: "Lave", Position: &gom.Coordinate{X: 0}}))
+	require.Nil(t, sdb.newSystem(&gom.System{Id: 2, Name: "Diso", Position: &gom.Coordinate{X: 10}}))
+	require.Nil(t, sdb.newSystem(&gom.System{Id: 3, Name: "Leesti", Position: &gom.Coordinate{X: 200}}))
+	require.Nil(t, sdb.newSystem(&gom.System{Id: 4, Name: "Azure", Position: &gom.Coordinate{X: 205}}))
+	require.Nil(t, sdb.newFacility(&gom.Facility{Id: 1, SystemId: 1, Name: "Port"}))
+	require.Nil(t, sdb.newFacility(&gom.Facility{Id: 2, SystemId: 2, Name: "Port"}))
+	require.Nil(t, sdb.newFacility(&gom.Facility{Id: 3, SystemId: 3, Name: "Port"}))
+	require.Nil(t, sdb.newFacility(&gom.Facility{Id: 4, SystemId: 4, Name: "Port"}))
+	require.Nil(t, sdb.newListings(&gom.FacilityListing{Id: 1, Listings: []*gom.CommodityListing{{CommodityId: 1, SupplyUnits: 10, SupplyCredits: 1000}, {CommodityId: 2, SupplyUnits: 10, SupplyCredits: 1000}}}))
+	require.Nil(t, sdb.newListings(&gom.FacilityListing{Id: 2, Listings: []*gom.CommodityListing{{CommodityId: 2, SupplyUnits: 10, SupplyCredits: 1000}}}))
+	require.Nil(t, sdb.newListings(&gom.FacilityListing{Id: 3, Listings: []*gom.CommodityListing{{CommodityId: 3, SupplyUnits: 10, SupplyCredits: 1000}}}))
+	require.Nil(t, sdb.newListings(&gom.FacilityListing{Id: 4, Listings: []*gom.CommodityListing{{CommodityId: 4, SupplyUnits: 10, SupplyCredits: 500}}}))
+
+	var output bytes.Buffer
+	repl := &Repl{sdb: sdb, out: &output}
+	cmdRareLoop(repl, strings.Fields("300 Lave"), nil)
+	assert.Contains(t, output.String(), "sell Lave Brandy")
+	assert.Contains(t, output.String(), "Loop: 410.00ly, 7500cr")
+}
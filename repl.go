@@ -2,12 +2,17 @@ package main
 
 import (
 	"bufio"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"github.com/kfsone/gomenacing/pkg/gomschema"
 	"github.com/mattn/go-shellwords"
+	"google.golang.org/protobuf/proto"
 	"io"
 	"log"
 	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -25,6 +30,8 @@ type Repl struct {
 	src        *bufio.Scanner
 	out        io.Writer
 	terminated bool
+	journal    <-chan JournalBatch
+	commander  *Commander
 }
 
 func (r *Repl) Write(p []byte) (int, error) {
@@ -33,12 +40,51 @@ func (r *Repl) Write(p []byte) (int, error) {
 
 func NewRepl(db *Database, sdb *SystemDatabase, src *bufio.Scanner, out io.Writer) (*Repl, error) {
 	repl := Repl{db: db, sdb: sdb, src: src, out: out}
+	if db != nil {
+		commander, err := LoadCommander(CommanderPath(db))
+		if err != nil {
+			return nil, err
+		}
+		repl.commander = commander
+	}
 	return &repl, nil
 }
 
+// getCommander returns the commander, creating an unsaved one if there isn't one yet.
+func (r *Repl) getCommander() *Commander {
+	if r.commander == nil {
+		r.commander = NewCommander("")
+	}
+	return r.commander
+}
+
+// saveCommander saves changes to the commander.
+func (r *Repl) saveCommander() {
+	if err := r.getCommander().Save(); err != nil {
+		fmt.Fprintf(r, "Warning: unable to save commander: %s\n", err)
+	}
+}
+
+// getCarrier returns the commander's fleet carrier, creating an empty one if there isn't one yet.
+func (r *Repl) getCarrier() *Carrier {
+	commander := r.getCommander()
+	if commander.Carrier == nil {
+		commander.Carrier = NewCarrier()
+	}
+	return commander.Carrier
+}
+
+// lookupSystem finds a system by name, or the commander's system for "here".
+func (r *Repl) lookupSystem(name string) *System {
+	if strings.EqualFold(strings.TrimSpace(name), hereName) {
+		return r.getCommander().System(r.sdb)
+	}
+	return r.sdb.GetSystem(name)
+}
+
 func cmdSystemFind(r *Repl, args []string, _ *CommandParser) {
 	name := strings.Join(args, " ")
-	system := r.sdb.GetSystem(name)
+	system := r.lookupSystem(name)
 	if system == nil {
 		fmt.Fprintln(r, "Not found.")
 	} else {
@@ -46,12 +92,87 @@ func cmdSystemFind(r *Repl, args []string, _ *CommandParser) {
 	}
 }
 
+// lookupFacility finds a facility by Frontier market id or "system/station"
+// name, or the station the commander is docked at for "here".
+func (r *Repl) lookupFacility(name string) *Facility {
+	if strings.EqualFold(strings.TrimSpace(name), hereName) {
+		return r.getCommander().Facility(r.sdb)
+	}
+	if marketID, err := strconv.ParseUint(name, 10, 64); err == nil {
+		return r.sdb.GetFacilityByMarketID(marketID)
+	}
+	separator := strings.LastIndex(name, "/")
+	if separator < 0 {
+		return nil
+	}
+	system := r.sdb.GetSystem(strings.TrimSpace(name[:separator]))
+	if system == nil {
+		return nil
+	}
+	return system.GetFacility(strings.TrimSpace(name[separator+1:]))
+}
+
+func cmdStationFind(r *Repl, args []string, _ *CommandParser) {
+	name := strings.Join(args, " ")
+	facility := r.lookupFacility(name)
+	if facility == nil {
+		fmt.Fprintln(r, "Not found. Use <market id> or <system>/<station>.")
+		return
+	}
+	fmt.Fprintf(r, "%s (#%d)\n", facility.Name(), facility.ID)
+	fmt.Fprintf(r, "- Type: %s, %dls from star\n", facility.FacilityType, facility.LsFromStar)
+	if facility.MarketID != 0 {
+		fmt.Fprintf(r, "- Market ID: %d\n", facility.MarketID)
+	}
+	if facility.Body != "" {
+		fmt.Fprintf(r, "- Body: %s\n", facility.Body)
+	}
+	fmt.Fprintf(r, "- Planetary: %t\n", facility.IsPlanetary())
+	if len(facility.Economies) > 0 {
+		economies := make([]string, len(facility.Economies))
+		for idx, economy := range facility.Economies {
+			economies[idx] = economy.String()
+		}
+		fmt.Fprintf(r, "- Economies: %s\n", strings.Join(economies, ", "))
+	}
+	fmt.Fprintf(r, "- Government: %s, Allegiance: %s\n", facility.Government, facility.Allegiance)
+	fmt.Fprintf(r, "- Listings: %d\n", len(facility.listings))
+}
+
 func cmdImport(r *Repl, args []string, _ *CommandParser) {
+	if len(args) > 0 && strings.EqualFold(args[0], "td") {
+		cmdImportTD(r, args[1:])
+		return
+	}
+	if len(args) > 0 && strings.EqualFold(args[0], "galaxy") {
+		cmdImportGalaxy(r, args[1:])
+		return
+	}
 	pathname := strings.Join(args, " ")
 
+	// The game's own journal logs and market files.
+	if filename := filepath.Base(pathname); isJournalFile(filename) || filename == marketFilename {
+		importer := NewJournalImporter(r.sdb, r.db)
+		defer importer.Close()
+		importer.commander = r.getCommander()
+		if err := importer.ImportFile(pathname); err != nil {
+			fmt.Fprintf(r, "import %s: %s\n", pathname, err)
+		}
+		fmt.Fprintf(r, "%s: applied %d updates.\n", pathname, importer.Updates)
+		if importer.Updates > 0 {
+			r.updateSnapshot()
+		}
+		if importer.CommanderUpdates > 0 {
+			r.saveCommander()
+		}
+		return
+	}
+
 	// If they named a specific .gom file, go ahead and import just that.
-	if strings.HasSuffix(pathname, ".gom") {
+	if isGOMFilename(pathname) {
 		fnImportFile(r, pathname, true)
+	} else if strings.HasSuffix(pathname, ".prices") {
+		fnImportPrices(r, pathname)
 	} else {
 		stat, err := os.Stat(pathname)
 		if err != nil && !os.IsExist(err) {
@@ -63,15 +184,460 @@ func cmdImport(r *Repl, args []string, _ *CommandParser) {
 			return
 		}
 
-		imports := 0
-		for _, filename := range GetImportFilenames() {
-			if fnImportFile(r, filepath.Join(pathname, filename), false) {
-				imports++
-			}
+		if fnImportDir(r, pathname) == 0 {
+			fmt.Fprintln(r, "Nothing to import.")
 		}
+	}
+	r.updateSnapshot()
+}
 
-		if imports == 0 {
-			fmt.Fprintln(r, "Nothing to import.")
+// updateSnapshot refreshes the database snapshot after the database changes.
+func (r *Repl) updateSnapshot() {
+	if *UseSnapshot {
+		if err := UpdateSnapshot(r.db, r.sdb); err != nil {
+			fmt.Fprintf(r, "Warning: unable to save snapshot: %s\n", err)
+		}
+	}
+}
+
+// cmdImportTD reads TradeDangerous System, Item, Station and StationItem csv files.
+func cmdImportTD(r *Repl, args []string) {
+	dir := strings.Join(args, " ")
+	if dir == "" {
+		fmt.Fprintln(r, "Please specify the directory to import from, e.g: import td ~/tradedangerous/data")
+		return
+	}
+	tables, err := r.sdb.ImportTD(dir, r.db)
+	for _, table := range tables {
+		fmt.Fprintf(r, "- %s: %d rows\n", table.Filename, table.Count)
+	}
+	if err != nil {
+		fmt.Fprintf(r, "import td %s: %s\n", dir, err)
+	} else if len(tables) == 0 {
+		fmt.Fprintln(r, "Nothing to import.")
+	}
+	if len(tables) > 0 {
+		r.updateSnapshot()
+	}
+}
+
+// cmdImportGalaxy adds the systems in a Spansh or EDSM galaxy dump that we
+// don't already know about.
+func cmdImportGalaxy(r *Repl, args []string) {
+	pathname := strings.Join(args, " ")
+	if pathname == "" {
+		fmt.Fprintln(r, "Please specify the dump to import, e.g: import galaxy galaxy.json.gz")
+		return
+	}
+	importer, err := NewGalaxyImporter(r.sdb, r.db)
+	if err != nil {
+		fmt.Fprintf(r, "import galaxy %s: %s\n", pathname, err)
+		return
+	}
+	err = importer.ImportFile(pathname)
+	importer.Close()
+	if err != nil {
+		fmt.Fprintf(r, "import galaxy %s: %s\n", pathname, err)
+	}
+	fmt.Fprintf(r, "%s: %d new systems, %d already known, %d skipped.\n", pathname, importer.Added, importer.Known, importer.Skipped)
+	if importer.Added > 0 {
+		r.updateSnapshot()
+	}
+}
+
+func cmdExportTD(r *Repl, args []string, _ *CommandParser) {
+	dir := strings.Join(args, " ")
+	if dir == "" {
+		fmt.Fprintln(r, "Please specify the directory to write to, e.g: export td tddata")
+		return
+	}
+	tables, err := r.sdb.ExportTD(dir)
+	for _, table := range tables {
+		fmt.Fprintf(r, "- %s: %d rows\n", table.Filename, table.Count)
+	}
+	if err != nil {
+		fmt.Fprintf(r, "export td %s: %s\n", dir, err)
+	}
+}
+
+// cmdExportDelta writes what changed since a given time: <dir> [since <when>] [as <source>].
+func cmdExportDelta(r *Repl, args []string, _ *CommandParser) {
+	joined := strings.Join(args, " ")
+	source := ""
+	if separator := strings.LastIndex(joined, " as "); separator >= 0 {
+		joined, source = strings.TrimSpace(joined[:separator]), strings.TrimSpace(joined[separator+4:])
+	}
+	dir, since := joined, ""
+	if separator := strings.LastIndex(joined, " since "); separator >= 0 {
+		dir, since = strings.TrimSpace(joined[:separator]), strings.TrimSpace(joined[separator+7:])
+	}
+	if dir == "" {
+		fmt.Fprintln(r, "Please specify <dir> [since <when>] [as <source>], e.g: export delta deltas/today since 24h as curator")
+		return
+	}
+	var base uint64
+	if since != "" {
+		var err error
+		if base, err = parseDeltaTime(since, time.Now()); err != nil {
+			fmt.Fprintln(r, err)
+			return
+		}
+	}
+	target, files, err := ExportDelta(r.db, dir, base, source)
+	for _, file := range files {
+		fmt.Fprintf(r, "- %s: %d items\n", file.Filename, file.Count)
+	}
+	if err != nil {
+		fmt.Fprintf(r, "export delta %s: %s\n", dir, err)
+		return
+	}
+	fmt.Fprintf(r, "Wrote changes from %s to %s into %s.\n", formatDeltaTime(base), formatDeltaTime(target), dir)
+}
+
+// cmdExportDump writes the whole database for import elsewhere: <dir> [as <source>].
+func cmdExportDump(r *Repl, args []string, _ *CommandParser) {
+	dir, source := strings.Join(args, " "), ""
+	if separator := strings.LastIndex(dir, " as "); separator >= 0 {
+		dir, source = strings.TrimSpace(dir[:separator]), strings.TrimSpace(dir[separator+4:])
+	}
+	if dir == "" {
+		fmt.Fprintln(r, "Please specify <dir> [as <source>], e.g: export dump dumps/full as curator")
+		return
+	}
+	dumped, files, err := ExportDump(r.db, dir, source)
+	for _, file := range files {
+		fmt.Fprintf(r, "- %s: %d items\n", file.Filename, file.Count)
+	}
+	if err != nil {
+		fmt.Fprintf(r, "export dump %s: %s\n", dir, err)
+		return
+	}
+	fmt.Fprintf(r, "Wrote everything up to %s into %s.\n", formatDeltaTime(dumped), dir)
+}
+
+// cmdExportPrices writes stations' listings in TradeDangerous .prices format.
+func cmdExportPrices(r *Repl, args []string, _ *CommandParser) {
+	joined := strings.Join(args, " ")
+	pathname := ""
+	if separator := strings.LastIndex(joined, " to "); separator >= 0 {
+		joined, pathname = joined[:separator], strings.TrimSpace(joined[separator+4:])
+	}
+	if strings.TrimSpace(joined) == "" {
+		fmt.Fprintln(r, "Please specify <station>[, <station> ...] [to <file>], e.g: export prices sol/galileo to sol.prices")
+		return
+	}
+	facilities := make([]*Facility, 0, 4)
+	for _, name := range strings.Split(joined, ",") {
+		facility := r.lookupFacility(strings.TrimSpace(name))
+		if facility == nil {
+			fmt.Fprintf(r, "Unrecognized station: %s. Use <market id>, <system>/<station> or here.\n", strings.TrimSpace(name))
+			return
+		}
+		facilities = append(facilities, facility)
+	}
+
+	if pathname == "" {
+		if err := r.sdb.WritePrices(r, facilities); err != nil {
+			fmt.Fprintln(r, "Error:", err)
+		}
+		return
+	}
+	file, err := os.Create(pathname)
+	if err != nil {
+		fmt.Fprintf(r, "export %s: %s\n", pathname, err)
+		return
+	}
+	err = r.sdb.WritePrices(file, facilities)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		fmt.Fprintf(r, "export %s: %s\n", pathname, err)
+		return
+	}
+	fmt.Fprintf(r, "Wrote %d stations to %s.\n", len(facilities), pathname)
+}
+
+// cmdDbSynced shows the time the database is synced to or, for a database
+// built from dumps that don't record one, sets it: [<when>].
+func cmdDbSynced(r *Repl, args []string, _ *CommandParser) {
+	if len(args) > 0 {
+		synced, err := parseDeltaTime(strings.Join(args, " "), time.Now())
+		if err == nil {
+			err = r.db.setSyncedTo(synced)
+		}
+		if err != nil {
+			fmt.Fprintln(r, err)
+			return
+		}
+	}
+	synced, err := r.db.SyncedTo()
+	if err != nil {
+		fmt.Fprintln(r, "Error:", err)
+		return
+	}
+	fmt.Fprintf(r, "Synced to %s.\n", formatDeltaTime(synced))
+}
+
+func cmdDbBackup(r *Repl, args []string, _ *CommandParser) {
+	pathname := strings.Join(args, " ")
+	if pathname == "" {
+		fmt.Fprintln(r, "Please specify the file to write the backup to, e.g: db backup menace.bak")
+		return
+	}
+	entries, err := BackupDatabase(r.db, pathname)
+	if err != nil {
+		fmt.Fprintf(r, "backup %s: %s\n", pathname, err)
+		return
+	}
+	for _, entry := range entries {
+		fmt.Fprintf(r, "- %s: %d items\n", entry.Filename, entry.Count)
+	}
+	fmt.Fprintf(r, "Backup written to %s.\n", pathname)
+}
+
+func cmdDbRestore(r *Repl, args []string, _ *CommandParser) {
+	pathname := strings.Join(args, " ")
+	if pathname == "" {
+		fmt.Fprintln(r, "Please specify the backup file to restore, e.g: db restore menace.bak")
+		return
+	}
+	entries, err := RestoreDatabase(r.db, pathname)
+	if err != nil {
+		fmt.Fprintf(r, "restore %s: %s\n", pathname, err)
+		return
+	}
+	for _, entry := range entries {
+		fmt.Fprintf(r, "- %s: %d items\n", entry.Filename, entry.Count)
+	}
+
+	// The in-memory indexes describe the old database, so rebuild them.
+	sdb := NewSystemDatabase(r.db)
+	if err = r.db.LoadDatabase(sdb); err != nil {
+		fmt.Fprintf(r, "restore %s: reloading: %s\n", pathname, err)
+		return
+	}
+	r.sdb = sdb
+	r.updateSnapshot()
+	fmt.Fprintf(r, "Restored from %s.\n", pathname)
+}
+
+func cmdDbKeygen(r *Repl, args []string, _ *CommandParser) {
+	pathname := strings.Join(args, " ")
+	if pathname == "" {
+		fmt.Fprintln(r, "Please specify the file to write the new key to, e.g: db keygen curator.key")
+		return
+	}
+	public, err := GenerateSigningKey(pathname)
+	if err != nil {
+		fmt.Fprintf(r, "keygen %s: %s\n", pathname, err)
+		return
+	}
+	fmt.Fprintf(r, "Wrote private key to %s; keep it safe.\nPublic key: %s\n", pathname, hex.EncodeToString(public))
+}
+
+// cmdDbSign signs a GOM file: <key file> <gom file> [to <gom file>].
+func cmdDbSign(r *Repl, args []string, _ *CommandParser) {
+	if len(args) < 2 {
+		fmt.Fprintln(r, "Please specify <key file> <gom file> [to <gom file>], e.g: db sign curator.key listings.gom to listings.gom.zst")
+		return
+	}
+	inPath := strings.Join(args[1:], " ")
+	outPath := inPath
+	if separator := strings.LastIndex(inPath, " to "); separator >= 0 {
+		inPath, outPath = inPath[:separator], strings.TrimSpace(inPath[separator+4:])
+	}
+	key, err := LoadSigningKey(args[0])
+	if err != nil {
+		fmt.Fprintf(r, "sign: %s\n", err)
+		return
+	}
+	count, err := SignGOMFile(inPath, outPath, key)
+	if err != nil {
+		fmt.Fprintf(r, "sign %s: %s\n", inPath, err)
+		return
+	}
+	fmt.Fprintf(r, "Signed %d items into %s.\n", count, outPath)
+}
+
+func cmdDbVerify(r *Repl, args []string, _ *CommandParser) {
+	pathname := strings.Join(args, " ")
+	if pathname == "" {
+		fmt.Fprintln(r, "Please specify the GOM file to verify, e.g: db verify listings.gom.gz")
+		return
+	}
+	verification, err := VerifyGOMFile(pathname)
+	if err != nil {
+		fmt.Fprintf(r, "verify %s: %s\n", pathname, err)
+		return
+	}
+	checksum := verification.Checksum
+	if checksum == "" {
+		checksum = "none"
+	}
+	fmt.Fprintf(r, "%s: %d items, checksum: %s\n", pathname, verification.Count, checksum)
+	if verification.Signer == nil {
+		fmt.Fprintln(r, "Not signed.")
+		return
+	}
+	trust := "not in --trustedkeys"
+	if *TrustedKeys == "" {
+		trust = "no --trustedkeys given"
+	} else if trusted, err := LoadTrustedKeys(*TrustedKeys); err != nil {
+		trust = err.Error()
+	} else if trusted[hex.EncodeToString(verification.Signer)] {
+		trust = "trusted"
+	}
+	fmt.Fprintf(r, "Signed by %s (%s).\n", hex.EncodeToString(verification.Signer), trust)
+}
+
+func cmdDbSources(r *Repl, _ []string, _ *CommandParser) {
+	// Reread the file, in case it has been edited.
+	r.sdb.sources = nil
+	sources := r.sdb.dataSources().Sorted()
+	if len(sources) == 0 {
+		fmt.Fprintln(r, "No sources are registered with --sources, so the newest data wins.")
+		return
+	}
+	for _, source := range sources {
+		fmt.Fprintf(r, "- %s: precedence %d, %s\n", source.Name, source.Precedence, source.Trust)
+	}
+	fmt.Fprintln(r, "Other sources have precedence 0 and are trusted.")
+}
+
+// cmdDbProvenance shows the provenance of a system's or station's fields: <system> or <system>/<station>.
+func cmdDbProvenance(r *Repl, args []string, _ *CommandParser) {
+	name := strings.Join(args, " ")
+	var (
+		schemaName string
+		id         EntityID
+		fallback   Provenance
+		message    proto.Message
+		facility   *Facility
+	)
+	if facility = r.lookupFacility(name); facility != nil {
+		schemaName, id, message = "facilities", facility.ID, &gomschema.Facility{}
+		fallback.TimestampUtc = facility.GetTimestampUtc()
+		fmt.Fprintf(r, "%s (#%d):\n", facility.Name(), facility.ID)
+	} else if system := r.lookupSystem(name); system != nil {
+		schemaName, id, message = "systems", system.ID, &gomschema.System{}
+		fallback.TimestampUtc = system.GetTimestampUtc()
+		fmt.Fprintf(r, "%s (#%d):\n", system.Name(), system.ID)
+	} else {
+		fmt.Fprintln(r, "Not found. Use <system>, <system>/<station> or <market id>.")
+		return
+	}
+
+	describe := func(provenance Provenance) string {
+		source := provenance.Source
+		if source == "" {
+			source = "unknown source"
+		}
+		return fmt.Sprintf("%s at %s", source, time.Unix(int64(provenance.TimestampUtc), 0).UTC().Format(pricesTimeFormat))
+	}
+	record, err := r.db.GetProvenance(schemaName, id)
+	if err != nil {
+		fmt.Fprintf(r, "provenance: %s\n", err)
+		return
+	}
+	fields := message.ProtoReflect().Descriptor().Fields()
+	for idx := 0; idx < fields.Len(); idx++ {
+		if field := string(fields.Get(idx).Name()); field != "id" && field != "timestamp_utc" {
+			fmt.Fprintf(r, "- %s: %s\n", field, describe(provenanceRecord(record).get(field, fallback)))
+		}
+	}
+	if facility == nil || len(facility.listings) == 0 {
+		return
+	}
+
+	if record, err = r.db.GetProvenance("listings", id); err != nil {
+		fmt.Fprintf(r, "provenance: %s\n", err)
+		return
+	}
+	listings := make([]*Listing, 0, len(facility.listings))
+	for _, listing := range facility.listings {
+		listings = append(listings, listing)
+	}
+	sort.Slice(listings, func(i, j int) bool { return listings[i].CommodityID < listings[j].CommodityID })
+	fmt.Fprintln(r, "Listings:")
+	for _, listing := range listings {
+		provenance := provenanceRecord(record).get(listingField(listing.CommodityID), Provenance{TimestampUtc: listing.GetTimestampUtc()})
+		fmt.Fprintf(r, "- %s: %s\n", r.sdb.GetCommodityByID(listing.CommodityID).Name(), describe(provenance))
+	}
+}
+
+// cmdDbDiff compares two databases, GOM file sets or backups: <a> <b> [json].
+func cmdDbDiff(r *Repl, args []string, _ *CommandParser) {
+	asJSON := len(args) > 0 && strings.EqualFold(args[len(args)-1], "json")
+	if asJSON {
+		args = args[:len(args)-1]
+	}
+	if len(args) != 2 {
+		fmt.Fprintln(r, "Please specify the two databases, GOM directories or backups to compare, e.g: db diff yesterday.bak menace.db")
+		return
+	}
+	diff, err := NewDatabaseDiff(args[0], args[1])
+	if err != nil {
+		fmt.Fprintf(r, "diff: %s\n", err)
+		return
+	}
+	if asJSON {
+		data, err := json.MarshalIndent(diff, "", "  ")
+		if err != nil {
+			fmt.Fprintf(r, "diff: %s\n", err)
+			return
+		}
+		fmt.Fprintln(r, string(data))
+		return
+	}
+	for _, change := range diff.Changes {
+		fmt.Fprintln(r, change)
+	}
+	summaries := make([]string, 0, len(diffKinds))
+	for _, kind := range diffKinds {
+		summary := diff.Summary[kind]
+		summaries = append(summaries, fmt.Sprintf("%s: %d added, %d removed, %d changed", kind, summary.Added, summary.Removed, summary.Changed))
+	}
+	fmt.Fprintln(r, strings.Join(summaries, "; "))
+}
+
+// FollowJournal watches the game's journal directory, applying new events
+// between commands until stop is called.
+func (r *Repl) FollowJournal(dir string) (stop func(), err error) {
+	tailer, err := NewJournalTailer(dir)
+	if err != nil {
+		return nil, err
+	}
+	done := make(chan struct{})
+	r.journal = tailer.Follow(journalPollInterval, done)
+	return func() { close(done) }, nil
+}
+
+// applyJournal applies the journal data collected since the last command.
+func (r *Repl) applyJournal() {
+	if r.journal == nil {
+		return
+	}
+	importer := NewJournalImporter(r.sdb, r.db)
+	defer importer.Close()
+	importer.commander = r.getCommander()
+	defer func() {
+		if importer.CommanderUpdates > 0 {
+			r.saveCommander()
+		}
+	}()
+	for {
+		select {
+		case batch, ok := <-r.journal:
+			if !ok {
+				r.journal = nil
+				return
+			}
+			if err := FilterError(batch.Import(importer)); err != nil {
+				fmt.Fprintf(r, "journal: %s: %s\n", batch.Filename, err)
+			}
+		default:
+			return
 		}
 	}
 }
@@ -98,6 +664,7 @@ func (r *Repl) Run(prompt string) error {
 			continue
 		}
 		if len(args) >= 0 {
+			r.applyJournal()
 			commands.Parse(r, args)
 		}
 	}
@@ -195,6 +762,55 @@ func cmdToggleOnDuplicate(r *Repl, args []string, _ *CommandParser) {
 	fmt.Fprintln(r, "OnDuplicate is now:", toggleBool(ErrorOnUnknown))
 }
 
+func cmdSetMaxAge(r *Repl, args []string, _ *CommandParser) {
+	if len(args) == 1 {
+		maxAge, err := ParseAge(args[0])
+		if err != nil {
+			fmt.Fprintln(r, err)
+			return
+		}
+		*MaxAge = maxAge
+		r.sdb.maxAge = maxAge
+	}
+	if r.sdb.maxAge == 0 {
+		fmt.Fprintln(r, "MaxAge is now: off")
+	} else {
+		fmt.Fprintln(r, "MaxAge is now:", r.sdb.maxAge)
+	}
+}
+
+func cmdSetMiningRate(r *Repl, args []string, _ *CommandParser) {
+	if len(args) == 1 {
+		rate, err := strconv.ParseInt(args[0], 10, 64)
+		if err != nil || rate < 0 {
+			fmt.Fprintf(r, "Invalid mining rate: %s\n", args[0])
+			return
+		}
+		*MiningRate = rate
+		r.sdb.miningRate = rate
+	}
+	fmt.Fprintf(r, "MiningRate is now: %dcr/h\n", r.sdb.miningRate)
+}
+
+// overrideMaxAge strips a "maxage=<age>" argument from args and applies it to
+// the database until restore is called.
+func (r *Repl) overrideMaxAge(args []string) (remaining []string, restore func(), err error) {
+	previous := r.sdb.maxAge
+	restore = func() { r.sdb.maxAge = previous }
+	remaining = make([]string, 0, len(args))
+	for _, arg := range args {
+		if strings.HasPrefix(strings.ToLower(arg), "maxage=") {
+			if r.sdb.maxAge, err = ParseAge(arg[len("maxage="):]); err != nil {
+				restore()
+				return nil, nil, err
+			}
+			continue
+		}
+		remaining = append(remaining, arg)
+	}
+	return remaining, restore, nil
+}
+
 func cmdToggleOnUnknown(r *Repl, args []string, _ *CommandParser) {
 	fmt.Fprintln(r, "OnUnknown is now:", toggleBool(ErrorOnDuplicate))
 }
@@ -217,7 +833,7 @@ func cmdProbe(r *Repl, args []string, _ *CommandParser) {
 	}
 
 	systemName := strings.Join(args[1:], " ")
-	system := r.sdb.GetSystem(systemName)
+	system := r.lookupSystem(systemName)
 	if system == nil {
 		fmt.Fprintf(r, "Unrecognized system: %s\n", err)
 		return
@@ -252,11 +868,719 @@ func cmdProbe(r *Repl, args []string, _ *CommandParser) {
 	}
 }
 
-var commands = CommandParser{
-	commands: map[string]CommandParser{
-		"exit":   {help: "Exit the application.", action: func(r *Repl, _ []string, _ *CommandParser) { r.terminated = true }},
+func cmdSystemNearest(r *Repl, args []string, _ *CommandParser) {
+	if len(args) < 2 {
+		fmt.Fprintln(r, "Please specify <count> <system name> [filters], e.g: system nearest 5 sol allegiance=empire pad=large")
+		fmt.Fprintln(r, "Filters:", SystemFilterHelp)
+		return
+	}
+	count, err := strconv.Atoi(args[0])
+	if err != nil || count <= 0 {
+		fmt.Fprintf(r, "Invalid count: %s\n", args[0])
+		return
+	}
+
+	// The system name runs up to the first filter.
+	nameEnd := 2
+	for nameEnd < len(args) && !IsSystemFilter(args[nameEnd]) {
+		nameEnd++
+	}
+	systemName := strings.Join(args[1:nameEnd], " ")
+	system := r.lookupSystem(systemName)
+	if system == nil {
+		fmt.Fprintf(r, "Unrecognized system: %s\n", systemName)
+		return
+	}
+	predicate, err := ParseSystemFilters(args[nameEnd:])
+	if err != nil {
+		fmt.Fprintln(r, err)
+		return
+	}
+
+	start := time.Now()
+	neighbors := r.sdb.NearestSystems(system, count, predicate)
+	if len(neighbors) == 0 {
+		fmt.Fprintln(r, "No matching systems.")
+		return
+	}
+	for _, neighbor := range neighbors {
+		fmt.Fprintf(r, "- %8.2fly %s\n", neighbor.DistSq.Root(), neighbor.System.Name())
+	}
+	fmt.Fprintf(r, "Took: %s\n", time.Since(start))
+}
+
+// findNearCount is how many systems 'find ... near' commands list.
+const findNearCount = 10
+
+// defaultCargoUnits is the hold size assumed when ranking trades, unless the
+// commander's hold size is known.
+const defaultCargoUnits = 100
+
+// cargoUnits is the hold size to rank trades for.
+func (r *Repl) cargoUnits() int {
+	if capacity := r.getCommander().CargoCapacity; capacity > 0 {
+		return capacity
+	}
+	return defaultCargoUnits
+}
+
+// parseFindNear splits "<item> near <system> [filters]" arguments.
+func parseFindNear(args []string) (item string, systemName string, filters []string, ok bool) {
+	near := -1
+	for idx, arg := range args {
+		if strings.EqualFold(arg, "near") {
+			near = idx
+		}
+	}
+	if near < 1 || near == len(args)-1 {
+		return "", "", nil, false
+	}
+	nameEnd := near + 2
+	for nameEnd < len(args) && !IsSystemFilter(args[nameEnd]) {
+		nameEnd++
+	}
+	return strings.Join(args[:near], " "), strings.Join(args[near+1:nameEnd], " "), args[nameEnd:], true
+}
+
+// cmdFindNear lists the nearest facilities selling any of the items matching a name.
+func cmdFindNear(r *Repl, args []string, kind string, lookup func(string) []EntityID, sells func(*Facility, []EntityID) bool) {
+	itemName, systemName, filters, ok := parseFindNear(args)
+	if !ok {
+		fmt.Fprintf(r, "Please specify <%s> near <system> [filters], e.g: %s find python near sol\n", kind, kind)
+		return
+	}
+	items := lookup(itemName)
+	if len(items) == 0 {
+		fmt.Fprintf(r, "Unrecognized %s: %s\n", kind, itemName)
+		return
+	}
+	system := r.lookupSystem(systemName)
+	if system == nil {
+		fmt.Fprintf(r, "Unrecognized system: %s\n", systemName)
+		return
+	}
+	systemFilter, err := ParseSystemFilters(filters)
+	if err != nil {
+		fmt.Fprintln(r, err)
+		return
+	}
+
+	start := time.Now()
+	results := r.sdb.NearestFacilities(system, findNearCount, func(facility *Facility) bool {
+		return sells(facility, items) && (systemFilter == nil || systemFilter(facility.System))
+	})
+	if len(results) == 0 {
+		fmt.Fprintf(r, "Nowhere is known to sell %s.\n", itemName)
+		return
+	}
+	for _, result := range results {
+		fmt.Fprintf(r, "- %8.2fly %s (%dls)\n", result.DistSq.Root(), result.Facility.Name(), result.Facility.LsFromStar)
+	}
+	fmt.Fprintf(r, "Took: %s\n", time.Since(start))
+}
+
+func cmdOutfitFind(r *Repl, args []string, _ *CommandParser) {
+	cmdFindNear(r, args, "module", r.sdb.FindModules, func(facility *Facility, modules []EntityID) bool {
+		return facility.Outfitting.HasAny(modules)
+	})
+}
+
+func cmdShipFind(r *Repl, args []string, _ *CommandParser) {
+	cmdFindNear(r, args, "ship", r.sdb.FindShips, func(facility *Facility, ships []EntityID) bool {
+		return facility.Shipyard.HasAny(ships)
+	})
+}
+
+// cmdTrade lists what to buy at one station to sell at another, best first
+// for the commander's hold: <station> to <station>.
+func cmdTrade(r *Repl, args []string, _ *CommandParser) {
+	args, restore, err := r.overrideMaxAge(args)
+	if err != nil {
+		fmt.Fprintln(r, err)
+		return
+	}
+	defer restore()
+	joined := strings.Join(args, " ")
+	separator := strings.LastIndex(joined, " to ")
+	if separator < 0 {
+		fmt.Fprintln(r, "Please specify <system>/<station> to <system>/<station>.")
+		return
+	}
+	src, dst := r.lookupFacility(joined[:separator]), r.lookupFacility(joined[separator+4:])
+	if src == nil || dst == nil {
+		fmt.Fprintln(r, "Unrecognized station. Use <market id>, <system>/<station> or here.")
+		return
+	}
+	units := r.cargoUnits()
+	outcomes := r.sdb.FindTradeOutcomes(src, dst, units, uint64(time.Now().Unix()))
+	if len(outcomes) == 0 {
+		fmt.Fprintf(r, "Nothing to trade from %s to %s.\n", src.Name(), dst.Name())
+		return
+	}
+	fmt.Fprintf(r, "%s -> %s, %d tons:\n", src.Name(), dst.Name(), units)
+	for _, outcome := range outcomes {
+		fmt.Fprintf(r, "- %-24s buy %7dcr, gain %7dcr x %d, supply %s, demand %s, est %.0fcr", outcome.Commodity.Name(),
+			outcome.CostCr, outcome.GainCr, outcome.TradableUnits(units), bracketName(outcome.SupplyLevel),
+			bracketName(outcome.DemandLevel), outcome.Score(units))
+		if perHour := outcome.CreditsPerHour(units); perHour > 0 {
+			fmt.Fprintf(r, ", %.0fcr/h", perHour)
+		}
+		fmt.Fprintln(r)
+	}
+}
+
+func cmdSmuggleRun(r *Repl, args []string, _ *CommandParser) {
+	args, restore, err := r.overrideMaxAge(args)
+	if err != nil {
+		fmt.Fprintln(r, err)
+		return
+	}
+	defer restore()
+	joined := strings.Join(args, " ")
+	separator := strings.LastIndex(joined, " to ")
+	if separator < 0 {
+		fmt.Fprintln(r, "Please specify <system>/<station> to <system>/<station>.")
+		return
+	}
+	src, dst := r.lookupFacility(joined[:separator]), r.lookupFacility(joined[separator+4:])
+	if src == nil || dst == nil {
+		fmt.Fprintln(r, "Unrecognized station. Use <market id>, <system>/<station> or here.")
+		return
+	}
+	if !dst.HasFeatures(FeatBlackMarket) {
+		fmt.Fprintf(r, "%s has no black market.\n", dst.Name())
+		return
+	}
+	units := r.cargoUnits()
+	outcomes := r.sdb.FindSmugglingOutcomes(src, dst, units, uint64(time.Now().Unix()))
+	if len(outcomes) == 0 {
+		fmt.Fprintf(r, "Nothing to smuggle from %s to %s.\n", src.Name(), dst.Name())
+		return
+	}
+	fmt.Fprintf(r, "%s -> %s (%s security):\n", src.Name(), dst.Name(), dst.System.SecurityLevel)
+	for _, outcome := range outcomes {
+		fmt.Fprintf(r, "- %-24s buy %7dcr, gain %7dcr, %.0fcr/h, risk %s\n", outcome.Commodity.Name(), outcome.CostCr, outcome.GainCr,
+			outcome.CreditsPerHour(units), outcome.Risk)
+	}
+}
+
+func cmdSmuggleFence(r *Repl, args []string, _ *CommandParser) {
+	itemName, systemName, filters, ok := parseFindNear(args)
+	if !ok {
+		fmt.Fprintln(r, "Please specify <commodity> near <system> [filters], e.g: smuggle fence slaves near lave")
+		return
+	}
+	commodities := r.sdb.FindCommodities(itemName)
+	if len(commodities) != 1 {
+		fmt.Fprintf(r, "Unrecognized or ambiguous commodity: %s\n", itemName)
+		return
+	}
+	commodity := r.sdb.GetCommodityByID(commodities[0])
+	system := r.lookupSystem(systemName)
+	if system == nil {
+		fmt.Fprintf(r, "Unrecognized system: %s\n", systemName)
+		return
+	}
+	systemFilter, err := ParseSystemFilters(filters)
+	if err != nil {
+		fmt.Fprintln(r, err)
+		return
+	}
+
+	fences := r.sdb.FindFences(system, findNearCount, systemFilter)
+	if len(fences) == 0 {
+		fmt.Fprintln(r, "No black markets found.")
+		return
+	}
+	for _, fence := range fences {
+		fmt.Fprintf(r, "- %8.2fly %s (%dls): %dcr, %s security\n", fence.DistSq.Root(), fence.Facility.Name(),
+			fence.Facility.LsFromStar, BlackMarketPrice(commodity, fence.Facility), fence.Facility.System.SecurityLevel)
+	}
+}
+
+// rareLoopStops is the most stops a planned rare loop will have.
+const rareLoopStops = 8
+
+// findRareSources parses "<distance> <system>" and returns the rares sold in range.
+func (r *Repl) findRareSources(args []string, command string) []RareSource {
+	args, restore, err := r.overrideMaxAge(args)
+	if err != nil {
+		fmt.Fprintln(r, err)
+		return nil
+	}
+	defer restore()
+	if len(args) < 2 {
+		fmt.Fprintf(r, "Please specify <distance in ly> and <system name>, e.g: rare %s 80 lave\n", command)
+		return nil
+	}
+	distance, err := strconv.ParseFloat(args[0], 64)
+	if err != nil {
+		fmt.Fprintf(r, "Invalid distance value: %s\n", err)
+		return nil
+	}
+	systemName := strings.Join(args[1:], " ")
+	system := r.lookupSystem(systemName)
+	if system == nil {
+		fmt.Fprintf(r, "Unrecognized system: %s\n", systemName)
+		return nil
+	}
+	sources, err := r.sdb.FindRareSources(system, distance, uint64(time.Now().Unix()))
+	if err != nil {
+		fmt.Fprintln(r, err)
+		return nil
+	}
+	if len(sources) == 0 {
+		fmt.Fprintf(r, "No rares are sold within %.fly of %s.\n", distance, system.Name())
+	}
+	return sources
+}
+
+func cmdRareFind(r *Repl, args []string, _ *CommandParser) {
+	for _, source := range r.findRareSources(args, "find") {
+		fmt.Fprintf(r, "- %-28s %s (%dls): %dcr, avg %dcr\n", source.Commodity.Name(), source.Facility.Name(),
+			source.Facility.LsFromStar, source.Listing.StationAsks, source.Commodity.AverageCr)
+	}
+}
+
+func cmdRareLoop(r *Repl, args []string, _ *CommandParser) {
+	sources := r.findRareSources(args, "loop")
+	if len(sources) == 0 {
+		return
+	}
+	loop := PlanRareLoop(sources, rareLoopStops)
+	for idx, stop := range loop.Stops {
+		fmt.Fprintf(r, "%d. %s (%.2fly)\n", idx+1, stop.Facility.Name(), stop.DistanceLy)
+		for _, rare := range stop.Sell {
+			fmt.Fprintf(r, "   sell %s\n", rare.Commodity.Name())
+		}
+		for _, rare := range stop.Buy {
+			fmt.Fprintf(r, "   buy  %s\n", rare.Commodity.Name())
+		}
+	}
+	fmt.Fprintf(r, "Loop: %.2fly, %dcr per unit of each rare\n", loop.DistanceLy, loop.GainCr)
+	for _, rare := range loop.Unsold {
+		fmt.Fprintf(r, "- unsold: %s from %s\n", rare.Commodity.Name(), rare.Facility.Name())
+	}
+}
+
+func cmdCarrierRoute(r *Repl, args []string, _ *CommandParser) {
+	joined := strings.Join(args, " ")
+	separator := strings.LastIndex(joined, " to ")
+	if separator < 0 {
+		fmt.Fprintln(r, "Please specify <system> to <system>, e.g: carrier route sol to colonia")
+		return
+	}
+	from, to := r.lookupSystem(joined[:separator]), r.lookupSystem(joined[separator+4:])
+	if from == nil || to == nil {
+		fmt.Fprintln(r, "Unrecognized system.")
+		return
+	}
+	load := 0
+	carrier := r.getCommander().Carrier
+	if carrier != nil {
+		load = carrier.Load()
+	}
+	route, err := r.sdb.PlanCarrierRoute(from, to, load)
+	if err != nil {
+		fmt.Fprintln(r, err)
+		return
+	}
+	for idx, jump := range route.Jumps {
+		fmt.Fprintf(r, "%d. %s (%.2fly, %dt tritium)\n", idx+1, jump.System.Name(), jump.DistanceLy, jump.Tritium)
+	}
+	fmt.Fprintf(r, "%d jumps, %.2fly, %dt tritium\n", len(route.Jumps), route.DistanceLy, route.Tritium)
+	if carrier != nil && route.Tritium > carrier.Tritium {
+		fmt.Fprintf(r, "Warning: the carrier only has %dt tritium.\n", carrier.Tritium)
+	}
+}
+
+func cmdCarrierSetups(r *Repl, args []string, _ *CommandParser) {
+	args, restore, err := r.overrideMaxAge(args)
+	if err != nil {
+		fmt.Fprintln(r, err)
+		return
+	}
+	defer restore()
+	if len(args) < 2 {
+		fmt.Fprintln(r, "Please specify <distance in ly> and <system name>, e.g: carrier setups 40 sol")
+		return
+	}
+	distance, err := strconv.ParseFloat(args[0], 64)
+	if err != nil {
+		fmt.Fprintf(r, "Invalid distance value: %s\n", err)
+		return
+	}
+	systemName := strings.Join(args[1:], " ")
+	system := r.lookupSystem(systemName)
+	if system == nil {
+		fmt.Fprintf(r, "Unrecognized system: %s\n", systemName)
+		return
+	}
+	capacity := CarrierCapacity
+	if carrier := r.getCommander().Carrier; carrier != nil {
+		capacity = carrier.FreeSpace()
+	}
+	if capacity <= 0 {
+		fmt.Fprintln(r, "The carrier has no free space.")
+		return
+	}
+	setups, err := r.sdb.SuggestCarrierSetups(system, distance, capacity, uint64(time.Now().Unix()))
+	if err != nil {
+		fmt.Fprintln(r, err)
+		return
+	}
+	if len(setups) == 0 {
+		fmt.Fprintln(r, "No high-demand markets found.")
+		return
+	}
+	if len(setups) > findNearCount {
+		setups = setups[:findNearCount]
+	}
+	for _, setup := range setups {
+		fmt.Fprintf(r, "- park at %s: %s from %s, %dcr/t, demand %d, %.0fcr/h\n", setup.Station.Name(), setup.Outcome.Commodity.Name(),
+			setup.Source.Name(), setup.Outcome.GainCr, setup.Outcome.Demand, setup.Outcome.CreditsPerHour(capacity))
+	}
+}
+
+func cmdCarrierShow(r *Repl, _ []string, _ *CommandParser) {
+	carrier := r.getCarrier()
+	fmt.Fprintf(r, "Carrier: hold %d/%dt, %dt tritium, %dt free\n", carrier.Load(), carrier.Capacity, carrier.Tritium, carrier.FreeSpace())
+	showOrders := func(label string, orders map[EntityID]CarrierOrder) {
+		ids := make([]EntityID, 0, len(orders))
+		for commodityID := range orders {
+			ids = append(ids, commodityID)
+		}
+		sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+		for _, commodityID := range ids {
+			if commodity := r.sdb.GetCommodityByID(commodityID); commodity != nil {
+				order := orders[commodityID]
+				fmt.Fprintf(r, "- %s %d %s at %dcr\n", label, order.Units, commodity.Name(), order.PriceCr)
+			}
+		}
+	}
+	for commodityID, units := range carrier.Cargo {
+		if commodity := r.sdb.GetCommodityByID(commodityID); commodity != nil {
+			fmt.Fprintf(r, "- %dt %s\n", units, commodity.Name())
+		}
+	}
+	showOrders("buying", carrier.BuyOrders)
+	showOrders("selling", carrier.SellOrders)
+}
+
+func cmdCarrierCargo(r *Repl, args []string, _ *CommandParser) {
+	text := strings.Join(args, " ")
+	if text == "" {
+		fmt.Fprintln(r, "Please specify <tons> <commodity>[, ...] or empty, e.g: carrier cargo 2000 gold, 500 silver")
+		return
+	}
+	cargo := make(map[EntityID]int)
+	if !strings.EqualFold(text, "empty") {
+		items, err := r.parseMinedCargo(text)
+		if err != nil {
+			fmt.Fprintln(r, err)
+			return
+		}
+		for _, item := range items {
+			cargo[item.Commodity.ID] += item.Units
+		}
+	}
+	carrier := r.getCarrier()
+	load := carrier.Tritium
+	for _, units := range cargo {
+		load += units
+	}
+	if load > carrier.Capacity {
+		fmt.Fprintf(r, "%s: %dt in a %dt hold\n", ErrCarrierCapacity, load, carrier.Capacity)
+		return
+	}
+	carrier.SetCargo(cargo)
+	r.saveCommander()
+	cmdCarrierShow(r, nil, nil)
+}
+
+func cmdCarrierTritium(r *Repl, args []string, _ *CommandParser) {
+	if len(args) != 1 {
+		fmt.Fprintln(r, "Please specify the tons of tritium in the hold, e.g: carrier tritium 1000")
+		return
+	}
+	tons, err := strconv.Atoi(strings.TrimSuffix(strings.ToLower(args[0]), "t"))
+	if err != nil || tons < 0 {
+		fmt.Fprintf(r, "Invalid tritium: %s\n", args[0])
+		return
+	}
+	carrier := r.getCarrier()
+	if carrier.Load()-carrier.Tritium+tons > carrier.Capacity {
+		fmt.Fprintf(r, "%s: %dt tritium with %dt free\n", ErrCarrierCapacity, tons, carrier.Capacity-carrier.Load()+carrier.Tritium)
+		return
+	}
+	carrier.Tritium = tons
+	r.saveCommander()
+	cmdCarrierShow(r, nil, nil)
+}
+
+// parseCarrierOrder parses "<units> <commodity> [at <price>]" for a carrier market order.
+func (r *Repl) parseCarrierOrder(args []string) (*Commodity, int, uint32, error) {
+	if len(args) < 2 {
+		return nil, 0, 0, fmt.Errorf("expected <units> <commodity> at <price>: %s", strings.Join(args, " "))
+	}
+	units, err := strconv.Atoi(args[0])
+	if err != nil || units < 0 {
+		return nil, 0, 0, fmt.Errorf("invalid units: %s", args[0])
+	}
+	var price uint64
+	fields := args[1:]
+	if len(fields) > 2 && strings.EqualFold(fields[len(fields)-2], "at") {
+		price, err = strconv.ParseUint(strings.TrimSuffix(strings.ToLower(fields[len(fields)-1]), "cr"), 10, 32)
+		if err != nil {
+			return nil, 0, 0, fmt.Errorf("invalid price: %s", fields[len(fields)-1])
+		}
+		fields = fields[:len(fields)-2]
+	} else if units > 0 {
+		return nil, 0, 0, fmt.Errorf("expected <units> <commodity> at <price>: %s", strings.Join(args, " "))
+	}
+	name := strings.Join(fields, " ")
+	commodities := r.sdb.FindCommodities(name)
+	if len(commodities) != 1 {
+		return nil, 0, 0, fmt.Errorf("%w: unrecognized or ambiguous commodity: %s", ErrUnknownEntity, name)
+	}
+	return r.sdb.GetCommodityByID(commodities[0]), units, uint32(price), nil
+}
+
+func cmdCarrierBuy(r *Repl, args []string, _ *CommandParser) {
+	commodity, units, price, err := r.parseCarrierOrder(args)
+	if err == nil {
+		err = r.getCarrier().SetBuyOrder(commodity, units, price)
+	}
+	if err != nil {
+		fmt.Fprintln(r, err)
+		return
+	}
+	r.saveCommander()
+	cmdCarrierShow(r, nil, nil)
+}
+
+func cmdCarrierSell(r *Repl, args []string, _ *CommandParser) {
+	commodity, units, price, err := r.parseCarrierOrder(args)
+	if err == nil {
+		err = r.getCarrier().SetSellOrder(commodity, units, price)
+	}
+	if err != nil {
+		fmt.Fprintln(r, err)
+		return
+	}
+	r.saveCommander()
+	cmdCarrierShow(r, nil, nil)
+}
+
+// parseMinedCargo parses a comma-separated list of "<tons> <commodity>",
+// adding up commodities that are listed more than once.
+func (r *Repl) parseMinedCargo(text string) ([]MinedCargo, error) {
+	cargo := make([]MinedCargo, 0, 4)
+	for _, item := range strings.Split(text, ",") {
+		fields := strings.Fields(item)
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("expected <tons> <commodity>: %s", strings.TrimSpace(item))
+		}
+		units, err := strconv.Atoi(fields[0])
+		if err != nil || units <= 0 {
+			return nil, fmt.Errorf("invalid tonnage: %s", fields[0])
+		}
+		name := strings.Join(fields[1:], " ")
+		commodities := r.sdb.FindCommodities(name)
+		if len(commodities) != 1 {
+			return nil, fmt.Errorf("%w: unrecognized or ambiguous commodity: %s", ErrUnknownEntity, name)
+		}
+		cargo = append(cargo, MinedCargo{Commodity: r.sdb.GetCommodityByID(commodities[0]), Units: units})
+	}
+	return mergeMinedCargo(cargo), nil
+}
+
+func cmdMiningSell(r *Repl, args []string, _ *CommandParser) {
+	args, restore, err := r.overrideMaxAge(args)
+	if err != nil {
+		fmt.Fprintln(r, err)
+		return
+	}
+	defer restore()
+	joined := strings.Join(args, " ")
+	separator := strings.Index(joined, ":")
+	var fields []string
+	if separator >= 0 {
+		fields = strings.Fields(joined[:separator])
+	}
+	if len(fields) < 2 {
+		fmt.Fprintln(r, "Please specify <distance in ly> <system>: <tons> <commodity>[, ...], e.g: mining sell 40 sol: 200 painite, 56 platinum")
+		return
+	}
+	distance, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		fmt.Fprintf(r, "Invalid distance value: %s\n", err)
+		return
+	}
+	systemName := strings.Join(fields[1:], " ")
+	system := r.lookupSystem(systemName)
+	if system == nil {
+		fmt.Fprintf(r, "Unrecognized system: %s\n", systemName)
+		return
+	}
+	cargo, err := r.parseMinedCargo(joined[separator+1:])
+	if err != nil {
+		fmt.Fprintln(r, err)
+		return
+	}
+
+	plan, err := r.sdb.PlanMiningSales(system, distance, cargo, uint64(time.Now().Unix()))
+	if err != nil {
+		fmt.Fprintln(r, err)
+		return
+	}
+	if len(plan.Stops) == 0 {
+		fmt.Fprintln(r, "Nowhere in range buys any of it.")
+		return
+	}
+	for idx, stop := range plan.Stops {
+		fmt.Fprintf(r, "%d. %s (%dls): %dcr, travel %dcr\n", idx+1, stop.Facility.Name(), stop.Facility.LsFromStar, stop.GrossCr, stop.TravelCostCr)
+		for _, sale := range stop.Sales {
+			fmt.Fprintf(r, "   %5dt %s @ %dcr\n", sale.Units, sale.Commodity.Name(), sale.PriceCr)
+		}
+	}
+	fmt.Fprintf(r, "Total: %dcr after travel at %dcr/h\n", plan.NetCr(), r.sdb.miningRate)
+	for _, haul := range plan.Unsold {
+		fmt.Fprintf(r, "- unsold: %dt %s\n", haul.Units, haul.Commodity.Name())
+	}
+}
+
+func cmdCommanderShow(r *Repl, _ []string, _ *CommandParser) {
+	commander := r.getCommander()
+	location := "unknown"
+	if facility := commander.Facility(r.sdb); facility != nil {
+		location = "docked at " + facility.Name()
+	} else if system := commander.System(r.sdb); system != nil {
+		location = "in " + system.Name()
+	}
+	fmt.Fprintf(r, "Location: %s\n", location)
+	ship := "unknown"
+	if known := commander.Ship(r.sdb); known != nil {
+		ship = known.Name()
+	}
+	if commander.ShipName != "" {
+		ship += fmt.Sprintf(" \"%s\"", commander.ShipName)
+	}
+	fmt.Fprintf(r, "Ship: %s, hold %d/%dt\n", ship, commander.CargoUnits(), commander.CargoCapacity)
+	fmt.Fprintf(r, "Credits: %dcr\n", commander.CreditsCr)
+	for commodityID, units := range commander.Cargo {
+		if commodity := r.sdb.GetCommodityByID(commodityID); commodity != nil {
+			fmt.Fprintf(r, "- %dt %s\n", units, commodity.Name())
+		}
+	}
+}
+
+func cmdCommanderAt(r *Repl, args []string, _ *CommandParser) {
+	name := strings.Join(args, " ")
+	if name == "" {
+		fmt.Fprintln(r, "Please specify <system>, <system>/<station> or <market id>, e.g: cmdr at sol/galileo")
+		return
+	}
+	facility := r.lookupFacility(name)
+	var system *System
+	if facility == nil {
+		if system = r.lookupSystem(name); system == nil {
+			fmt.Fprintf(r, "Unrecognized system or station: %s\n", name)
+			return
+		}
+	}
+	r.getCommander().SetLocation(system, facility)
+	r.saveCommander()
+	cmdCommanderShow(r, nil, nil)
+}
+
+func cmdCommanderCredits(r *Repl, args []string, _ *CommandParser) {
+	if len(args) != 1 {
+		fmt.Fprintln(r, "Please specify your credit balance, e.g: cmdr credits 1500000")
+		return
+	}
+	credits, err := strconv.ParseInt(strings.TrimSuffix(strings.ToLower(args[0]), "cr"), 10, 64)
+	if err != nil {
+		fmt.Fprintf(r, "Invalid credits: %s\n", args[0])
+		return
+	}
+	r.getCommander().CreditsCr = credits
+	r.saveCommander()
+	fmt.Fprintf(r, "Credits: %dcr\n", credits)
+}
+
+func cmdCommanderShip(r *Repl, args []string, _ *CommandParser) {
+	if len(args) < 2 {
+		fmt.Fprintln(r, "Please specify <hold tons> <ship>, e.g: cmdr ship 256 python")
+		return
+	}
+	capacity, err := strconv.Atoi(args[0])
+	if err != nil || capacity < 0 {
+		fmt.Fprintf(r, "Invalid hold size: %s\n", args[0])
+		return
+	}
+	name := strings.Join(args[1:], " ")
+	ships := r.sdb.FindShips(name)
+	if len(ships) != 1 {
+		fmt.Fprintf(r, "Unrecognized or ambiguous ship: %s\n", name)
+		return
+	}
+	commander := r.getCommander()
+	commander.ShipID, commander.ShipName, commander.CargoCapacity = ships[0], "", capacity
+	r.saveCommander()
+	cmdCommanderShow(r, nil, nil)
+}
+
+func cmdCommanderCargo(r *Repl, args []string, _ *CommandParser) {
+	text := strings.Join(args, " ")
+	if text == "" {
+		fmt.Fprintln(r, "Please specify <tons> <commodity>[, ...] or empty, e.g: cmdr cargo 64 gold, 32 silver")
+		return
+	}
+	commander := r.getCommander()
+	if strings.EqualFold(text, "empty") {
+		commander.Cargo = make(map[EntityID]int)
+	} else {
+		cargo, err := r.parseMinedCargo(text)
+		if err != nil {
+			fmt.Fprintln(r, err)
+			return
+		}
+		commander.Cargo = make(map[EntityID]int)
+		for _, item := range cargo {
+			commander.AddCargo(item.Commodity.ID, item.Units)
+		}
+	}
+	r.saveCommander()
+	cmdCommanderShow(r, nil, nil)
+}
+
+var commands = CommandParser{
+	commands: map[string]CommandParser{
+		"exit":   {help: "Exit the application.", action: func(r *Repl, _ []string, _ *CommandParser) { r.terminated = true }},
 		"quit":   {help: "", action: func(r *Repl, _ []string, _ *CommandParser) { r.terminated = true }},
-		"import": {help: "Import data from a file or directory.", action: cmdImport},
+		"import": {help: "Import data from a file or directory, TradeDangerous csv files with: import td <dir>, or a galaxy dump with: import galaxy <file>.", action: cmdImport},
+		"export": {commands: map[string]CommandParser{
+			"prices": {help: "Write stations' markets as TradeDangerous .prices: <station>[, ...] [to <file>].", action: cmdExportPrices},
+			"td":     {help: "Write TradeDangerous System, Item, Station and StationItem csv files into a directory.", action: cmdExportTD},
+			"delta":  {help: "Write what changed since a time as GOM files for import: <dir> [since <when>] [as <source>].", action: cmdExportDelta},
+			"dump":   {help: "Write the whole database as GOM files that deltas can follow: <dir> [as <source>].", action: cmdExportDump},
+		},
+			help: "Export data for other tools."},
+		"db": {commands: map[string]CommandParser{
+			"backup":  {help: "Write a backup archive of the database.", action: cmdDbBackup},
+			"restore": {help: "Replace the database with a backup archive.", action: cmdDbRestore},
+			"keygen":  {help: "Create a key for signing GOM files: <key file>.", action: cmdDbKeygen},
+			"sign":    {help: "Checksum and sign a GOM file: <key file> <gom file> [to <gom file>].", action: cmdDbSign},
+			"verify":  {help: "Check a GOM file's checksum and signature.", action: cmdDbVerify},
+			"diff":    {help: "Compare two databases, GOM file sets or backups: <a> <b> [json].", action: cmdDbDiff},
+			"synced": {help: "Show or set the time the database is synced to, which deltas must start from: [<when>].",
+				action: cmdDbSynced},
+			"sources": {help: "List the data sources registered with --sources, highest precedence first.", action: cmdDbSources},
+			"provenance": {help: "Show which source set each field of a system or station, and when: <system> or <system>/<station>.",
+				action: cmdDbProvenance},
+		},
+			help: "Database maintenance commands."},
 		"stats": {help: "Show stats on current database.", action: func(r *Repl, _ []string, _ *CommandParser) {
 			r.sdb.Stats(r.out)
 		}},
@@ -264,14 +1588,63 @@ var commands = CommandParser{
 			"warnings":    {help: "Toggle warnings on/off.", action: cmdToggleWarnings},
 			"onduplicate": {help: "Toggle on-duplicate on/off.", action: cmdToggleOnDuplicate},
 			"onunknown":   {help: "Toggle on-unknown on/off.", action: cmdToggleOnUnknown},
+			"maxage":      {help: "Ignore market data older than <age>, e.g. 36h, 3d or off. Queries also accept maxage=<age>.", action: cmdSetMaxAge},
+			"miningrate":  {help: "Set what an hour of mining earns, which selling a haul charges travel at: <credits>.", action: cmdSetMiningRate},
 		},
 			help: "Change environment settings.",
 		},
+		"cmdr": {commands: map[string]CommandParser{
+			"show":    {help: "Show the commander's location, ship, cargo and credits.", action: cmdCommanderShow},
+			"at":      {help: "Set the commander's location: <system>, <system>/<station> or <market id>.", action: cmdCommanderAt},
+			"credits": {help: "Set the commander's credit balance.", action: cmdCommanderCredits},
+			"ship":    {help: "Set the commander's ship: <hold tons> <ship>.", action: cmdCommanderShip},
+			"cargo":   {help: "Set the commander's cargo: <tons> <commodity>[, ...] or empty.", action: cmdCommanderCargo},
+		},
+			help:   "Commander state, kept up to date from the journal; commands accept 'here' for its location.",
+			action: cmdCommanderShow},
 		"system": {commands: map[string]CommandParser{
-			"find": {help: "Lookup a system by name.", action: cmdSystemFind},
-			"scan": {help: "Find other systems within a given distance of a system.", action: cmdProbe},
+			"find":    {help: "Lookup a system by name.", action: cmdSystemFind},
+			"scan":    {help: "Find other systems within a given distance of a system.", action: cmdProbe},
+			"nearest": {help: "Find the closest systems to a system, optionally filtered.", action: cmdSystemNearest},
 		},
 			help: "System-related commands."},
+		"station": {commands: map[string]CommandParser{
+			"find": {help: "Lookup a station by market id or <system>/<station>.", action: cmdStationFind},
+		},
+			help: "Station-related commands."},
+		"outfit": {commands: map[string]CommandParser{
+			"find": {help: "Find the nearest stations selling a module: <module> near <system> [filters].", action: cmdOutfitFind},
+		},
+			help: "Outfitting-related commands."},
+		"carrier": {commands: map[string]CommandParser{
+			"show":    {help: "Show the carrier's hold and market orders.", action: cmdCarrierShow},
+			"cargo":   {help: "Set the carrier's hold: <tons> <commodity>[, ...] or empty.", action: cmdCarrierCargo},
+			"tritium": {help: "Set the tons of tritium in the carrier's hold.", action: cmdCarrierTritium},
+			"buy":     {help: "Place a buy order on the carrier: <units> <commodity> at <price>; 0 units cancels.", action: cmdCarrierBuy},
+			"sell":    {help: "Place a sell order on the carrier: <units> <commodity> at <price>; 0 units cancels.", action: cmdCarrierSell},
+			"route":   {help: "Plot fleet carrier jumps between two systems, carrying its hold: <system> to <system>.", action: cmdCarrierRoute},
+			"setups":  {help: "Suggest stations to park a carrier beside within a given distance of a system, filling its free space.", action: cmdCarrierSetups},
+		},
+			help: "Fleet carrier commands."},
+		"mining": {commands: map[string]CommandParser{
+			"sell": {help: "Find where to sell mined cargo: <distance> <system>: <tons> <commodity>[, ...].", action: cmdMiningSell},
+		},
+			help: "Mining commands."},
+		"rare": {commands: map[string]CommandParser{
+			"find": {help: "List rare goods sold within a given distance of a system.", action: cmdRareFind},
+			"loop": {help: "Plan a loop buying and selling the rares within a given distance of a system.", action: cmdRareLoop},
+		},
+			help: "Rare goods commands."},
+		"trade": {help: "List commodities to buy at one station and sell at another, best first: <station> to <station>.", action: cmdTrade},
+		"smuggle": {commands: map[string]CommandParser{
+			"run":   {help: "List contraband to buy at one station and sell at another's black market: <station> to <station>.", action: cmdSmuggleRun},
+			"fence": {help: "Find the safest nearby black markets for stolen or illegal goods: <commodity> near <system> [filters].", action: cmdSmuggleFence},
+		},
+			help: "Black market trading."},
+		"ship": {commands: map[string]CommandParser{
+			"find": {help: "Find the nearest shipyards selling a ship: <ship> near <system> [filters].", action: cmdShipFind},
+		},
+			help: "Shipyard-related commands."},
 	},
 	action: func(r *Repl, _ []string, cp *CommandParser) {
 		cp.Info(r, "")
@@ -11,19 +11,42 @@ type Schema struct {
 	db    *Database
 	name  string
 	store *pogreb.DB
+	dirty bool // Whether anything has been written since the schema was opened.
+	// Where the provenance of the schema's fields is kept, once opened.
+	provenance *Schema
 }
 
 func (s *Schema) Close() error {
 	if s.store == nil {
 		panic("double close")
 	}
+	if s.provenance != nil {
+		if err := s.provenance.Close(); err != nil {
+			return err
+		}
+		s.provenance = nil
+	}
 	if err := s.store.Close(); err != nil {
 		return err
 	}
 	s.store = nil
+	s.dirty = false
 	return nil
 }
 
+// markDirty bumps the database's generation the first time the schema is
+// written to after being opened.
+func (s *Schema) markDirty() error {
+	if s.dirty {
+		return nil
+	}
+	s.dirty = true
+	if s.db == nil {
+		return nil
+	}
+	return s.db.bumpGeneration()
+}
+
 func (s Schema) Name() string {
 	return s.name
 }
@@ -33,9 +56,43 @@ func (s Schema) Count() uint32 {
 }
 
 func (s *Schema) Put(key []byte, value []byte) error {
+	if err := s.markDirty(); err != nil {
+		return err
+	}
 	return s.store.Put(key, value)
 }
 
+// Delete removes the value stored under key, if any.
+func (s *Schema) Delete(key []byte) error {
+	if err := s.markDirty(); err != nil {
+		return err
+	}
+	return s.store.Delete(key)
+}
+
+// Get returns the value stored under key, or nil if there is none.
+func (s *Schema) Get(key []byte) ([]byte, error) {
+	return s.store.Get(key)
+}
+
+// Iterate passes every key/value pair in the schema to callback, stopping
+// at the first error.
+func (s *Schema) Iterate(callback func(key, value []byte) error) error {
+	it := s.store.Items()
+	for {
+		key, val, err := it.Next()
+		if err == pogreb.ErrIterationDone {
+			return nil
+		}
+		if err == nil {
+			err = callback(key, val)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
 func (s *Schema) LoadData(loader *DataLoader) (int, error) {
 	defer func() { failOnError(s.Close()) }()
 
@@ -51,7 +108,7 @@ func (s *Schema) LoadData(loader *DataLoader) (int, error) {
 				break
 			}
 			if FilterError(err) != nil {
-				failOnError(s.store.Delete(key))
+				failOnError(s.Delete(key))
 				return loaded, err
 			}
 		}
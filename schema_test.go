@@ -6,7 +6,6 @@ import (
 	"github.com/stretchr/testify/require"
 	"os"
 	"path/filepath"
-	"strings"
 	"testing"
 )
 
@@ -88,16 +87,17 @@ func TestSchema_LoadData(t *testing.T) {
 		loaded := 0
 		loader := NewDataLoader(func([]byte) error { loaded++; return nil }, func() error { return nil })
 		require.NotNil(t, loader)
-		err = schema.LoadData("nothing", loader)
+		count, err := schema.LoadData(loader)
 		assert.Nil(t, err)
+		assert.Zero(t, count)
 		assert.Zero(t, loaded)
 	})
-	assert.Len(t, log, 1)
-	assert.True(t, strings.HasSuffix(log[0], "Loaded 0 nothing."))
+	// Reporting what was loaded is left to the caller.
+	assert.Empty(t, log)
 	// It should also have closed the schema.
 	assert.Panics(t, func() { failOnError(schema.Close()) })
 
-	runTest := func(setupFn func(*Schema)) ([]string, []string, uint32, error) {
+	runTest := func(setupFn func(*Schema)) ([]string, []string, int, uint32, error) {
 		schema, err = db.GetSchema("schema")
 		require.Nil(t, err)
 		setupFn(schema)
@@ -113,34 +113,35 @@ func TestSchema_LoadData(t *testing.T) {
 			}
 			return nil
 		}, func() error { loaded++; return nil })
+		var records int
 		log := captureLog(t, func(t *testing.T) {
-			err = schema.LoadData("stuff", loader)
+			records, err = schema.LoadData(loader)
 			assert.Panics(t, func() { failOnError(schema.Close()) })
 		})
 		schema, _ = db.GetSchema("schema")
 		defer func() { failOnError(schema.Close()) }()
 		count := schema.Count()
-		return log, marshaled, count, err
+		return log, marshaled, records, count, err
 	}
 
-	log, marshalled, count, err := runTest(func(schema *Schema) {
+	log, marshalled, records, count, err := runTest(func(schema *Schema) {
 		assert.Nil(t, schema.Put([]byte("hello"), []byte("world")))
 		assert.Nil(t, schema.Put([]byte("world"), []byte("hello")))
 		assert.Nil(t, schema.Put([]byte("final"), []byte("biscuit")))
 	})
 	assert.Nil(t, err)
 	assert.Equal(t, []string{"world", "hello", "biscuit"}, marshalled)
-	if assert.Len(t, log, 1) {
-		assert.True(t, strings.HasSuffix(log[0], "Loaded 3 stuff."))
-	}
+	assert.Empty(t, log)
+	assert.Equal(t, 3, records)
 	assert.EqualValues(t, 3, count)
 
-	log, marshalled, count, err = runTest(func(schema *Schema) {
+	log, marshalled, records, count, err = runTest(func(schema *Schema) {
 		assert.Nil(t, schema.Put([]byte("final"), []byte("error")))
 	})
 	assert.Error(t, err)
 	assert.Equal(t, []string{"world", "hello", "error"}, marshalled)
 	assert.Empty(t, log)
+	assert.Equal(t, 2, records)
 	assert.EqualValues(t, count, 2)
 }
 
@@ -0,0 +1,193 @@
+package main
+
+// GOM files can carry a checksum of their messages and an ed25519 signature,
+// so that dumps passed around by hand can be checked before they're used.
+// A curator generates a key with "db keygen" and signs dumps with "db sign";
+// everyone else lists the curator's public key in their --trustedkeys file.
+
+import (
+	"bufio"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/kfsone/gomenacing/pkg/gomschema"
+	flag "github.com/spf13/pflag"
+	"google.golang.org/protobuf/proto"
+)
+
+// TrustedKeys names a file of the public keys whose signed GOM files may be imported.
+var TrustedKeys = flag.String("trustedkeys", "", "File of hex ed25519 public keys; only GOM files they signed are imported.")
+
+// ErrUntrusted indicates a GOM file wasn't signed by a trusted key.
+var ErrUntrusted = errors.New("not signed by a trusted key")
+
+// GenerateSigningKey writes a new private key to pathname, returning its public key.
+func GenerateSigningKey(pathname string) (ed25519.PublicKey, error) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(pathname, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return nil, err
+	}
+	_, err = fmt.Fprintln(file, hex.EncodeToString(private.Seed()))
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return public, err
+}
+
+// LoadSigningKey reads a private key written by GenerateSigningKey.
+func LoadSigningKey(pathname string) (ed25519.PrivateKey, error) {
+	data, err := ioutil.ReadFile(pathname)
+	if err != nil {
+		return nil, err
+	}
+	seed, err := hex.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil || len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("%s: not a signing key", pathname)
+	}
+	return ed25519.NewKeyFromSeed(seed), nil
+}
+
+// LoadTrustedKeys reads a file of hex public keys, one per line; anything
+// after a '#' is a comment, such as who the key belongs to.
+func LoadTrustedKeys(pathname string) (map[string]bool, error) {
+	file, err := os.Open(pathname)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { Must(file.Close()) }()
+
+	keys := make(map[string]bool)
+	scanner := bufio.NewScanner(file)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := scanner.Text()
+		if comment := strings.IndexByte(line, '#'); comment >= 0 {
+			line = line[:comment]
+		}
+		line = strings.ToLower(strings.TrimSpace(line))
+		if line == "" {
+			continue
+		}
+		if key, err := hex.DecodeString(line); err != nil || len(key) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("%s: line %d: not a public key: %s", pathname, lineNo, line)
+		}
+		keys[line] = true
+	}
+	return keys, scanner.Err()
+}
+
+// checkSigner returns ErrUntrusted if trusted keys are configured and the
+// file wasn't signed by one of them.
+func checkSigner(gomFile *gomschema.GOMFile) error {
+	if *TrustedKeys == "" {
+		return nil
+	}
+	trusted, err := LoadTrustedKeys(*TrustedKeys)
+	if err != nil {
+		return err
+	}
+	if signer := gomFile.Signer(); signer == nil || !trusted[hex.EncodeToString(signer)] {
+		return ErrUntrusted
+	}
+	return nil
+}
+
+// GOMVerification describes what was checked when verifying a GOM file.
+type GOMVerification struct {
+	Count    int
+	Checksum string // Which checksum the file carries, if any.
+	Signer   ed25519.PublicKey
+}
+
+// VerifyGOMFile reads every message of a GOM file, checking it against the
+// file's checksum and signature.
+func VerifyGOMFile(pathname string) (verification GOMVerification, err error) {
+	file, err := os.Open(pathname)
+	if err != nil {
+		return verification, err
+	}
+	defer func() { Must(file.Close()) }()
+	gomFile, err := gomschema.OpenGOMFile(file)
+	if err != nil {
+		return verification, err
+	}
+	defer gomFile.Close()
+
+	for _, checksum := range []string{gomschema.ChecksumSHA256Key, gomschema.ChecksumCRC32CKey} {
+		if _, present := gomFile.Header().Userdata[checksum]; present {
+			verification.Checksum = strings.TrimPrefix(checksum, "checksum-")
+		}
+	}
+	verification.Signer = gomFile.Signer()
+	err = gomFile.Read(func(proto.Message, uint) error {
+		verification.Count++
+		return nil
+	})
+	return verification, err
+}
+
+// SignGOMFile rewrites a GOM file as outPath with a SHA-256 checksum and
+// signed by key. The messages are verified against any existing checksum or
+// signature first, and outPath is compressed according to its extension.
+func SignGOMFile(inPath, outPath string, key ed25519.PrivateKey) (count int, err error) {
+	file, err := os.Open(inPath)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { Must(file.Close()) }()
+	gomFile, err := gomschema.OpenGOMFile(file)
+	if err != nil {
+		return 0, err
+	}
+	defer gomFile.Close()
+
+	header := gomFile.Header()
+	writer := gomschema.NewGOMWriter(header.HeaderType, header.Source)
+	for name, value := range header.Userdata {
+		if name == gomschema.PayloadCompressionKey {
+			compression, err := gomschema.ParseCompression(string(value))
+			if err != nil {
+				return 0, err
+			}
+			writer.SetPayloadCompression(compression)
+			continue
+		}
+		if writer.Header().Userdata == nil {
+			writer.Header().Userdata = make(map[string][]byte)
+		}
+		writer.Header().Userdata[name] = value
+	}
+	if err = gomFile.Read(func(message proto.Message, _ uint) error { return writer.AddMessage(message) }); err != nil {
+		return 0, err
+	}
+	writer.SetChecksum(gomschema.ChecksumSHA256)
+	writer.Sign(key)
+
+	// Write alongside and then replace, so outPath may be inPath.
+	tmpPath := outPath + ".tmp"
+	out, err := os.Create(tmpPath)
+	if err != nil {
+		return 0, err
+	}
+	err = writer.WriteCompressed(out, gomschema.CompressionForFilename(outPath))
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmpPath, outPath)
+	}
+	if err != nil {
+		_ = os.Remove(tmpPath)
+		return 0, err
+	}
+	return writer.Count(), nil
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/hex"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	gom "github.com/kfsone/gomenacing/pkg/gomschema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+// writeIntegrityTestGOM returns a stream of two commodities with the given protection.
+func writeIntegrityTestGOM(t *testing.T, checksum gom.Checksum, key ed25519.PrivateKey) []byte {
+	writer := gom.NewGOMWriter(gom.Header_CCommodity, "test-source")
+	require.Nil(t, writer.AddMessage(&gom.Commodity{Id: 1, Name: "Gold"}))
+	require.Nil(t, writer.AddMessage(&gom.Commodity{Id: 2, Name: "Silver"}))
+	writer.SetChecksum(checksum)
+	writer.Sign(key)
+	var data bytes.Buffer
+	_, err := writer.WriteTo(&data)
+	require.Nil(t, err)
+	return data.Bytes()
+}
+
+// readIntegrityTestGOM returns how many messages were read from data before any error.
+func readIntegrityTestGOM(data []byte) (int, error) {
+	file, err := gom.OpenGOMFile(bytes.NewReader(data))
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+	count := 0
+	err = file.Read(func(proto.Message, uint) error {
+		count++
+		return nil
+	})
+	return count, err
+}
+
+func TestGOMChecksums(t *testing.T) {
+	for _, checksum := range []gom.Checksum{gom.ChecksumCRC32C, gom.ChecksumSHA256} {
+		data := writeIntegrityTestGOM(t, checksum, nil)
+		count, err := readIntegrityTestGOM(data)
+		assert.Nil(t, err)
+		assert.Equal(t, 2, count)
+
+		// Corrupt the last message: nothing is passed on.
+		data[len(data)-1] ^= 0x20
+		count, err = readIntegrityTestGOM(data)
+		assert.True(t, errors.Is(err, gom.ErrChecksumMismatch), err)
+		assert.Equal(t, 0, count)
+
+		_, err = readIntegrityTestGOM(data[:len(data)-3])
+		assert.Error(t, err)
+	}
+
+	// Without a checksum the corruption goes unnoticed.
+	data := writeIntegrityTestGOM(t, gom.ChecksumNone, nil)
+	data[len(data)-1] ^= 0x20
+	count, err := readIntegrityTestGOM(data)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, count)
+}
+
+func TestGOMSignatures(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(nil)
+	require.Nil(t, err)
+	data := writeIntegrityTestGOM(t, gom.ChecksumNone, private)
+	file, err := gom.OpenGOMFile(bytes.NewReader(data))
+	require.Nil(t, err)
+	assert.Equal(t, public, file.Signer())
+	file.Close()
+	count, err := readIntegrityTestGOM(data)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, count)
+
+	tampered := append([]byte{}, data...)
+	tampered[len(tampered)-1] ^= 0x20
+	_, err = readIntegrityTestGOM(tampered)
+	assert.True(t, errors.Is(err, gom.ErrBadSignature), err)
+
+	// The header is signed too.
+	tampered = bytes.Replace(data, []byte("test-source"), []byte("evil-source"), 1)
+	_, err = readIntegrityTestGOM(tampered)
+	assert.True(t, errors.Is(err, gom.ErrBadSignature), err)
+
+	unsigned := writeIntegrityTestGOM(t, gom.ChecksumNone, nil)
+	file, err = gom.OpenGOMFile(bytes.NewReader(unsigned))
+	require.Nil(t, err)
+	assert.Nil(t, file.Signer())
+	file.Close()
+}
+
+func TestSigningKeys(t *testing.T) {
+	testDir := GetTestDir()
+	defer testDir.Close()
+	keyPath := filepath.Join(testDir.Path(), "curator.key")
+
+	public, err := GenerateSigningKey(keyPath)
+	require.Nil(t, err)
+	private, err := LoadSigningKey(keyPath)
+	require.Nil(t, err)
+	assert.Equal(t, public, private.Public())
+
+	// An existing key isn't overwritten.
+	_, err = GenerateSigningKey(keyPath)
+	assert.Error(t, err)
+
+	trustedPath := filepath.Join(testDir.Path(), "trusted")
+	require.Nil(t, ioutil.WriteFile(trustedPath, []byte("# Our curator\n"+strings.ToUpper(hex.EncodeToString(public))+"  # Jameson\n\n"), 0644))
+	trusted, err := LoadTrustedKeys(trustedPath)
+	require.Nil(t, err)
+	assert.Equal(t, map[string]bool{hex.EncodeToString(public): true}, trusted)
+
+	require.Nil(t, ioutil.WriteFile(trustedPath, []byte("0123\n"), 0644))
+	_, err = LoadTrustedKeys(trustedPath)
+	assert.Error(t, err)
+	_, err = LoadSigningKey(trustedPath)
+	assert.Error(t, err)
+}
+
+func TestRepl_SignedGOM(t *testing.T) {
+	testDir := GetTestDir()
+	defer testDir.Close()
+	defer func(saved string) { *TrustedKeys = saved }(*TrustedKeys)
+	db, err := OpenDatabase(testDir.Path(), "signed.db")
+	require.Nil(t, err)
+	defer db.Close()
+
+	var output bytes.Buffer
+	repl := &Repl{db: db, sdb: NewSystemDatabase(db), out: &output}
+	keyPath := filepath.Join(testDir.Path(), "curator.key")
+	cmdDbKeygen(repl, []string{keyPath}, nil)
+	assert.Contains(t, output.String(), "Public key: ")
+	public := strings.TrimSpace(output.String()[strings.Index(output.String(), "Public key: ")+12:])
+	trustedPath := filepath.Join(testDir.Path(), "trusted")
+	require.Nil(t, ioutil.WriteFile(trustedPath, []byte(public+"\n"), 0644))
+
+	unsignedPath := filepath.Join(testDir.Path(), "commodities.gom")
+	signedPath := filepath.Join(testDir.Path(), "signed.gom.zst")
+	require.Nil(t, ioutil.WriteFile(unsignedPath, writeIntegrityTestGOM(t, gom.ChecksumNone, nil), 0644))
+
+	output.Reset()
+	cmdDbSign(repl, strings.Fields(keyPath+" "+unsignedPath+" to "+signedPath), nil)
+	assert.Contains(t, output.String(), "Signed 2 items into "+signedPath)
+
+	output.Reset()
+	*TrustedKeys = trustedPath
+	cmdDbVerify(repl, []string{signedPath}, nil)
+	assert.Contains(t, output.String(), ": 2 items, checksum: sha256\n")
+	assert.Contains(t, output.String(), "Signed by "+public+" (trusted).")
+	output.Reset()
+	cmdDbVerify(repl, []string{unsignedPath}, nil)
+	assert.Contains(t, output.String(), "checksum: none\nNot signed.")
+
+	output.Reset()
+	cmdImport(repl, []string{unsignedPath}, nil)
+	assert.Contains(t, output.String(), "not signed by a trusted key")
+	assert.Nil(t, repl.sdb.GetCommodity("Gold"))
+	cmdImport(repl, []string{signedPath}, nil)
+	assert.Contains(t, output.String(), "signed.gom.zst: read 2 items.")
+	assert.NotNil(t, repl.sdb.GetCommodity("Gold"))
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"sort"
+	"strings"
+
+	gom "github.com/kfsone/gomenacing/pkg/gomschema"
+)
+
+// GovernmentMask is a set of government types.
+type GovernmentMask uint32
+
+func governments(types ...gom.GovernmentType) GovernmentMask {
+	var mask GovernmentMask
+	for _, government := range types {
+		mask |= 1 << uint(government)
+	}
+	return mask
+}
+
+// Has returns true if government is in the set.
+func (m GovernmentMask) Has(government gom.GovernmentType) bool {
+	return m&(1<<uint(government)) != 0
+}
+
+// lawfulGovernments is every government that enforces any laws: anarchies
+// and unpopulated space don't care what's in your hold.
+var lawfulGovernments = governments(
+	gom.GovernmentType_GovCommunism, gom.GovernmentType_GovConfederacy, gom.GovernmentType_GovCooperative,
+	gom.GovernmentType_GovCorporate, gom.GovernmentType_GovDemocracy, gom.GovernmentType_GovDictatorship,
+	gom.GovernmentType_GovFeudal, gom.GovernmentType_GovPatronage, gom.GovernmentType_GovPrison,
+	gom.GovernmentType_GovPrisonColony, gom.GovernmentType_GovTheocracy)
+
+// ContrabandTable lists, by lower-case commodity name, the governments under
+// which a commodity is illegal.
+type ContrabandTable map[string]GovernmentMask
+
+// DefaultContraband is the table of illegal goods used unless told otherwise.
+var DefaultContraband = ContrabandTable{
+	"battle weapons": governments(
+		gom.GovernmentType_GovConfederacy, gom.GovernmentType_GovCooperative, gom.GovernmentType_GovCorporate,
+		gom.GovernmentType_GovDemocracy, gom.GovernmentType_GovCommunism),
+	"combat stabilisers": governments(
+		gom.GovernmentType_GovConfederacy, gom.GovernmentType_GovCooperative, gom.GovernmentType_GovDemocracy,
+		gom.GovernmentType_GovCommunism, gom.GovernmentType_GovTheocracy),
+	"imperial slaves": governments(
+		gom.GovernmentType_GovConfederacy, gom.GovernmentType_GovCooperative, gom.GovernmentType_GovDemocracy),
+	"landmines":        lawfulGovernments,
+	"narcotics":        lawfulGovernments,
+	"nerve agents":     lawfulGovernments,
+	"onionhead":        lawfulGovernments &^ governments(gom.GovernmentType_GovDictatorship),
+	"personal weapons": governments(gom.GovernmentType_GovConfederacy, gom.GovernmentType_GovCooperative, gom.GovernmentType_GovDemocracy, gom.GovernmentType_GovTheocracy),
+	"slaves":           lawfulGovernments &^ governments(gom.GovernmentType_GovDictatorship, gom.GovernmentType_GovFeudal, gom.GovernmentType_GovPatronage),
+	"tobacco":          governments(gom.GovernmentType_GovCooperative, gom.GovernmentType_GovTheocracy),
+	"beer":             governments(gom.GovernmentType_GovTheocracy),
+	"liquor":           governments(gom.GovernmentType_GovTheocracy),
+	"wine":             governments(gom.GovernmentType_GovTheocracy),
+}
+
+// IsIllegal returns true if commodity is contraband under government.
+func (t ContrabandTable) IsIllegal(commodity *Commodity, government gom.GovernmentType) bool {
+	return t[strings.ToLower(commodity.DbName)].Has(government)
+}
+
+// LegalityRisk describes the chance of being caught with contraband.
+type LegalityRisk int
+
+const (
+	// RiskNone means the cargo is legal.
+	RiskNone LegalityRisk = iota
+	// RiskMinimal means the cargo is illegal, but nobody is looking.
+	RiskMinimal
+	RiskLow
+	RiskMedium
+	RiskHigh
+)
+
+func (r LegalityRisk) String() string {
+	switch r {
+	case RiskNone:
+		return "none"
+	case RiskMinimal:
+		return "minimal"
+	case RiskLow:
+		return "low"
+	case RiskMedium:
+		return "medium"
+	default:
+		return "high"
+	}
+}
+
+// securityRisk is the risk of carrying contraband through a system with the given security.
+func securityRisk(security gom.SecurityLevel) LegalityRisk {
+	switch security {
+	case gom.SecurityLevel_SecurityNone, gom.SecurityLevel_SecurityAnarchy:
+		return RiskMinimal
+	case gom.SecurityLevel_SecurityLow:
+		return RiskLow
+	case gom.SecurityLevel_SecurityMedium:
+		return RiskMedium
+	default:
+		return RiskHigh
+	}
+}
+
+// riskWeight discounts a trade by the chance of losing the cargo to a scan.
+func riskWeight(risk LegalityRisk) float64 {
+	switch risk {
+	case RiskNone, RiskMinimal:
+		return 1.0
+	case RiskLow:
+		return 0.85
+	case RiskMedium:
+		return 0.6
+	default:
+		return 0.35
+	}
+}
+
+// facilityGovernment is the government whose laws apply when docking at facility.
+func facilityGovernment(facility *Facility) gom.GovernmentType {
+	if facility.Government != gom.GovernmentType_GovNone || facility.System == nil {
+		return facility.Government
+	}
+	return facility.System.Government
+}
+
+// assessLegality flags whether the outcome's commodity is contraband where it
+// is to be sold, and the risk of getting it there.
+func (sdb *SystemDatabase) assessLegality(outcome *TradeOutcome, dst *Facility) {
+	outcome.Illegal = sdb.contraband.IsIllegal(outcome.Commodity, facilityGovernment(dst))
+	outcome.Risk = RiskNone
+	if outcome.Illegal {
+		outcome.Risk = securityRisk(dst.System.SecurityLevel)
+	}
+}
+
+// BlackMarketPrice is what dst's black market is expected to pay for
+// commodity: the listed price if there is one, otherwise the galactic average.
+func BlackMarketPrice(commodity *Commodity, dst *Facility) uint32 {
+	if listing, exists := dst.listings[commodity.ID]; exists && listing.StationPays > 0 {
+		return listing.StationPays
+	}
+	return commodity.AverageCr
+}
+
+// FindSmugglingOutcomes lists the profitable runs of goods bought at src that
+// are illegal at dst, to be sold through dst's black market, best first for a
+// load of the given number of units. Black markets have no fixed demand, so
+// the weighting comes from the supply and the risk of the run.
+func (sdb *SystemDatabase) FindSmugglingOutcomes(src, dst *Facility, units int, now uint64) []*TradeOutcome {
+	if !dst.HasFeatures(FeatBlackMarket) {
+		return nil
+	}
+	government := facilityGovernment(dst)
+	travelTime := sdb.travel.Estimate(src, dst).Total()
+	outcomes := make([]*TradeOutcome, 0, 8)
+	for commodityID, seller := range src.listings {
+		commodity := sdb.GetCommodityByID(commodityID)
+		if commodity == nil || !sdb.contraband.IsIllegal(commodity, government) || sdb.isStale(seller.TimestampUtc, now) {
+			continue
+		}
+		buyer := &Listing{
+			CommodityID:   commodityID,
+			StationPays:   BlackMarketPrice(commodity, dst),
+			DemandBracket: gom.MarketBracket_BracketHigh,
+			TimestampUtc:  dst.TimestampUtc,
+		}
+		if outcome := NewTradeOutcome(commodity, seller, buyer, now); outcome != nil {
+			sdb.assessLegality(outcome, dst)
+			outcome.TravelTime = travelTime
+			outcomes = append(outcomes, outcome)
+		}
+	}
+	RankTradeOutcomes(outcomes, units)
+	return outcomes
+}
+
+// FindFences returns the black markets in the count systems nearest origin
+// that have one and satisfy predicate, safest first and then nearest first.
+// Stolen goods are only sellable at black markets, regardless of the law.
+func (sdb *SystemDatabase) FindFences(origin *System, count int, predicate SystemPredicate) []FacilityNeighbor {
+	fences := sdb.NearestFacilities(origin, count, func(facility *Facility) bool {
+		return facility.HasFeatures(FeatBlackMarket) && (predicate == nil || predicate(facility.System))
+	})
+	sort.SliceStable(fences, func(i, j int) bool {
+		return securityRisk(fences[i].Facility.System.SecurityLevel) < securityRisk(fences[j].Facility.System.SecurityLevel)
+	})
+	return fences
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	gom "github.com/kfsone/gomenacing/pkg/gomschema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContrabandTable_IsIllegal(t *testing.T) {
+	slaves := &Commodity{DbEntity: DbEntity{ID: 1, DbName: "Imperial Slaves"}}
+	tea := &Commodity{DbEntity: DbEntity{ID: 2, DbName: "Tea"}}
+	narcotics := &Commodity{DbEntity: DbEntity{ID: 3, DbName: "Narcotics"}}
+
+	assert.True(t, DefaultContraband.IsIllegal(slaves, gom.GovernmentType_GovDemocracy))
+	assert.False(t, DefaultContraband.IsIllegal(slaves, gom.GovernmentType_GovDictatorship))
+	assert.False(t, DefaultContraband.IsIllegal(tea, gom.GovernmentType_GovTheocracy))
+	assert.True(t, DefaultContraband.IsIllegal(narcotics, gom.GovernmentType_GovCorporate))
+	// Anarchies don't have laws.
+	assert.False(t, DefaultContraband.IsIllegal(narcotics, gom.GovernmentType_GovAnarchy))
+	assert.False(t, DefaultContraband.IsIllegal(narcotics, gom.GovernmentType_GovNone))
+}
+
+func TestLegalityRisk(t *testing.T) {
+	assert.Equal(t, RiskMinimal, securityRisk(gom.SecurityLevel_SecurityAnarchy))
+	assert.Equal(t, RiskLow, securityRisk(gom.SecurityLevel_SecurityLow))
+	assert.Equal(t, RiskHigh, securityRisk(gom.SecurityLevel_SecurityHigh))
+	assert.Equal(t, "medium", RiskMedium.String())
+
+	// The same trade is worth less the likelier it is to be caught.
+	safe := &TradeOutcome{GainCr: 1000, Risk: RiskMinimal}
+	risky := &TradeOutcome{GainCr: 1000, Risk: RiskHigh}
+	assert.True(t, safe.Score(10) > risky.Score(10))
+}
+
+func TestSystemDatabase_FindSmugglingOutcomes(t *testing.T) {
+	sdb := NewSystemDatabase(nil)
+	require.Nil(t, sdb.newCommodity(&gom.Commodity{Id: 1, Name: "Narcotics", CategoryId: gom.Commodity_CatLegalDrugs, AverageCr: 9000}))
+	require.Nil(t, sdb.newCommodity(&gom.Commodity{Id: 2, Name: "Tea", CategoryId: gom.Commodity_CatFoods, AverageCr: 1500}))
+	require.Nil(t, sdb.newSystem(&gom.System{Id: 1, Name: "Anarchy", Position: &gom.Coordinate{}, Government: gom.GovernmentType_GovAnarchy, SecurityLevel: gom.SecurityLevel_SecurityAnarchy}))
+	require.Nil(t, sdb.newSystem(&gom.System{Id: 2, Name: "Lawful", Position: &gom.Coordinate{X: 5}, Government: gom.GovernmentType_GovDemocracy, SecurityLevel: gom.SecurityLevel_SecurityHigh}))
+	require.Nil(t, sdb.newSystem(&gom.System{Id: 3, Name: "Lax", Position: &gom.Coordinate{X: 10}, Government: gom.GovernmentType_GovCorporate, SecurityLevel: gom.SecurityLevel_SecurityLow}))
+	require.Nil(t, sdb.newFacility(&gom.Facility{Id: 1, SystemId: 1, Name: "Den", Features: uint32(FeatCommodities | FeatBlackMarket)}))
+	require.Nil(t, sdb.newFacility(&gom.Facility{Id: 2, SystemId: 2, Name: "Hub", Features: uint32(FeatCommodities | FeatBlackMarket), Government: gom.GovernmentType_GovDemocracy}))
+	require.Nil(t, sdb.newFacility(&gom.Facility{Id: 3, SystemId: 3, Name: "Market", Features: uint32(FeatCommodities | FeatBlackMarket), Government: gom.GovernmentType_GovCorporate}))
+	require.Nil(t, sdb.newListings(&gom.FacilityListing{Id: 1, Listings: []*gom.CommodityListing{
+		{CommodityId: 1, SupplyUnits: 100, SupplyCredits: 4000, SupplyBracket: gom.MarketBracket_BracketHigh},
+		{CommodityId: 2, SupplyUnits: 100, SupplyCredits: 1000, SupplyBracket: gom.MarketBracket_BracketHigh},
+	}}))
+	den, hub, market := sdb.GetFacilityByID(1), sdb.GetFacilityByID(2), sdb.GetFacilityByID(3)
+
+	// Tea is legal, so only the narcotics are worth smuggling.
+	outcomes := sdb.FindSmugglingOutcomes(den, hub, 50, 0)
+	require.Len(t, outcomes, 1)
+	assert.Equal(t, "Narcotics", outcomes[0].Commodity.DbName)
+	assert.EqualValues(t, 5000, outcomes[0].GainCr)
+	assert.True(t, outcomes[0].Illegal)
+	assert.Equal(t, RiskHigh, outcomes[0].Risk)
+
+	// A listed black market price beats the galactic average.
+	market.listings = map[EntityID]*Listing{1: {CommodityID: 1, StationPays: 12000}}
+	outcomes = sdb.FindSmugglingOutcomes(den, market, 50, 0)
+	require.Len(t, outcomes, 1)
+	assert.EqualValues(t, 8000, outcomes[0].GainCr)
+	assert.Equal(t, RiskLow, outcomes[0].Risk)
+
+	// Nothing is illegal in an anarchy, and no black market means no buyer.
+	assert.Empty(t, sdb.FindSmugglingOutcomes(hub, den, 50, 0))
+	hub.Features &^= FeatBlackMarket
+	assert.Nil(t, sdb.FindSmugglingOutcomes(den, hub, 50, 0))
+}
+
+func TestSystemDatabase_FindFences(t *testing.T) {
+	sdb := NewSystemDatabase(nil)
+	require.Nil(t, sdb.newSystem(&gom.System{Id: 1, Name: "Anarchy", Position: &gom.Coordinate{}, Government: gom.GovernmentType_GovAnarchy, SecurityLevel: gom.SecurityLevel_SecurityAnarchy}))
+	require.Nil(t, sdb.newSystem(&gom.System{Id: 2, Name: "Lawful", Position: &gom.Coordinate{X: 5}, Government: gom.GovernmentType_GovDemocracy, SecurityLevel: gom.SecurityLevel_SecurityHigh}))
+	require.Nil(t, sdb.newSystem(&gom.System{Id: 3, Name: "Lax", Position: &gom.Coordinate{X: 10}, Government: gom.GovernmentType_GovCorporate, SecurityLevel: gom.SecurityLevel_SecurityLow}))
+	require.Nil(t, sdb.newFacility(&gom.Facility{Id: 1, SystemId: 1, Name: "Den", Features: uint32(FeatCommodities | FeatBlackMarket)}))
+	require.Nil(t, sdb.newFacility(&gom.Facility{Id: 2, SystemId: 2, Name: "Hub", Features: uint32(FeatCommodities | FeatBlackMarket), Government: gom.GovernmentType_GovDemocracy}))
+	require.Nil(t, sdb.newFacility(&gom.Facility{Id: 3, SystemId: 3, Name: "Market", Features: uint32(FeatCommodities | FeatBlackMarket), Government: gom.GovernmentType_GovCorporate}))
+	lawful := sdb.GetSystem("Lawful")
+
+	fences := sdb.FindFences(lawful, 10, nil)
+	require.Len(t, fences, 3)
+	// Safest first, regardless of distance.
+	assert.Equal(t, "Den", fences[0].Facility.DbName)
+	assert.Equal(t, "Market", fences[1].Facility.DbName)
+	assert.Equal(t, "Hub", fences[2].Facility.DbName)
+
+	predicate, err := ParseSystemFilters([]string{"security=low"})
+	require.Nil(t, err)
+	fences = sdb.FindFences(lawful, 10, predicate)
+	require.Len(t, fences, 1)
+	assert.Equal(t, "Market", fences[0].Facility.DbName)
+}
+
+func TestRepl_SmuggleRun(t *testing.T) {
+	sdb := NewSystemDatabase(nil)
+	require.Nil(t, sdb.newCommodity(&gom.Commodity{Id: 1, Name: "Narcotics", CategoryId: gom.Commodity_CatLegalDrugs, AverageCr: 9000}))
+	require.Nil(t, sdb.newCommodity(&gom.Commodity{Id: 2, Name: "Tea", CategoryId: gom.Commodity_CatFoods, AverageCr: 1500}))
+	require.Nil(t, sdb.newSystem(&gom.System{Id: 1, Name: "Anarchy", Position: &gom.Coordinate{}, Government: gom.GovernmentType_GovAnarchy, SecurityLevel: gom.SecurityLevel_SecurityAnarchy}))
+	require.Nil(t, sdb.newSystem(&gom.System{Id: 2, Name: "Lawful", Position: &gom.Coordinate{X: 5}, Government: gom.GovernmentType_GovDemocracy, SecurityLevel: gom.SecurityLevel_SecurityHigh}))
+	require.Nil(t, sdb.newSystem(&gom.System{Id: 3, Name: "Lax", Position: &gom.Coordinate{X: 10}, Government: gom.GovernmentType_GovCorporate, SecurityLevel: gom.SecurityLevel_SecurityLow}))
+	require.Nil(t, sdb.newFacility(&gom.Facility{Id: 1, SystemId: 1, Name: "Den", Features: uint32(FeatCommodities | FeatBlackMarket)}))
+	require.Nil(t, sdb.newFacility(&gom.Facility{Id: 2, SystemId: 2, Name: "Hub", Features: uint32(FeatCommodities | FeatBlackMarket), Government: gom.GovernmentType_GovDemocracy}))
+	require.Nil(t, sdb.newFacility(&gom.Facility{Id: 3, SystemId: 3, Name: "Market", Features: uint32(FeatCommodities | FeatBlackMarket), Government: gom.GovernmentType_GovCorporate}))
+	require.Nil(t, sdb.newListings(&gom.FacilityListing{Id: 1, Listings: []*gom.CommodityListing{
+		{CommodityId: 1, SupplyUnits: 100, SupplyCredits: 4000, SupplyBracket: gom.MarketBracket_BracketHigh},
+		{CommodityId: 2, SupplyUnits: 100, SupplyCredits: 1000, SupplyBracket: gom.MarketBracket_BracketHigh},
+	}}))
+
+	var output bytes.Buffer
+	repl := &Repl{sdb: sdb, out: &output}
+	cmdSmuggleRun(repl, strings.Fields("Anarchy/Den to Lawful/Hub"), nil)
+	assert.Contains(t, output.String(), "Narcotics")
+	assert.Contains(t, output.String(), "risk high")
+	assert.NotContains(t, output.String(), "Tea")
+
+	output.Reset()
+	cmdSmuggleFence(repl, strings.Fields("narcotics near lawful"), nil)
+	assert.Contains(t, output.String(), "Anarchy/Den")
+}
@@ -0,0 +1,288 @@
+package main
+
+// A snapshot is a memory image of the fully built SystemDatabase, so that
+// startup can skip decoding and registering every record in the schemas.
+// Snapshots record the database generation they were taken at, and are
+// ignored once the database has been modified since.
+
+import (
+	"bufio"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	flag "github.com/spf13/pflag"
+)
+
+// UseSnapshot enables loading from, and saving of, the in-memory snapshot.
+var UseSnapshot = flag.Bool("snapshot", true, "Use a memory snapshot of the database to speed up startup.")
+
+// snapshotFile is the name of the snapshot within the database directory.
+const snapshotFile = "snapshot.gms"
+
+// snapshotMagic identifies a snapshot stream.
+const snapshotMagic = "GOMS"
+
+// snapshotVersion must be increased whenever the layout of the snapshot records changes.
+const snapshotVersion = 5
+
+// ErrStaleSnapshot indicates a snapshot that does not match the current database.
+var ErrStaleSnapshot = errors.New("stale snapshot")
+
+type snapshotHeader struct {
+	Version    int
+	Generation uint64
+}
+
+type snapshotSystem struct {
+	System   System
+	Position Coordinate
+}
+
+type snapshotFacility struct {
+	Facility Facility // With System cleared, see SystemID.
+	SystemID EntityID
+	Listings []Listing
+}
+
+// SnapshotPath returns the location of the snapshot for a database.
+func SnapshotPath(db *Database) string {
+	return filepath.Join(db.Path(), snapshotFile)
+}
+
+// WriteSnapshot encodes the contents of sdb, tagged with the given generation.
+func (sdb *SystemDatabase) WriteSnapshot(w io.Writer, generation uint64) error {
+	if _, err := io.WriteString(w, snapshotMagic); err != nil {
+		return err
+	}
+	encoder := gob.NewEncoder(w)
+	if err := encoder.Encode(snapshotHeader{Version: snapshotVersion, Generation: generation}); err != nil {
+		return err
+	}
+
+	commodities := make([]Commodity, 0, len(sdb.commoditiesByID))
+	for _, commodity := range sdb.commoditiesByID {
+		commodities = append(commodities, *commodity)
+	}
+	if err := encoder.Encode(commodities); err != nil {
+		return err
+	}
+	modules := make([]Module, 0, len(sdb.modulesByID))
+	for _, module := range sdb.modulesByID {
+		modules = append(modules, *module)
+	}
+	if err := encoder.Encode(modules); err != nil {
+		return err
+	}
+	ships := make([]Ship, 0, len(sdb.shipsByID))
+	for _, ship := range sdb.shipsByID {
+		ships = append(ships, *ship)
+	}
+	if err := encoder.Encode(ships); err != nil {
+		return err
+	}
+
+	systems := make([]snapshotSystem, 0, len(sdb.systemsByID))
+	facilities := make([]snapshotFacility, 0, len(sdb.facilitiesByID))
+	for _, system := range sdb.systemsByID {
+		record := snapshotSystem{System: *system, Position: system.position}
+		record.System.facilities = nil
+		systems = append(systems, record)
+		// Facilities are written per-system so they retain their order within it.
+		for _, facility := range system.facilities {
+			record := snapshotFacility{Facility: *facility, SystemID: system.ID}
+			record.Facility.System = nil
+			record.Facility.listings = nil
+			record.Listings = make([]Listing, 0, len(facility.listings))
+			for _, listing := range facility.listings {
+				record.Listings = append(record.Listings, *listing)
+			}
+			facilities = append(facilities, record)
+		}
+	}
+	if err := encoder.Encode(systems); err != nil {
+		return err
+	}
+	return encoder.Encode(facilities)
+}
+
+// ReadSnapshot populates an empty sdb from a snapshot. If the snapshot was not
+// taken at the given generation, ErrStaleSnapshot is returned.
+func (sdb *SystemDatabase) ReadSnapshot(r io.Reader, generation uint64) error {
+	magic := make([]byte, len(snapshotMagic))
+	if _, err := io.ReadFull(r, magic); err != nil {
+		return err
+	}
+	if string(magic) != snapshotMagic {
+		return errors.New("unsupported snapshot format")
+	}
+	decoder := gob.NewDecoder(r)
+	var header snapshotHeader
+	if err := decoder.Decode(&header); err != nil {
+		return err
+	}
+	if header.Version != snapshotVersion {
+		return fmt.Errorf("%w: version %d", ErrStaleSnapshot, header.Version)
+	}
+	if header.Generation != generation {
+		return fmt.Errorf("%w: generation %d v %d", ErrStaleSnapshot, header.Generation, generation)
+	}
+
+	var commodities []Commodity
+	if err := decoder.Decode(&commodities); err != nil {
+		return err
+	}
+	var modules []Module
+	if err := decoder.Decode(&modules); err != nil {
+		return err
+	}
+	var ships []Ship
+	if err := decoder.Decode(&ships); err != nil {
+		return err
+	}
+	var systems []snapshotSystem
+	if err := decoder.Decode(&systems); err != nil {
+		return err
+	}
+	var facilities []snapshotFacility
+	if err := decoder.Decode(&facilities); err != nil {
+		return err
+	}
+
+	// The snapshot was built from registered data, so skip straight to indexing.
+	sdb.commoditiesByID = make(map[EntityID]*Commodity, len(commodities))
+	sdb.commodityIDs = make(map[string]EntityID, len(commodities))
+	for idx := range commodities {
+		commodity := &commodities[idx]
+		sdb.commoditiesByID[commodity.ID] = commodity
+		advanceID(&sdb.nextCommodity, commodity.ID)
+		sdb.commodityIDs[strings.ToLower(commodity.DbName)] = commodity.ID
+	}
+
+	sdb.modulesByID = make(map[EntityID]*Module, len(modules))
+	sdb.moduleIDs = make(map[string]EntityID, len(modules))
+	for idx := range modules {
+		module := &modules[idx]
+		sdb.modulesByID[module.ID] = module
+		sdb.moduleIDs[strings.ToLower(module.DbName)] = module.ID
+	}
+	sdb.shipsByID = make(map[EntityID]*Ship, len(ships))
+	sdb.shipIDs = make(map[string]EntityID, len(ships))
+	for idx := range ships {
+		ship := &ships[idx]
+		sdb.shipsByID[ship.ID] = ship
+		sdb.shipIDs[strings.ToLower(ship.DbName)] = ship.ID
+	}
+
+	sdb.systemsByID = make(map[EntityID]*System, len(systems))
+	sdb.systemIDs = make(map[string]EntityID, len(systems))
+	for idx := range systems {
+		system := &systems[idx].System
+		system.position = systems[idx].Position
+		sdb.systemsByID[system.ID] = system
+		advanceID(&sdb.nextSystem, system.ID)
+		sdb.systemIDs[strings.ToLower(system.DbName)] = system.ID
+		sdb.indexSystem(system)
+	}
+
+	sdb.facilitiesByID = make(map[EntityID]*Facility, len(facilities))
+	sdb.facilitiesByMarketID = make(map[uint64]*Facility, len(facilities))
+	for idx := range facilities {
+		record := &facilities[idx]
+		facility := &record.Facility
+		facility.System = sdb.systemsByID[record.SystemID]
+		if facility.System == nil {
+			return fmt.Errorf("%w: snapshot facility %d: system %d", ErrUnknownEntity, facility.ID, record.SystemID)
+		}
+		if len(record.Listings) > 0 {
+			facility.listings = make(map[EntityID]*Listing, len(record.Listings))
+			for lidx := range record.Listings {
+				listing := &record.Listings[lidx]
+				facility.listings[listing.CommodityID] = listing
+			}
+		}
+		facility.System.facilities = append(facility.System.facilities, facility)
+		sdb.facilitiesByID[facility.ID] = facility
+		advanceID(&sdb.nextFacility, facility.ID)
+		sdb.indexMarketID(facility, 0)
+	}
+
+	return nil
+}
+
+// LoadSnapshot tries to populate sdb from the database's snapshot.
+func LoadSnapshot(db *Database, sdb *SystemDatabase) error {
+	generation, err := db.Generation()
+	if err != nil {
+		return err
+	}
+	file, err := os.Open(SnapshotPath(db))
+	if err != nil {
+		return err
+	}
+	defer func() { _ = file.Close() }()
+	return sdb.ReadSnapshot(bufio.NewReader(file), generation)
+}
+
+// snapshotGeneration returns the generation an existing snapshot was taken at.
+func snapshotGeneration(db *Database) (uint64, error) {
+	file, err := os.Open(SnapshotPath(db))
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = file.Close() }()
+	magic := make([]byte, len(snapshotMagic))
+	if _, err = io.ReadFull(file, magic); err != nil {
+		return 0, err
+	}
+	var header snapshotHeader
+	if err = gob.NewDecoder(file).Decode(&header); err != nil {
+		return 0, err
+	}
+	if string(magic) != snapshotMagic || header.Version != snapshotVersion {
+		return 0, ErrStaleSnapshot
+	}
+	return header.Generation, nil
+}
+
+// SaveSnapshot writes a snapshot of sdb for the current generation of db.
+func SaveSnapshot(db *Database, sdb *SystemDatabase) error {
+	generation, err := db.Generation()
+	if err != nil {
+		return err
+	}
+	tmpPath := SnapshotPath(db) + ".tmp"
+	file, err := os.Create(tmpPath)
+	if err != nil {
+		return err
+	}
+	writer := bufio.NewWriter(file)
+	err = sdb.WriteSnapshot(writer, generation)
+	if err == nil {
+		err = writer.Flush()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(tmpPath)
+		return err
+	}
+	return os.Rename(tmpPath, SnapshotPath(db))
+}
+
+// UpdateSnapshot saves a snapshot unless the existing one is already current.
+func UpdateSnapshot(db *Database, sdb *SystemDatabase) error {
+	generation, err := db.Generation()
+	if err != nil {
+		return err
+	}
+	if existing, err := snapshotGeneration(db); err == nil && existing == generation {
+		return nil
+	}
+	return SaveSnapshot(db, sdb)
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/kfsone/gomenacing/pkg/gomschema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSystemDatabase_Snapshot(t *testing.T) {
+	sdb := NewSystemDatabase(nil)
+	require.Nil(t, sdb.newCommodity(&gomschema.Commodity{Id: 1, Name: "Gold", CategoryId: gomschema.Commodity_CatMetals, IsRare: true, AverageCr: 9000}))
+	require.Nil(t, sdb.newSystem(&gomschema.System{Id: 10, Name: "Sol", Position: &gomschema.Coordinate{X: 0, Y: 0, Z: 0}, Populated: true}))
+	require.Nil(t, sdb.newSystem(&gomschema.System{Id: 11, Name: "Lave", Position: &gomschema.Coordinate{X: 300, Y: -20, Z: 5}, Government: gomschema.GovernmentType_GovDictatorship}))
+	require.Nil(t, sdb.newFacility(&gomschema.Facility{Id: 100, SystemId: 10, Name: "Abraham Lincoln", LsFromStar: 500, Features: uint32(FeatLargePad)}))
+	require.Nil(t, sdb.newFacility(&gomschema.Facility{Id: 101, SystemId: 10, Name: "Daedalus"}))
+	require.Nil(t, sdb.newFacility(&gomschema.Facility{Id: 102, SystemId: 11, Name: "Lave Station"}))
+	require.Nil(t, sdb.newListings(&gomschema.FacilityListing{Id: 100, Listings: []*gomschema.CommodityListing{
+		{CommodityId: 1, SupplyUnits: 10, SupplyCredits: 8000, TimestampUtc: 1234},
+	}}))
+
+	var buffer bytes.Buffer
+	require.Nil(t, sdb.WriteSnapshot(&buffer, 42))
+	data := buffer.Bytes()
+
+	t.Run("Round trip", func(t *testing.T) {
+		loaded := NewSystemDatabase(nil)
+		require.Nil(t, loaded.ReadSnapshot(bytes.NewReader(data), 42))
+
+		assert.Equal(t, sdb.commoditiesByID, loaded.commoditiesByID)
+		assert.Equal(t, sdb.commodityIDs, loaded.commodityIDs)
+		assert.Equal(t, sdb.systemIDs, loaded.systemIDs)
+		assert.Len(t, loaded.systemsByID, 2)
+		assert.Len(t, loaded.facilitiesByID, 3)
+		assert.Equal(t, sdb.spatial.Len(), loaded.spatial.Len())
+
+		lave := loaded.GetSystem("lave")
+		require.NotNil(t, lave)
+		assert.Equal(t, Coordinate{300, -20, 5}, *lave.Position())
+		assert.Equal(t, gomschema.GovernmentType_GovDictatorship, lave.Government)
+
+		sol := loaded.GetSystem("sol")
+		require.NotNil(t, sol)
+		assert.True(t, sol.Populated)
+		if assert.Len(t, sol.facilities, 2) {
+			assert.Equal(t, "Abraham Lincoln", sol.facilities[0].DbName)
+			assert.Equal(t, "Daedalus", sol.facilities[1].DbName)
+		}
+
+		facility := loaded.GetFacilityByID(100)
+		require.NotNil(t, facility)
+		assert.Equal(t, sol, facility.System)
+		assert.EqualValues(t, 500, facility.LsFromStar)
+		assert.True(t, facility.HasFeatures(FeatLargePad))
+		assert.Equal(t, sdb.GetFacilityByID(100).listings, facility.listings)
+		assert.Nil(t, loaded.GetFacilityByID(101).listings)
+	})
+
+	t.Run("Stale generation", func(t *testing.T) {
+		loaded := NewSystemDatabase(nil)
+		err := loaded.ReadSnapshot(bytes.NewReader(data), 43)
+		assert.True(t, errors.Is(err, ErrStaleSnapshot))
+		assert.Empty(t, loaded.systemsByID)
+	})
+
+	t.Run("Not a snapshot", func(t *testing.T) {
+		loaded := NewSystemDatabase(nil)
+		assert.Error(t, loaded.ReadSnapshot(bytes.NewReader([]byte("GOMD00000000")), 42))
+		assert.Error(t, loaded.ReadSnapshot(bytes.NewReader(nil), 42))
+	})
+}
+
+func TestUpdateSnapshot(t *testing.T) {
+	testDir := GetTestDir()
+	defer testDir.Close()
+
+	db, err := OpenDatabase(testDir.Path(), "snapshot.db")
+	require.Nil(t, err)
+	sdb := NewSystemDatabase(nil)
+	require.Nil(t, sdb.newSystem(&gomschema.System{Id: 10, Name: "Sol", Position: &gomschema.Coordinate{}}))
+	require.Nil(t, sdb.newSystem(&gomschema.System{Id: 11, Name: "Lave", Position: &gomschema.Coordinate{X: 300, Y: -20, Z: 5}}))
+
+	require.Nil(t, UpdateSnapshot(db, sdb))
+	assert.FileExists(t, SnapshotPath(db))
+	generation, err := snapshotGeneration(db)
+	require.Nil(t, err)
+	assert.Zero(t, generation)
+
+	loaded := NewSystemDatabase(db)
+	require.Nil(t, LoadSnapshot(db, loaded))
+	assert.Len(t, loaded.systemsByID, 2)
+
+	// Modifying the database should invalidate the snapshot.
+	populateTestDatabase(t, db, &gomschema.System{Id: 12, Name: "Achenar", Position: &gomschema.Coordinate{}})
+	err = LoadSnapshot(db, NewSystemDatabase(db))
+	assert.True(t, errors.Is(err, ErrStaleSnapshot))
+
+	require.Nil(t, UpdateSnapshot(db, sdb))
+	generation, err = snapshotGeneration(db)
+	require.Nil(t, err)
+	assert.EqualValues(t, 1, generation)
+	assert.Nil(t, LoadSnapshot(db, NewSystemDatabase(db)))
+}
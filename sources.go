@@ -0,0 +1,487 @@
+package main
+
+// Data arrives from sources of differing reliability - EDDB dumps, EDDN,
+// journals, manual edits - so "newest wins" lets a bad source overwrite a good
+// one. Sources can be registered in the --sources file with a precedence and
+// a trust level, and every stored field remembers the source and timestamp
+// that set it: its provenance. An update to a field is accepted if:
+//
+//  - the update's source has a higher precedence than the field's, or
+//  - they have the same precedence and the update isn't older.
+//
+// An accepted update that repeats a field's value only advances its
+// timestamp, so each field keeps the source that actually set it.
+// Untrusted sources may add entities, and update what they supplied, but not
+// change anyone else's data. Data from ignored sources is dropped. Sources
+// that aren't registered have precedence 0 and are trusted, so without a
+// --sources file the newest data wins.
+//
+// Provenance is kept beside each schema rather than in the GOM messages; it's
+// carried by backups and deltas. A field without provenance counts as from an
+// unregistered source at its entity's timestamp.
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	flag "github.com/spf13/pflag"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// SourcesFile names the file registering data sources' precedence and trust.
+var SourcesFile = flag.String("sources", "", "File of data sources: <name> <precedence> [trusted|untrusted|ignored] per line.")
+
+// Sources of the data read by the built-in importers; GOM files name their own.
+const (
+	SourceJournal        = "journal"
+	SourceTradeDangerous = "tradedangerous"
+	SourceGalaxy         = "galaxy"
+)
+
+// provenanceDir is where the provenance of each schema's fields is kept.
+const provenanceDir = "provenance"
+
+// Trust is how far the data from a source is believed.
+type Trust int
+
+const (
+	TrustTrusted Trust = iota
+	TrustUntrusted
+	TrustIgnored
+)
+
+var trustNames = []string{"trusted", "untrusted", "ignored"}
+
+func (t Trust) String() string {
+	return trustNames[t]
+}
+
+// ParseTrust returns the Trust with the given name.
+func ParseTrust(name string) (Trust, error) {
+	for trust, trustName := range trustNames {
+		if strings.EqualFold(name, trustName) {
+			return Trust(trust), nil
+		}
+	}
+	return TrustTrusted, fmt.Errorf("unknown trust level: %s", name)
+}
+
+// DataSource is a registered source of data.
+type DataSource struct {
+	Name       string
+	Precedence int
+	Trust      Trust
+}
+
+// DataSources are the registered sources, by lower-cased name.
+type DataSources map[string]DataSource
+
+// Get returns the registration of the named source, or the default for
+// unregistered ones.
+func (s DataSources) Get(name string) DataSource {
+	if source, registered := s[strings.ToLower(name)]; registered {
+		return source
+	}
+	return DataSource{Name: name}
+}
+
+// Sorted returns the registered sources, highest precedence first.
+func (s DataSources) Sorted() []DataSource {
+	sources := make([]DataSource, 0, len(s))
+	for _, source := range s {
+		sources = append(sources, source)
+	}
+	sort.Slice(sources, func(i, j int) bool {
+		if sources[i].Precedence != sources[j].Precedence {
+			return sources[i].Precedence > sources[j].Precedence
+		}
+		return sources[i].Name < sources[j].Name
+	})
+	return sources
+}
+
+// accepts returns true if a value from incoming may replace one from held.
+func (s DataSources) accepts(incoming, held Provenance) bool {
+	source := s.Get(incoming.Source)
+	switch {
+	case source.Trust == TrustIgnored:
+		return false
+	case source.Trust == TrustUntrusted && !strings.EqualFold(incoming.Source, held.Source):
+		return false
+	}
+	if holder := s.Get(held.Source); holder.Precedence != source.Precedence {
+		return source.Precedence > holder.Precedence
+	}
+	return requireNewer(incoming, held) == nil
+}
+
+// confirm returns the provenance of a value that an accepted update from
+// incoming repeats: it stays with the source that set it, as of the newer time.
+func confirm(incoming, held Provenance) Provenance {
+	if incoming.TimestampUtc > held.TimestampUtc {
+		held.TimestampUtc = incoming.TimestampUtc
+	}
+	return held
+}
+
+// LoadDataSources reads a file registering sources, one per line as
+// "<name> <precedence> [trust]"; anything after a '#' is a comment.
+func LoadDataSources(pathname string) (DataSources, error) {
+	sources := make(DataSources)
+	if pathname == "" {
+		return sources, nil
+	}
+	file, err := os.Open(pathname)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { Must(file.Close()) }()
+
+	scanner := bufio.NewScanner(file)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := scanner.Text()
+		if comment := strings.IndexByte(line, '#'); comment >= 0 {
+			line = line[:comment]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) > 3 {
+			return nil, fmt.Errorf("%s: line %d: expected <name> <precedence> [trust]", pathname, lineNo)
+		}
+		source := DataSource{Name: fields[0]}
+		if len(fields) > 1 {
+			if source.Precedence, err = strconv.Atoi(fields[1]); err != nil {
+				return nil, fmt.Errorf("%s: line %d: invalid precedence: %s", pathname, lineNo, fields[1])
+			}
+		}
+		if len(fields) > 2 {
+			if source.Trust, err = ParseTrust(fields[2]); err != nil {
+				return nil, fmt.Errorf("%s: line %d: %w", pathname, lineNo, err)
+			}
+		}
+		sources[strings.ToLower(source.Name)] = source
+	}
+	return sources, scanner.Err()
+}
+
+// dataSources returns the sources registered in --sources, loading them on first use.
+func (sdb *SystemDatabase) dataSources() DataSources {
+	if sdb.sources == nil {
+		sources, err := LoadDataSources(*SourcesFile)
+		if err != nil {
+			log.Printf("sources: %s", err)
+			sources = make(DataSources)
+		}
+		sdb.sources = sources
+	}
+	return sdb.sources
+}
+
+// Provenance is where and when a stored value came from.
+type Provenance struct {
+	Source       string
+	TimestampUtc uint64
+}
+
+func (p Provenance) GetTimestampUtc() uint64 {
+	return p.TimestampUtc
+}
+
+// attribution is where an update came from: the source that supplied it
+// and, if it was exported from another database that trusted it, the
+// provenance its fields had there.
+type attribution struct {
+	source  string
+	carried provenanceRecord
+}
+
+// of returns the provenance of a field of the update, which was made at timestamp.
+func (a attribution) of(field string, timestamp uint64) Provenance {
+	return a.carried.get(field, Provenance{Source: a.source, TimestampUtc: timestamp})
+}
+
+// record returns the provenance of an update, made at timestamp, as a whole.
+func (a attribution) record(timestamp uint64) provenanceRecord {
+	if len(a.carried) > 0 {
+		record := make(provenanceRecord, len(a.carried))
+		for field, provenance := range a.carried {
+			record[field] = provenance
+		}
+		return record
+	}
+	return provenanceRecord{"": {Source: a.source, TimestampUtc: timestamp}}
+}
+
+// provenanceRecord is the provenance of an entity's fields, by field name;
+// fields that aren't listed have the provenance under "".
+type provenanceRecord map[string]Provenance
+
+// get returns the provenance of field, or fallback if there's none.
+func (r provenanceRecord) get(field string, fallback Provenance) Provenance {
+	if provenance, present := r[field]; present {
+		return provenance
+	}
+	if provenance, present := r[""]; present {
+		return provenance
+	}
+	return fallback
+}
+
+// compact returns the record for the given fields and their fallbacks, with
+// the most common provenance as the default so that an entity that came from
+// one update takes one entry.
+func (r provenanceRecord) compact(fallbacks map[string]Provenance) provenanceRecord {
+	full := make(map[string]Provenance, len(fallbacks))
+	counts := make(map[Provenance]int)
+	for field, fallback := range fallbacks {
+		full[field] = r.get(field, fallback)
+		counts[full[field]]++
+	}
+	var common Provenance
+	for provenance, count := range counts {
+		// Break ties consistently, preferring newer provenance.
+		better := count > counts[common] || (count == counts[common] && (provenance.TimestampUtc > common.TimestampUtc ||
+			(provenance.TimestampUtc == common.TimestampUtc && provenance.Source < common.Source)))
+		if better {
+			common = provenance
+		}
+	}
+	compacted := provenanceRecord{"": common}
+	for field, provenance := range full {
+		if provenance != common {
+			compacted[field] = provenance
+		}
+	}
+	return compacted
+}
+
+func (r provenanceRecord) marshal() []byte {
+	fields := make([]string, 0, len(r))
+	for field := range r {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	var data bytes.Buffer
+	varint := make([]byte, binary.MaxVarintLen64)
+	putUvarint := func(value uint64) {
+		data.Write(varint[:binary.PutUvarint(varint, value)])
+	}
+	putString := func(text string) {
+		putUvarint(uint64(len(text)))
+		data.WriteString(text)
+	}
+	putUvarint(uint64(len(fields)))
+	for _, field := range fields {
+		putString(field)
+		putString(r[field].Source)
+		putUvarint(r[field].TimestampUtc)
+	}
+	return data.Bytes()
+}
+
+func unmarshalProvenance(data []byte) (provenanceRecord, error) {
+	errMalformed := errors.New("malformed provenance")
+	next := func() (uint64, bool) {
+		value, size := binary.Uvarint(data)
+		if size <= 0 {
+			return 0, false
+		}
+		data = data[size:]
+		return value, true
+	}
+	nextString := func() (string, bool) {
+		length, ok := next()
+		if !ok || length > uint64(len(data)) {
+			return "", false
+		}
+		text := string(data[:length])
+		data = data[length:]
+		return text, true
+	}
+	count, ok := next()
+	if !ok {
+		return nil, errMalformed
+	}
+	record := make(provenanceRecord, count)
+	for ; count > 0; count-- {
+		field, okField := nextString()
+		source, okSource := nextString()
+		timestamp, okTimestamp := next()
+		if !okField || !okSource || !okTimestamp {
+			return nil, errMalformed
+		}
+		record[field] = Provenance{Source: source, TimestampUtc: timestamp}
+	}
+	return record, nil
+}
+
+// provenanceSchema returns the schema keeping the provenance of this one's
+// entities, opening it on first use; it's closed along with this one.
+func (s *Schema) provenanceSchema() (*Schema, error) {
+	if s.provenance == nil {
+		provenance, err := s.db.GetSchema(filepath.Join(provenanceDir, s.name))
+		if err != nil {
+			return nil, err
+		}
+		s.provenance = provenance
+	}
+	return s.provenance, nil
+}
+
+// getProvenance returns the provenance stored for key, which is empty if
+// there's none or the schema has no database.
+func (s *Schema) getProvenance(key []byte) (provenanceRecord, error) {
+	if s.db == nil {
+		return provenanceRecord{}, nil
+	}
+	provenance, err := s.provenanceSchema()
+	if err != nil {
+		return nil, err
+	}
+	data, err := provenance.Get(key)
+	if err != nil || data == nil {
+		return provenanceRecord{}, err
+	}
+	return unmarshalProvenance(data)
+}
+
+func (s *Schema) putProvenance(key []byte, record provenanceRecord) error {
+	if s.db == nil {
+		return nil
+	}
+	provenance, err := s.provenanceSchema()
+	if err != nil {
+		return err
+	}
+	return provenance.Put(key, record.marshal())
+}
+
+func (s *Schema) deleteProvenance(key []byte) error {
+	if s.db == nil {
+		return nil
+	}
+	provenance, err := s.provenanceSchema()
+	if err != nil {
+		return err
+	}
+	return provenance.Delete(key)
+}
+
+// GetProvenance returns the provenance of the stored fields of the entity
+// with the given id, or of its listings by commodity id.
+func (db *Database) GetProvenance(schemaName string, id EntityID) (map[string]Provenance, error) {
+	schema, err := db.GetSchema(filepath.Join(provenanceDir, schemaName))
+	if err != nil {
+		return nil, err
+	}
+	data, err := schema.Get(messageKey(uint32(id)))
+	if closeErr := schema.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil || data == nil {
+		return nil, err
+	}
+	return unmarshalProvenance(data)
+}
+
+// resolveUpdate decides, field by field, which of update's values replace
+// those stored under its id, returning the merged message and its
+// provenance. merged is nil if nothing from update was accepted. If nothing
+// is stored, update is accepted or rejected as a whole against current.
+func (sdb *SystemDatabase) resolveUpdate(update proto.Message, current Timestamped, schema *Schema, from attribution) (merged proto.Message, record provenanceRecord, err error) {
+	key := messageKey(update.(Identifiable).GetId())
+	if record, err = schema.getProvenance(key); err != nil {
+		return nil, nil, err
+	}
+	data, err := schema.Get(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	sources := sdb.dataSources()
+	timestamp := update.(Timestamped).GetTimestampUtc()
+	if data == nil {
+		if !sources.accepts(from.of("", timestamp), record.get("", Provenance{TimestampUtc: current.GetTimestampUtc()})) {
+			return nil, nil, nil
+		}
+		return update, from.record(timestamp), nil
+	}
+
+	stored := update.ProtoReflect().New().Interface()
+	if err = proto.Unmarshal(data, stored); err != nil {
+		return nil, nil, err
+	}
+	target, values := stored.ProtoReflect(), update.ProtoReflect()
+	fallback := Provenance{TimestampUtc: stored.(Timestamped).GetTimestampUtc()}
+	fields := target.Descriptor().Fields()
+	fallbacks := make(map[string]Provenance, fields.Len())
+	accepted := false
+	for idx := 0; idx < fields.Len(); idx++ {
+		fd := fields.Get(idx)
+		name := string(fd.Name())
+		if name == "id" || name == "timestamp_utc" {
+			continue
+		}
+		fallbacks[name] = fallback
+		held, incoming := record.get(name, fallback), from.of(name, timestamp)
+		if !sources.accepts(incoming, held) {
+			continue
+		}
+		accepted = true
+		if fieldsEqual(target, values, fd) {
+			record[name] = confirm(incoming, held)
+			continue
+		}
+		if values.Has(fd) {
+			target.Set(fd, values.Get(fd))
+		} else {
+			target.Clear(fd)
+		}
+		record[name] = incoming
+	}
+	if !accepted {
+		return nil, nil, nil
+	}
+	if timestamp > fallback.TimestampUtc {
+		target.Set(fields.ByName("timestamp_utc"), protoreflect.ValueOfUint64(timestamp))
+	}
+	return stored, record.compact(fallbacks), nil
+}
+
+// fieldsEqual returns true if two messages of the same type have the same value of field.
+func fieldsEqual(a, b protoreflect.Message, field protoreflect.FieldDescriptor) bool {
+	if a.Has(field) != b.Has(field) {
+		return false
+	}
+	if !a.Has(field) {
+		return true
+	}
+	left, right := a.New(), b.New()
+	left.Set(field, a.Get(field))
+	right.Set(field, b.Get(field))
+	return proto.Equal(left.Interface(), right.Interface())
+}
+
+// writeMessageWithProvenance stores message and the provenance of its fields.
+func writeMessageWithProvenance(message proto.Message, record provenanceRecord, schema *Schema) error {
+	if err := writeMessageForId(message, schema); err != nil {
+		return err
+	}
+	return schema.putProvenance(messageKey(message.(Identifiable).GetId()), record)
+}
+
+// listingField is the provenance field of a facility's listing of a commodity.
+func listingField(commodityID EntityID) string {
+	return strconv.FormatUint(uint64(commodityID), 10)
+}
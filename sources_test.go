@@ -0,0 +1,261 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	gom "github.com/kfsone/gomenacing/pkg/gomschema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+const testSourcesFile = `# name     precedence  trust
+manual     100
+EDDN       20          trusted
+eddb       10
+community  0           untrusted  # Fills gaps only.
+spam       0           ignored
+`
+
+func TestLoadDataSources(t *testing.T) {
+	testDir := GetTestDir()
+	defer testDir.Close()
+	pathname := filepath.Join(testDir.Path(), "sources")
+	require.Nil(t, ioutil.WriteFile(pathname, []byte(testSourcesFile), 0644))
+
+	sources, err := LoadDataSources(pathname)
+	require.Nil(t, err)
+	assert.Equal(t, DataSource{Name: "EDDN", Precedence: 20}, sources.Get("eddn"))
+	assert.Equal(t, DataSource{Name: "community", Trust: TrustUntrusted}, sources.Get("Community"))
+	assert.Equal(t, DataSource{Name: "journal"}, sources.Get("journal"))
+	assert.Equal(t, []string{"manual", "EDDN", "eddb", "community", "spam"}, func() (names []string) {
+		for _, source := range sources.Sorted() {
+			names = append(names, source.Name)
+		}
+		return names
+	}())
+
+	for _, bad := range []string{"eddn twenty\n", "eddn 20 sometimes\n", "eddn 20 trusted extra\n"} {
+		require.Nil(t, ioutil.WriteFile(pathname, []byte(bad), 0644))
+		_, err = LoadDataSources(pathname)
+		assert.Error(t, err, bad)
+	}
+	sources, err = LoadDataSources("")
+	assert.Nil(t, err)
+	assert.Empty(t, sources)
+}
+
+func TestDataSources_accepts(t *testing.T) {
+	sources := DataSources{
+		"eddn":      {Name: "eddn", Precedence: 20},
+		"eddb":      {Name: "eddb", Precedence: 10},
+		"community": {Name: "community", Trust: TrustUntrusted},
+		"spam":      {Name: "spam", Trust: TrustIgnored},
+	}
+	at := func(source string, timestamp uint64) Provenance { return Provenance{source, timestamp} }
+	assert.True(t, sources.accepts(at("eddn", 100), at("eddb", 200)))
+	assert.False(t, sources.accepts(at("eddb", 300), at("eddn", 200)))
+	assert.True(t, sources.accepts(at("eddb", 300), at("eddb", 200)))
+	assert.True(t, sources.accepts(at("eddb", 200), at("eddb", 200)))
+	assert.False(t, sources.accepts(at("eddb", 100), at("eddb", 200)))
+	assert.True(t, sources.accepts(at("journal", 300), at("", 200)))
+	assert.False(t, sources.accepts(at("journal", 300), at("eddb", 200)))
+	assert.False(t, sources.accepts(at("community", 300), at("", 200)))
+	assert.True(t, sources.accepts(at("Community", 300), at("community", 200)))
+	assert.False(t, sources.accepts(at("spam", 300), at("spam", 200)))
+}
+
+func Test_provenanceRecord(t *testing.T) {
+	eddb, eddn := Provenance{"eddb", 100}, Provenance{"eddn", 200}
+	record := provenanceRecord{"": eddb, "b": eddn, "c": eddn}
+	assert.Equal(t, eddn, record.get("b", Provenance{}))
+	assert.Equal(t, eddb, record.get("a", Provenance{}))
+	assert.Equal(t, Provenance{TimestampUtc: 5}, provenanceRecord{}.get("a", Provenance{TimestampUtc: 5}))
+
+	// The most common provenance becomes the default, and fields that are gone are dropped.
+	fallbacks := map[string]Provenance{"a": {}, "b": {}, "c": {}}
+	assert.Equal(t, provenanceRecord{"": eddn, "a": eddb}, record.compact(fallbacks))
+	assert.Equal(t, provenanceRecord{"": eddb}, provenanceRecord{"d": eddn}.compact(map[string]Provenance{"a": eddb}))
+
+	decoded, err := unmarshalProvenance(record.marshal())
+	require.Nil(t, err)
+	assert.Equal(t, record, decoded)
+	_, err = unmarshalProvenance(record.marshal()[:5])
+	assert.Error(t, err)
+}
+
+func TestSourcePrecedence(t *testing.T) {
+	testDir := GetTestDir()
+	defer testDir.Close()
+	sourcesPath := filepath.Join(testDir.Path(), "sources")
+	require.Nil(t, ioutil.WriteFile(sourcesPath, []byte(testSourcesFile), 0644))
+	defer func(saved string) { *SourcesFile = saved }(*SourcesFile)
+	*SourcesFile = sourcesPath
+
+	db, err := OpenDatabase(testDir.Path(), "sources.db")
+	require.Nil(t, err)
+	sdb := NewSystemDatabase(db)
+	apply := func(source string, messages ...proto.Message) {
+		writer := newMessageWriter(sdb, db, source)
+		defer writer.Close()
+		for _, message := range messages {
+			require.Nil(t, writer.apply(message))
+		}
+	}
+	galileo := func(timestamp uint64, lsFromStar uint32, government gom.GovernmentType) *gom.Facility {
+		return &gom.Facility{Id: 1, SystemId: 1, Name: "Galileo", TimestampUtc: timestamp, LsFromStar: lsFromStar, Government: government}
+	}
+	listing := func(facilityID, commodityID, credits uint32, timestamp uint64) *gom.FacilityListing {
+		return &gom.FacilityListing{Id: facilityID, Listings: []*gom.CommodityListing{
+			{CommodityId: commodityID, SupplyCredits: credits, TimestampUtc: timestamp},
+		}}
+	}
+
+	apply("eddb",
+		&gom.Commodity{Id: 1, Name: "Gold"},
+		&gom.Commodity{Id: 2, Name: "Silver"},
+		&gom.System{Id: 1, Name: "Sol", TimestampUtc: 100, Position: &gom.Coordinate{}},
+		galileo(100, 500, gom.GovernmentType_GovDemocracy),
+		listing(1, 1, 9000, 100),
+	)
+	// A manual correction of one field; the rest is repeated.
+	apply("manual", galileo(150, 505, gom.GovernmentType_GovDemocracy))
+	apply("eddn", listing(1, 1, 9500, 200))
+	// A newer dump can't undo the correction or the live price, but its other news is taken.
+	apply("eddb", galileo(300, 600, gom.GovernmentType_GovCorporate), listing(1, 1, 9100, 300))
+	apply("eddn", galileo(400, 610, gom.GovernmentType_GovCorporate))
+
+	facility := sdb.GetFacilityByID(1)
+	assert.EqualValues(t, 505, facility.LsFromStar)
+	assert.Equal(t, gom.GovernmentType_GovCorporate, facility.Government)
+	assert.EqualValues(t, 400, facility.TimestampUtc)
+	assert.EqualValues(t, 9500, facility.listings[1].StationAsks)
+
+	// Untrusted sources only add, and ignored ones are dropped.
+	apply("community",
+		galileo(500, 500, gom.GovernmentType_GovAnarchy),
+		&gom.Facility{Id: 2, SystemId: 1, Name: "Abraham Lincoln", TimestampUtc: 500},
+		&gom.FacilityListing{Id: 1, Listings: []*gom.CommodityListing{
+			{CommodityId: 1, SupplyCredits: 1, TimestampUtc: 500},
+			{CommodityId: 2, SupplyCredits: 4000, TimestampUtc: 500},
+		}},
+	)
+	apply("spam", &gom.System{Id: 2, Name: "Spam", TimestampUtc: 600, Position: &gom.Coordinate{}})
+	assert.Equal(t, gom.GovernmentType_GovCorporate, facility.Government)
+	assert.NotNil(t, sdb.GetSystem("Sol").GetFacility("Abraham Lincoln"))
+	assert.EqualValues(t, 9500, facility.listings[1].StationAsks)
+	assert.EqualValues(t, 4000, facility.listings[2].StationAsks)
+	assert.Nil(t, sdb.GetSystem("Spam"))
+
+	record, err := db.GetProvenance("facilities", 1)
+	require.Nil(t, err)
+	assert.Equal(t, Provenance{"manual", 150}, provenanceRecord(record).get("ls_from_star", Provenance{}))
+	assert.Equal(t, Provenance{"eddb", 400}, provenanceRecord(record).get("government", Provenance{}))
+
+	// What was merged is what was stored.
+	reloaded := NewSystemDatabase(db)
+	require.Nil(t, db.LoadDatabase(reloaded))
+	assert.EqualValues(t, 505, reloaded.GetFacilityByID(1).LsFromStar)
+	assert.Equal(t, facility.listings, reloaded.GetFacilityByID(1).listings)
+
+	var output bytes.Buffer
+	repl := &Repl{db: db, sdb: sdb, out: &output}
+	cmdDbProvenance(repl, []string{"Sol/Galileo"}, nil)
+	assert.Contains(t, output.String(), "Sol/Galileo (#1):\n")
+	assert.Contains(t, output.String(), "- ls_from_star: manual at 1970-01-01 00:02:30\n")
+	assert.Contains(t, output.String(), "Listings:\n- Gold: eddn at 1970-01-01 00:03:20\n- Silver: community at 1970-01-01 00:08:20\n")
+
+	output.Reset()
+	cmdDbSources(repl, nil, nil)
+	assert.Contains(t, output.String(), "- manual: precedence 100, trusted\n- EDDN: precedence 20, trusted\n")
+	assert.Contains(t, output.String(), "- spam: precedence 0, ignored\n")
+}
+
+func TestSourcePrecedence_catalogs(t *testing.T) {
+	testDir := GetTestDir()
+	defer testDir.Close()
+	sourcesPath := filepath.Join(testDir.Path(), "sources")
+	require.Nil(t, ioutil.WriteFile(sourcesPath, []byte(testSourcesFile), 0644))
+	defer func(saved string) { *SourcesFile = saved }(*SourcesFile)
+	*SourcesFile = sourcesPath
+
+	db, err := OpenDatabase(testDir.Path(), "catalogs.db")
+	require.Nil(t, err)
+	sdb := NewSystemDatabase(db)
+	apply := func(source string, messages ...proto.Message) {
+		writer := newMessageWriter(sdb, db, source)
+		defer writer.Close()
+		for _, message := range messages {
+			require.Nil(t, writer.apply(message))
+		}
+	}
+	apply("eddb",
+		&gom.Commodity{Id: 1, Name: "Gold", TimestampUtc: 100, AverageCr: 9000},
+		&gom.Module{Id: 1, Name: "5A Frame Shift Drive", TimestampUtc: 100, PriceCr: 5000},
+		&gom.Module{Id: 2, Name: "5D Frame Shift Drive", TimestampUtc: 100},
+		&gom.Ship{Id: 1, Name: "Python", TimestampUtc: 100, PriceCr: 56000},
+		&gom.System{Id: 1, Name: "Sol", TimestampUtc: 100, Position: &gom.Coordinate{}},
+		&gom.Facility{Id: 1, SystemId: 1, Name: "Galileo", TimestampUtc: 100},
+		&gom.FacilityOutfitting{Id: 1, TimestampUtc: 100, ModuleIds: []uint32{1}},
+		&gom.FacilityShipyard{Id: 1, TimestampUtc: 100, ShipIds: []uint32{1}},
+	)
+	apply("manual", &gom.Commodity{Id: 1, Name: "Gold", TimestampUtc: 150, AverageCr: 9100})
+	apply("eddn", &gom.FacilityOutfitting{Id: 1, TimestampUtc: 90, ModuleIds: []uint32{1, 2}})
+
+	// Newer data from a lesser source can't undo either, but can change what's its own.
+	apply("eddb",
+		&gom.Commodity{Id: 1, Name: "Gold", TimestampUtc: 300, AverageCr: 8000, IsRare: true},
+		&gom.FacilityOutfitting{Id: 1, TimestampUtc: 300, ModuleIds: []uint32{2}},
+		&gom.FacilityShipyard{Id: 1, TimestampUtc: 300},
+	)
+	gold := sdb.GetCommodity("Gold")
+	assert.EqualValues(t, 9100, gold.AverageCr)
+	assert.True(t, gold.IsRare)
+	assert.Equal(t, []uint32{1, 2}, sdb.GetFacilityByID(1).Outfitting.IDs())
+	assert.Empty(t, sdb.GetFacilityByID(1).Shipyard.IDs())
+
+	// Untrusted sources can't change anyone else's catalog entries or inventories.
+	apply("community",
+		&gom.Ship{Id: 1, Name: "Python", TimestampUtc: 500, PriceCr: 1},
+		&gom.Module{Id: 1, Name: "5A Frame Shift Drive", TimestampUtc: 500, PriceCr: 1},
+		&gom.FacilityShipyard{Id: 1, TimestampUtc: 500, ShipIds: []uint32{1}},
+	)
+	assert.EqualValues(t, 56000, sdb.GetShipByID(1).PriceCr)
+	assert.EqualValues(t, 5000, sdb.GetModuleByID(1).PriceCr)
+	assert.Empty(t, sdb.GetFacilityByID(1).Shipyard.IDs())
+
+	record, err := db.GetProvenance("outfitting", 1)
+	require.Nil(t, err)
+	assert.Equal(t, Provenance{"eddn", 90}, provenanceRecord(record).get("module_ids", Provenance{}))
+	record, err = db.GetProvenance("commodities", 1)
+	require.Nil(t, err)
+	assert.Equal(t, Provenance{"manual", 150}, provenanceRecord(record).get("average_cr", Provenance{}))
+
+	reloaded := NewSystemDatabase(db)
+	require.Nil(t, db.LoadDatabase(reloaded))
+	assert.EqualValues(t, 9100, reloaded.GetCommodity("Gold").AverageCr)
+	assert.Equal(t, []uint32{1, 2}, reloaded.GetFacilityByID(1).Outfitting.IDs())
+}
+
+func TestImport_recordsSource(t *testing.T) {
+	testDir := GetTestDir()
+	defer testDir.Close()
+	db, err := OpenDatabase(testDir.Path(), "source.db")
+	require.Nil(t, err)
+	var output bytes.Buffer
+	repl := &Repl{db: db, sdb: NewSystemDatabase(db), out: &output}
+
+	writer := gom.NewGOMWriter(gom.Header_CSystem, "eddn")
+	require.Nil(t, writer.AddMessage(&gom.System{Id: 1, Name: "Sol", TimestampUtc: 100, Position: &gom.Coordinate{}}))
+	pathname := filepath.Join(testDir.Path(), "systems.gom")
+	require.Nil(t, writer.WriteFile(pathname))
+	cmdImport(repl, []string{pathname}, nil)
+	assert.Contains(t, output.String(), "read 1 items.")
+
+	record, err := db.GetProvenance("systems", 1)
+	require.Nil(t, err)
+	assert.Equal(t, map[string]Provenance{"": {"eddn", 100}}, record)
+}
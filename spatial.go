@@ -0,0 +1,274 @@
+package main
+
+import (
+	"container/heap"
+	"sort"
+)
+
+// SpatialIndex is a k-d tree over the positions of systems. The tree is
+// implicit: each range of the arrays is split at its midpoint, which holds
+// the median along the axis for that depth (x, y, z, x, ...). Coordinates are
+// held in per-axis arrays so that searches walk compact float slices rather
+// than chasing System pointers.
+//
+// Adding or moving systems marks the tree as stale, and it is rebuilt the
+// next time it is queried.
+type SpatialIndex struct {
+	systems []*System        // Systems in tree order.
+	axes    [3][]float64     // X, Y and Z of each system, in tree order.
+	slots   map[EntityID]int // Where each system currently lives in the arrays.
+	stale   bool             // Whether the tree needs rebuilding before use.
+}
+
+// Neighbor is a system found by a proximity search and its distance^2 from the origin.
+type Neighbor struct {
+	System *System
+	DistSq SquareFloat
+}
+
+// NewSpatialIndex creates an empty index with room for capacity systems.
+func NewSpatialIndex(capacity int) *SpatialIndex {
+	index := &SpatialIndex{
+		systems: make([]*System, 0, capacity),
+		slots:   make(map[EntityID]int, capacity),
+	}
+	for axis := range index.axes {
+		index.axes[axis] = make([]float64, 0, capacity)
+	}
+	return index
+}
+
+// Len returns the number of systems in the index.
+func (si *SpatialIndex) Len() int {
+	return len(si.systems)
+}
+
+// Insert adds a system to the index, or replaces the existing entry with the
+// same id. Either way the tree is rebuilt before the next query.
+func (si *SpatialIndex) Insert(system *System) {
+	if slot, exists := si.slots[system.ID]; exists {
+		si.systems[slot] = system
+	} else {
+		si.slots[system.ID] = len(si.systems)
+		si.systems = append(si.systems, system)
+		for axis := range si.axes {
+			si.axes[axis] = append(si.axes[axis], 0)
+		}
+	}
+	si.stale = true
+}
+
+// Rekey follows a system the index holds that has been given a new id.
+func (si *SpatialIndex) Rekey(oldID EntityID, system *System) {
+	if slot, exists := si.slots[oldID]; exists {
+		delete(si.slots, oldID)
+		si.slots[system.ID] = slot
+	}
+}
+
+// Invalidate tells the index that the position of a system it holds has changed.
+func (si *SpatialIndex) Invalidate() {
+	si.stale = true
+}
+
+func axisValue(c *Coordinate, axis int) float64 {
+	switch axis {
+	case 0:
+		return c.X
+	case 1:
+		return c.Y
+	default:
+		return c.Z
+	}
+}
+
+func (si *SpatialIndex) swap(i, j int) {
+	si.systems[i], si.systems[j] = si.systems[j], si.systems[i]
+	for axis := range si.axes {
+		si.axes[axis][i], si.axes[axis][j] = si.axes[axis][j], si.axes[axis][i]
+	}
+}
+
+// selectMedian partially orders [lo, hi) so that the element at mid has the
+// value it would have if the range were sorted on axis (quickselect).
+func (si *SpatialIndex) selectMedian(lo, hi, mid, axis int) {
+	values := si.axes[axis]
+	for hi-lo > 1 {
+		// Median-of-three pivot, moved to the end of the range.
+		last := hi - 1
+		center := lo + (hi-lo)/2
+		if values[center] < values[lo] {
+			si.swap(center, lo)
+		}
+		if values[last] < values[lo] {
+			si.swap(last, lo)
+		}
+		if values[center] < values[last] {
+			si.swap(center, last)
+		}
+		pivot := values[last]
+		store := lo
+		for i := lo; i < last; i++ {
+			if values[i] < pivot {
+				si.swap(i, store)
+				store++
+			}
+		}
+		si.swap(store, last)
+		switch {
+		case store == mid:
+			return
+		case mid < store:
+			hi = store
+		default:
+			lo = store + 1
+		}
+	}
+}
+
+func (si *SpatialIndex) build(lo, hi, depth int) {
+	if hi-lo <= 1 {
+		return
+	}
+	mid := (lo + hi) / 2
+	si.selectMedian(lo, hi, mid, depth%3)
+	si.build(lo, mid, depth+1)
+	si.build(mid+1, hi, depth+1)
+}
+
+// refresh rebuilds the tree if anything changed since it was last built.
+func (si *SpatialIndex) refresh() {
+	if !si.stale {
+		return
+	}
+	for idx, system := range si.systems {
+		position := system.Position()
+		si.axes[0][idx], si.axes[1][idx], si.axes[2][idx] = position.X, position.Y, position.Z
+	}
+	si.build(0, len(si.systems), 0)
+	for idx, system := range si.systems {
+		si.slots[system.ID] = idx
+	}
+	si.stale = false
+}
+
+func (si *SpatialIndex) distanceSq(idx int, center *Coordinate) SquareFloat {
+	return NewSquareFloat(si.axes[0][idx]-center.X) + NewSquareFloat(si.axes[1][idx]-center.Y) + NewSquareFloat(si.axes[2][idx]-center.Z)
+}
+
+// WithinRadius calls callback with every system within radius of center,
+// until callback returns false. Systems are not visited in any particular
+// order. Returns false if the search was stopped by the callback.
+func (si *SpatialIndex) WithinRadius(center Coordinate, radius float64, callback func(*System, SquareFloat) bool) bool {
+	si.refresh()
+	return si.radiusSearch(0, len(si.systems), 0, &center, NewSquareFloat(radius), callback)
+}
+
+func (si *SpatialIndex) radiusSearch(lo, hi, depth int, center *Coordinate, radiusSq SquareFloat, callback func(*System, SquareFloat) bool) bool {
+	if lo >= hi {
+		return true
+	}
+	mid := (lo + hi) / 2
+	if distSq := si.distanceSq(mid, center); distSq <= radiusSq {
+		if !callback(si.systems[mid], distSq) {
+			return false
+		}
+	}
+	axis := depth % 3
+	delta := axisValue(center, axis) - si.axes[axis][mid]
+	nearLo, nearHi, farLo, farHi := lo, mid, mid+1, hi
+	if delta >= 0 {
+		nearLo, nearHi, farLo, farHi = mid+1, hi, lo, mid
+	}
+	if !si.radiusSearch(nearLo, nearHi, depth+1, center, radiusSq, callback) {
+		return false
+	}
+	if NewSquareFloat(delta) <= radiusSq {
+		return si.radiusSearch(farLo, farHi, depth+1, center, radiusSq, callback)
+	}
+	return true
+}
+
+// WithinBox calls callback with every system inside the axis-aligned box
+// between min and max (inclusive), until callback returns false. Returns
+// false if the search was stopped by the callback.
+func (si *SpatialIndex) WithinBox(min, max Coordinate, callback func(*System) bool) bool {
+	si.refresh()
+	return si.boxSearch(0, len(si.systems), 0, &min, &max, callback)
+}
+
+func (si *SpatialIndex) boxSearch(lo, hi, depth int, min, max *Coordinate, callback func(*System) bool) bool {
+	if lo >= hi {
+		return true
+	}
+	mid := (lo + hi) / 2
+	x, y, z := si.axes[0][mid], si.axes[1][mid], si.axes[2][mid]
+	if x >= min.X && x <= max.X && y >= min.Y && y <= max.Y && z >= min.Z && z <= max.Z {
+		if !callback(si.systems[mid]) {
+			return false
+		}
+	}
+	axis := depth % 3
+	value := si.axes[axis][mid]
+	if axisValue(min, axis) <= value && !si.boxSearch(lo, mid, depth+1, min, max, callback) {
+		return false
+	}
+	if axisValue(max, axis) >= value {
+		return si.boxSearch(mid+1, hi, depth+1, min, max, callback)
+	}
+	return true
+}
+
+// neighborHeap is a max-heap on distance, so the furthest candidate is on top.
+type neighborHeap []Neighbor
+
+func (h neighborHeap) Len() int            { return len(h) }
+func (h neighborHeap) Less(i, j int) bool  { return h[i].DistSq > h[j].DistSq }
+func (h neighborHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *neighborHeap) Push(x interface{}) { *h = append(*h, x.(Neighbor)) }
+func (h *neighborHeap) Pop() interface{} {
+	old := *h
+	item := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return item
+}
+
+// Nearest returns up to k systems closest to center, nearest first. If
+// predicate is not nil, only systems for which it returns true are considered.
+// The search radius is unbounded.
+func (si *SpatialIndex) Nearest(center Coordinate, k int, predicate func(*System) bool) []Neighbor {
+	if k <= 0 {
+		return nil
+	}
+	si.refresh()
+	candidates := make(neighborHeap, 0, k)
+	si.nearestSearch(0, len(si.systems), 0, &center, k, predicate, &candidates)
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].DistSq < candidates[j].DistSq })
+	return candidates
+}
+
+func (si *SpatialIndex) nearestSearch(lo, hi, depth int, center *Coordinate, k int, predicate func(*System) bool, candidates *neighborHeap) {
+	if lo >= hi {
+		return
+	}
+	mid := (lo + hi) / 2
+	distSq := si.distanceSq(mid, center)
+	if len(*candidates) < k || distSq < (*candidates)[0].DistSq {
+		if predicate == nil || predicate(si.systems[mid]) {
+			heap.Push(candidates, Neighbor{System: si.systems[mid], DistSq: distSq})
+			if len(*candidates) > k {
+				heap.Pop(candidates)
+			}
+		}
+	}
+	axis := depth % 3
+	delta := axisValue(center, axis) - si.axes[axis][mid]
+	nearLo, nearHi, farLo, farHi := lo, mid, mid+1, hi
+	if delta >= 0 {
+		nearLo, nearHi, farLo, farHi = mid+1, hi, lo, mid
+	}
+	si.nearestSearch(nearLo, nearHi, depth+1, center, k, predicate, candidates)
+	if len(*candidates) < k || NewSquareFloat(delta) < (*candidates)[0].DistSq {
+		si.nearestSearch(farLo, farHi, depth+1, center, k, predicate, candidates)
+	}
+}
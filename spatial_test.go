@@ -0,0 +1,253 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// makeTestGalaxy creates a database with count systems scattered in a disc
+// roughly the shape of the populated bubble, but sized by the caller.
+func makeTestGalaxy(count int, radius float64, seed int64) *SystemDatabase {
+	rng := rand.New(rand.NewSource(seed))
+	sdb := NewSystemDatabase(nil)
+	for id := 1; id <= count; id++ {
+		system := &System{
+			DbEntity: DbEntity{ID: EntityID(id), DbName: "system"},
+			position: Coordinate{
+				X: (rng.Float64()*2 - 1) * radius,
+				Y: (rng.Float64()*2 - 1) * radius / 4,
+				Z: (rng.Float64()*2 - 1) * radius,
+			},
+			Populated: id%3 == 0,
+		}
+		sdb.systemsByID[system.ID] = system
+		sdb.indexSystem(system)
+	}
+	return sdb
+}
+
+func bruteForceWithin(sdb *SystemDatabase, center Coordinate, radius float64) []EntityID {
+	radiusSq := NewSquareFloat(radius)
+	ids := make([]EntityID, 0)
+	for _, system := range sdb.systemsByID {
+		if Distance(system, center) <= radiusSq {
+			ids = append(ids, system.ID)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+func TestSpatialIndex_Empty(t *testing.T) {
+	index := NewSpatialIndex(0)
+	assert.Zero(t, index.Len())
+	assert.True(t, index.WithinRadius(Coordinate{}, 1000, func(*System, SquareFloat) bool {
+		t.Error("unexpected callback")
+		return true
+	}))
+	assert.True(t, index.WithinBox(Coordinate{-1, -1, -1}, Coordinate{1, 1, 1}, func(*System) bool {
+		t.Error("unexpected callback")
+		return true
+	}))
+	assert.Empty(t, index.Nearest(Coordinate{}, 5, nil))
+}
+
+func TestSpatialIndex_WithinRadius(t *testing.T) {
+	sdb := makeTestGalaxy(5000, 500, 1)
+	rng := rand.New(rand.NewSource(2))
+	for i := 0; i < 50; i++ {
+		center := Coordinate{rng.Float64()*800 - 400, rng.Float64()*200 - 100, rng.Float64()*800 - 400}
+		radius := rng.Float64() * 60
+		found := make([]EntityID, 0)
+		assert.True(t, sdb.spatial.WithinRadius(center, radius, func(system *System, distSq SquareFloat) bool {
+			assert.Equal(t, Distance(system, center), distSq)
+			found = append(found, system.ID)
+			return true
+		}))
+		sort.Slice(found, func(i, j int) bool { return found[i] < found[j] })
+		require.Equal(t, bruteForceWithin(sdb, center, radius), found)
+	}
+
+	// Returning false from the callback should stop the search.
+	calls := 0
+	assert.False(t, sdb.spatial.WithinRadius(Coordinate{}, 500, func(*System, SquareFloat) bool {
+		calls++
+		return false
+	}))
+	assert.Equal(t, 1, calls)
+}
+
+func TestSpatialIndex_WithinBox(t *testing.T) {
+	sdb := makeTestGalaxy(2000, 300, 3)
+	min, max := Coordinate{-50, -20, 10}, Coordinate{40, 30, 90}
+	expected := make([]EntityID, 0)
+	for _, system := range sdb.systemsByID {
+		p := system.Position()
+		if p.X >= min.X && p.X <= max.X && p.Y >= min.Y && p.Y <= max.Y && p.Z >= min.Z && p.Z <= max.Z {
+			expected = append(expected, system.ID)
+		}
+	}
+	sort.Slice(expected, func(i, j int) bool { return expected[i] < expected[j] })
+	require.NotEmpty(t, expected)
+
+	found := make([]EntityID, 0, len(expected))
+	assert.True(t, sdb.spatial.WithinBox(min, max, func(system *System) bool {
+		found = append(found, system.ID)
+		return true
+	}))
+	sort.Slice(found, func(i, j int) bool { return found[i] < found[j] })
+	assert.Equal(t, expected, found)
+}
+
+func TestSpatialIndex_Nearest(t *testing.T) {
+	sdb := makeTestGalaxy(3000, 400, 4)
+	center := Coordinate{12, -3, 40}
+
+	all := make([]Neighbor, 0, len(sdb.systemsByID))
+	for _, system := range sdb.systemsByID {
+		all = append(all, Neighbor{system, Distance(system, center)})
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].DistSq < all[j].DistSq })
+
+	assert.Nil(t, sdb.spatial.Nearest(center, 0, nil))
+	assert.Equal(t, all[:10], sdb.spatial.Nearest(center, 10, nil))
+	assert.Len(t, sdb.spatial.Nearest(center, 5000, nil), 3000)
+
+	populated := func(s *System) bool { return s.Populated }
+	expected := make([]Neighbor, 0, 7)
+	for _, neighbor := range all {
+		if neighbor.System.Populated && len(expected) < 7 {
+			expected = append(expected, neighbor)
+		}
+	}
+	assert.Equal(t, expected, sdb.spatial.Nearest(center, 7, populated))
+}
+
+func TestSpatialIndex_Insert(t *testing.T) {
+	index := NewSpatialIndex(4)
+	near := &System{DbEntity: DbEntity{ID: 1, DbName: "near"}, position: Coordinate{1, 0, 0}}
+	far := &System{DbEntity: DbEntity{ID: 2, DbName: "far"}, position: Coordinate{100, 0, 0}}
+	index.Insert(near)
+	index.Insert(far)
+	assert.Equal(t, near, index.Nearest(Coordinate{}, 1, nil)[0].System)
+
+	// Moving a system requires the index to be told.
+	far.position.X = 0.5
+	index.Invalidate()
+	assert.Equal(t, far, index.Nearest(Coordinate{}, 1, nil)[0].System)
+
+	// Re-inserting a system with the same id replaces it.
+	replacement := &System{DbEntity: DbEntity{ID: 1, DbName: "near"}, position: Coordinate{0, 0, 0}}
+	index.Insert(replacement)
+	assert.Equal(t, 2, index.Len())
+	assert.Equal(t, replacement, index.Nearest(Coordinate{}, 1, nil)[0].System)
+}
+
+func TestSystemDatabase_getSystemsWithinRange(t *testing.T) {
+	sdb := makeTestGalaxy(1000, 200, 5)
+	origin := sdb.systemsByID[1]
+
+	_, err := sdb.getSystemsWithinRange(origin, 0, func(*System, SquareFloat) bool { return true })
+	assert.Error(t, err)
+
+	found := make([]EntityID, 0)
+	matched, err := sdb.getSystemsWithinRange(origin, 40, func(system *System, _ SquareFloat) bool {
+		found = append(found, system.ID)
+		return true
+	})
+	require.Nil(t, err)
+	assert.True(t, matched)
+	sort.Slice(found, func(i, j int) bool { return found[i] < found[j] })
+	assert.Equal(t, bruteForceWithin(sdb, *origin.Position(), 40), found)
+}
+
+// legacySectorSearch is the map-of-sectors search the spatial index replaced,
+// retained as a baseline for the benchmarks.
+type legacySectorSearch map[SectorKey][]*System
+
+func newLegacySectorSearch(sdb *SystemDatabase) legacySectorSearch {
+	sectors := make(legacySectorSearch, 1024)
+	for _, system := range sdb.systemsByID {
+		key := system.Position().SectorKey()
+		sectors[key] = append(sectors[key], system)
+	}
+	return sectors
+}
+
+func (sectors legacySectorSearch) withinRange(origin *System, radius float64, callback func(*System, SquareFloat) bool) {
+	centerKey := origin.Position().SectorKey()
+	radiusSq := NewSquareFloat(radius)
+	sectorRadius := int64(math.Ceil(radius))
+	sectorRadiusSq := NewSquareInt(sectorRadius)
+	for x := -sectorRadius; x <= sectorRadius; x++ {
+		for y := -sectorRadius; y <= sectorRadius; y++ {
+			for z := -sectorRadius; z <= sectorRadius; z++ {
+				if NewSquareInt(x)+NewSquareInt(y)+NewSquareInt(z) > sectorRadiusSq {
+					continue
+				}
+				key := SectorKey{centerKey.X + int(x), centerKey.Y + int(y), centerKey.Z + int(z)}
+				for _, system := range sectors[key] {
+					if distSq := Distance(system, origin); distSq <= radiusSq {
+						if !callback(system, distSq) {
+							return
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+func benchmarkOrigins(sdb *SystemDatabase, count int) []*System {
+	origins := make([]*System, 0, count)
+	for id := EntityID(1); len(origins) < count; id += 97 {
+		origins = append(origins, sdb.systemsByID[id])
+	}
+	return origins
+}
+
+func BenchmarkGetSystemsWithinRange(b *testing.B) {
+	sdb := makeTestGalaxy(70000, 1000, 6)
+	origins := benchmarkOrigins(sdb, 256)
+	sdb.spatial.refresh()
+	legacy := newLegacySectorSearch(sdb)
+	counter := func(*System, SquareFloat) bool { return true }
+
+	for _, radius := range []float64{15, 30, 60} {
+		b.Run(fmt.Sprintf("legacy-sectors/%.0fly", radius), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				legacy.withinRange(origins[i%len(origins)], radius, counter)
+			}
+		})
+		b.Run(fmt.Sprintf("kdtree/%.0fly", radius), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				_, _ = sdb.getSystemsWithinRange(origins[i%len(origins)], radius, counter)
+			}
+		})
+	}
+}
+
+func BenchmarkSpatialIndex_Nearest(b *testing.B) {
+	sdb := makeTestGalaxy(70000, 1000, 7)
+	origins := benchmarkOrigins(sdb, 256)
+	sdb.spatial.refresh()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		sdb.spatial.Nearest(*origins[i%len(origins)].Position(), 10, nil)
+	}
+}
+
+func BenchmarkSpatialIndex_Build(b *testing.B) {
+	sdb := makeTestGalaxy(70000, 1000, 8)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		sdb.spatial.Invalidate()
+		sdb.spatial.refresh()
+	}
+}
@@ -17,9 +17,33 @@ type System struct {
 	Government    gom.GovernmentType
 	Allegiance    gom.AllegianceType
 
+	Population           uint64
+	PrimaryEconomy       gom.EconomyType
+	States               []gom.StateType
+	ControllingFactionID uint32
+	ControllingFaction   string
+	Factions             []FactionPresence
+
 	facilities []*Facility
 }
 
+// FactionPresence describes a minor faction's standing within a system.
+type FactionPresence struct {
+	FactionID uint32
+	Influence float32
+	States    []gom.StateType
+}
+
+// hasState returns true if state appears in states.
+func hasState(states []gom.StateType, state gom.StateType) bool {
+	for _, candidate := range states {
+		if candidate == state {
+			return true
+		}
+	}
+	return false
+}
+
 func NewSystem(dbEntity DbEntity, position Coordinate) *System {
 	return &System{
 		DbEntity: dbEntity,
@@ -44,6 +68,11 @@ func (s *System) GetTimestampUtc() uint64 {
 	return s.TimestampUtc
 }
 
+// HasState returns true if the system is currently in the given state.
+func (s *System) HasState(state gom.StateType) bool {
+	return hasState(s.States, state)
+}
+
 func (s *System) Name() string {
 	return s.DbName
 }
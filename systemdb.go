@@ -7,8 +7,9 @@ import (
 	"github.com/kfsone/gomenacing/pkg/gomschema"
 	"google.golang.org/protobuf/proto"
 	"log"
-	"math"
+	"sort"
 	"strings"
+	"time"
 
 	flag "github.com/spf13/pflag"
 )
@@ -29,23 +30,69 @@ type SystemDatabase struct {
 	systemIDs map[string]EntityID
 	// Index of Facilities by their database ids.
 	facilitiesByID map[EntityID]*Facility
+	// Index of Facilities by their Frontier market ids.
+	facilitiesByMarketID map[uint64]*Facility
 	// Index of Commodities by their database ids.
 	commoditiesByID map[EntityID]*Commodity
 	// Look-up a commodity's EntityID by it's name.
 	commodityIDs map[string]EntityID
-	// Localized index of systems based on their sector keys.
-	sectors map[SectorKey][]*System
+	// Index of ship Modules by their database ids.
+	modulesByID map[EntityID]*Module
+	// Look-up a module's EntityID by its name.
+	moduleIDs map[string]EntityID
+	// Index of Ships by their database ids.
+	shipsByID map[EntityID]*Ship
+	// Look-up a ship's EntityID by its name.
+	shipIDs map[string]EntityID
+	// Spatial index of systems for proximity searches.
+	spatial *SpatialIndex
+	// Cache of recent range searches over the spatial index.
+	probe *Probe
+	// Which commodities are illegal under which governments.
+	contraband ContrabandTable
+	// How travel times are estimated.
+	travel TravelModel
+	// Market data older than this is ignored by queries, if non-zero.
+	maxAge time.Duration
+	// What an hour of a miner's travel costs, in credits.
+	miningRate int64
+	// Registered data sources, once loaded.
+	sources DataSources
+	// The ids for the next commodity, system and facility that come without
+	// one, kept past every id registered so they needn't be searched for.
+	nextCommodity, nextSystem, nextFacility EntityID
 }
 
 func NewSystemDatabase(db *Database) *SystemDatabase {
+	spatial := NewSpatialIndex(4096)
 	return &SystemDatabase{
-		db:              db,
-		systemsByID:     make(map[EntityID]*System, 4096),
-		systemIDs:       make(map[string]EntityID, 4096),
-		facilitiesByID:  make(map[EntityID]*Facility, 8192),
-		commoditiesByID: make(map[EntityID]*Commodity, 500),
-		commodityIDs:    make(map[string]EntityID, 500),
-		sectors:         make(map[SectorKey][]*System, 1024),
+		db:                   db,
+		systemsByID:          make(map[EntityID]*System, 4096),
+		systemIDs:            make(map[string]EntityID, 4096),
+		facilitiesByID:       make(map[EntityID]*Facility, 8192),
+		facilitiesByMarketID: make(map[uint64]*Facility, 8192),
+		commoditiesByID:      make(map[EntityID]*Commodity, 500),
+		commodityIDs:         make(map[string]EntityID, 500),
+		modulesByID:          make(map[EntityID]*Module, 1024),
+		moduleIDs:            make(map[string]EntityID, 1024),
+		shipsByID:            make(map[EntityID]*Ship, 64),
+		shipIDs:              make(map[string]EntityID, 64),
+		spatial:              spatial,
+		probe:                NewProbe(spatial, *ProbeCacheSize),
+		contraband:           DefaultContraband,
+		travel:               DefaultTravelModel(),
+		maxAge:               *MaxAge,
+		miningRate:           *MiningRate,
+		nextCommodity:        1,
+		nextSystem:           syntheticIDBase,
+		nextFacility:         syntheticIDBase,
+	}
+}
+
+// advanceID moves next past id, if it isn't already.
+func advanceID(next *EntityID, id EntityID) {
+	if id >= *next {
+		*next = id + 1
 	}
 }
 
@@ -58,10 +105,24 @@ func registerIDLookup(entity *DbEntity, ids map[string]EntityID) bool {
 	return true
 }
 
+// renameIDLookup gives entity a new name, moving its entry in ids, unless
+// another entity already has the name.
+func renameIDLookup(entity *DbEntity, name string, ids map[string]EntityID) bool {
+	lower := strings.ToLower(name)
+	if id, present := ids[lower]; present && id != entity.ID {
+		return false
+	}
+	delete(ids, strings.ToLower(entity.DbName))
+	entity.DbName = name
+	ids[lower] = entity.ID
+	return true
+}
+
 func (sdb *SystemDatabase) registerCommodity(commodity *Commodity) (err error) {
 	if _, present := sdb.commoditiesByID[commodity.ID]; present == false {
 		if registerIDLookup(&commodity.DbEntity, sdb.commodityIDs) {
 			sdb.commoditiesByID[commodity.ID] = commodity
+			advanceID(&sdb.nextCommodity, commodity.ID)
 			return nil
 		}
 		err = fmt.Errorf("%w: item name", ErrDuplicateEntity)
@@ -75,6 +136,7 @@ func (sdb *SystemDatabase) registerSystem(system *System) (err error) {
 	if _, present := sdb.systemsByID[system.ID]; present == false {
 		if registerIDLookup(&system.DbEntity, sdb.systemIDs) {
 			sdb.systemsByID[system.ID] = system
+			advanceID(&sdb.nextSystem, system.ID)
 			return nil
 		}
 		err = fmt.Errorf("%w: system name", ErrDuplicateEntity)
@@ -85,21 +147,10 @@ func (sdb *SystemDatabase) registerSystem(system *System) (err error) {
 	return fmt.Errorf("%s (#%d): %w", system.DbName, system.ID, err)
 }
 
-func (sdb *SystemDatabase) registerSystemToSector(system *System) {
-	key := system.Position().SectorKey()
-	sector, exists := sdb.sectors[key]
-	if !exists {
-		sector = make([]*System, 0, 8)
-	}
-	// Find an existing match.
-	for idx, existing := range sector {
-		if existing.GetId() == system.GetId() {
-			sector[idx] = system
-			return
-		}
-	}
-	// Just add to the end.
-	sdb.sectors[key] = append(sector, system)
+// indexSystem (re)adds a system to the spatial index after it was added or moved.
+func (sdb *SystemDatabase) indexSystem(system *System) {
+	sdb.spatial.Insert(system)
+	sdb.probe.Invalidate()
 }
 
 func (sdb *SystemDatabase) registerFacility(facility *Facility) error {
@@ -120,29 +171,201 @@ func (sdb *SystemDatabase) registerFacility(facility *Facility) error {
 
 	system.facilities = append(system.facilities, facility)
 	sdb.facilitiesByID[facility.ID] = facility
+	advanceID(&sdb.nextFacility, facility.ID)
+	sdb.indexMarketID(facility, 0)
 
 	return nil
 }
 
-func (sdb *SystemDatabase) registerFromMessage(message proto.Message, schema *Schema) error {
+// indexMarketID updates the market id lookup for a facility whose market id
+// was previously oldMarketID (0 for none).
+func (sdb *SystemDatabase) indexMarketID(facility *Facility, oldMarketID uint64) {
+	if oldMarketID != 0 && sdb.facilitiesByMarketID[oldMarketID] == facility {
+		delete(sdb.facilitiesByMarketID, oldMarketID)
+	}
+	if facility.MarketID != 0 {
+		sdb.facilitiesByMarketID[facility.MarketID] = facility
+	}
+}
+
+// registerFromMessage applies an update from the named source, unless the
+// source is ignored.
+func (sdb *SystemDatabase) registerFromMessage(message proto.Message, schema *Schema, source string) error {
+	return sdb.registerAttributed(message, schema, attribution{source: source})
+}
+
+// registerAttributed applies an update with the given attribution, unless
+// its source is ignored.
+func (sdb *SystemDatabase) registerAttributed(message proto.Message, schema *Schema, from attribution) error {
+	if sdb.dataSources().Get(from.source).Trust == TrustIgnored {
+		return nil
+	}
 	switch typed := message.(type) {
 	case *gomschema.Commodity:
-		return sdb.updateCommodity(typed, schema)
+		return sdb.updateCommodity(typed, schema, from)
 
 	case *gomschema.System:
-		return sdb.updateSystem(typed, schema)
+		return sdb.updateSystem(typed, schema, from)
 
 	case *gomschema.Facility:
-		return sdb.updateFacility(typed, schema)
+		return sdb.updateFacility(typed, schema, from)
 
 	case *gomschema.FacilityListing:
-		return sdb.updateFacilityListing(typed, schema)
+		return sdb.updateFacilityListing(typed, schema, from)
+
+	case *gomschema.Module:
+		return sdb.updateModule(typed, schema, from)
+
+	case *gomschema.Ship:
+		return sdb.updateShip(typed, schema, from)
+
+	case *gomschema.FacilityOutfitting:
+		return sdb.updateFacilityOutfitting(typed, schema, from)
+
+	case *gomschema.FacilityShipyard:
+		return sdb.updateFacilityShipyard(typed, schema, from)
 
 	default:
 		panic("Unknown message type")
 	}
 }
 
+// nextCommodityID is the id for a commodity that came without one.
+func (sdb *SystemDatabase) nextCommodityID() EntityID {
+	return sdb.nextCommodity
+}
+
+// syntheticIDBase is the first id given to systems and facilities that came
+// without one, e.g. from galaxy dumps or the journal. EDDB's ids stay well
+// below it, so a later EDDB import can't collide with them.
+const syntheticIDBase EntityID = 1 << 31
+
+// isSyntheticID returns true if id was made up here rather than given by EDDB.
+func isSyntheticID(id EntityID) bool {
+	return id >= syntheticIDBase
+}
+
+// nextSystemID is the id for a system that came without one, e.g. from the journal.
+func (sdb *SystemDatabase) nextSystemID() EntityID {
+	return sdb.nextSystem
+}
+
+// nextFacilityID is the id for a facility that came without one, e.g. from the journal.
+func (sdb *SystemDatabase) nextFacilityID() EntityID {
+	return sdb.nextFacility
+}
+
+// moveRecord re-stores the record for oldID, and its provenance, under the
+// id that retarget gives message.
+func moveRecord(schema *Schema, oldID EntityID, message proto.Message, retarget func()) error {
+	key := messageKey(uint32(oldID))
+	data, err := schema.Get(key)
+	if err != nil || data == nil {
+		return err
+	}
+	if err = proto.Unmarshal(data, message); err != nil {
+		return err
+	}
+	provenance, err := schema.getProvenance(key)
+	if err != nil {
+		return err
+	}
+	retarget()
+	if err = writeMessageWithProvenance(message, provenance, schema); err != nil {
+		return err
+	}
+	if err = schema.deleteProvenance(key); err != nil {
+		return err
+	}
+	return schema.Delete(key)
+}
+
+// rekeyFacility moves a facility that was given a synthetic id to the id
+// EDDB has for it, along with its listings, outfitting and shipyard.
+func (sdb *SystemDatabase) rekeyFacility(facility *Facility, id EntityID, schema *Schema) error {
+	if other := sdb.GetFacilityByID(id); other != nil {
+		return fmt.Errorf("%s (#%d): %w: facility id #%d is %s", facility.Name(), facility.ID, ErrDuplicateEntity, id, other.Name())
+	}
+	oldID := facility.ID
+	item := &gomschema.Facility{}
+	if err := moveRecord(schema, oldID, item, func() { item.Id = uint32(id) }); err != nil {
+		return err
+	}
+	if schema.db != nil {
+		listing, outfitting, shipyard := &gomschema.FacilityListing{}, &gomschema.FacilityOutfitting{}, &gomschema.FacilityShipyard{}
+		moves := []struct {
+			open     func() (*Schema, error)
+			message  proto.Message
+			retarget func()
+		}{
+			{schema.db.Listings, listing, func() { listing.Id = uint32(id) }},
+			{schema.db.Outfitting, outfitting, func() { outfitting.Id = uint32(id) }},
+			{schema.db.Shipyards, shipyard, func() { shipyard.Id = uint32(id) }},
+		}
+		for _, move := range moves {
+			related, err := move.open()
+			if err != nil {
+				return err
+			}
+			err = moveRecord(related, oldID, move.message, move.retarget)
+			Must(related.Close())
+			if err != nil {
+				return err
+			}
+		}
+	}
+	delete(sdb.facilitiesByID, oldID)
+	facility.ID = id
+	sdb.facilitiesByID[id] = facility
+	advanceID(&sdb.nextFacility, id)
+	return nil
+}
+
+// rekeySystem moves a system that was given a synthetic id to the id EDDB
+// has for it, along with the records of its facilities.
+func (sdb *SystemDatabase) rekeySystem(system *System, id EntityID, schema *Schema) error {
+	if other := sdb.GetSystemByID(id); other != nil {
+		return fmt.Errorf("%s (#%d): %w: system id #%d is %s", system.DbName, system.ID, ErrDuplicateEntity, id, other.DbName)
+	}
+	oldID := system.ID
+	item := &gomschema.System{}
+	if err := moveRecord(schema, oldID, item, func() { item.Id = uint32(id) }); err != nil {
+		return err
+	}
+	if len(system.facilities) > 0 && schema.db != nil {
+		facilities, err := schema.db.Facilities()
+		if err != nil {
+			return err
+		}
+		defer func() { Must(facilities.Close()) }()
+		for _, facility := range system.facilities {
+			data, err := facilities.Get(messageKey(uint32(facility.ID)))
+			if err != nil {
+				return err
+			}
+			if data == nil {
+				continue
+			}
+			item := &gomschema.Facility{}
+			if err = proto.Unmarshal(data, item); err != nil {
+				return err
+			}
+			item.SystemId = uint32(id)
+			if err = writeMessageForId(item, facilities); err != nil {
+				return err
+			}
+		}
+	}
+	delete(sdb.systemsByID, oldID)
+	system.ID = id
+	sdb.systemsByID[id] = system
+	advanceID(&sdb.nextSystem, id)
+	sdb.systemIDs[strings.ToLower(system.DbName)] = id
+	sdb.spatial.Rekey(oldID, system)
+	sdb.probe.Invalidate()
+	return nil
+}
+
 func (sdb *SystemDatabase) GetCommodityByID(id EntityID) *Commodity {
 	if commodity, exists := sdb.commoditiesByID[id]; exists {
 		return commodity
@@ -150,6 +373,19 @@ func (sdb *SystemDatabase) GetCommodityByID(id EntityID) *Commodity {
 	return nil
 }
 
+// GetCommodity returns the commodity with the given name, if any.
+func (sdb *SystemDatabase) GetCommodity(name string) *Commodity {
+	if id, exists := sdb.commodityIDs[strings.ToLower(name)]; exists {
+		return sdb.commoditiesByID[id]
+	}
+	return nil
+}
+
+// FindCommodities returns the commodities matching name exactly, or partially.
+func (sdb *SystemDatabase) FindCommodities(name string) []EntityID {
+	return matchNames(name, sdb.commodityIDs)
+}
+
 func (sdb *SystemDatabase) GetSystemByID(id EntityID) *System {
 	if system, exists := sdb.systemsByID[id]; exists {
 		return system
@@ -171,19 +407,32 @@ func (sdb *SystemDatabase) GetFacilityByID(id EntityID) *Facility {
 	return nil
 }
 
-func writeMessageForId(message proto.Message, schema *Schema) error {
-	type Identifiable interface {
-		GetId() uint32
+// GetFacilityByMarketID looks up a facility by Frontier's market id.
+func (sdb *SystemDatabase) GetFacilityByMarketID(marketID uint64) *Facility {
+	if facility, exists := sdb.facilitiesByMarketID[marketID]; exists {
+		return facility
 	}
+	return nil
+}
+
+// Identifiable is a message with an id.
+type Identifiable interface {
+	GetId() uint32
+}
 
+// messageKey is the key a message with the given id is stored under.
+func messageKey(id uint32) []byte {
 	key := make([]byte, 4)
-	binary.LittleEndian.PutUint32(key, message.(Identifiable).GetId())
+	binary.LittleEndian.PutUint32(key, id)
+	return key
+}
 
+func writeMessageForId(message proto.Message, schema *Schema) error {
 	value, err := proto.Marshal(message)
 	if err != nil {
 		return err
 	}
-	return schema.Put(key, value)
+	return schema.Put(messageKey(message.(Identifiable).GetId()), value)
 }
 
 func (sdb *SystemDatabase) newCommodity(gomItem *gomschema.Commodity) error {
@@ -210,10 +459,16 @@ func (sdb *SystemDatabase) newSystem(gomItem *gomschema.System) error {
 	item.SecurityLevel = gomItem.GetSecurityLevel()
 	item.Government = gomItem.GetGovernment()
 	item.Allegiance = gomItem.GetAllegiance()
+	item.Population = gomItem.GetPopulation()
+	item.PrimaryEconomy = gomItem.GetPrimaryEconomy()
+	item.States = copyStates(gomItem.GetStates())
+	item.ControllingFactionID = gomItem.GetControllingFactionId()
+	item.ControllingFaction = gomItem.GetControllingFaction()
+	item.Factions = deserializeFactions(gomItem.GetFactions())
 
 	err = sdb.registerSystem(item)
 	if err == nil {
-		sdb.registerSystemToSector(item)
+		sdb.indexSystem(item)
 	}
 
 	return err
@@ -234,6 +489,10 @@ func (sdb *SystemDatabase) newFacility(gomItem *gomschema.Facility) error {
 		item.LsFromStar = gomItem.GetLsFromStar()
 		item.Government = gomItem.GetGovernment()
 		item.Allegiance = gomItem.GetAllegiance()
+		item.MarketID = gomItem.GetMarketId()
+		item.Economies = copyEconomies(gomItem.GetEconomies())
+		item.Body = gomItem.GetBody()
+		item.Landing = gomItem.GetPlanetaryLanding()
 		err = sdb.registerFacility(item)
 	}
 	return err
@@ -253,26 +512,31 @@ func (sdb *SystemDatabase) newListings(gomItem *gomschema.FacilityListing) error
 		facility.listings = make(map[EntityID]*Listing, len(listings))
 	}
 	for _, gomListing := range listings {
-		l := Listing{
-			CommodityID:  EntityID(gomListing.CommodityId),
-			Supply:       gomListing.GetSupplyUnits(),
-			StationPays:  gomListing.GetSupplyCredits(),
-			Demand:       gomListing.GetDemandUnits(),
-			StationAsks:  gomListing.GetDemandCredits(),
-			TimestampUtc: gomListing.TimestampUtc,
-		}
+		l := Listing{CommodityID: EntityID(gomListing.CommodityId)}
+		l.applyCommodityListing(gomListing)
 		facility.listings[l.CommodityID] = &l
 	}
 
 	return nil
 }
 
-func (sdb *SystemDatabase) updateCommodity(item *gomschema.Commodity, schema *Schema) error {
+// updateCommodity applies the fields of item that the source may change.
+func (sdb *SystemDatabase) updateCommodity(item *gomschema.Commodity, schema *Schema, from attribution) error {
+	provenance := from.record(item.TimestampUtc)
 	name := strings.ToLower(item.Name)
 	if existing, exists := sdb.commodityIDs[name]; exists {
 		if existing != EntityID(item.Id) {
 			return fmt.Errorf("commodity %s: %d: name collides with #%d", item.Name, item.Id, existing)
 		}
+		merged, record, err := sdb.resolveUpdate(item, Provenance{}, schema, from)
+		if err != nil {
+			return err
+		}
+		if merged == nil {
+			log.Printf("commodity %s (%d): stale or overridden update from %s", item.Name, item.Id, from.source)
+			return nil
+		}
+		item, provenance = merged.(*gomschema.Commodity), record
 		commodity := sdb.commoditiesByID[existing]
 		commodity.DbEntity.DbName = item.Name
 		commodity.CategoryID = item.GetCategoryId()
@@ -284,7 +548,7 @@ func (sdb *SystemDatabase) updateCommodity(item *gomschema.Commodity, schema *Sc
 			return err
 		}
 	}
-	return writeMessageForId(item, schema)
+	return writeMessageWithProvenance(item, provenance, schema)
 }
 
 func requireNewer(newer, older Timestamped) error {
@@ -294,39 +558,60 @@ func requireNewer(newer, older Timestamped) error {
 	return nil
 }
 
-func (sdb *SystemDatabase) updateSystem(item *gomschema.System, schema *Schema) error {
+// updateSystem applies the fields of item that the source may change.
+func (sdb *SystemDatabase) updateSystem(item *gomschema.System, schema *Schema, from attribution) error {
+	provenance := from.record(item.TimestampUtc)
 	name := strings.ToLower(item.Name)
 	if existing, exists := sdb.systemIDs[name]; exists {
 		if existing != EntityID(item.Id) {
-			return fmt.Errorf("system %s (%d): name collides with #%d", item.Name, item.Id, existing)
+			// A system we made up an id for takes the one EDDB gives it.
+			if !isSyntheticID(existing) || isSyntheticID(EntityID(item.Id)) {
+				return fmt.Errorf("system %s (%d): name collides with #%d", item.Name, item.Id, existing)
+			}
+			if err := sdb.rekeySystem(sdb.systemsByID[existing], EntityID(item.Id), schema); err != nil {
+				return err
+			}
+			existing = EntityID(item.Id)
 		}
 		// Is this an update?
 		system := sdb.systemsByID[existing]
-		if err := requireNewer(item, system); err != nil {
-			log.Printf("%s (%d): %s", item.Name, item.Id, err)
+		merged, record, err := sdb.resolveUpdate(item, system, schema, from)
+		if err != nil {
+			return err
+		}
+		if merged == nil {
+			log.Printf("%s (%d): stale or overridden update from %s", item.Name, item.Id, from.source)
 			return nil
 		}
+		item, provenance = merged.(*gomschema.System), record
 		system.DbEntity.DbName = item.Name
 		system.TimestampUtc = item.TimestampUtc
-		system.position = Coordinate{item.Position.X, item.Position.Y, item.Position.Z}
+		position := Coordinate{item.Position.X, item.Position.Y, item.Position.Z}
+		moved := position != system.position
+		system.position = position
 		system.Populated = item.Populated
 		system.NeedsPermit = item.NeedsPermit
 		system.SecurityLevel = item.SecurityLevel
 		system.Government = item.Government
 		system.Allegiance = item.Allegiance
+		system.Population = item.Population
+		system.PrimaryEconomy = item.PrimaryEconomy
+		system.States = copyStates(item.States)
+		system.ControllingFactionID = item.ControllingFactionId
+		system.ControllingFaction = item.ControllingFaction
+		system.Factions = deserializeFactions(item.Factions)
+		if moved {
+			sdb.indexSystem(system)
+		}
 	} else {
 		if err := sdb.newSystem(item); err != nil {
 			return err
 		}
 	}
-	return writeMessageForId(item, schema)
+	return writeMessageWithProvenance(item, provenance, schema)
 }
 
 func updateExistingFacility(sdb *SystemDatabase, newSystem *System, oldFacility *Facility, item *gomschema.Facility) error {
-	if err := requireNewer(item, oldFacility); err != nil {
-		log.Printf("%s (%d): %s", oldFacility.Name(), item.Id, err)
-		return err
-	}
 	if oldFacility.System != newSystem || strings.ToLower(oldFacility.GetName()) != strings.ToLower(item.Name) {
 		return errors.New("can't handle facility renames/relocates")
 	}
@@ -339,11 +624,18 @@ func updateExistingFacility(sdb *SystemDatabase, newSystem *System, oldFacility
 	oldFacility.LsFromStar = item.GetLsFromStar()
 	oldFacility.Government = item.GetGovernment()
 	oldFacility.Allegiance = item.GetAllegiance()
+	oldMarketID := oldFacility.MarketID
+	oldFacility.MarketID = item.GetMarketId()
+	oldFacility.Economies = copyEconomies(item.GetEconomies())
+	oldFacility.Body = item.GetBody()
+	oldFacility.Landing = item.GetPlanetaryLanding()
+	sdb.indexMarketID(oldFacility, oldMarketID)
 
 	return nil
 }
 
-func (sdb *SystemDatabase) updateFacility(item *gomschema.Facility, schema *Schema) (err error) {
+// updateFacility applies the fields of item that the source may change.
+func (sdb *SystemDatabase) updateFacility(item *gomschema.Facility, schema *Schema, from attribution) (err error) {
 	// Does the destination system exist?
 	system := sdb.GetSystemByID(EntityID(item.SystemId))
 	if system == nil {
@@ -351,7 +643,30 @@ func (sdb *SystemDatabase) updateFacility(item *gomschema.Facility, schema *Sche
 	}
 
 	// Does the facility already exist?
-	if oldFacility, exists := sdb.facilitiesByID[EntityID(item.Id)]; exists {
+	provenance := from.record(item.TimestampUtc)
+	oldFacility, exists := sdb.facilitiesByID[EntityID(item.Id)]
+	if !exists && !isSyntheticID(EntityID(item.Id)) {
+		// A facility we made up an id for takes the one EDDB gives it.
+		if named := system.GetFacility(item.Name); named != nil && isSyntheticID(named.ID) {
+			if err = sdb.rekeyFacility(named, EntityID(item.Id), schema); err != nil {
+				return err
+			}
+			oldFacility, exists = named, true
+		}
+	}
+	if exists {
+		merged, record, err := sdb.resolveUpdate(item, oldFacility, schema, from)
+		if err != nil {
+			return err
+		}
+		if merged == nil {
+			log.Printf("%s (%d): stale or overridden update from %s", oldFacility.Name(), item.Id, from.source)
+			return nil
+		}
+		item, provenance = merged.(*gomschema.Facility), record
+		if system = sdb.GetSystemByID(EntityID(item.SystemId)); system == nil {
+			return fmt.Errorf("%w: facility %s (%d): no such system %d", ErrUnknownEntity, item.Name, item.Id, item.SystemId)
+		}
 		err = updateExistingFacility(sdb, system, oldFacility, item)
 	} else {
 		err = sdb.newFacility(item)
@@ -359,133 +674,76 @@ func (sdb *SystemDatabase) updateFacility(item *gomschema.Facility, schema *Sche
 	if err != nil {
 		return err
 	}
-	return writeMessageForId(item, schema)
+	return writeMessageWithProvenance(item, provenance, schema)
 }
 
-func (sdb *SystemDatabase) updateFacilityListing(item *gomschema.FacilityListing, schema *Schema) (err error) {
+// updateFacilityListing applies the items of item that the source may
+// change; each commodity's listing has its own provenance.
+func (sdb *SystemDatabase) updateFacilityListing(item *gomschema.FacilityListing, schema *Schema, from attribution) (err error) {
 	facility := sdb.GetFacilityByID(EntityID(item.Id))
 	if facility == nil {
 		return fmt.Errorf("%w: facility for listing: %d", ErrUnknownEntity, item.Id)
 	}
+	provenance, err := schema.getProvenance(messageKey(item.Id))
+	if err != nil {
+		return err
+	}
+	sources := sdb.dataSources()
 
-	// If there's nothing here, this is going to be simple.
 	if facility.listings == nil {
 		facility.listings = make(map[EntityID]*Listing, len(item.Listings))
-	} else {
-		for _, update := range item.Listings {
-			commodityId := EntityID(update.CommodityId)
-			if sdb.GetCommodityByID(commodityId) == nil {
-				FilterError(fmt.Errorf("%w: facility %s (%d): commodity: %d", ErrUnknownEntity, facility.Name(), facility.GetId(), commodityId))
+	}
+	for _, update := range item.Listings {
+		commodityId := EntityID(update.CommodityId)
+		if sdb.GetCommodityByID(commodityId) == nil {
+			FilterError(fmt.Errorf("%w: facility %s (%d): commodity: %d", ErrUnknownEntity, facility.Name(), facility.GetId(), commodityId))
+			continue
+		}
+		incoming := from.of(listingField(commodityId), update.TimestampUtc)
+		existing, existed := facility.listings[commodityId]
+		if existed {
+			// Check this is an update the source may make.
+			held := provenance.get(listingField(commodityId), Provenance{TimestampUtc: existing.GetTimestampUtc()})
+			if !sources.accepts(incoming, held) {
 				continue
 			}
-			existing, existed := facility.listings[commodityId]
-			if existed {
-				// Check this is an update.
-				if requireNewer(update, existing) != nil {
-					continue
-				}
-			} else {
-				existing = &Listing{CommodityID: commodityId}
-				facility.listings[existing.CommodityID] = existing
-			}
-			existing.Supply = update.SupplyUnits
-			existing.StationAsks = update.SupplyCredits
-			existing.Demand = update.DemandUnits
-			existing.StationPays = update.DemandCredits
-			existing.TimestampUtc = update.TimestampUtc
+		} else {
+			existing = &Listing{CommodityID: commodityId}
+			facility.listings[existing.CommodityID] = existing
 		}
+		existing.applyCommodityListing(update)
+		provenance[listingField(commodityId)] = incoming
 	}
 
-	return writeMessageForId(item, schema)
-}
-
-type VolumeQuery struct {
-	center         Coordinate
-	centerKey      SectorKey
-	radius         float64
-	radiusSq       SquareFloat
-	sectorRadius   int64
-	sectorRadiusSq SquareInt
-}
-
-func NewVolumeQuery(center Positioned, radius float64) (*VolumeQuery, error) {
-	if radius <= 0 {
-		return nil, errors.New("invalid radius")
-	}
-	sectorRadius := int64(math.Ceil(radius))
-	coordinate := center.Coordinate()
-	return &VolumeQuery{
-		center:       *coordinate,
-		centerKey:    coordinate.SectorKey(),
-		radius:       radius,
-		radiusSq:     NewSquareFloat(radius),
-		sectorRadius: sectorRadius,
-		// By squaring ahead of time, we won't have to sqrt distances
-		sectorRadiusSq: NewSquareInt(sectorRadius),
-	}, nil
-}
-
-func (v *VolumeQuery) InRange(target Positioned) bool {
-	return Distance(v.center, target) > v.radiusSq
-}
-
-func (v *VolumeQuery) volumeSectorKeys(callback func (SectorKey) bool) bool {
-	var sectorKey SectorKey
-	var result bool
-	///TODO: Two obvious optimizations:
-	/// 1. We can pre-calculate the Y and Z constraints each loop,
-	///    based on the outer values. That is: when x is sectorRadius,
-	///    there is no room left for Y or Z exploration.
-	///    yRange := math.Min(math.Sqrt(sectorRange - x*x), sectorRange)
-	///    zRange := math.Min(math.Sqrt(sectorRange - (x*x+y*y)
-	/// 2. Replace the map[SectorKey] with [x][y][z][]*System and ensure the
-	///    outer lists are sorted so we can binary search.
-	for x := -v.sectorRadius; x <= +v.sectorRadius; x++ {
-		sectorKey.X = v.centerKey.X + int(x)
-		xDeltaSq := NewSquareInt(x)
-		for y := -v.sectorRadius; y <= v.sectorRadius; y++ {
-			sectorKey.Y = v.centerKey.Y + int(y)
-			yDeltaSq := NewSquareInt(y)
-			for z := -v.sectorRadius; z <= v.sectorRadius; z++ {
-				zDeltaSq := NewSquareInt(z)
-				if xDeltaSq + yDeltaSq + zDeltaSq <= v.sectorRadiusSq {
-					sectorKey := SectorKey{sectorKey.X, sectorKey.Y, v.centerKey.Z + int(z)}
-					if !callback(sectorKey) {
-						return false
-					}
-					result = true
-				}
-			}
-		}
+	// Store what the facility has now, rather than just what changed.
+	merged := &gomschema.FacilityListing{Id: item.Id, Listings: make([]*gomschema.CommodityListing, 0, len(facility.listings))}
+	fallbacks := make(map[string]Provenance, len(facility.listings))
+	for _, listing := range facility.listings {
+		merged.Listings = append(merged.Listings, listing.commodityListing())
+		fallbacks[listingField(listing.CommodityID)] = Provenance{TimestampUtc: listing.GetTimestampUtc()}
 	}
-	return result
-}
-
-func (sdb *SystemDatabase) getSystemsFromVolume(query *VolumeQuery, callback func(*System) bool) bool {
-	return query.volumeSectorKeys(func (sectorKey SectorKey) bool {
-		for _, system := range sdb.sectors[sectorKey] {
-			if !callback(system) {
-				return false
-			}
-		}
-		return true
-	})
+	sort.Slice(merged.Listings, func(i, j int) bool { return merged.Listings[i].CommodityId < merged.Listings[j].CommodityId })
+	return writeMessageWithProvenance(merged, provenance.compact(fallbacks), schema)
 }
 
+// getSystemsWithinRange calls callback, nearest first, for each system within distance ly
+// of origin, until callback returns false. Results are shared via the probe cache.
 func (sdb *SystemDatabase) getSystemsWithinRange(origin *System, distance float64, callback func(*System, SquareFloat) bool) (bool, error) {
-	query, err := NewVolumeQuery(origin, distance)
-	if err != nil {
-		return false, err
+	if distance <= 0 {
+		return false, errors.New("invalid radius")
 	}
-	matched := false
-	success := sdb.getSystemsFromVolume(query, func (system *System) bool {
-		if distSq := Distance(system, origin); distSq <= query.radiusSq {
-			if !callback(system, distSq) {
-				return false
-			}
-			matched = true
+	neighbors := sdb.probe.Neighbors(origin, distance)
+	for _, neighbor := range neighbors {
+		if !callback(neighbor.System, neighbor.DistSq) {
+			return false, nil
 		}
-		return true
-	})
-	return success && matched, nil
+	}
+	return len(neighbors) > 0, nil
+}
+
+// NearestSystems returns up to count systems closest to origin, nearest first,
+// that satisfy predicate (or all systems, if predicate is nil). The search
+// radius is unbounded and origin itself is a candidate.
+func (sdb *SystemDatabase) NearestSystems(origin *System, count int, predicate SystemPredicate) []Neighbor {
+	return sdb.spatial.Nearest(*origin.Position(), count, predicate)
 }
@@ -7,6 +7,7 @@ import (
 	"math"
 	"sort"
 	"strings"
+	"time"
 )
 
 func sum(list []int) (total int64, average float64) {
@@ -51,14 +52,23 @@ func average(value, of int) (result float64) {
 	return 0.
 }
 
-func getBounds(sdb *SystemDatabase) (x1, x2, y1, y2, z1, z2 int) {
-	if len(sdb.sectors) == 0 {
+// sectorPopulations counts how many systems fall within each sector.
+func sectorPopulations(sdb *SystemDatabase) map[SectorKey]int {
+	sectors := make(map[SectorKey]int, len(sdb.systemsByID)/8)
+	for _, system := range sdb.systemsByID {
+		sectors[system.Position().SectorKey()]++
+	}
+	return sectors
+}
+
+func getBounds(sectors map[SectorKey]int) (x1, x2, y1, y2, z1, z2 int) {
+	if len(sectors) == 0 {
 		return 0, 0, 0, 0, 0, 0
 	}
-	xs := make([]int, 0, len(sdb.sectors))
-	ys := make([]int, 0, len(sdb.sectors))
-	zs := make([]int, 0, len(sdb.sectors))
-	for key, _ := range sdb.sectors {
+	xs := make([]int, 0, len(sectors))
+	ys := make([]int, 0, len(sectors))
+	zs := make([]int, 0, len(sectors))
+	for key := range sectors {
 		xs = append(xs, key.X)
 		ys = append(ys, key.Y)
 		zs = append(zs, key.Z)
@@ -165,6 +175,9 @@ func reportOnSystems(o io.Writer, sdb *SystemDatabase) {
 	govtDistrib := make(map[string]int, 32)
 	allegDistrib := make(map[string]int, 32)
 	securityDistrib := make(map[string]int, 32)
+	economyDistrib := make(map[string]int, 32)
+	stateDistrib := make(map[string]int, 32)
+	totalPopulation := uint64(0)
 	for _, system := range sdb.systemsByID {
 		if system.Populated {
 			populated++
@@ -175,38 +188,52 @@ func reportOnSystems(o io.Writer, sdb *SystemDatabase) {
 		govtDistrib[gomschema.GovernmentType_name[int32(system.Government)]]++
 		allegDistrib[gomschema.AllegianceType_name[int32(system.Allegiance)]]++
 		securityDistrib[gomschema.FacilityType_name[int32(system.SecurityLevel)]]++
+		economyDistrib[gomschema.EconomyType_name[int32(system.PrimaryEconomy)]]++
+		for _, state := range system.States {
+			stateDistrib[gomschema.StateType_name[int32(state)]]++
+		}
+		totalPopulation += system.Population
 	}
 
 	analyzeSystems(o, "w/facilities", sdb.systemsByID, func(s *System) int { return len(s.facilities) })
 	fmt.Fprintf(o, "- Populated: %d (%.2f%%)\n", populated, percentage(populated, len(sdb.systemsByID)))
 	fmt.Fprintf(o, "- Need permit: %d (%.2f%%)\n", permits, percentage(permits, len(sdb.systemsByID)))
+	fmt.Fprintf(o, "- Total population: %d\n", totalPopulation)
+	analyzeSystems(o, "w/factions", sdb.systemsByID, func(s *System) int { return len(s.Factions) })
 	stats := produceStats(govtDistrib, total)
 	fmt.Fprintf(o, "- Governments: %s\n", strings.Join(stats, ", "))
 	stats = produceStats(allegDistrib, total)
 	fmt.Fprintf(o, "- Allegiances: %s\n", strings.Join(stats, ", "))
 	stats = produceStats(securityDistrib, total)
 	fmt.Fprintf(o, "- Security Levels: %s\n", strings.Join(stats, ", "))
+	stats = produceStats(economyDistrib, total)
+	fmt.Fprintf(o, "- Primary Economies: %s\n", strings.Join(stats, ", "))
+	stats = produceStats(stateDistrib, total)
+	fmt.Fprintf(o, "- States: %s\n", strings.Join(stats, ", "))
 }
 
 func reportOnSectors(o io.Writer, sdb *SystemDatabase) {
-	if len(sdb.sectors) == 0 {
+	sectors := sectorPopulations(sdb)
+	if len(sectors) == 0 {
 		fmt.Fprintf(o, "Sectors: 0\n")
 		return
 	}
 
-	x1, x2, y1, y2, z1, z2 := getBounds(sdb)
-	fmt.Fprintf(o, "Sectors: %d [(%d-%d),(%d-%d),(%d-%d)]\n", len(sdb.sectors), x1, x2, y1, y2, z1, z2)
-	populations := make([]int, 0, len(sdb.sectors))
+	x1, x2, y1, y2, z1, z2 := getBounds(sectors)
+	fmt.Fprintf(o, "Sectors: %d [(%d-%d),(%d-%d),(%d-%d)]\n", len(sectors), x1, x2, y1, y2, z1, z2)
+	populations := make([]int, 0, len(sectors))
 	totalSecPop := 0
-	for _, sector := range sdb.sectors {
-		populations = append(populations, len(sector))
-		totalSecPop += len(sector)
+	for _, population := range sectors {
+		populations = append(populations, population)
+		totalSecPop += population
 	}
-	avg := average(totalSecPop, len(sdb.sectors))
+	avg := average(totalSecPop, len(sectors))
 	sort.Ints(populations)
 	if len(populations) > 0 {
 		fmt.Fprintf(o, "- Min/Avg/P95/Max Population: %d/%.2f/%d/%d\n", populations[0], avg, percentile(.95, populations), populations[len(populations)-1])
 	}
+	fmt.Fprintf(o, "- Spatially indexed: %d\n", sdb.spatial.Len())
+	fmt.Fprintf(o, "- Range cache: %d origins, %d hits, %d misses\n", sdb.probe.Len(), sdb.probe.Hits, sdb.probe.Misses)
 }
 
 func reportOnFacilities(o io.Writer, sdb *SystemDatabase) {
@@ -216,7 +243,8 @@ func reportOnFacilities(o io.Writer, sdb *SystemDatabase) {
 		return
 	}
 
-	withCommodities, withListings, planetary := 0, 0, 0
+	withCommodities, withListings, planetary, withMarketID := 0, 0, 0, 0
+	economyDistrib := make(map[string]int, 32)
 	govtDistrib := make(map[string]int, 32)
 	allegDistrib := make(map[string]int, 32)
 	typeDistrib := make(map[string]int, 32)
@@ -228,9 +256,13 @@ func reportOnFacilities(o io.Writer, sdb *SystemDatabase) {
 		if len(facility.listings) > 0 {
 			withListings++
 		}
-		if facility.HasFeatures(FeatPlanetary) {
+		if facility.IsPlanetary() {
 			planetary++
 		}
+		if facility.MarketID != 0 {
+			withMarketID++
+		}
+		economyDistrib[gomschema.EconomyType_name[int32(facility.PrimaryEconomy())]]++
 		govtDistrib[gomschema.GovernmentType_name[int32(facility.Government)]]++
 		allegDistrib[gomschema.AllegianceType_name[int32(facility.Allegiance)]]++
 		typeDistrib[gomschema.FacilityType_name[int32(facility.FacilityType)]]++
@@ -247,6 +279,8 @@ func reportOnFacilities(o io.Writer, sdb *SystemDatabase) {
 
 	fmt.Fprintf(o, "- Marked 'Has Commodities': %d (%.2f%%)\n", withCommodities, percentage(withCommodities, total))
 	fmt.Fprintf(o, "- Known Listings: %d (%.2f%%)\n", withListings, percentage(withListings, total))
+	fmt.Fprintf(o, "- Market IDs: %d (%.2f%%)\n", withMarketID, percentage(withMarketID, total))
+	fmt.Fprintf(o, "- Planetary: %d (%.2f%%)\n", planetary, percentage(planetary, total))
 
 	stats := produceStats(typeDistrib, total)
 	fmt.Fprintf(o, "- Types: %s\n", strings.Join(stats, ", "))
@@ -254,6 +288,44 @@ func reportOnFacilities(o io.Writer, sdb *SystemDatabase) {
 	fmt.Fprintf(o, "- Governments: %s\n", strings.Join(stats, ", "))
 	stats = produceStats(allegDistrib, total)
 	fmt.Fprintf(o, "- Allegiances: %s\n", strings.Join(stats, ", "))
+	stats = produceStats(economyDistrib, total)
+	fmt.Fprintf(o, "- Primary Economies: %s\n", strings.Join(stats, ", "))
+}
+
+func reportOnOutfitting(o io.Writer, sdb *SystemDatabase) {
+	fmt.Fprintf(o, "Modules: %d, Ships: %d\n", len(sdb.modulesByID), len(sdb.shipsByID))
+	outfitters, shipyards := 0, 0
+	for _, facility := range sdb.facilitiesByID {
+		if facility.Outfitting != nil {
+			outfitters++
+		}
+		if facility.Shipyard != nil {
+			shipyards++
+		}
+	}
+	total := len(sdb.facilitiesByID)
+	fmt.Fprintf(o, "- Known Outfitting: %d (%.2f%%)\n", outfitters, percentage(outfitters, total))
+	fmt.Fprintf(o, "- Known Shipyards: %d (%.2f%%)\n", shipyards, percentage(shipyards, total))
+}
+
+func reportOnFreshness(o io.Writer, sdb *SystemDatabase, now uint64) {
+	counts := freshnessHistogram(sdb, now)
+	total := 0
+	for _, count := range counts {
+		total += count
+	}
+	fmt.Fprintf(o, "Listing Freshness: %d listings\n", total)
+	if total == 0 {
+		return
+	}
+	for idx, bucket := range freshnessBuckets {
+		fmt.Fprintf(o, "- %-6s %8d (%6.2f%%)\n", bucket.label, counts[idx], percentage(counts[idx], total))
+	}
+	unknown := counts[len(freshnessBuckets)]
+	fmt.Fprintf(o, "- %-6s %8d (%6.2f%%)\n", "undated", unknown, percentage(unknown, total))
+	if sdb.maxAge > 0 {
+		fmt.Fprintf(o, "- Max age: %s\n", sdb.maxAge)
+	}
 }
 
 func (sdb *SystemDatabase) Stats(o io.Writer) {
@@ -261,4 +333,6 @@ func (sdb *SystemDatabase) Stats(o io.Writer) {
 	reportOnSystems(o, sdb)
 	reportOnSectors(o, sdb)
 	reportOnFacilities(o, sdb)
+	reportOnOutfitting(o, sdb)
+	reportOnFreshness(o, sdb, uint64(time.Now().Unix()))
 }
@@ -1,7 +1,9 @@
 package main
 
 import (
+	"bytes"
 	"errors"
+	gom "github.com/kfsone/gomenacing/pkg/gomschema"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"testing"
@@ -16,7 +18,7 @@ func TestNewSystemDatabase(t *testing.T) {
 	assert.NotNil(t, sdb.facilitiesByID)
 	assert.NotNil(t, sdb.commoditiesByID)
 	assert.NotNil(t, sdb.commodityIDs)
-	assert.NotNil(t, sdb.sectors)
+	assert.NotNil(t, sdb.spatial)
 }
 
 func TestSystemDatabase_registerCommodity(t *testing.T) {
@@ -72,7 +74,7 @@ func TestSystemDatabase_registerSystem(t *testing.T) {
 	require.Nil(t, sdb.registerSystem(&first))
 	assert.Len(t, sdb.systemsByID, 1)
 	assert.Len(t, sdb.systemIDs, 1)
-	assert.Len(t, sdb.sectors, 0)
+	assert.Zero(t, sdb.spatial.Len())
 	lookup, ok = sdb.systemsByID[1]
 	assert.True(t, ok)
 	assert.Equal(t, &first, lookup)
@@ -84,7 +86,7 @@ func TestSystemDatabase_registerSystem(t *testing.T) {
 	require.Nil(t, sdb.registerSystem(&second))
 	assert.Len(t, sdb.systemsByID, 2)
 	assert.Len(t, sdb.systemIDs, 2)
-	assert.Len(t, sdb.sectors, 0)
+	assert.Zero(t, sdb.spatial.Len())
 
 	// Check the first system is still correct
 	lookup, ok = sdb.systemsByID[1]
@@ -106,13 +108,13 @@ func TestSystemDatabase_registerSystem(t *testing.T) {
 	err = sdb.registerSystem(&first)
 	if assert.True(t, errors.Is(err, ErrDuplicateEntity)) {
 		assert.Equal(t, "first (#1): duplicate: system id", err.Error())
-		assert.Len(t, sdb.sectors, 0)
+		assert.Zero(t, sdb.spatial.Len())
 	}
 
 	err = sdb.registerSystem(&System{DbEntity: DbEntity{3, "first"}})
 	if assert.Error(t, err) {
 		assert.Equal(t, "first (#3): duplicate: system name", err.Error())
-		assert.Len(t, sdb.sectors, 0)
+		assert.Zero(t, sdb.spatial.Len())
 	}
 }
 
@@ -285,53 +287,39 @@ func TestSystemDatabase_GetSystemByID(t *testing.T) {
 	assert.Len(t, sdb.systemsByID, 2)
 }
 
-func TestSystemDatabase_registerSystemToSector(t *testing.T) {
+func TestSystemDatabase_indexSystem(t *testing.T) {
 	sdb := NewSystemDatabase(nil)
 	system1 := System{DbEntity: DbEntity{ID: 101, DbName: "System1"}}
-	zeroKey := system1.position.SectorKey()
-	sdb.registerSystemToSector(&system1)
-	if assert.Len(t, sdb.sectors, 1) {
-		systems, exists := sdb.sectors[zeroKey]
-		if assert.True(t, exists) {
-			assert.Equal(t, []*System{&system1}, systems)
-		}
-	}
+	sdb.indexSystem(&system1)
+	assert.Equal(t, 1, sdb.spatial.Len())
 
 	// Adding it again should have no effect.
-	sdb.registerSystemToSector(&system1)
-	if assert.Len(t, sdb.sectors, 1) {
-		systems, exists := sdb.sectors[zeroKey]
-		if assert.True(t, exists) {
-			assert.Equal(t, []*System{&system1}, systems)
-		}
-	}
+	sdb.indexSystem(&system1)
+	assert.Equal(t, 1, sdb.spatial.Len())
 
-	// Add one at a different location.
+	// Moving it and re-indexing should update where it is found.
 	system1.position.X = 11110002
 	system1.position.Y = -2341
 	system1.position.Z = 1.23423
-	newKey := system1.position.SectorKey()
-	sdb.registerSystemToSector(&system1)
-	if assert.Len(t, sdb.sectors, 2) {
-		systems, exists := sdb.sectors[zeroKey]
-		if assert.True(t, exists) {
-			assert.Equal(t, []*System{&system1}, systems)
-			systems, exists = sdb.sectors[newKey]
-			if assert.True(t, exists) {
-				assert.Equal(t, []*System{&system1}, systems)
-			}
-		}
+	sdb.indexSystem(&system1)
+	assert.Equal(t, 1, sdb.spatial.Len())
+	found := sdb.spatial.Nearest(Coordinate{}, 1, nil)
+	if assert.Len(t, found, 1) {
+		assert.Equal(t, &system1, found[0].System)
+		assert.Equal(t, Distance(&system1, Coordinate{}), found[0].DistSq)
 	}
 
 	// Adding a second system should work
 	system2 := System{DbEntity: DbEntity{ID: 404, DbName: "System2"}, position: system1.position}
-	sdb.registerSystemToSector(&system2)
-	if assert.Len(t, sdb.sectors, 2) {
-		systems, exists := sdb.sectors[newKey]
-		if assert.True(t, exists) {
-			assert.Equal(t, []*System{&system1, &system2}, systems)
-		}
-	}
+	sdb.indexSystem(&system2)
+	assert.Equal(t, 2, sdb.spatial.Len())
+	matches := 0
+	sdb.spatial.WithinRadius(system1.position, 1, func(system *System, distSq SquareFloat) bool {
+		assert.Zero(t, distSq)
+		matches++
+		return true
+	})
+	assert.Equal(t, 2, matches)
 }
 
 type TestTimestamped struct {
@@ -353,3 +341,96 @@ func Test_requireNewer(t *testing.T) {
 	first.timestamp = second.timestamp
 	assert.Nil(t, requireNewer(first, second))
 }
+
+func TestSystemDatabase_GetFacilityByMarketID(t *testing.T) {
+	testDir := GetTestDir()
+	defer testDir.Close()
+	db, err := OpenDatabase(testDir.Path(), "marketid.db")
+	require.Nil(t, err)
+	defer db.Close()
+	schema, err := db.Facilities()
+	require.Nil(t, err)
+	defer func() { failOnError(schema.Close()) }()
+
+	sdb := NewSystemDatabase(db)
+	require.Nil(t, sdb.registerSystem(&System{DbEntity: DbEntity{ID: 1, DbName: "Sol"}}))
+	assert.Nil(t, sdb.GetFacilityByMarketID(128016640))
+
+	station := &gom.Facility{Id: 1, SystemId: 1, Name: "Abraham Lincoln", TimestampUtc: 1, MarketId: 128016640}
+	require.Nil(t, sdb.updateFacility(station, schema, attribution{source: "test"}))
+	facility := sdb.GetFacilityByMarketID(128016640)
+	require.NotNil(t, facility)
+	assert.EqualValues(t, 1, facility.ID)
+
+	// Changing the market id should move the facility in the index.
+	station.TimestampUtc, station.MarketId = 2, 128016641
+	station.Economies = []gom.EconomyType{gom.EconomyType_EcoService}
+	require.Nil(t, sdb.updateFacility(station, schema, attribution{source: "test"}))
+	assert.Nil(t, sdb.GetFacilityByMarketID(128016640))
+	assert.Equal(t, facility, sdb.GetFacilityByMarketID(128016641))
+	assert.Equal(t, []gom.EconomyType{gom.EconomyType_EcoService}, facility.Economies)
+}
+
+func TestSystemDatabase_updateFacilityListing(t *testing.T) {
+	testDir := GetTestDir()
+	defer testDir.Close()
+	db, err := OpenDatabase(testDir.Path(), "listings.db")
+	require.Nil(t, err)
+	defer db.Close()
+	schema, err := db.Listings()
+	require.Nil(t, err)
+	defer func() { failOnError(schema.Close()) }()
+
+	sdb := NewSystemDatabase(db)
+	require.Nil(t, sdb.newCommodity(&gom.Commodity{Id: 1, Name: "Gold", CategoryId: gom.Commodity_CatMetals}))
+	require.Nil(t, sdb.newSystem(&gom.System{Id: 1, Name: "Sol", Position: &gom.Coordinate{}}))
+	require.Nil(t, sdb.newFacility(&gom.Facility{Id: 1, SystemId: 1, Name: "Abraham Lincoln"}))
+
+	// The first listing for a facility should be recorded.
+	update := &gom.FacilityListing{Id: 1, Listings: []*gom.CommodityListing{
+		{CommodityId: 1, SupplyUnits: 10, SupplyCredits: 9000, TimestampUtc: 100, SupplyBracket: gom.MarketBracket_BracketLow},
+	}}
+	require.Nil(t, sdb.updateFacilityListing(update, schema, attribution{source: "test"}))
+	listing := sdb.GetFacilityByID(1).listings[1]
+	require.NotNil(t, listing)
+	assert.EqualValues(t, 9000, listing.StationAsks)
+	assert.Equal(t, gom.MarketBracket_BracketLow, listing.SupplyBracket)
+
+	// Stale updates are ignored, newer ones applied.
+	update.Listings[0].SupplyCredits, update.Listings[0].TimestampUtc = 8000, 50
+	require.Nil(t, sdb.updateFacilityListing(update, schema, attribution{source: "test"}))
+	assert.EqualValues(t, 9000, listing.StationAsks)
+	update.Listings[0].TimestampUtc, update.Listings[0].SupplyBracket = 200, gom.MarketBracket_BracketHigh
+	require.Nil(t, sdb.updateFacilityListing(update, schema, attribution{source: "test"}))
+	assert.EqualValues(t, 8000, listing.StationAsks)
+	assert.Equal(t, gom.MarketBracket_BracketHigh, listing.SupplyBracket)
+}
+
+func TestSystemDatabase_nextIDs(t *testing.T) {
+	sdb := NewSystemDatabase(nil)
+	assert.EqualValues(t, 1, sdb.nextCommodityID())
+	assert.Equal(t, syntheticIDBase, sdb.nextSystemID())
+	assert.Equal(t, syntheticIDBase, sdb.nextFacilityID())
+
+	// EDDB's ids don't use up synthetic ones.
+	require.Nil(t, sdb.newCommodity(&gom.Commodity{Id: 7, Name: "Gold"}))
+	require.Nil(t, sdb.newSystem(&gom.System{Id: 7, Name: "Sol", Position: &gom.Coordinate{}}))
+	require.Nil(t, sdb.newFacility(&gom.Facility{Id: 7, SystemId: 7, Name: "Galileo"}))
+	assert.EqualValues(t, 8, sdb.nextCommodityID())
+	assert.Equal(t, syntheticIDBase, sdb.nextSystemID())
+	assert.Equal(t, syntheticIDBase, sdb.nextFacilityID())
+
+	require.Nil(t, sdb.newSystem(&gom.System{Id: uint32(sdb.nextSystemID()), Name: "Lave", Position: &gom.Coordinate{}}))
+	require.Nil(t, sdb.newFacility(&gom.Facility{Id: uint32(sdb.nextFacilityID()), SystemId: 7, Name: "Daedalus"}))
+	assert.Equal(t, syntheticIDBase+1, sdb.nextSystemID())
+	assert.Equal(t, syntheticIDBase+1, sdb.nextFacilityID())
+
+	// Loading a snapshot skips registration, but not the counters.
+	var buffer bytes.Buffer
+	require.Nil(t, sdb.WriteSnapshot(&buffer, 1))
+	loaded := NewSystemDatabase(nil)
+	require.Nil(t, loaded.ReadSnapshot(&buffer, 1))
+	assert.EqualValues(t, 8, loaded.nextCommodityID())
+	assert.Equal(t, syntheticIDBase+1, loaded.nextSystemID())
+	assert.Equal(t, syntheticIDBase+1, loaded.nextFacilityID())
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	gom "github.com/kfsone/gomenacing/pkg/gomschema"
+)
+
+// SystemPredicate decides whether a system is of interest to a search.
+type SystemPredicate func(*System) bool
+
+// FacilityPredicate decides whether a facility is of interest to a search.
+type FacilityPredicate func(*Facility) bool
+
+// ErrInvalidFilter is returned for filters that can't be parsed.
+var ErrInvalidFilter = errors.New("invalid filter")
+
+// SystemFilterHelp describes the filters accepted by ParseSystemFilters.
+const SystemFilterHelp = "populated, unpopulated, permit, nopermit, allegiance=<name>, government=<name>, " +
+	"security=<level>, economy=<name>, state=<name>, minpop=<population>, faction=<part of name>, " +
+	"pad=<small|medium|large>, feature=<name>[,<name>...], facility=<type>, " +
+	"stationeconomy=<name>, landing=<planetary|orbital>, body=<part of name>"
+
+// parseEnumName looks up an enum value by name, ignoring case and the prefix
+// the schema uses for the type's names, e.g. "empire" for "AllegEmpire".
+func parseEnumName(names map[int32]string, prefix string, value string) (int32, error) {
+	for number, name := range names {
+		if strings.EqualFold(name, value) || strings.EqualFold(strings.TrimPrefix(name, prefix), value) {
+			return number, nil
+		}
+	}
+	options := make([]string, 0, len(names))
+	for _, name := range names {
+		options = append(options, strings.ToLower(strings.TrimPrefix(name, prefix)))
+	}
+	sort.Strings(options)
+	return 0, fmt.Errorf("%w: unrecognized value '%s', expected one of: %s", ErrInvalidFilter, value, strings.Join(options, ", "))
+}
+
+// parsePadSize translates a pad size name into the matching feature.
+func parsePadSize(value string) (FacilityFeatureMask, error) {
+	switch strings.ToLower(value) {
+	case "s", "small":
+		return FeatSmallPad, nil
+	case "m", "medium":
+		return FeatMediumPad, nil
+	case "l", "large":
+		return FeatLargePad, nil
+	default:
+		return 0, fmt.Errorf("%w: unrecognized pad size '%s', expected small, medium or large", ErrInvalidFilter, value)
+	}
+}
+
+// IsSystemFilter returns true if the argument looks like a filter rather than
+// part of a system name.
+func IsSystemFilter(arg string) bool {
+	switch strings.ToLower(arg) {
+	case "populated", "unpopulated", "permit", "nopermit":
+		return true
+	}
+	return strings.Contains(arg, "=")
+}
+
+// ParseSystemFilters builds a predicate that requires all of the given filters
+// to match. Facility filters (pad, feature, facility, stationeconomy,
+// landing, body) must all be satisfied
+// by the same facility. With no filters, the predicate is nil.
+func ParseSystemFilters(args []string) (SystemPredicate, error) {
+	systemTests := make([]SystemPredicate, 0, len(args))
+	facilityTests := make([]FacilityPredicate, 0, len(args))
+	for _, arg := range args {
+		key, value := strings.ToLower(arg), ""
+		if idx := strings.IndexByte(arg, '='); idx >= 0 {
+			key, value = strings.ToLower(arg[:idx]), arg[idx+1:]
+		}
+		switch key {
+		case "populated":
+			systemTests = append(systemTests, func(s *System) bool { return s.Populated })
+		case "unpopulated":
+			systemTests = append(systemTests, func(s *System) bool { return !s.Populated })
+		case "permit":
+			systemTests = append(systemTests, func(s *System) bool { return s.NeedsPermit })
+		case "nopermit":
+			systemTests = append(systemTests, func(s *System) bool { return !s.NeedsPermit })
+		case "allegiance":
+			number, err := parseEnumName(gom.AllegianceType_name, "Alleg", value)
+			if err != nil {
+				return nil, err
+			}
+			systemTests = append(systemTests, func(s *System) bool { return s.Allegiance == gom.AllegianceType(number) })
+		case "government":
+			number, err := parseEnumName(gom.GovernmentType_name, "Gov", value)
+			if err != nil {
+				return nil, err
+			}
+			systemTests = append(systemTests, func(s *System) bool { return s.Government == gom.GovernmentType(number) })
+		case "security":
+			number, err := parseEnumName(gom.SecurityLevel_name, "Security", value)
+			if err != nil {
+				return nil, err
+			}
+			systemTests = append(systemTests, func(s *System) bool { return s.SecurityLevel == gom.SecurityLevel(number) })
+		case "economy":
+			number, err := parseEnumName(gom.EconomyType_name, "Eco", value)
+			if err != nil {
+				return nil, err
+			}
+			systemTests = append(systemTests, func(s *System) bool { return s.PrimaryEconomy == gom.EconomyType(number) })
+		case "state":
+			number, err := parseEnumName(gom.StateType_name, "State", value)
+			if err != nil {
+				return nil, err
+			}
+			systemTests = append(systemTests, func(s *System) bool { return s.HasState(gom.StateType(number)) })
+		case "minpop":
+			population, err := strconv.ParseUint(value, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("%w: minpop: %s", ErrInvalidFilter, err)
+			}
+			systemTests = append(systemTests, func(s *System) bool { return s.Population >= population })
+		case "faction":
+			// Faction names tend to have spaces in, so match any part of the name.
+			fragment := strings.ToLower(value)
+			systemTests = append(systemTests, func(s *System) bool {
+				return strings.Contains(strings.ToLower(s.ControllingFaction), fragment)
+			})
+		case "pad":
+			size, err := parsePadSize(value)
+			if err != nil {
+				return nil, err
+			}
+			facilityTests = append(facilityTests, func(f *Facility) bool { return f.SupportsPadSize(size) })
+		case "feature", "features":
+			mask := FacilityFeatureMask(0)
+			for _, name := range strings.Split(value, ",") {
+				bit, err := parseEnumName(gom.FeatureBit_name, "", name)
+				if err != nil {
+					return nil, err
+				}
+				mask |= FacilityFeatureMask(1 << bit)
+			}
+			facilityTests = append(facilityTests, func(f *Facility) bool { return f.HasFeatures(mask) })
+		case "facility", "type":
+			number, err := parseEnumName(gom.FacilityType_name, "FT", value)
+			if err != nil {
+				return nil, err
+			}
+			facilityTests = append(facilityTests, func(f *Facility) bool { return f.FacilityType == gom.FacilityType(number) })
+		case "stationeconomy":
+			number, err := parseEnumName(gom.EconomyType_name, "Eco", value)
+			if err != nil {
+				return nil, err
+			}
+			facilityTests = append(facilityTests, func(f *Facility) bool { return f.HasEconomy(gom.EconomyType(number)) })
+		case "landing":
+			var planetary bool
+			switch strings.ToLower(value) {
+			case "planetary", "surface", "yes":
+				planetary = true
+			case "orbital", "space", "no":
+				planetary = false
+			default:
+				return nil, fmt.Errorf("%w: landing: expected planetary or orbital, got '%s'", ErrInvalidFilter, value)
+			}
+			facilityTests = append(facilityTests, func(f *Facility) bool { return f.IsPlanetary() == planetary })
+		case "body":
+			fragment := strings.ToLower(value)
+			facilityTests = append(facilityTests, func(f *Facility) bool {
+				return strings.Contains(strings.ToLower(f.Body), fragment)
+			})
+		default:
+			return nil, fmt.Errorf("%w: %s (filters are: %s)", ErrInvalidFilter, arg, SystemFilterHelp)
+		}
+	}
+
+	if len(facilityTests) > 0 {
+		systemTests = append(systemTests, func(s *System) bool {
+			for _, facility := range s.facilities {
+				if allFacilityTests(facilityTests, facility) {
+					return true
+				}
+			}
+			return false
+		})
+	}
+	if len(systemTests) == 0 {
+		return nil, nil
+	}
+	return func(s *System) bool {
+		for _, test := range systemTests {
+			if !test(s) {
+				return false
+			}
+		}
+		return true
+	}, nil
+}
+
+func allFacilityTests(tests []FacilityPredicate, facility *Facility) bool {
+	for _, test := range tests {
+		if !test(facility) {
+			return false
+		}
+	}
+	return true
+}
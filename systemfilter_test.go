@@ -0,0 +1,115 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	gom "github.com/kfsone/gomenacing/pkg/gomschema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_parseEnumName(t *testing.T) {
+	number, err := parseEnumName(gom.AllegianceType_name, "Alleg", "empire")
+	assert.Nil(t, err)
+	assert.EqualValues(t, gom.AllegianceType_AllegEmpire, number)
+
+	number, err = parseEnumName(gom.AllegianceType_name, "Alleg", "AllegFederation")
+	assert.Nil(t, err)
+	assert.EqualValues(t, gom.AllegianceType_AllegFederation, number)
+
+	_, err = parseEnumName(gom.AllegianceType_name, "Alleg", "thargoid")
+	assert.True(t, errors.Is(err, ErrInvalidFilter))
+}
+
+func TestIsSystemFilter(t *testing.T) {
+	assert.True(t, IsSystemFilter("populated"))
+	assert.True(t, IsSystemFilter("NoPermit"))
+	assert.True(t, IsSystemFilter("pad=large"))
+	assert.False(t, IsSystemFilter("Sol"))
+	assert.False(t, IsSystemFilter("Ross"))
+}
+
+func TestParseSystemFilters(t *testing.T) {
+	imperial := &System{DbEntity: DbEntity{ID: 1, DbName: "Imperial"}, Populated: true, Allegiance: gom.AllegianceType_AllegEmpire, SecurityLevel: gom.SecurityLevel_SecurityHigh,
+		Population: 250000, PrimaryEconomy: gom.EconomyType_EcoHighTech, States: []gom.StateType{gom.StateType_StateOutbreak},
+		ControllingFaction: "Imperial Guard of Achenar"}
+	outpost, _ := NewFacility(DbEntity{ID: 1, DbName: "Outpost"}, imperial, gom.FacilityType_FTCivilianOutpost, FeatSmallPad|FeatMarket)
+	starport, _ := NewFacility(DbEntity{ID: 2, DbName: "Port"}, imperial, gom.FacilityType_FTOrbisStarport, FeatLargePad|FeatShipyard)
+	starport.Economies = []gom.EconomyType{gom.EconomyType_EcoRefinery, gom.EconomyType_EcoExtraction}
+	starport.Body = "Imperial 3 a"
+	outpost.Landing = true
+	imperial.facilities = []*Facility{outpost, starport}
+	empty := &System{DbEntity: DbEntity{ID: 2, DbName: "Empty"}, NeedsPermit: true}
+
+	predicate, err := ParseSystemFilters(nil)
+	assert.Nil(t, err)
+	assert.Nil(t, predicate)
+
+	tests := []struct {
+		filters  []string
+		imperial bool
+		empty    bool
+	}{
+		{[]string{"populated"}, true, false},
+		{[]string{"unpopulated"}, false, true},
+		{[]string{"permit"}, false, true},
+		{[]string{"nopermit"}, true, false},
+		{[]string{"allegiance=empire"}, true, false},
+		{[]string{"allegiance=none"}, false, true},
+		{[]string{"security=high", "populated"}, true, false},
+		{[]string{"government=anarchy"}, false, false},
+		{[]string{"economy=hightech"}, true, false},
+		{[]string{"economy=none"}, false, true},
+		{[]string{"state=outbreak"}, true, false},
+		{[]string{"state=boom"}, false, false},
+		{[]string{"minpop=250000"}, true, false},
+		{[]string{"minpop=250001"}, false, false},
+		{[]string{"faction=achenar"}, true, false},
+		{[]string{"pad=l"}, true, false},
+		{[]string{"pad=small"}, true, false},
+		{[]string{"facility=orbisstarport"}, true, false},
+		{[]string{"feature=shipyard"}, true, false},
+		{[]string{"stationeconomy=extraction"}, true, false},
+		{[]string{"stationeconomy=tourism"}, false, false},
+		{[]string{"landing=planetary", "pad=small"}, true, false},
+		{[]string{"landing=planetary", "pad=large"}, false, false},
+		{[]string{"landing=orbital", "pad=large"}, true, false},
+		{[]string{"body=3 A"}, true, false},
+		// Facility filters have to be satisfied by a single facility.
+		{[]string{"pad=large", "feature=market"}, false, false},
+		{[]string{"pad=large", "feature=shipyard"}, true, false},
+	}
+	for _, tt := range tests {
+		predicate, err := ParseSystemFilters(tt.filters)
+		require.Nil(t, err, tt.filters)
+		assert.Equal(t, tt.imperial, predicate(imperial), tt.filters)
+		assert.Equal(t, tt.empty, predicate(empty), tt.filters)
+	}
+
+	for _, invalid := range []string{"pad=huge", "allegiance=thargoid", "feature=cake", "colour=blue", "state=calm", "minpop=lots", "landing=maybe"} {
+		_, err := ParseSystemFilters([]string{invalid})
+		assert.True(t, errors.Is(err, ErrInvalidFilter), invalid)
+	}
+}
+
+func TestSystemDatabase_NearestSystems(t *testing.T) {
+	sdb := makeTestGalaxy(1000, 100, 10)
+	origin := sdb.systemsByID[1]
+
+	nearest := sdb.NearestSystems(origin, 3, nil)
+	require.Len(t, nearest, 3)
+	assert.Equal(t, origin, nearest[0].System)
+
+	predicate, err := ParseSystemFilters([]string{"populated"})
+	require.Nil(t, err)
+	nearest = sdb.NearestSystems(origin, 5, predicate)
+	require.Len(t, nearest, 5)
+	for _, neighbor := range nearest {
+		assert.True(t, neighbor.System.Populated)
+	}
+
+	// The search isn't limited by distance.
+	nearest = sdb.NearestSystems(origin, 5000, predicate)
+	assert.Len(t, nearest, countSystems(sdb, predicate))
+}
@@ -0,0 +1,498 @@
+package main
+
+// TradeDangerous keeps its static data in CSV tables: System.csv,
+// Station.csv, Item.csv and StationItem.csv. Rows refer to each other by
+// name, strings are single-quoted with quotes doubled, and flags are 'Y',
+// 'N' or '?'. Entities are matched to ours by name; anything new is given
+// the next free id.
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	gom "github.com/kfsone/gomenacing/pkg/gomschema"
+	"github.com/kfsone/gomenacing/pkg/parsing"
+)
+
+// TDTable describes a table written or read by the TradeDangerous exchange.
+type TDTable struct {
+	Filename string
+	Count    int
+}
+
+// tdTypeIDs maps facility types to TradeDangerous station type ids.
+var tdTypeIDs = map[gom.FacilityType]int{
+	gom.FacilityType_FTCivilianOutpost:     1,
+	gom.FacilityType_FTCommercialOutpost:   2,
+	gom.FacilityType_FTCoriolisStarport:    3,
+	gom.FacilityType_FTIndustrialOutpost:   4,
+	gom.FacilityType_FTMilitaryOutpost:     5,
+	gom.FacilityType_FTMiningOutpost:       6,
+	gom.FacilityType_FTOcellusStarport:     7,
+	gom.FacilityType_FTOrbisStarport:       8,
+	gom.FacilityType_FTScientificOutpost:   9,
+	gom.FacilityType_FTPlanetaryOutpost:    13,
+	gom.FacilityType_FTPlanetaryPort:       14,
+	gom.FacilityType_FTPlanetarySettlement: 16,
+	gom.FacilityType_FTMegaship:            19,
+	gom.FacilityType_FTAsteroidBase:        20,
+	gom.FacilityType_FTFleetCarrier:        24,
+}
+
+// tdFlags are the Station.csv columns that map directly onto features.
+var tdFlags = []struct {
+	column  string
+	feature FacilityFeatureMask
+}{
+	{"blackmarket", FeatBlackMarket},
+	{"market", FeatMarket | FeatCommodities},
+	{"shipyard", FeatShipyard},
+	{"outfitting", FeatOutfitting},
+	{"rearm", FeatRearm},
+	{"refuel", FeatRefuel},
+	{"repair", FeatRepair},
+	{"planetary", FeatPlanetary},
+	{"fleet", FeatFleet},
+}
+
+// tdPadFeatures are the features described by max_pad_size.
+const tdPadFeatures = FeatSmallPad | FeatMediumPad | FeatLargePad
+
+func tdQuote(text string) string {
+	return "'" + strings.ReplaceAll(text, "'", "''") + "'"
+}
+
+func tdUnquote(text string) string {
+	text = strings.TrimSpace(text)
+	if len(text) >= 2 && text[0] == '\'' && text[len(text)-1] == '\'' {
+		text = strings.ReplaceAll(text[1:len(text)-1], "''", "'")
+	}
+	return strings.Trim(text, "\"")
+}
+
+func tdFlag(set bool) string {
+	if set {
+		return "'Y'"
+	}
+	return "'N'"
+}
+
+func tdTime(timestamp uint64) string {
+	return tdQuote(time.Unix(int64(timestamp), 0).UTC().Format(pricesTimeFormat))
+}
+
+func parseTDTime(text string) (uint64, error) {
+	when, err := time.Parse(pricesTimeFormat, tdUnquote(text))
+	if err != nil || when.Unix() < 0 {
+		return 0, fmt.Errorf("invalid timestamp: %s", text)
+	}
+	return uint64(when.Unix()), nil
+}
+
+// tdPadSize is the largest pad at facility, "?" if unknown.
+func tdPadSize(facility *Facility) string {
+	switch {
+	case facility.Features&FeatLargePad != 0:
+		return "'L'"
+	case facility.Features&FeatMediumPad != 0:
+		return "'M'"
+	case facility.Features&FeatSmallPad != 0:
+		return "'S'"
+	default:
+		return "'?'"
+	}
+}
+
+// tdLevel translates a bracket to a TradeDangerous level, -1 for unknown.
+func tdLevel(bracket gom.MarketBracket) string {
+	return strconv.Itoa(int(bracket) - 1)
+}
+
+func parseTDLevel(text string) (gom.MarketBracket, error) {
+	level, err := strconv.Atoi(text)
+	if err != nil || level < -1 || level > 3 {
+		return 0, fmt.Errorf("invalid level: %s", text)
+	}
+	return gom.MarketBracket(level + 1), nil
+}
+
+// parseTDCategory finds the category with the given display name.
+func parseTDCategory(name string) gom.Commodity_Category {
+	for value := range gom.Commodity_Category_name {
+		if strings.EqualFold(categoryName(gom.Commodity_Category(value)), name) {
+			return gom.Commodity_Category(value)
+		}
+	}
+	return gom.Commodity_CatUnknown
+}
+
+// tdTable describes how to write and read one of the TradeDangerous tables.
+type tdTable struct {
+	filename string
+	headers  []string
+	rows     func(sdb *SystemDatabase) [][]string
+	read     func(r *tdReader, row []string) error
+}
+
+// tdStationHeaders are the columns of Station.csv.
+var tdStationHeaders = []string{"unq@System.name", "unq:name", "ls_from_star", "blackmarket", "max_pad_size", "market", "shipyard",
+	"modified", "outfitting", "rearm", "refuel", "repair", "planetary", "type_id", "fleet"}
+
+// tdTables are the tables, in the order they must be read.
+var tdTables = []tdTable{
+	{"System.csv", []string{"unq:name", "pos_x", "pos_y", "pos_z", "modified"}, tdSystemRows, (*tdReader).readSystem},
+	{"Item.csv", []string{"unq:item_id", "name@Category.category_id", "name", "avg_price"}, tdItemRows, (*tdReader).readItem},
+	{"Station.csv", tdStationHeaders, tdStationRows, (*tdReader).readStation},
+	{"StationItem.csv", []string{"unq@System.name", "unq@Station.name", "unq@Item.name", "demand_price", "demand_units", "demand_level",
+		"supply_price", "supply_units", "supply_level", "modified"}, tdStationItemRows, (*tdReader).readStationItem},
+}
+
+func tdSystemRows(sdb *SystemDatabase) [][]string {
+	systems := make([]*System, 0, len(sdb.systemsByID))
+	for _, system := range sdb.systemsByID {
+		systems = append(systems, system)
+	}
+	sort.Slice(systems, func(i, j int) bool { return systems[i].DbName < systems[j].DbName })
+	rows := make([][]string, 0, len(systems))
+	for _, system := range systems {
+		position := system.Position()
+		rows = append(rows, []string{tdQuote(strings.ToUpper(system.DbName)), strconv.FormatFloat(position.X, 'f', -1, 64),
+			strconv.FormatFloat(position.Y, 'f', -1, 64), strconv.FormatFloat(position.Z, 'f', -1, 64), tdTime(system.TimestampUtc)})
+	}
+	return rows
+}
+
+// sortedCommodities returns the commodities by category and then name.
+func (sdb *SystemDatabase) sortedCommodities() []*Commodity {
+	commodities := make([]*Commodity, 0, len(sdb.commoditiesByID))
+	for _, commodity := range sdb.commoditiesByID {
+		commodities = append(commodities, commodity)
+	}
+	sort.Slice(commodities, func(i, j int) bool {
+		if commodities[i].CategoryID != commodities[j].CategoryID {
+			return categoryName(commodities[i].CategoryID) < categoryName(commodities[j].CategoryID)
+		}
+		return commodities[i].DbName < commodities[j].DbName
+	})
+	return commodities
+}
+
+func tdItemRows(sdb *SystemDatabase) [][]string {
+	commodities := sdb.sortedCommodities()
+	rows := make([][]string, 0, len(commodities))
+	for _, commodity := range commodities {
+		rows = append(rows, []string{strconv.Itoa(int(commodity.ID)), tdQuote(categoryName(commodity.CategoryID)),
+			tdQuote(commodity.DbName), strconv.Itoa(int(commodity.AverageCr))})
+	}
+	return rows
+}
+
+// sortedFacilities returns the facilities by system and then name.
+func (sdb *SystemDatabase) sortedFacilities() []*Facility {
+	facilities := make([]*Facility, 0, len(sdb.facilitiesByID))
+	for _, facility := range sdb.facilitiesByID {
+		facilities = append(facilities, facility)
+	}
+	sort.Slice(facilities, func(i, j int) bool {
+		if facilities[i].System != facilities[j].System {
+			return facilities[i].System.DbName < facilities[j].System.DbName
+		}
+		return facilities[i].DbName < facilities[j].DbName
+	})
+	return facilities
+}
+
+func tdStationRows(sdb *SystemDatabase) [][]string {
+	facilities := sdb.sortedFacilities()
+	rows := make([][]string, 0, len(facilities))
+	for _, facility := range facilities {
+		flags := make(map[string]string, len(tdFlags))
+		for _, flag := range tdFlags {
+			flags[flag.column] = tdFlag(facility.Features&flag.feature == flag.feature)
+		}
+		rows = append(rows, []string{tdQuote(strings.ToUpper(facility.System.DbName)), tdQuote(facility.DbName),
+			strconv.Itoa(int(facility.LsFromStar)), flags["blackmarket"], tdPadSize(facility), flags["market"], flags["shipyard"],
+			tdTime(facility.TimestampUtc), flags["outfitting"], flags["rearm"], flags["refuel"], flags["repair"], flags["planetary"],
+			strconv.Itoa(tdTypeIDs[facility.FacilityType]), flags["fleet"]})
+	}
+	return rows
+}
+
+func tdStationItemRows(sdb *SystemDatabase) [][]string {
+	rows := make([][]string, 0, 1024)
+	commodities := sdb.sortedCommodities()
+	for _, facility := range sdb.sortedFacilities() {
+		for _, commodity := range commodities {
+			listing, exists := facility.listings[commodity.ID]
+			if !exists {
+				continue
+			}
+			rows = append(rows, []string{tdQuote(strings.ToUpper(facility.System.DbName)), tdQuote(facility.DbName),
+				tdQuote(commodity.DbName), strconv.Itoa(int(listing.StationPays)), strconv.Itoa(int(listing.Demand)),
+				tdLevel(listing.DemandBracket), strconv.Itoa(int(listing.StationAsks)), strconv.Itoa(int(listing.Supply)),
+				tdLevel(listing.SupplyBracket), tdTime(listing.TimestampUtc)})
+		}
+	}
+	return rows
+}
+
+// ExportTD writes the TradeDangerous tables into dir.
+func (sdb *SystemDatabase) ExportTD(dir string) ([]TDTable, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	tables := make([]TDTable, 0, len(tdTables))
+	for _, table := range tdTables {
+		rows := table.rows(sdb)
+		var text strings.Builder
+		text.WriteString(strings.Join(table.headers, ","))
+		text.WriteString("\n")
+		for _, row := range rows {
+			text.WriteString(strings.Join(row, ","))
+			text.WriteString("\n")
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, table.filename), []byte(text.String()), 0644); err != nil {
+			return tables, err
+		}
+		tables = append(tables, TDTable{table.filename, len(rows)})
+	}
+	return tables, nil
+}
+
+// tdReader translates rows of the TradeDangerous tables into updates.
+type tdReader struct {
+	messageWriter
+	// listings collects each station's items, which are applied together.
+	listings map[EntityID]*gom.FacilityListing
+}
+
+// ImportTD reads the TradeDangerous tables in dir, skipping any that are
+// missing, and applies them to sdb and db.
+func (sdb *SystemDatabase) ImportTD(dir string, db *Database) ([]TDTable, error) {
+	reader := &tdReader{messageWriter: newMessageWriter(sdb, db, SourceTradeDangerous)}
+	defer reader.Close()
+
+	tables := make([]TDTable, 0, len(tdTables))
+	for _, table := range tdTables {
+		count, err := reader.readTable(filepath.Join(dir, table.filename), table)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return tables, fmt.Errorf("%s: %w", table.filename, err)
+		}
+		tables = append(tables, TDTable{table.filename, count})
+	}
+	return tables, nil
+}
+
+func (r *tdReader) readTable(pathname string, table tdTable) (count int, err error) {
+	file, err := os.Open(pathname)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { Must(file.Close()) }()
+
+	rows, err := parsing.ParseCSV(file, table.headers)
+	if errors.Is(err, io.EOF) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	r.listings = make(map[EntityID]*gom.FacilityListing)
+	for row := range rows {
+		if err == nil {
+			if err = FilterError(table.read(r, row)); err == nil {
+				count++
+			}
+		}
+	}
+	if err != nil {
+		return count, err
+	}
+	// Listings are applied as a whole per station.
+	ids := make([]EntityID, 0, len(r.listings))
+	for id := range r.listings {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	for _, id := range ids {
+		if err = r.apply(r.listings[id]); err != nil {
+			return count, err
+		}
+	}
+	return count, nil
+}
+
+func (r *tdReader) readSystem(row []string) error {
+	name := tdUnquote(row[0])
+	var position [3]float64
+	for idx := range position {
+		value, err := strconv.ParseFloat(row[1+idx], 64)
+		if err != nil {
+			return fmt.Errorf("system %s: invalid position: %s", name, row[1+idx])
+		}
+		position[idx] = value
+	}
+	timestamp, err := parseTDTime(row[4])
+	if err != nil {
+		return fmt.Errorf("system %s: %w", name, err)
+	}
+
+	item := &gom.System{Position: &gom.Coordinate{}}
+	if system := r.sdb.GetSystem(name); system != nil {
+		if timestamp < system.TimestampUtc {
+			return nil
+		}
+		SerializeSystem(item, system)
+	} else {
+		item.Id, item.Name = uint32(r.sdb.nextSystemID()), name
+	}
+	item.TimestampUtc = timestamp
+	item.Position.X, item.Position.Y, item.Position.Z = position[0], position[1], position[2]
+	return r.apply(item)
+}
+
+func (r *tdReader) readItem(row []string) error {
+	name := tdUnquote(row[2])
+	averageCr, err := strconv.ParseUint(row[3], 10, 32)
+	if err != nil {
+		return fmt.Errorf("item %s: invalid avg_price: %s", name, row[3])
+	}
+	item := &gom.Commodity{}
+	if commodity := r.sdb.GetCommodity(name); commodity != nil {
+		SerializeCommodity(item, commodity)
+	} else {
+		item.Id, item.Name = uint32(r.sdb.nextCommodityID()), name
+	}
+	item.CategoryId = parseTDCategory(tdUnquote(row[1]))
+	item.AverageCr = uint32(averageCr)
+	return r.apply(item)
+}
+
+func (r *tdReader) readStation(row []string) error {
+	columns := make(map[string]string, len(row))
+	for idx, header := range tdStationHeaders {
+		columns[header] = tdUnquote(row[idx])
+	}
+	name := columns["unq:name"]
+	system := r.sdb.GetSystem(columns["unq@System.name"])
+	if system == nil {
+		return fmt.Errorf("%w: system for station %s: %s", ErrUnknownEntity, name, columns["unq@System.name"])
+	}
+	timestamp, err := parseTDTime(columns["modified"])
+	if err != nil {
+		return fmt.Errorf("station %s: %w", name, err)
+	}
+	lsFromStar, err := strconv.ParseFloat(columns["ls_from_star"], 64)
+	if err != nil {
+		return fmt.Errorf("station %s: invalid ls_from_star: %s", name, columns["ls_from_star"])
+	}
+	typeID, err := strconv.Atoi(columns["type_id"])
+	if err != nil {
+		return fmt.Errorf("station %s: invalid type_id: %s", name, columns["type_id"])
+	}
+
+	item := &gom.Facility{}
+	if facility := system.GetFacility(name); facility != nil {
+		if timestamp < facility.TimestampUtc {
+			return nil
+		}
+		Must(SerializeFacility(item, facility))
+	} else {
+		item.Id, item.SystemId, item.Name = uint32(r.sdb.nextFacilityID()), uint32(system.ID), name
+	}
+	item.TimestampUtc = timestamp
+	item.LsFromStar = uint32(lsFromStar + 0.5)
+	item.FacilityType = gom.FacilityType_FTNone
+	for facilityType, id := range tdTypeIDs {
+		if id == typeID {
+			item.FacilityType = facilityType
+		}
+	}
+
+	// Only replace what the table knows about.
+	features := FacilityFeatureMask(item.Features) &^ tdPadFeatures
+	for _, flag := range tdFlags {
+		switch strings.ToUpper(columns[flag.column]) {
+		case "Y":
+			features |= flag.feature
+		case "N":
+			features &^= flag.feature
+		}
+	}
+	switch strings.ToUpper(columns["max_pad_size"]) {
+	case "L":
+		features |= FeatLargePad
+	case "M":
+		features |= FeatMediumPad
+	case "S":
+		features |= FeatSmallPad
+	}
+	item.Features = uint32(features)
+	item.PlanetaryLanding = features&FeatPlanetary != 0
+	return r.apply(item)
+}
+
+func (r *tdReader) readStationItem(row []string) error {
+	systemName, stationName, itemName := tdUnquote(row[0]), tdUnquote(row[1]), tdUnquote(row[2])
+	system := r.sdb.GetSystem(systemName)
+	if system == nil {
+		return fmt.Errorf("%w: system for station item: %s", ErrUnknownEntity, systemName)
+	}
+	facility := system.GetFacility(stationName)
+	if facility == nil {
+		return fmt.Errorf("%w: station for station item: %s/%s", ErrUnknownEntity, systemName, stationName)
+	}
+	commodity := r.sdb.GetCommodity(itemName)
+	if commodity == nil {
+		return fmt.Errorf("%w: item for station item: %s", ErrUnknownEntity, itemName)
+	}
+
+	numbers := make([]uint32, 0, 4)
+	for _, idx := range []int{3, 4, 6, 7} {
+		value, err := strconv.ParseUint(row[idx], 10, 32)
+		if err != nil {
+			return fmt.Errorf("station item %s/%s/%s: invalid value: %s", systemName, stationName, itemName, row[idx])
+		}
+		numbers = append(numbers, uint32(value))
+	}
+	demandBracket, err := parseTDLevel(row[5])
+	if err != nil {
+		return err
+	}
+	supplyBracket, err := parseTDLevel(row[8])
+	if err != nil {
+		return err
+	}
+	timestamp, err := parseTDTime(row[9])
+	if err != nil {
+		return err
+	}
+
+	listing, exists := r.listings[facility.ID]
+	if !exists {
+		listing = &gom.FacilityListing{Id: uint32(facility.ID)}
+		r.listings[facility.ID] = listing
+	}
+	listing.Listings = append(listing.Listings, &gom.CommodityListing{
+		CommodityId:   uint32(commodity.ID),
+		DemandCredits: numbers[0],
+		DemandUnits:   numbers[1],
+		DemandBracket: demandBracket,
+		SupplyCredits: numbers[2],
+		SupplyUnits:   numbers[3],
+		SupplyBracket: supplyBracket,
+		TimestampUtc:  timestamp,
+	})
+	return nil
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	gom "github.com/kfsone/gomenacing/pkg/gomschema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func openTDTestDatabase(t *testing.T, testDir TestDir, name string) (*SystemDatabase, *Database) {
+	db, err := OpenDatabase(testDir.Path(), name)
+	require.Nil(t, err)
+	return NewSystemDatabase(db), db
+}
+
+func Test_tdQuoting(t *testing.T) {
+	assert.Equal(t, "'Smith''s Base'", tdQuote("Smith's Base"))
+	assert.Equal(t, "Smith's Base", tdUnquote("'Smith''s Base'"))
+	assert.Equal(t, "Sol", tdUnquote(`"Sol"`))
+	assert.Equal(t, "42", tdUnquote("42"))
+
+	for bracket := range gom.MarketBracket_name {
+		level, err := parseTDLevel(tdLevel(gom.MarketBracket(bracket)))
+		assert.Nil(t, err)
+		assert.EqualValues(t, bracket, level)
+	}
+	assert.Equal(t, "-1", tdLevel(gom.MarketBracket_BracketUnknown))
+	_, err := parseTDLevel("4")
+	assert.Error(t, err)
+
+	assert.Equal(t, gom.Commodity_CatConsumerItems, parseTDCategory("consumer items"))
+	assert.Equal(t, gom.Commodity_CatUnknown, parseTDCategory("Space Junk"))
+}
+
+func TestSystemDatabase_TD(t *testing.T) {
+	testDir := GetTestDir()
+	defer testDir.Close()
+
+	sdb, db := openTDTestDatabase(t, testDir, "source.db")
+	writer := newMessageWriter(sdb, db, SourceTradeDangerous)
+	for _, message := range []proto.Message{
+		&gom.Commodity{Id: 1, Name: "Gold", CategoryId: gom.Commodity_CatMetals, AverageCr: 9401},
+		&gom.Commodity{Id: 2, Name: "Mineral Oil", CategoryId: gom.Commodity_CatChemicals, AverageCr: 180},
+		&gom.System{Id: 1, Name: "Sol", TimestampUtc: 1596283200, Position: &gom.Coordinate{}},
+		&gom.System{Id: 2, Name: "Alpha Centauri", TimestampUtc: 1596283200, Position: &gom.Coordinate{X: 3.03125, Y: -0.09375, Z: 3.15625}},
+		&gom.Facility{Id: 1, SystemId: 1, Name: "Galileo", TimestampUtc: 1596283200, LsFromStar: 505, FacilityType: gom.FacilityType_FTOcellusStarport,
+			Features: uint32(FeatLargePad | FeatMarket | FeatCommodities | FeatBlackMarket | FeatShipyard | FeatRefuel)},
+		&gom.Facility{Id: 2, SystemId: 2, Name: "Hutton's Orbital", TimestampUtc: 1596283200, LsFromStar: 6784404, FacilityType: gom.FacilityType_FTPlanetaryPort,
+			Features: uint32(FeatSmallPad | FeatPlanetary)},
+		// A market only small ships can reach mustn't pass for one with medium pads.
+		&gom.Facility{Id: 3, SystemId: 2, Name: "Worlidge Terminal", TimestampUtc: 1596283200, LsFromStar: 120, FacilityType: gom.FacilityType_FTCommercialOutpost,
+			Features: uint32(FeatSmallPad | FeatMarket | FeatCommodities)},
+		&gom.FacilityListing{Id: 1, Listings: []*gom.CommodityListing{
+			{CommodityId: 1, DemandCredits: 9400, DemandUnits: 1200, DemandBracket: gom.MarketBracket_BracketHigh,
+				SupplyBracket: gom.MarketBracket_BracketNone, TimestampUtc: 1596283200},
+			{CommodityId: 2, SupplyCredits: 150, SupplyUnits: 5000, SupplyBracket: gom.MarketBracket_BracketMedium, TimestampUtc: 1596283100},
+		}},
+	} {
+		require.Nil(t, writer.apply(message))
+	}
+	writer.Close()
+
+	dir := filepath.Join(testDir.Path(), "td")
+	tables, err := sdb.ExportTD(dir)
+	require.Nil(t, err)
+	assert.Equal(t, []TDTable{{"System.csv", 2}, {"Item.csv", 2}, {"Station.csv", 3}, {"StationItem.csv", 2}}, tables)
+	stations, err := ioutil.ReadFile(filepath.Join(dir, "Station.csv"))
+	require.Nil(t, err)
+	assert.Contains(t, string(stations), "'ALPHA CENTAURI','Hutton''s Orbital',6784404,'N','S','N','N','2020-08-01 12:00:00','N','N','N','N','Y',14,'N'\n")
+	assert.Contains(t, string(stations), "'ALPHA CENTAURI','Worlidge Terminal',120,'N','S','Y',")
+	assert.Contains(t, string(stations), "'SOL','Galileo',505,'Y','L','Y','Y','2020-08-01 12:00:00','N','N','Y','N','N',7,'N'\n")
+
+	// Importing into an empty database recreates everything.
+	copied, copiedDb := openTDTestDatabase(t, testDir, "copy.db")
+	tables, err = copied.ImportTD(dir, copiedDb)
+	require.Nil(t, err)
+	assert.Equal(t, []TDTable{{"System.csv", 2}, {"Item.csv", 2}, {"Station.csv", 3}, {"StationItem.csv", 2}}, tables)
+
+	system := copied.GetSystem("alpha centauri")
+	require.NotNil(t, system)
+	assert.Equal(t, sdb.GetSystem("Alpha Centauri").Position(), system.Position())
+	assert.Equal(t, gom.Commodity_CatChemicals, copied.GetCommodity("Mineral Oil").CategoryID)
+	assert.EqualValues(t, 180, copied.GetCommodity("Mineral Oil").AverageCr)
+	for _, original := range sdb.facilitiesByID {
+		facility := copied.GetSystem(original.System.DbName).GetFacility(original.DbName)
+		require.NotNil(t, facility, original.DbName)
+		assert.Equal(t, original.Features, facility.Features, original.DbName)
+		assert.Equal(t, original.FacilityType, facility.FacilityType, original.DbName)
+		assert.Equal(t, original.LsFromStar, facility.LsFromStar, original.DbName)
+		assert.Equal(t, len(original.listings), len(facility.listings), original.DbName)
+	}
+	galileo := copied.GetSystem("Sol").GetFacility("Galileo")
+	gold := copied.GetCommodity("Gold")
+	assert.Equal(t, Listing{CommodityID: gold.ID, StationPays: 9400, Demand: 1200, DemandBracket: gom.MarketBracket_BracketHigh,
+		SupplyBracket: gom.MarketBracket_BracketNone, TimestampUtc: 1596283200}, *galileo.listings[gold.ID])
+	copiedDb.Close()
+
+	// Re-importing onto the same data changes nothing, and TradeDangerous' own
+	// quoted headings are understood.
+	for _, table := range tdTables {
+		pathname := filepath.Join(dir, table.filename)
+		data, err := ioutil.ReadFile(pathname)
+		require.Nil(t, err)
+		lines := strings.SplitN(string(data), "\n", 2)
+		quoted := "'" + strings.ReplaceAll(lines[0], ",", "','") + "'"
+		require.Nil(t, ioutil.WriteFile(pathname, []byte(quoted+"\n"+lines[1]), 0644))
+	}
+	tables, err = sdb.ImportTD(dir, db)
+	require.Nil(t, err)
+	assert.Len(t, tables, 4)
+	assert.Len(t, sdb.systemsByID, 2)
+	assert.Len(t, sdb.facilitiesByID, 3)
+	db.Close()
+}
+
+func TestRepl_TD(t *testing.T) {
+	testDir := GetTestDir()
+	defer testDir.Close()
+	sdb, db := openTDTestDatabase(t, testDir, "repl.db")
+	defer db.Close()
+
+	var output bytes.Buffer
+	repl := &Repl{db: db, sdb: sdb, out: &output}
+	dir := filepath.Join(testDir.Path(), "td")
+	cmdExportTD(repl, []string{dir}, nil)
+	assert.Contains(t, output.String(), "- StationItem.csv: 0 rows")
+
+	output.Reset()
+	cmdImport(repl, []string{"td", dir}, nil)
+	assert.Contains(t, output.String(), "- System.csv: 0 rows")
+
+	output.Reset()
+	cmdImport(repl, []string{"td", testDir.Path()}, nil)
+	assert.Contains(t, output.String(), "Nothing to import.")
+}
@@ -1,5 +1,13 @@
 package main
 
+import (
+	"sort"
+	"strings"
+	"time"
+
+	gom "github.com/kfsone/gomenacing/pkg/gomschema"
+)
+
 // TradeOutcome represents the purchase and up-sale of a commodity, intended
 // to be itemized as part of a TradeHop (facility -> facility).
 type TradeOutcome struct {
@@ -12,13 +20,142 @@ type TradeOutcome struct {
 	// Supply indicates how many units the selling facility is expected to have.
 	Supply int
 	// SupplyLevel indicates how actively the selling facility stocks this item.
-	SupplyLevel int
+	SupplyLevel gom.MarketBracket
 	// Demand indicates how many units the purchasing facility is expected to want.
 	Demand int
 	// DemandLevel indicates how actively the purchasing facility is acquiring this item.
-	DemandLevel int
+	DemandLevel gom.MarketBracket
 	// SrcAge is how old in seconds the seller's data was when this outcome was calculated.
 	SrcAge int
 	// DstAge is how old in seconds the buyer's data was when this outcome was calculated.
 	DstAge int
+	// Illegal is true if the commodity is contraband where it is to be sold.
+	Illegal bool
+	// Risk is the chance of being caught carrying the commodity to the buyer.
+	Risk LegalityRisk
+	// TravelTime is how long it takes to get from the seller to the buyer, if known.
+	TravelTime time.Duration
+}
+
+func ageAt(timestamp, now uint64) int {
+	if timestamp >= now {
+		return 0
+	}
+	return int(now - timestamp)
+}
+
+// NewTradeOutcome describes buying a commodity per the seller's listing and
+// selling it per the buyer's, as of now (unix time). Returns nil if the
+// seller isn't selling, the buyer isn't buying, or there is no profit in it.
+func NewTradeOutcome(commodity *Commodity, seller, buyer *Listing, now uint64) *TradeOutcome {
+	if seller.StationAsks == 0 || buyer.StationPays <= seller.StationAsks {
+		return nil
+	}
+	if seller.SupplyBracket == gom.MarketBracket_BracketNone || buyer.DemandBracket == gom.MarketBracket_BracketNone {
+		return nil
+	}
+	return &TradeOutcome{
+		Commodity:   commodity,
+		CostCr:      int64(seller.StationAsks),
+		GainCr:      int64(buyer.StationPays) - int64(seller.StationAsks),
+		Supply:      int(seller.Supply),
+		SupplyLevel: seller.SupplyBracket,
+		Demand:      int(buyer.Demand),
+		DemandLevel: buyer.DemandBracket,
+		SrcAge:      ageAt(seller.TimestampUtc, now),
+		DstAge:      ageAt(buyer.TimestampUtc, now),
+	}
+}
+
+// FindTradeOutcomes lists the profitable trades from src to dst, best first
+// for a load of the given number of units, ignoring stale listings.
+func (sdb *SystemDatabase) FindTradeOutcomes(src, dst *Facility, units int, now uint64) []*TradeOutcome {
+	outcomes := make([]*TradeOutcome, 0, len(src.listings))
+	travelTime := sdb.travel.Estimate(src, dst).Total()
+	for commodityID, seller := range src.listings {
+		buyer, exists := dst.listings[commodityID]
+		if !exists || sdb.isStale(seller.TimestampUtc, now) || sdb.isStale(buyer.TimestampUtc, now) {
+			continue
+		}
+		commodity := sdb.GetCommodityByID(commodityID)
+		if commodity == nil {
+			continue
+		}
+		if outcome := NewTradeOutcome(commodity, seller, buyer, now); outcome != nil {
+			sdb.assessLegality(outcome, dst)
+			outcome.TravelTime = travelTime
+			outcomes = append(outcomes, outcome)
+		}
+	}
+	RankTradeOutcomes(outcomes, units)
+	return outcomes
+}
+
+// bracketWeight reflects how well a price will hold up as it is traded into:
+// a high-demand market will keep paying, a low one crashes after a few tons.
+func bracketWeight(bracket gom.MarketBracket) float64 {
+	switch bracket {
+	case gom.MarketBracket_BracketHigh:
+		return 1.0
+	case gom.MarketBracket_BracketMedium:
+		return 0.8
+	case gom.MarketBracket_BracketLow:
+		return 0.5
+	case gom.MarketBracket_BracketNone:
+		return 0.2
+	default:
+		// Unknown: assume it's middling.
+		return 0.7
+	}
+}
+
+// bracketName is how a supply or demand bracket is shown to people.
+func bracketName(bracket gom.MarketBracket) string {
+	return strings.ToLower(strings.TrimPrefix(bracket.String(), "Bracket"))
+}
+
+// TradableUnits is how many of units can be traded, given the known supply and demand.
+func (t *TradeOutcome) TradableUnits(units int) int {
+	if t.Supply > 0 && t.Supply < units {
+		units = t.Supply
+	}
+	if t.Demand > 0 && t.Demand < units {
+		units = t.Demand
+	}
+	return units
+}
+
+// Score estimates the credits a load of units will earn, discounted by the
+// supply and demand brackets and the legality risk. Demand carries the most
+// weight, since it's the sale price that collapses when a low-demand market is
+// flooded.
+func (t *TradeOutcome) Score(units int) float64 {
+	weight := bracketWeight(t.DemandLevel) * (0.5 + 0.5*bracketWeight(t.SupplyLevel)) * riskWeight(t.Risk)
+	return float64(t.GainCr) * float64(t.TradableUnits(units)) * weight
+}
+
+// CreditsPerHour is the Score for a load of units over the travel time, or
+// zero if the travel time isn't known.
+func (t *TradeOutcome) CreditsPerHour(units int) float64 {
+	if t.TravelTime <= 0 {
+		return 0
+	}
+	return t.Score(units) / t.TravelTime.Hours()
+}
+
+// rate is what outcomes are ranked by: credits per hour where travel times
+// are known, so a lucrative trade to a station deep in a system can lose to
+// a lesser one next to the star, discounted by the confidence in the data.
+func (t *TradeOutcome) rate(units int) float64 {
+	if t.TravelTime <= 0 {
+		return t.Score(units) * t.Confidence()
+	}
+	return t.CreditsPerHour(units) * t.Confidence()
+}
+
+// RankTradeOutcomes sorts outcomes best first for a load of the given number of units.
+func RankTradeOutcomes(outcomes []*TradeOutcome, units int) {
+	sort.SliceStable(outcomes, func(i, j int) bool {
+		return outcomes[i].rate(units) > outcomes[j].rate(units)
+	})
 }
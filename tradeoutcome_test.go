@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	gom "github.com/kfsone/gomenacing/pkg/gomschema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewTradeOutcome(t *testing.T) {
+	commodity := &Commodity{DbEntity: DbEntity{ID: 1, DbName: "Gold"}}
+	seller := &Listing{CommodityID: 1, Supply: 500, StationAsks: 9000, TimestampUtc: 1000, SupplyBracket: gom.MarketBracket_BracketMedium}
+	buyer := &Listing{CommodityID: 1, Demand: 200, StationPays: 10500, TimestampUtc: 1900, DemandBracket: gom.MarketBracket_BracketHigh}
+
+	outcome := NewTradeOutcome(commodity, seller, buyer, 2000)
+	require.NotNil(t, outcome)
+	assert.Equal(t, &TradeOutcome{
+		Commodity:   commodity,
+		CostCr:      9000,
+		GainCr:      1500,
+		Supply:      500,
+		SupplyLevel: gom.MarketBracket_BracketMedium,
+		Demand:      200,
+		DemandLevel: gom.MarketBracket_BracketHigh,
+		SrcAge:      1000,
+		DstAge:      100,
+	}, outcome)
+
+	// Reversed, there's no profit.
+	assert.Nil(t, NewTradeOutcome(commodity, buyer, seller, 2000))
+
+	// A buyer with no demand isn't a buyer.
+	noDemand := *buyer
+	noDemand.DemandBracket = gom.MarketBracket_BracketNone
+	assert.Nil(t, NewTradeOutcome(commodity, seller, &noDemand, 2000))
+}
+
+func TestTradeOutcome_TradableUnits(t *testing.T) {
+	outcome := TradeOutcome{}
+	assert.Equal(t, 100, outcome.TradableUnits(100))
+	outcome.Supply = 60
+	assert.Equal(t, 60, outcome.TradableUnits(100))
+	outcome.Demand = 20
+	assert.Equal(t, 20, outcome.TradableUnits(100))
+	assert.Equal(t, 10, outcome.TradableUnits(10))
+}
+
+func TestRankTradeOutcomes(t *testing.T) {
+	// A slightly better price into a low demand market should lose out to a
+	// high demand market.
+	lowDemand := &TradeOutcome{GainCr: 1200, DemandLevel: gom.MarketBracket_BracketLow, SupplyLevel: gom.MarketBracket_BracketHigh}
+	highDemand := &TradeOutcome{GainCr: 1000, DemandLevel: gom.MarketBracket_BracketHigh, SupplyLevel: gom.MarketBracket_BracketHigh}
+	unknown := &TradeOutcome{GainCr: 1100}
+	limited := &TradeOutcome{GainCr: 5000, Demand: 10, DemandLevel: gom.MarketBracket_BracketHigh, SupplyLevel: gom.MarketBracket_BracketHigh}
+
+	outcomes := []*TradeOutcome{lowDemand, unknown, limited, highDemand}
+	RankTradeOutcomes(outcomes, 100)
+	assert.Equal(t, []*TradeOutcome{highDemand, unknown, lowDemand, limited}, outcomes)
+
+	// With a small hold, the limited demand doesn't matter.
+	RankTradeOutcomes(outcomes, 8)
+	assert.Equal(t, limited, outcomes[0])
+}
+
+func TestSystemDatabase_FindTradeOutcomes(t *testing.T) {
+	sdb := NewSystemDatabase(nil)
+	require.Nil(t, sdb.newCommodity(&gom.Commodity{Id: 1, Name: "Gold", CategoryId: gom.Commodity_CatMetals}))
+	require.Nil(t, sdb.newCommodity(&gom.Commodity{Id: 2, Name: "Silver", CategoryId: gom.Commodity_CatMetals}))
+	require.Nil(t, sdb.newCommodity(&gom.Commodity{Id: 3, Name: "Tea", CategoryId: gom.Commodity_CatFoods}))
+	require.Nil(t, sdb.newSystem(&gom.System{Id: 1, Name: "Sol", Position: &gom.Coordinate{}}))
+	require.Nil(t, sdb.newFacility(&gom.Facility{Id: 1, SystemId: 1, Name: "Src"}))
+	require.Nil(t, sdb.newFacility(&gom.Facility{Id: 2, SystemId: 1, Name: "Dst"}))
+	require.Nil(t, sdb.newListings(&gom.FacilityListing{Id: 1, Listings: []*gom.CommodityListing{
+		{CommodityId: 1, SupplyUnits: 100, SupplyCredits: 9000, SupplyBracket: gom.MarketBracket_BracketHigh},
+		{CommodityId: 2, SupplyUnits: 100, SupplyCredits: 4000, SupplyBracket: gom.MarketBracket_BracketHigh},
+		{CommodityId: 3, SupplyUnits: 100, SupplyCredits: 1000, SupplyBracket: gom.MarketBracket_BracketHigh},
+	}}))
+	require.Nil(t, sdb.newListings(&gom.FacilityListing{Id: 2, Listings: []*gom.CommodityListing{
+		{CommodityId: 1, DemandUnits: 100, DemandCredits: 10000, DemandBracket: gom.MarketBracket_BracketLow},
+		{CommodityId: 2, DemandUnits: 100, DemandCredits: 4900, DemandBracket: gom.MarketBracket_BracketHigh},
+		{CommodityId: 3, DemandUnits: 100, DemandCredits: 900, DemandBracket: gom.MarketBracket_BracketHigh},
+	}}))
+
+	outcomes := sdb.FindTradeOutcomes(sdb.GetFacilityByID(1), sdb.GetFacilityByID(2), 50, 0)
+	require.Len(t, outcomes, 2)
+	// Gold pays more per ton, but into a low demand market.
+	assert.Equal(t, "Silver", outcomes[0].Commodity.DbName)
+	assert.Equal(t, "Gold", outcomes[1].Commodity.DbName)
+
+	var output bytes.Buffer
+	repl := &Repl{sdb: sdb, out: &output}
+	cmdTrade(repl, strings.Fields("Sol/Src to Sol/Dst"), nil)
+	lines := strings.Split(output.String(), "\n")
+	require.Len(t, lines, 4)
+	assert.Equal(t, fmt.Sprintf("Sol/Src -> Sol/Dst, %d tons:", defaultCargoUnits), lines[0])
+	listed := strings.Join(lines[1:3], "\n")
+	assert.Contains(t, listed, "Silver")
+	assert.Contains(t, listed, "supply high, demand high")
+	assert.Contains(t, listed, "Gold")
+	assert.NotContains(t, listed, "Tea")
+
+	output.Reset()
+	cmdTrade(repl, strings.Fields("Sol/Dst to Sol/Src"), nil)
+	assert.Equal(t, "Nothing to trade from Sol/Dst to Sol/Src.\n", output.String())
+}
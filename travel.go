@@ -0,0 +1,124 @@
+package main
+
+import (
+	"math"
+	"time"
+
+	gom "github.com/kfsone/gomenacing/pkg/gomschema"
+	flag "github.com/spf13/pflag"
+)
+
+// JumpRange is the ship's laden jump range, used for travel time estimates.
+var JumpRange = flag.Float64("jumprange", 20, "Laden jump range of your ship in ly, for travel time estimates.")
+
+const (
+	// hyperspaceTime covers charging, jumping and lining up for the next jump.
+	hyperspaceTime = 45 * time.Second
+	// unknownLsFromStar is assumed for facilities whose distance isn't known.
+	unknownLsFromStar = 1000
+)
+
+// TravelModel estimates how long it takes to get between facilities.
+type TravelModel struct {
+	JumpRangeLy float64
+}
+
+// DefaultTravelModel is the model configured by the command line.
+func DefaultTravelModel() TravelModel {
+	return TravelModel{JumpRangeLy: *JumpRange}
+}
+
+// TravelTime breaks down the time taken to travel to a facility.
+type TravelTime struct {
+	Jumps       int
+	Hyperspace  time.Duration
+	Supercruise time.Duration
+	Docking     time.Duration
+}
+
+// Total is the end-to-end travel time.
+func (t TravelTime) Total() time.Duration {
+	return t.Hyperspace + t.Supercruise + t.Docking
+}
+
+// Jumps is how many hyperspace jumps it takes to cover distanceLy.
+func (m TravelModel) Jumps(distanceLy float64) int {
+	if distanceLy <= 0 {
+		return 0
+	}
+	if m.JumpRangeLy <= 0 {
+		return 1
+	}
+	return int(math.Ceil(distanceLy / m.JumpRangeLy))
+}
+
+// SupercruiseTime estimates the time to reach a facility lsFromStar from the
+// arrival star. Ships accelerate with distance, so it grows far slower than
+// the distance does: 1,000ls takes about a minute and a half, 1,000,000ls
+// about 25 minutes.
+func SupercruiseTime(lsFromStar uint32) time.Duration {
+	if lsFromStar == 0 {
+		lsFromStar = unknownLsFromStar
+	}
+	return supercruiseTime(float64(lsFromStar))
+}
+
+func supercruiseTime(ls float64) time.Duration {
+	seconds := 10 + 5.5*math.Pow(ls, 0.4)
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// DockingTime is the overhead of requesting docking and landing at a facility
+// of the given type.
+func DockingTime(facilityType gom.FacilityType) time.Duration {
+	switch facilityType {
+	case gom.FacilityType_FTPlanetaryOutpost, gom.FacilityType_FTPlanetaryPort, gom.FacilityType_FTPlanetarySettlement:
+		// Glide, descent and a walk-in landing pad.
+		return 4 * time.Minute
+	case gom.FacilityType_FTCivilianOutpost, gom.FacilityType_FTCommercialOutpost, gom.FacilityType_FTIndustrialOutpost,
+		gom.FacilityType_FTMilitaryOutpost, gom.FacilityType_FTMiningOutpost, gom.FacilityType_FTScientificOutpost,
+		gom.FacilityType_FTFleetCarrier:
+		// Open pads, no mail slot.
+		return 75 * time.Second
+	default:
+		return 2 * time.Minute
+	}
+}
+
+// UndockingTime is the overhead of launching and getting clear of a facility.
+func UndockingTime(facilityType gom.FacilityType) time.Duration {
+	switch facilityType {
+	case gom.FacilityType_FTPlanetaryOutpost, gom.FacilityType_FTPlanetaryPort, gom.FacilityType_FTPlanetarySettlement:
+		// Climbing out of the gravity well.
+		return 2 * time.Minute
+	case gom.FacilityType_FTCivilianOutpost, gom.FacilityType_FTCommercialOutpost, gom.FacilityType_FTIndustrialOutpost,
+		gom.FacilityType_FTMilitaryOutpost, gom.FacilityType_FTMiningOutpost, gom.FacilityType_FTScientificOutpost,
+		gom.FacilityType_FTFleetCarrier:
+		return 30 * time.Second
+	default:
+		return time.Minute
+	}
+}
+
+// Arrive estimates the time to get from a system to docked at facility.
+func (m TravelModel) Arrive(from *System, to *Facility) TravelTime {
+	jumps := m.Jumps(Distance(from, to.System).Root())
+	return TravelTime{
+		Jumps:       jumps,
+		Hyperspace:  time.Duration(jumps) * hyperspaceTime,
+		Supercruise: SupercruiseTime(to.LsFromStar),
+		Docking:     DockingTime(to.FacilityType),
+	}
+}
+
+// Estimate estimates the time to get from docked at src to docked at dst.
+// Travel within a system is by supercruise from src rather than the star, so
+// the leg is the difference in distances, when they're known.
+func (m TravelModel) Estimate(src, dst *Facility) TravelTime {
+	travel := m.Arrive(src.System, dst)
+	if src.System == dst.System && src.LsFromStar != 0 && dst.LsFromStar != 0 {
+		travel.Supercruise = supercruiseTime(math.Abs(float64(dst.LsFromStar) - float64(src.LsFromStar)))
+	}
+	travel.Docking += UndockingTime(src.FacilityType)
+	return travel
+}
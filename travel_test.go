@@ -0,0 +1,72 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	gom "github.com/kfsone/gomenacing/pkg/gomschema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTravelModel_Jumps(t *testing.T) {
+	model := TravelModel{JumpRangeLy: 20}
+	assert.Equal(t, 0, model.Jumps(0))
+	assert.Equal(t, 1, model.Jumps(4.4))
+	assert.Equal(t, 1, model.Jumps(20))
+	assert.Equal(t, 2, model.Jumps(20.1))
+}
+
+func TestSupercruiseTime(t *testing.T) {
+	// Non-linear: a thousand times further is nowhere near a thousand times longer.
+	near, far := SupercruiseTime(1000), SupercruiseTime(1000000)
+	assert.InDelta(t, 97, near.Seconds(), 1)
+	assert.InDelta(t, 1391, far.Seconds(), 1)
+	assert.Equal(t, near, SupercruiseTime(0), "unknown distances are assumed to be 1000ls")
+}
+
+func TestDockingTime(t *testing.T) {
+	assert.True(t, DockingTime(gom.FacilityType_FTPlanetaryPort) > DockingTime(gom.FacilityType_FTCoriolisStarport))
+	assert.True(t, DockingTime(gom.FacilityType_FTCoriolisStarport) > DockingTime(gom.FacilityType_FTCivilianOutpost))
+	assert.True(t, UndockingTime(gom.FacilityType_FTPlanetaryPort) > UndockingTime(gom.FacilityType_FTOrbisStarport))
+}
+
+func TestTravelModel_Estimate(t *testing.T) {
+	sol := &System{DbEntity: DbEntity{ID: 1, DbName: "Sol"}}
+	lhs := &System{DbEntity: DbEntity{ID: 2, DbName: "LHS 3447"}, position: Coordinate{X: 30}}
+	galileo := &Facility{System: sol, FacilityType: gom.FacilityType_FTOcellusStarport, LsFromStar: 500}
+	daedalus := &Facility{System: sol, FacilityType: gom.FacilityType_FTOrbisStarport, LsFromStar: 200}
+	bluford := &Facility{System: lhs, FacilityType: gom.FacilityType_FTPlanetaryPort, LsFromStar: 1000}
+
+	model := TravelModel{JumpRangeLy: 20}
+	travel := model.Estimate(galileo, bluford)
+	assert.Equal(t, 2, travel.Jumps)
+	assert.Equal(t, 90*time.Second, travel.Hyperspace)
+	assert.Equal(t, SupercruiseTime(1000), travel.Supercruise)
+	assert.Equal(t, DockingTime(gom.FacilityType_FTPlanetaryPort)+UndockingTime(gom.FacilityType_FTOcellusStarport), travel.Docking)
+	assert.Equal(t, travel.Hyperspace+travel.Supercruise+travel.Docking, travel.Total())
+
+	// Within a system there's no jump, and supercruise is from station to station.
+	travel = model.Estimate(galileo, daedalus)
+	assert.Equal(t, 0, travel.Jumps)
+	assert.Equal(t, supercruiseTime(300), travel.Supercruise)
+}
+
+func TestTradeOutcome_CreditsPerHour(t *testing.T) {
+	sol := &System{DbEntity: DbEntity{ID: 1, DbName: "Sol"}}
+	galileo := &Facility{System: sol, FacilityType: gom.FacilityType_FTOcellusStarport, LsFromStar: 500}
+	distant := &Facility{System: sol, FacilityType: gom.FacilityType_FTOrbisStarport, LsFromStar: 2000000}
+	nearby := &Facility{System: sol, FacilityType: gom.FacilityType_FTOrbisStarport, LsFromStar: 600}
+	model := TravelModel{JumpRangeLy: 20}
+
+	assert.Equal(t, 0.0, (&TradeOutcome{GainCr: 5000}).CreditsPerHour(10))
+
+	// A 5000cr/ton trade 2 million ls out loses to a 3000cr trade near the star.
+	lucrative := &TradeOutcome{GainCr: 5000, TravelTime: model.Estimate(galileo, distant).Total()}
+	modest := &TradeOutcome{GainCr: 3000, TravelTime: model.Estimate(galileo, nearby).Total()}
+	assert.True(t, lucrative.Score(100) > modest.Score(100))
+	outcomes := []*TradeOutcome{lucrative, modest}
+	RankTradeOutcomes(outcomes, 100)
+	require.Equal(t, modest, outcomes[0])
+	assert.True(t, modest.CreditsPerHour(100) > lucrative.CreditsPerHour(100))
+}